@@ -0,0 +1,240 @@
+// Package anonymize masks fields of documents, by hashing, redacting or replacing them
+// with fake values, so production data can be exported or copied to staging safely.
+//
+// See docs/anonymize.md for detailed usage guide and examples.
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Masker returns the masked value of a field. Null values are never passed to a
+// Masker and stay null.
+type Masker func(value bson.RawValue) (any, error)
+
+// removed is returned by the Masker of Remove.
+type removed struct{}
+
+// Rules maps dotted field paths to the Masker applied to them. A path through an array
+// applies to the matching field of every element, e.g. "contacts.email".
+type Rules map[string]Masker
+
+// Apply returns doc with the fields of the rules masked. Fields without a rule, and
+// rules whose field is missing, are left unchanged. Its signature matches the
+// Transform hooks of export.Options and mongo_kit.CopyOptions.
+//
+// Example:
+//
+//	rules := anonymize.Rules{
+//	    "email":          anonymize.FakeEmail(key),
+//	    "name":           anonymize.FakeName(key),
+//	    "ssn":            anonymize.Redact("***"),
+//	    "customer_id":    anonymize.Hash(key),
+//	    "notes":          anonymize.Remove(),
+//	    "contacts.phone": anonymize.FakePhone(key),
+//	}
+//	masked, err := rules.Apply(doc)
+func (r Rules) Apply(doc bson.Raw) (bson.Raw, error) {
+	if len(r) == 0 {
+		return doc, nil
+	}
+	masked, err := r.maskDocument(doc, "")
+	if err != nil {
+		return nil, err
+	}
+	data, err := bson.Marshal(masked)
+	if err != nil {
+		return nil, fmt.Errorf("anonymize: encode document: %w", err)
+	}
+	return data, nil
+}
+
+// maskDocument returns the elements of doc, at path prefix, with their rules applied.
+func (r Rules) maskDocument(doc bson.Raw, prefix string) (bson.D, error) {
+	elems, err := doc.Elements()
+	if err != nil {
+		return nil, fmt.Errorf("anonymize: read document: %w", err)
+	}
+
+	out := make(bson.D, 0, len(elems))
+	for _, elem := range elems {
+		path := prefix + elem.Key()
+		value, keep, err := r.maskValue(elem.Value(), path)
+		if err != nil {
+			return nil, err
+		}
+		if keep {
+			out = append(out, bson.E{Key: elem.Key(), Value: value})
+		}
+	}
+	return out, nil
+}
+
+// maskValue returns value, at path, with its rules applied, and false if it is removed.
+func (r Rules) maskValue(value bson.RawValue, path string) (any, bool, error) {
+	if mask, ok := r[path]; ok {
+		if value.Type == bsontype.Null || value.Type == bsontype.Undefined {
+			return value, true, nil
+		}
+		masked, err := mask(value)
+		if err != nil {
+			return nil, false, fmt.Errorf("anonymize: mask %s: %w", path, err)
+		}
+		if _, ok := masked.(removed); ok {
+			return nil, false, nil
+		}
+		return masked, true, nil
+	}
+
+	if !r.hasRuleUnder(path) {
+		return value, true, nil
+	}
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		masked, err := r.maskDocument(value.Document(), path+".")
+		return masked, true, err
+	case bsontype.Array:
+		values, err := value.Array().Values()
+		if err != nil {
+			return nil, false, fmt.Errorf("anonymize: read %s: %w", path, err)
+		}
+		masked := make(bson.A, 0, len(values))
+		for _, v := range values {
+			// Rules apply to the fields of the elements, not to the array indexes.
+			m, keep, err := r.maskValue(v, path)
+			if err != nil {
+				return nil, false, err
+			}
+			if keep {
+				masked = append(masked, m)
+			}
+		}
+		return masked, true, nil
+	}
+	return value, true, nil
+}
+
+// hasRuleUnder reports whether a rule applies to a field nested under path.
+func (r Rules) hasRuleUnder(path string) bool {
+	for rule := range r {
+		if strings.HasPrefix(rule, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// Remove returns a Masker that deletes the field.
+func Remove() Masker {
+	return func(bson.RawValue) (any, error) {
+		return removed{}, nil
+	}
+}
+
+// Redact returns a Masker that replaces the value with replacement.
+//
+// Example:
+//
+//	rules := anonymize.Rules{"ssn": anonymize.Redact("***-**-****")}
+func Redact(replacement any) Masker {
+	return func(bson.RawValue) (any, error) {
+		return replacement, nil
+	}
+}
+
+// Hash returns a Masker that replaces the value with the hex HMAC-SHA256 of its
+// Extended JSON representation (the text itself for strings), keyed by key. Equal
+// values hash equally, so references between collections survive, but without key the
+// original values cannot be recovered by hashing guesses.
+//
+// Example:
+//
+//	rules := anonymize.Rules{"customer_id": anonymize.Hash([]byte(os.Getenv("ANONYMIZE_KEY")))}
+func Hash(key []byte) Masker {
+	return func(value bson.RawValue) (any, error) {
+		text, err := valueText(value)
+		if err != nil {
+			return nil, err
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(text))
+		return hex.EncodeToString(mac.Sum(nil)), nil
+	}
+}
+
+// Fake returns a Masker that replaces the value with one generated by gen. The random
+// source is seeded from the HMAC-SHA256 of the original value, keyed by key, so equal
+// values are replaced by equal fake values across documents and runs, while fake values
+// cannot be traced back to candidate originals without key.
+//
+// Example:
+//
+//	plans := []string{"free", "pro", "team"}
+//	rules := anonymize.Rules{"plan": anonymize.Fake(key, func(r *rand.Rand) any {
+//	    return plans[r.IntN(len(plans))]
+//	})}
+func Fake(key []byte, gen func(r *rand.Rand) any) Masker {
+	return func(value bson.RawValue) (any, error) {
+		text, err := valueText(value)
+		if err != nil {
+			return nil, err
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(text))
+		sum := mac.Sum(nil)
+		seed := rand.NewPCG(binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16]))
+		return gen(rand.New(seed)), nil
+	}
+}
+
+var (
+	firstNames = []string{"Alex", "Blake", "Casey", "Dana", "Eden", "Frankie", "Gray", "Harper", "Indy", "Jordan", "Kai", "Logan", "Morgan", "Noel", "Oakley", "Parker", "Quinn", "Riley", "Sage", "Taylor"}
+	lastNames  = []string{"Adams", "Brooks", "Carter", "Diaz", "Ellis", "Foster", "Garcia", "Hayes", "Iverson", "Jensen", "Kim", "Lopez", "Miller", "Nguyen", "Owens", "Patel", "Reyes", "Silva", "Turner", "Walsh"}
+)
+
+// FakeName returns a Masker that replaces the value with a fake full name, seeded
+// as Fake does with key.
+func FakeName(key []byte) Masker {
+	return Fake(key, func(r *rand.Rand) any {
+		return firstNames[r.IntN(len(firstNames))] + " " + lastNames[r.IntN(len(lastNames))]
+	})
+}
+
+// FakeEmail returns a Masker that replaces the value with a fake address in the
+// reserved example.com domain, seeded as Fake does with key.
+func FakeEmail(key []byte) Masker {
+	return Fake(key, func(r *rand.Rand) any {
+		first := strings.ToLower(firstNames[r.IntN(len(firstNames))])
+		last := strings.ToLower(lastNames[r.IntN(len(lastNames))])
+		return fmt.Sprintf("%s.%s%d@example.com", first, last, r.IntN(10000))
+	})
+}
+
+// FakePhone returns a Masker that replaces the value with a fake phone number in the
+// fictional 555-01xx range, seeded as Fake does with key.
+func FakePhone(key []byte) Masker {
+	return Fake(key, func(r *rand.Rand) any {
+		return fmt.Sprintf("+1-%03d-555-01%02d", 200+r.IntN(800), r.IntN(100))
+	})
+}
+
+// valueText returns the text a value is hashed or seeded from.
+func valueText(value bson.RawValue) (string, error) {
+	if s, ok := value.StringValueOK(); ok {
+		return s, nil
+	}
+	data, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: value}}, true, false)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,142 @@
+package anonymize
+
+import (
+	"errors"
+	"math/rand/v2"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var testKey = []byte("test-key")
+
+func mustMarshal(t *testing.T, doc any) bson.Raw {
+	t.Helper()
+	data, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	return data
+}
+
+func TestRules_Apply(t *testing.T) {
+	doc := mustMarshal(t, bson.D{
+		{Key: "_id", Value: 1},
+		{Key: "name", Value: "Jane Doe"},
+		{Key: "ssn", Value: "123-45-6789"},
+		{Key: "notes", Value: "VIP"},
+		{Key: "phone", Value: nil},
+		{Key: "address", Value: bson.D{{Key: "street", Value: "1 Main St"}, {Key: "city", Value: "Springfield"}}},
+		{Key: "contacts", Value: bson.A{
+			bson.D{{Key: "email", Value: "a@corp.com"}},
+			bson.D{{Key: "email", Value: "b@corp.com"}},
+		}},
+	})
+
+	rules := Rules{
+		"name":           FakeName(testKey),
+		"ssn":            Redact("***"),
+		"notes":          Remove(),
+		"phone":          FakePhone(testKey),
+		"address.street": Redact(""),
+		"contacts.email": FakeEmail(testKey),
+		"missing":        Remove(),
+	}
+	masked, err := rules.Apply(doc)
+	require.NoError(t, err)
+
+	var out bson.M
+	require.NoError(t, bson.Unmarshal(masked, &out))
+	assert.EqualValues(t, 1, out["_id"])
+	assert.NotEqual(t, "Jane Doe", out["name"])
+	assert.Equal(t, "***", out["ssn"])
+	assert.NotContains(t, out, "notes")
+	assert.Nil(t, out["phone"], "null values stay null")
+	assert.Equal(t, bson.M{"street": "", "city": "Springfield"}, out["address"])
+
+	contacts := out["contacts"].(bson.A)
+	require.Len(t, contacts, 2)
+	email := regexp.MustCompile(`^[a-z]+\.[a-z]+\d+@example\.com$`)
+	for _, c := range contacts {
+		assert.Regexp(t, email, c.(bson.M)["email"])
+	}
+
+	keys := make([]string, 0)
+	elems, err := masked.Elements()
+	require.NoError(t, err)
+	for _, e := range elems {
+		keys = append(keys, e.Key())
+	}
+	assert.Equal(t, []string{"_id", "name", "ssn", "phone", "address", "contacts"}, keys, "field order is preserved")
+}
+
+func TestRules_Apply_NoRules(t *testing.T) {
+	doc := mustMarshal(t, bson.D{{Key: "a", Value: 1}})
+	masked, err := Rules{}.Apply(doc)
+	require.NoError(t, err)
+	assert.Equal(t, doc, masked)
+}
+
+func TestRules_Apply_MaskerError(t *testing.T) {
+	rules := Rules{"a": func(bson.RawValue) (any, error) { return nil, errors.New("boom") }}
+	_, err := rules.Apply(mustMarshal(t, bson.D{{Key: "a", Value: 1}}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "anonymize: mask a: boom")
+}
+
+func TestHash(t *testing.T) {
+	hash := Hash([]byte("key"))
+	value := func(v any) bson.RawValue {
+		return mustMarshal(t, bson.D{{Key: "v", Value: v}}).Lookup("v")
+	}
+
+	a, err := hash(value("customer-1"))
+	require.NoError(t, err)
+	b, err := hash(value("customer-1"))
+	require.NoError(t, err)
+	c, err := hash(value("customer-2"))
+	require.NoError(t, err)
+	assert.Equal(t, a, b, "equal values hash equally")
+	assert.NotEqual(t, a, c)
+	assert.Len(t, a, 64)
+
+	other, err := Hash([]byte("other"))(value("customer-1"))
+	require.NoError(t, err)
+	assert.NotEqual(t, a, other, "the key changes the hash")
+
+	n, err := hash(value(int32(42)))
+	require.NoError(t, err)
+	assert.Len(t, n, 64, "non-string values are hashed too")
+}
+
+func TestFake_Deterministic(t *testing.T) {
+	value := mustMarshal(t, bson.D{{Key: "v", Value: "jane@corp.com"}}).Lookup("v")
+	other := mustMarshal(t, bson.D{{Key: "v", Value: "john@corp.com"}}).Lookup("v")
+
+	for name, mask := range map[string]Masker{"name": FakeName(testKey), "email": FakeEmail(testKey), "phone": FakePhone(testKey)} {
+		t.Run(name, func(t *testing.T) {
+			a, err := mask(value)
+			require.NoError(t, err)
+			b, err := mask(value)
+			require.NoError(t, err)
+			assert.Equal(t, a, b)
+			c, err := mask(other)
+			require.NoError(t, err)
+			assert.NotEqual(t, a, c)
+		})
+	}
+
+	plans := Fake(testKey, func(r *rand.Rand) any { return r.IntN(1000) })
+	got, err := plans(value)
+	require.NoError(t, err)
+	assert.IsType(t, 0, got)
+
+	t.Run("depends on the key", func(t *testing.T) {
+		a, err := FakeEmail(testKey)(value)
+		require.NoError(t, err)
+		b, err := FakeEmail([]byte("another-key"))(value)
+		require.NoError(t, err)
+		assert.NotEqual(t, a, b)
+	})
+}
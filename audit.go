@@ -0,0 +1,251 @@
+package mongo_kit
+
+import (
+	"context"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Audit Fields
+//
+// This file provides the actor of an operation carried by contexts and the audit
+// fields repositories record from it: when and by whom each document was created and
+// last updated.
+//
+// See docs/repository.md for detailed usage guide and examples.
+
+// Field names of the audit fields recorded by repositories created WithAuditFields.
+const (
+	CreatedAtField = "created_at"
+	CreatedByField = "created_by"
+	UpdatedAtField = "updated_at"
+	UpdatedByField = "updated_by"
+)
+
+// actorKey is the context key of the actor of an operation.
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying the ID of the user or service performing
+// the operations run with it, recorded as created_by and updated_by by repositories
+// created WithAuditFields.
+//
+// Example:
+//
+//	// In an authentication middleware
+//	ctx = mongo_kit.WithActor(r.Context(), claims.Subject)
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor set on ctx with WithActor. The boolean is false
+// if ctx has no actor.
+func ActorFromContext(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok && actor != ""
+}
+
+// WithAuditFields records audit fields on the documents of the repository, using the
+// clock of the client and the actor of the context:
+//   - Create, CreateMany, Insert and CreateAndReturn set created_at and updated_at, and
+//     created_by and updated_by when the context has an actor. Struct fields are set
+//     when they are zero and their bson tag names an audit field; maps and bson.D
+//     documents get the keys they do not have.
+//   - UpdateByID, UpdateOne, UpdateMany, Upsert and UpsertWithBuilders add updated_at
+//     and updated_by to $set, and upserts add created_at and created_by to
+//     $setOnInsert. Fields the update already sets are left as they are.
+//
+// Updates given as pipelines or replacement documents, and BulkWrite models, are not
+// changed.
+//
+// Example:
+//
+//	type Order struct {
+//	    ID        primitive.ObjectID `bson:"_id,omitempty"`
+//	    CreatedAt time.Time          `bson:"created_at"`
+//	    CreatedBy string             `bson:"created_by,omitempty"`
+//	    UpdatedAt time.Time          `bson:"updated_at"`
+//	    UpdatedBy string             `bson:"updated_by,omitempty"`
+//	}
+//
+//	orders := mongo_kit.NewRepository[Order](client, "orders", mongo_kit.WithAuditFields())
+//	ctx = mongo_kit.WithActor(ctx, "user-42")
+//	_, err := orders.Create(ctx, order) // created_by and updated_by are "user-42"
+func WithAuditFields() RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.audit = true
+	}
+}
+
+// auditFields returns the audit fields of a write at now, with the created fields
+// when created is true.
+func auditFields(ctx context.Context, now time.Time, created bool) bson.D {
+	actor, hasActor := ActorFromContext(ctx)
+	var fields bson.D
+	if created {
+		fields = append(fields, bson.E{Key: CreatedAtField, Value: now})
+		if hasActor {
+			fields = append(fields, bson.E{Key: CreatedByField, Value: actor})
+		}
+	}
+	fields = append(fields, bson.E{Key: UpdatedAtField, Value: now})
+	if hasActor {
+		fields = append(fields, bson.E{Key: UpdatedByField, Value: actor})
+	}
+	return fields
+}
+
+// auditCreated sets the audit fields of a new document when audit fields are enabled.
+func (r *Repository[T]) auditCreated(ctx context.Context, document *T) {
+	if !r.opts.audit {
+		return
+	}
+	fields := auditFields(ctx, r.client.Now(), true)
+
+	if d, ok := any(*document).(bson.D); ok {
+		stamped := slices.Clone(d)
+		for _, field := range fields {
+			if !slices.ContainsFunc(d, func(e bson.E) bool { return e.Key == field.Key }) {
+				stamped = append(stamped, field)
+			}
+		}
+		*document = any(stamped).(T)
+		return
+	}
+
+	v := reflect.ValueOf(document).Elem()
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		if v.IsNil() {
+			return
+		}
+		stamped := reflect.MakeMapWithSize(v.Type(), v.Len()+len(fields))
+		iter := v.MapRange()
+		for iter.Next() {
+			stamped.SetMapIndex(iter.Key(), iter.Value())
+		}
+		for _, field := range fields {
+			key := reflect.ValueOf(field.Key).Convert(v.Type().Key())
+			value := reflect.ValueOf(field.Value)
+			if !v.MapIndex(key).IsValid() && value.Type().AssignableTo(v.Type().Elem()) {
+				stamped.SetMapIndex(key, value)
+			}
+		}
+		v.Set(stamped)
+	case v.Kind() == reflect.Struct:
+		for i := range v.NumField() {
+			sf := v.Type().Field(i)
+			name, _, _ := strings.Cut(sf.Tag.Get("bson"), ",")
+			if !sf.IsExported() || name == "" {
+				continue
+			}
+			field := v.Field(i)
+			for _, audit := range fields {
+				value := reflect.ValueOf(audit.Value)
+				if audit.Key == name && field.IsZero() && value.Type().AssignableTo(field.Type()) {
+					field.Set(value)
+				}
+			}
+		}
+	}
+}
+
+// auditUpdate returns update with the audit fields added to its $set, and to its
+// $setOnInsert when upsert is true, when audit fields are enabled. Updates that are not
+// documents of update operators are returned unchanged.
+func (r *Repository[T]) auditUpdate(ctx context.Context, update any, upsert bool) any {
+	if !r.opts.audit {
+		return update
+	}
+	operators := operatorElements(update)
+	if len(operators) == 0 || !strings.HasPrefix(operators[0].Key, "$") {
+		return update
+	}
+
+	updated := make(map[string]bool)
+	for _, operator := range operators {
+		for _, field := range operatorElements(operator.Value) {
+			updated[field.Key] = true
+		}
+	}
+
+	fields := auditFields(ctx, r.client.Now(), upsert)
+	var set, setOnInsert bson.D
+	for _, field := range fields {
+		switch {
+		case updated[field.Key]:
+		case field.Key == CreatedAtField || field.Key == CreatedByField:
+			setOnInsert = append(setOnInsert, field)
+		default:
+			set = append(set, field)
+		}
+	}
+
+	stamped := slices.Clone(operators)
+	stamped = mergeOperator(stamped, "$set", set)
+	stamped = mergeOperator(stamped, "$setOnInsert", setOnInsert)
+	return stamped
+}
+
+// mergeOperator adds fields to the operator op of update, adding the operator when
+// update does not have it.
+func mergeOperator(update bson.D, op string, fields bson.D) bson.D {
+	if len(fields) == 0 {
+		return update
+	}
+	for i, operator := range update {
+		if operator.Key != op {
+			continue
+		}
+		existing := operatorElements(operator.Value)
+		if existing == nil && operator.Value != nil {
+			return update // leave operators of unknown types to the driver
+		}
+		update[i].Value = append(slices.Clip(existing), fields...)
+		return update
+	}
+	return append(update, bson.E{Key: op, Value: fields})
+}
+
+// operatorElements returns the elements of a document given as a bson.D or a map, with
+// map keys sorted. Other values have no elements.
+func operatorElements(v any) bson.D {
+	var m map[string]any
+	switch v := v.(type) {
+	case bson.D:
+		return v
+	case bson.M:
+		m = v
+	case map[string]any:
+		m = v
+	default:
+		return nil
+	}
+	elems := make(bson.D, 0, len(m))
+	for _, key := range slices.Sorted(maps.Keys(m)) {
+		elems = append(elems, bson.E{Key: key, Value: m[key]})
+	}
+	return elems
+}
+
+// updateUpserts reports whether opts enable upserts.
+func updateUpserts(opts []*options.UpdateOptions) bool {
+	upsert := false
+	for _, opt := range opts {
+		if opt != nil && opt.Upsert != nil {
+			upsert = *opt.Upsert
+		}
+	}
+	return upsert
+}
@@ -0,0 +1,82 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type auditedOrder struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Status    string             `bson:"status"`
+	CreatedAt time.Time          `bson:"created_at"`
+	CreatedBy string             `bson:"created_by,omitempty"`
+	UpdatedAt time.Time          `bson:"updated_at"`
+	UpdatedBy string             `bson:"updated_by,omitempty"`
+}
+
+func TestRepository_AuditFields_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := testhelpers.NewFakeClock(created)
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database), WithClock(clock))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	repo := NewRepository[auditedOrder](client, "orders", WithAuditFields())
+	ctx := context.Background()
+
+	t.Run("Insert records the creator", func(t *testing.T) {
+		order := auditedOrder{Status: "new"}
+		_, err := repo.Insert(WithActor(ctx, "alice"), &order)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", order.CreatedBy)
+
+		stored, err := repo.FindByID(ctx, order.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created, stored.CreatedAt.UTC())
+		assert.Equal(t, "alice", stored.CreatedBy)
+		assert.Equal(t, created, stored.UpdatedAt.UTC())
+		assert.Equal(t, "alice", stored.UpdatedBy)
+	})
+
+	t.Run("updates record the last updater", func(t *testing.T) {
+		id, err := repo.Create(WithActor(ctx, "alice"), auditedOrder{Status: "new"})
+		require.NoError(t, err)
+
+		clock.Advance(time.Hour)
+		_, err = repo.UpdateByID(WithActor(ctx, "bob"), id, bson.M{"$set": bson.M{"status": "paid"}})
+		require.NoError(t, err)
+
+		stored, err := repo.FindByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "paid", stored.Status)
+		assert.Equal(t, "alice", stored.CreatedBy)
+		assert.Equal(t, "bob", stored.UpdatedBy)
+		assert.Equal(t, created.Add(time.Hour), stored.UpdatedAt.UTC())
+	})
+
+	t.Run("upserts record the creator on insert", func(t *testing.T) {
+		_, err := repo.Upsert(WithActor(ctx, "carol"), bson.M{"status": "imported"}, bson.M{"$set": bson.M{"status": "imported"}})
+		require.NoError(t, err)
+
+		stored, err := repo.FindOne(ctx, bson.M{"status": "imported"})
+		require.NoError(t, err)
+		assert.Equal(t, "carol", stored.CreatedBy)
+		assert.Equal(t, "carol", stored.UpdatedBy)
+		assert.False(t, stored.CreatedAt.IsZero())
+	})
+}
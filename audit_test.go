@@ -0,0 +1,138 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type auditDocument struct {
+	Name      string    `bson:"name"`
+	CreatedAt time.Time `bson:"created_at"`
+	CreatedBy string    `bson:"created_by,omitempty"`
+	UpdatedAt time.Time `bson:"updated_at"`
+	UpdatedBy string    `bson:"updated_by,omitempty"`
+}
+
+func newAuditRepository[T any](now time.Time, opts ...RepositoryOption) *Repository[T] {
+	client := &Client{config: withOptions(DefaultConfig(), WithClock(testhelpers.NewFakeClock(now)))}
+	return NewRepository[T](client, "docs", opts...)
+}
+
+func TestActorFromContext(t *testing.T) {
+	_, ok := ActorFromContext(context.Background())
+	assert.False(t, ok)
+
+	actor, ok := ActorFromContext(WithActor(context.Background(), "user-1"))
+	assert.True(t, ok)
+	assert.Equal(t, "user-1", actor)
+
+	_, ok = ActorFromContext(WithActor(context.Background(), ""))
+	assert.False(t, ok, "an empty actor is no actor")
+}
+
+func TestRepository_AuditCreated(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := WithActor(context.Background(), "user-1")
+
+	t.Run("sets zero struct fields", func(t *testing.T) {
+		repo := newAuditRepository[auditDocument](now, WithAuditFields())
+		earlier := now.Add(-time.Hour)
+		doc := auditDocument{Name: "a", CreatedAt: earlier}
+		repo.auditCreated(ctx, &doc)
+		assert.Equal(t, auditDocument{Name: "a", CreatedAt: earlier, CreatedBy: "user-1", UpdatedAt: now, UpdatedBy: "user-1"}, doc)
+	})
+
+	t.Run("sets struct fields through pointers", func(t *testing.T) {
+		repo := newAuditRepository[*auditDocument](now, WithAuditFields())
+		doc := &auditDocument{}
+		repo.auditCreated(context.Background(), &doc)
+		assert.Equal(t, now, doc.CreatedAt)
+		assert.Empty(t, doc.CreatedBy, "no actor in the context")
+	})
+
+	t.Run("adds missing keys to a copy of maps", func(t *testing.T) {
+		repo := newAuditRepository[bson.M](now, WithAuditFields())
+		original := bson.M{"name": "a", "created_by": "importer"}
+		doc := original
+		repo.auditCreated(ctx, &doc)
+		assert.Equal(t, bson.M{"name": "a", "created_at": now, "created_by": "importer", "updated_at": now, "updated_by": "user-1"}, doc)
+		assert.Len(t, original, 2, "the map of the caller is unchanged")
+	})
+
+	t.Run("appends missing elements to bson.D", func(t *testing.T) {
+		repo := newAuditRepository[bson.D](now, WithAuditFields())
+		doc := bson.D{{Key: "name", Value: "a"}, {Key: "updated_at", Value: "kept"}}
+		repo.auditCreated(ctx, &doc)
+		assert.Equal(t, bson.D{
+			{Key: "name", Value: "a"},
+			{Key: "updated_at", Value: "kept"},
+			{Key: "created_at", Value: now},
+			{Key: "created_by", Value: "user-1"},
+			{Key: "updated_by", Value: "user-1"},
+		}, doc)
+	})
+
+	t.Run("does nothing when disabled", func(t *testing.T) {
+		repo := newAuditRepository[auditDocument](now)
+		doc := auditDocument{}
+		repo.auditCreated(ctx, &doc)
+		assert.Equal(t, auditDocument{}, doc)
+	})
+}
+
+func TestRepository_AuditUpdate(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := WithActor(context.Background(), "user-1")
+	repo := newAuditRepository[auditDocument](now, WithAuditFields())
+
+	t.Run("adds updated fields to $set", func(t *testing.T) {
+		update := bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "b"}}}, {Key: "$inc", Value: bson.M{"n": 1}}}
+		assert.Equal(t, bson.D{
+			{Key: "$set", Value: bson.D{{Key: "name", Value: "b"}, {Key: "updated_at", Value: now}, {Key: "updated_by", Value: "user-1"}}},
+			{Key: "$inc", Value: bson.M{"n": 1}},
+		}, repo.auditUpdate(ctx, update, false))
+		assert.Equal(t, bson.D{{Key: "name", Value: "b"}}, update[0].Value, "the update of the caller is unchanged")
+	})
+
+	t.Run("adds $set and $setOnInsert to maps for upserts", func(t *testing.T) {
+		update := bson.M{"$inc": bson.M{"n": 1}}
+		assert.Equal(t, bson.D{
+			{Key: "$inc", Value: bson.M{"n": 1}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: now}, {Key: "updated_by", Value: "user-1"}}},
+			{Key: "$setOnInsert", Value: bson.D{{Key: "created_at", Value: now}, {Key: "created_by", Value: "user-1"}}},
+		}, repo.auditUpdate(ctx, update, true))
+	})
+
+	t.Run("keeps fields the update sets", func(t *testing.T) {
+		update := NewUpdateBuilder().Set("name", "b").CurrentDate("updated_at").Build()
+		assert.Equal(t, bson.D{
+			{Key: "$set", Value: bson.D{{Key: "name", Value: "b"}, {Key: "updated_by", Value: "user-1"}}},
+			{Key: "$currentDate", Value: bson.M{"updated_at": true}},
+		}, repo.auditUpdate(ctx, update, false))
+	})
+
+	t.Run("leaves other updates unchanged", func(t *testing.T) {
+		pipeline := bson.A{bson.D{{Key: "$set", Value: bson.D{{Key: "n", Value: 1}}}}}
+		assert.Equal(t, pipeline, repo.auditUpdate(ctx, pipeline, false))
+		replacement := bson.M{"name": "b"}
+		assert.Equal(t, replacement, repo.auditUpdate(ctx, replacement, false))
+		assert.Equal(t, bson.D{}, repo.auditUpdate(ctx, bson.D{}, false))
+
+		disabled := newAuditRepository[auditDocument](now)
+		update := bson.M{"$set": bson.M{"name": "b"}}
+		assert.Equal(t, update, disabled.auditUpdate(ctx, update, false))
+	})
+}
+
+func TestUpdateUpserts(t *testing.T) {
+	assert.False(t, updateUpserts(nil))
+	assert.True(t, updateUpserts([]*options.UpdateOptions{nil, options.Update().SetUpsert(true)}))
+	assert.False(t, updateUpserts([]*options.UpdateOptions{options.Update().SetUpsert(true), options.Update().SetUpsert(false)}))
+}
@@ -0,0 +1,248 @@
+// Package backfill rewrites the documents of a collection in _id-ordered batches,
+// with checkpoints to resume an interrupted run, rate limiting and progress reports.
+//
+// See docs/backfill.md for detailed usage guide and examples.
+package backfill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// defaultBatchSize is the number of documents read per batch when Job.BatchSize is not set.
+const defaultBatchSize = 500
+
+// Transform returns the write for a single document, or nil to leave it unchanged.
+type Transform func(ctx context.Context, doc bson.Raw) (mongo.WriteModel, error)
+
+// BatchTransform returns the writes for a batch of documents. It may return no writes.
+type BatchTransform func(ctx context.Context, docs []bson.Raw) ([]mongo.WriteModel, error)
+
+// Job describes a backfill. Exactly one of Transform and BatchTransform must be set.
+type Job struct {
+	Name           string                    // Identifies the job in the checkpoint store (required with Checkpoints)
+	Collection     string                    // Collection to rewrite (required)
+	Filter         any                       // Restricts the documents visited (default: all documents)
+	BatchSize      int                       // Documents read and written per batch (default: 500)
+	Transform      Transform                 // Called for each document
+	BatchTransform BatchTransform            // Called for each batch, instead of Transform
+	Checkpoints    CheckpointStore           // Persists the last processed _id to resume a run (default: none)
+	RateLimit      float64                   // Maximum documents processed per second (default: unlimited)
+	OnProgress     func(Progress)            // Called after each batch (default: nil)
+	WriteOptions   *options.BulkWriteOptions // Options of the bulk write of each batch (default: unordered)
+}
+
+// Progress reports the state of a running backfill.
+type Progress struct {
+	Batches   int           // Batches completed in this run
+	Processed int64         // Documents read in this run
+	Written   int64         // Writes sent in this run
+	Modified  int64         // Documents modified, inserted, upserted or deleted in this run
+	LastID    any           // _id of the last processed document
+	Elapsed   time.Duration // Time since the run started
+}
+
+// validate checks that the job can run.
+func (j *Job) validate() error {
+	if j.Collection == "" {
+		return errors.New("backfill: Collection is required")
+	}
+	if (j.Transform == nil) == (j.BatchTransform == nil) {
+		return errors.New("backfill: exactly one of Transform and BatchTransform is required")
+	}
+	if j.Checkpoints != nil && j.Name == "" {
+		return errors.New("backfill: Name is required with Checkpoints")
+	}
+	if j.BatchSize < 0 || j.RateLimit < 0 {
+		return errors.New("backfill: BatchSize and RateLimit cannot be negative")
+	}
+	return nil
+}
+
+// Run visits the documents of job.Collection in ascending _id order, in batches, and
+// bulk-writes the writes returned by the transform for each batch. After each batch the
+// last processed _id is saved to job.Checkpoints, so running the job again after a
+// failure or cancellation resumes after the last completed batch.
+// Run returns the progress of this run, also when it fails.
+//
+// Writes are applied after the batch is read, so a transform should be idempotent:
+// a batch interrupted between its write and its checkpoint is processed again.
+//
+// Example:
+//
+//	progress, err := backfill.Run(ctx, client, backfill.Job{
+//	    Name:        "users-normalize-email",
+//	    Collection:  "users",
+//	    Filter:      bson.M{"email_lower": bson.M{"$exists": false}},
+//	    Checkpoints: backfill.MongoCheckpoints(client, "backfill_checkpoints"),
+//	    RateLimit:   2000,
+//	    Transform: func(ctx context.Context, doc bson.Raw) (mongo.WriteModel, error) {
+//	        email, _ := doc.Lookup("email").StringValueOK()
+//	        return mongo.NewUpdateOneModel().
+//	            SetFilter(bson.M{"_id": doc.Lookup("_id")}).
+//	            SetUpdate(bson.M{"$set": bson.M{"email_lower": strings.ToLower(email)}}), nil
+//	    },
+//	    OnProgress: func(p backfill.Progress) { log.Printf("backfill: %d documents", p.Processed) },
+//	})
+func Run(ctx context.Context, client *mongokit.Client, job Job) (Progress, error) {
+	var progress Progress
+	if err := job.validate(); err != nil {
+		return progress, err
+	}
+
+	batchSize := job.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultBatchSize
+	}
+	writeOpts := job.WriteOptions
+	if writeOpts == nil {
+		writeOpts = options.BulkWrite().SetOrdered(false)
+	}
+
+	var lastID any
+	if job.Checkpoints != nil {
+		id, found, err := job.Checkpoints.Load(ctx, job.Name)
+		if err != nil {
+			return progress, fmt.Errorf("backfill: load checkpoint: %w", err)
+		}
+		if found {
+			lastID = id
+		}
+	}
+
+	repo := mongokit.NewRepository[bson.Raw](client, job.Collection)
+	findOpts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(batchSize))
+	start := time.Now()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+
+		batchStart := time.Now()
+		docs, err := repo.Find(ctx, batchFilter(job.Filter, lastID), findOpts)
+		if err != nil {
+			return progress, err
+		}
+		if len(docs) == 0 {
+			return progress, nil
+		}
+
+		models, err := transformBatch(ctx, job, docs)
+		if err != nil {
+			return progress, err
+		}
+
+		if len(models) > 0 {
+			result, err := repo.BulkWrite(ctx, models, writeOpts)
+			if err != nil {
+				return progress, err
+			}
+			progress.Written += int64(len(models))
+			progress.Modified += result.ModifiedCount + result.InsertedCount + result.UpsertedCount + result.DeletedCount
+		}
+
+		lastID = docs[len(docs)-1].Lookup("_id")
+		if job.Checkpoints != nil {
+			if err := job.Checkpoints.Save(ctx, job.Name, lastID); err != nil {
+				return progress, fmt.Errorf("backfill: save checkpoint: %w", err)
+			}
+		}
+
+		progress.Batches++
+		progress.Processed += int64(len(docs))
+		progress.LastID = lastID
+		progress.Elapsed = time.Since(start)
+		if job.OnProgress != nil {
+			job.OnProgress(progress)
+		}
+
+		if len(docs) < batchSize {
+			return progress, nil
+		}
+		if err := throttle(ctx, len(docs), job.RateLimit, time.Since(batchStart)); err != nil {
+			return progress, err
+		}
+	}
+}
+
+// Reset deletes the checkpoint of job, so the next run starts from the first document.
+func Reset(ctx context.Context, job Job) error {
+	if job.Checkpoints == nil {
+		return nil
+	}
+	return job.Checkpoints.Delete(ctx, job.Name)
+}
+
+// batchFilter returns the filter of the batch after lastID.
+func batchFilter(filter, lastID any) any {
+	if lastID == nil {
+		if filter == nil {
+			return bson.D{}
+		}
+		return filter
+	}
+
+	after := bson.D{{Key: "_id", Value: bson.D{{Key: "$gt", Value: lastID}}}}
+	if filter == nil {
+		return after
+	}
+	return bson.D{{Key: "$and", Value: bson.A{filter, after}}}
+}
+
+// transformBatch returns the writes of the job's transform for docs.
+func transformBatch(ctx context.Context, job Job, docs []bson.Raw) ([]mongo.WriteModel, error) {
+	if job.BatchTransform != nil {
+		models, err := job.BatchTransform(ctx, docs)
+		if err != nil {
+			return nil, fmt.Errorf("backfill: transform batch: %w", err)
+		}
+		return models, nil
+	}
+
+	models := make([]mongo.WriteModel, 0, len(docs))
+	for _, doc := range docs {
+		model, err := job.Transform(ctx, doc)
+		if err != nil {
+			return nil, fmt.Errorf("backfill: transform document %v: %w", doc.Lookup("_id"), err)
+		}
+		if model != nil {
+			models = append(models, model)
+		}
+	}
+	return models, nil
+}
+
+// throttle waits until processing n documents took at least n/rate seconds.
+func throttle(ctx context.Context, n int, rate float64, took time.Duration) error {
+	wait := throttleDelay(n, rate, took)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// throttleDelay returns how long to wait after processing n documents in took to stay
+// under rate documents per second. A rate of 0 means unlimited.
+func throttleDelay(n int, rate float64, took time.Duration) time.Duration {
+	if rate <= 0 {
+		return 0
+	}
+	return time.Duration(float64(n)/rate*float64(time.Second)) - took
+}
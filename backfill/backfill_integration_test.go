@@ -0,0 +1,123 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestRun_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := mongokit.New(mongokit.DefaultConfig(), mongokit.WithURI(container.URI), mongokit.WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	users := mongokit.NewRepository[bson.M](client, "users")
+
+	seed := func(t *testing.T, n int) {
+		_ = users.Drop(ctx)
+		docs := make([]bson.M, n)
+		for i := range docs {
+			docs[i] = bson.M{"_id": i, "email": "User" + string(rune('A'+i%26)) + "@Example.com"}
+		}
+		_, err := users.CreateMany(ctx, docs)
+		require.NoError(t, err)
+	}
+
+	lowercase := func(_ context.Context, doc bson.Raw) (mongo.WriteModel, error) {
+		email := doc.Lookup("email").StringValue()
+		return mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": doc.Lookup("_id")}).
+			SetUpdate(bson.M{"$set": bson.M{"email_lower": strings.ToLower(email)}}), nil
+	}
+
+	t.Run("rewrites every document in batches", func(t *testing.T) {
+		seed(t, 25)
+
+		var reports []Progress
+		progress, err := Run(ctx, client, Job{
+			Collection: "users",
+			BatchSize:  10,
+			Transform:  lowercase,
+			OnProgress: func(p Progress) { reports = append(reports, p) },
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, progress.Batches)
+		assert.Equal(t, int64(25), progress.Processed)
+		assert.Equal(t, int64(25), progress.Modified)
+		assert.Len(t, reports, 3)
+
+		count, err := users.Count(ctx, bson.M{"email_lower": bson.M{"$exists": true}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(25), count)
+	})
+
+	t.Run("resumes from the checkpoint", func(t *testing.T) {
+		seed(t, 30)
+		checkpoints := MongoCheckpoints(client, "backfill_checkpoints")
+		job := Job{Name: "lowercase", Collection: "users", BatchSize: 10, Checkpoints: checkpoints}
+		require.NoError(t, Reset(ctx, job))
+
+		failure := errors.New("interrupted")
+		calls := 0
+		job.Transform = func(ctx context.Context, doc bson.Raw) (mongo.WriteModel, error) {
+			calls++
+			if calls > 20 {
+				return nil, failure
+			}
+			return lowercase(ctx, doc)
+		}
+		progress, err := Run(ctx, client, job)
+		require.ErrorIs(t, err, failure)
+		assert.Equal(t, int64(20), progress.Processed)
+
+		job.Transform = lowercase
+		progress, err = Run(ctx, client, job)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), progress.Processed)
+
+		count, err := users.Count(ctx, bson.M{"email_lower": bson.M{"$exists": true}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(30), count)
+	})
+
+	t.Run("applies the filter and batch transform", func(t *testing.T) {
+		seed(t, 10)
+
+		progress, err := Run(ctx, client, Job{
+			Collection: "users",
+			Filter:     bson.M{"_id": bson.M{"$gte": 5}},
+			BatchTransform: func(_ context.Context, docs []bson.Raw) ([]mongo.WriteModel, error) {
+				ids := make(bson.A, len(docs))
+				for i, doc := range docs {
+					ids[i] = doc.Lookup("_id")
+				}
+				return []mongo.WriteModel{mongo.NewDeleteManyModel().SetFilter(bson.M{"_id": bson.M{"$in": ids}})}, nil
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), progress.Processed)
+		assert.Equal(t, int64(5), progress.Modified)
+
+		count, err := users.CountAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), count)
+	})
+}
@@ -0,0 +1,102 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func noopTransform(context.Context, bson.Raw) (mongo.WriteModel, error) { return nil, nil }
+
+func TestJob_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		job  Job
+		want string
+	}{
+		{"valid", Job{Collection: "users", Transform: noopTransform}, ""},
+		{"missing collection", Job{Transform: noopTransform}, "Collection is required"},
+		{"missing transform", Job{Collection: "users"}, "exactly one of"},
+		{"both transforms", Job{Collection: "users", Transform: noopTransform, BatchTransform: func(context.Context, []bson.Raw) ([]mongo.WriteModel, error) {
+			return nil, nil
+		}}, "exactly one of"},
+		{"checkpoints without name", Job{Collection: "users", Transform: noopTransform, Checkpoints: &mongoCheckpoints{}}, "Name is required"},
+		{"negative batch size", Job{Collection: "users", Transform: noopTransform, BatchSize: -1}, "cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.job.validate()
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestBatchFilter(t *testing.T) {
+	filter := bson.M{"active": true}
+	after := bson.D{{Key: "_id", Value: bson.D{{Key: "$gt", Value: 5}}}}
+
+	assert.Equal(t, bson.D{}, batchFilter(nil, nil))
+	assert.Equal(t, filter, batchFilter(filter, nil))
+	assert.Equal(t, after, batchFilter(nil, 5))
+	assert.Equal(t, bson.D{{Key: "$and", Value: bson.A{filter, after}}}, batchFilter(filter, 5))
+}
+
+func TestTransformBatch(t *testing.T) {
+	ctx := context.Background()
+	doc1, _ := bson.Marshal(bson.M{"_id": 1})
+	doc2, _ := bson.Marshal(bson.M{"_id": 2})
+	docs := []bson.Raw{doc1, doc2}
+
+	t.Run("skips documents without a write", func(t *testing.T) {
+		job := Job{Transform: func(_ context.Context, doc bson.Raw) (mongo.WriteModel, error) {
+			if doc.Lookup("_id").Int32() == 1 {
+				return nil, nil
+			}
+			return mongo.NewDeleteOneModel(), nil
+		}}
+		models, err := transformBatch(ctx, job, docs)
+		require.NoError(t, err)
+		assert.Len(t, models, 1)
+	})
+
+	t.Run("wraps transform errors", func(t *testing.T) {
+		boom := errors.New("boom")
+		job := Job{Transform: func(context.Context, bson.Raw) (mongo.WriteModel, error) { return nil, boom }}
+		_, err := transformBatch(ctx, job, docs)
+		assert.ErrorIs(t, err, boom)
+	})
+
+	t.Run("uses the batch transform", func(t *testing.T) {
+		job := Job{BatchTransform: func(_ context.Context, docs []bson.Raw) ([]mongo.WriteModel, error) {
+			return []mongo.WriteModel{mongo.NewDeleteManyModel()}, nil
+		}}
+		models, err := transformBatch(ctx, job, docs)
+		require.NoError(t, err)
+		assert.Len(t, models, 1)
+	})
+}
+
+func TestThrottleDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), throttleDelay(100, 0, 0))
+	assert.Equal(t, time.Second, throttleDelay(100, 100, 0))
+	assert.Equal(t, 750*time.Millisecond, throttleDelay(100, 100, 250*time.Millisecond))
+	assert.Negative(t, throttleDelay(100, 1000, time.Second))
+}
+
+func TestThrottle_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, throttle(ctx, 100, 1, 0), context.Canceled)
+}
@@ -0,0 +1,70 @@
+package backfill
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// CheckpointStore persists the last processed _id of each backfill job.
+type CheckpointStore interface {
+	// Load returns the last processed _id of job, and false if the job has no checkpoint.
+	Load(ctx context.Context, job string) (lastID any, found bool, err error)
+	// Save records lastID as the last processed _id of job.
+	Save(ctx context.Context, job string, lastID any) error
+	// Delete removes the checkpoint of job.
+	Delete(ctx context.Context, job string) error
+}
+
+// checkpoint is the document stored by MongoCheckpoints, one per job.
+type checkpoint struct {
+	Job       string        `bson:"_id"`
+	LastID    bson.RawValue `bson:"last_id"`
+	UpdatedAt time.Time     `bson:"updated_at"`
+}
+
+// mongoCheckpoints stores checkpoints in a collection of the client's database.
+type mongoCheckpoints struct {
+	client *mongokit.Client
+	repo   *mongokit.Repository[checkpoint]
+}
+
+// MongoCheckpoints returns a CheckpointStore that keeps one document per job in
+// collection, with the last processed _id and the time of the last update.
+//
+// Example:
+//
+//	job.Checkpoints = backfill.MongoCheckpoints(client, "backfill_checkpoints")
+func MongoCheckpoints(client *mongokit.Client, collection string) CheckpointStore {
+	return &mongoCheckpoints{client: client, repo: mongokit.NewRepository[checkpoint](client, collection)}
+}
+
+func (s *mongoCheckpoints) Load(ctx context.Context, job string) (any, bool, error) {
+	cp, err := s.repo.FindOne(ctx, bson.M{"_id": job})
+	if errors.Is(err, mongokit.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return cp.LastID, true, nil
+}
+
+func (s *mongoCheckpoints) Save(ctx context.Context, job string, lastID any) error {
+	_, err := s.repo.UpdateOne(ctx,
+		bson.M{"_id": job},
+		bson.M{"$set": bson.M{"last_id": lastID, "updated_at": s.client.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+func (s *mongoCheckpoints) Delete(ctx context.Context, job string) error {
+	_, err := s.repo.DeleteOne(ctx, bson.M{"_id": job})
+	return err
+}
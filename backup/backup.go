@@ -0,0 +1,381 @@
+// Package backup dumps the collections of a database, with their options and index
+// definitions, to a compressed archive and restores them, for test fixtures and
+// small-scale disaster recovery.
+//
+// See docs/backup.md for detailed usage guide and examples.
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+const (
+	// formatVersion is the version of the archive format written by Dump.
+	formatVersion = 1
+	// restoreBatchSize is the number of documents inserted per request by Restore.
+	restoreBatchSize = 1000
+)
+
+// Record types of an archive. An archive is a gzip stream of BSON documents: a header,
+// then for each collection a collection record followed by its document records, then
+// an end record.
+const (
+	recordHeader     = "header"
+	recordCollection = "collection"
+	recordDocument   = "document"
+	recordEnd        = "end"
+)
+
+// Stats describes a dump or a restore.
+type Stats struct {
+	Collections int   // Collections dumped or restored
+	Documents   int64 // Documents dumped or restored
+}
+
+// header is the first record of an archive.
+type header struct {
+	Type      string    `bson:"type"`
+	Version   int       `bson:"version"`
+	Database  string    `bson:"database"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// collection is the record starting the documents of a collection.
+type collection struct {
+	Type    string     `bson:"type"`
+	Name    string     `bson:"name"`
+	Options bson.Raw   `bson:"options,omitempty"`
+	Indexes []bson.Raw `bson:"indexes"`
+}
+
+// document is the record of a single document.
+type document struct {
+	Type     string   `bson:"type"`
+	Document bson.Raw `bson:"document"`
+}
+
+// end is the last record of an archive, used to detect truncated archives.
+type end struct {
+	Type        string `bson:"type"`
+	Collections int    `bson:"collections"`
+	Documents   int64  `bson:"documents"`
+}
+
+// openDatabase returns the database named db, or the client's default database if db is
+// empty.
+func openDatabase(client *mongokit.Client, db string) *mongo.Database {
+	if db == "" {
+		return client.Database()
+	}
+	return client.Database().Client().Database(db)
+}
+
+// Dump writes the collections of database db (the client's default database if db is
+// empty) to w as a gzip-compressed archive: the options and secondary indexes of each
+// collection, and its documents as BSON. System collections and views are skipped.
+//
+// The dump reads each collection with a cursor, so it is not a point-in-time snapshot
+// of a database receiving writes; stop writes first when consistency matters.
+//
+// Example:
+//
+//	f, err := os.Create("shop.backup.gz")
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//
+//	stats, err := backup.Dump(ctx, client, "shop", f)
+func Dump(ctx context.Context, client *mongokit.Client, db string, w io.Writer) (Stats, error) {
+	var stats Stats
+	database := openDatabase(client, db)
+
+	specs, err := database.ListCollectionSpecifications(ctx, bson.D{{Key: "type", Value: "collection"}})
+	if err != nil {
+		return stats, fmt.Errorf("backup: list collections: %w", err)
+	}
+	specs = slices.DeleteFunc(specs, func(s *mongo.CollectionSpecification) bool {
+		return strings.HasPrefix(s.Name, "system.")
+	})
+	slices.SortFunc(specs, func(a, b *mongo.CollectionSpecification) int { return strings.Compare(a.Name, b.Name) })
+
+	gz := gzip.NewWriter(w)
+	if err := writeRecord(gz, header{Type: recordHeader, Version: formatVersion, Database: database.Name(), CreatedAt: client.Now()}); err != nil {
+		return stats, err
+	}
+
+	for _, spec := range specs {
+		n, err := dumpCollection(ctx, gz, database.Collection(spec.Name), spec.Options)
+		if err != nil {
+			return stats, err
+		}
+		stats.Collections++
+		stats.Documents += n
+	}
+
+	if err := writeRecord(gz, end{Type: recordEnd, Collections: stats.Collections, Documents: stats.Documents}); err != nil {
+		return stats, err
+	}
+	if err := gz.Close(); err != nil {
+		return stats, fmt.Errorf("backup: write: %w", err)
+	}
+	return stats, nil
+}
+
+// dumpCollection writes the collection record and the documents of coll.
+func dumpCollection(ctx context.Context, w io.Writer, coll *mongo.Collection, opts bson.Raw) (int64, error) {
+	cursor, err := coll.Indexes().List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("backup: list indexes of %s: %w", coll.Name(), err)
+	}
+	var indexes []bson.Raw
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return 0, fmt.Errorf("backup: list indexes of %s: %w", coll.Name(), err)
+	}
+	indexes = slices.DeleteFunc(indexes, func(index bson.Raw) bool {
+		name, _ := index.Lookup("name").StringValueOK()
+		return name == "_id_"
+	})
+
+	if err := writeRecord(w, collection{Type: recordCollection, Name: coll.Name(), Options: opts, Indexes: indexes}); err != nil {
+		return 0, err
+	}
+
+	cursor, err = coll.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return 0, fmt.Errorf("backup: read %s: %w", coll.Name(), err)
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	var n int64
+	for cursor.Next(ctx) {
+		if err := writeRecord(w, document{Type: recordDocument, Document: cursor.Current}); err != nil {
+			return n, err
+		}
+		n++
+	}
+	if err := cursor.Err(); err != nil {
+		return n, fmt.Errorf("backup: read %s: %w", coll.Name(), err)
+	}
+	return n, nil
+}
+
+// writeRecord writes record as a BSON document.
+func writeRecord(w io.Writer, record any) error {
+	data, err := bson.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("backup: encode record: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("backup: write: %w", err)
+	}
+	return nil
+}
+
+// Restore recreates the collections of an archive written by Dump in database db (the
+// client's default database if db is empty), with their options, documents and
+// indexes. It fails before writing to a collection that already exists, so an
+// archive is only restored into collections it creates.
+//
+// Example:
+//
+//	f, err := os.Open("testdata/shop.backup.gz")
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//
+//	stats, err := backup.Restore(ctx, client, "shop_test", f)
+func Restore(ctx context.Context, client *mongokit.Client, db string, r io.Reader) (Stats, error) {
+	var stats Stats
+	database := openDatabase(client, db)
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return stats, fmt.Errorf("backup: read archive: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	var head header
+	if err := readRecord(gz, recordHeader, &head); err != nil {
+		return stats, err
+	}
+	if head.Version != formatVersion {
+		return stats, fmt.Errorf("backup: unsupported archive version %d", head.Version)
+	}
+
+	var current *restoredCollection
+	for {
+		raw, err := bson.NewFromIOReader(gz)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return stats, errors.New("backup: archive is truncated")
+		}
+		if err != nil {
+			return stats, fmt.Errorf("backup: read archive: %w", err)
+		}
+
+		recordType, _ := raw.Lookup("type").StringValueOK()
+		switch recordType {
+		case recordCollection:
+			if err := current.finish(ctx); err != nil {
+				return stats, err
+			}
+			var coll collection
+			if err := bson.Unmarshal(raw, &coll); err != nil {
+				return stats, fmt.Errorf("backup: decode collection record: %w", err)
+			}
+			if current, err = createCollection(ctx, database, coll); err != nil {
+				return stats, err
+			}
+			stats.Collections++
+
+		case recordDocument:
+			if current == nil {
+				return stats, errors.New("backup: document record before any collection")
+			}
+			doc, ok := raw.Lookup("document").DocumentOK()
+			if !ok {
+				return stats, errors.New("backup: document record without a document")
+			}
+			if err := current.add(ctx, doc); err != nil {
+				return stats, err
+			}
+			stats.Documents++
+
+		case recordEnd:
+			if err := current.finish(ctx); err != nil {
+				return stats, err
+			}
+			var last end
+			if err := bson.Unmarshal(raw, &last); err != nil {
+				return stats, fmt.Errorf("backup: decode end record: %w", err)
+			}
+			if last.Collections != stats.Collections || last.Documents != stats.Documents {
+				return stats, fmt.Errorf("backup: archive lists %d collections and %d documents, restored %d and %d",
+					last.Collections, last.Documents, stats.Collections, stats.Documents)
+			}
+			return stats, nil
+
+		default:
+			return stats, fmt.Errorf("backup: unknown record type %q", recordType)
+		}
+	}
+}
+
+// readRecord reads the next record of r into out and checks its type.
+func readRecord(r io.Reader, recordType string, out any) error {
+	raw, err := bson.NewFromIOReader(r)
+	if err != nil {
+		return fmt.Errorf("backup: read archive: %w", err)
+	}
+	if got, _ := raw.Lookup("type").StringValueOK(); got != recordType {
+		return fmt.Errorf("backup: expected %s record, got %q", recordType, got)
+	}
+	if err := bson.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("backup: decode %s record: %w", recordType, err)
+	}
+	return nil
+}
+
+// restoredCollection is a collection being restored.
+type restoredCollection struct {
+	coll    *mongo.Collection
+	indexes []bson.Raw
+	batch   []any
+}
+
+// createCollection creates the collection of a collection record.
+func createCollection(ctx context.Context, database *mongo.Database, coll collection) (*restoredCollection, error) {
+	names, err := database.ListCollectionNames(ctx, bson.D{{Key: "name", Value: coll.Name}})
+	if err != nil {
+		return nil, fmt.Errorf("backup: list collections: %w", err)
+	}
+	if len(names) > 0 {
+		return nil, fmt.Errorf("backup: collection %s already exists", coll.Name)
+	}
+
+	cmd := bson.D{{Key: "create", Value: coll.Name}}
+	if len(coll.Options) > 0 {
+		elems, err := coll.Options.Elements()
+		if err != nil {
+			return nil, fmt.Errorf("backup: decode options of %s: %w", coll.Name, err)
+		}
+		for _, elem := range elems {
+			cmd = append(cmd, bson.E{Key: elem.Key(), Value: elem.Value()})
+		}
+	}
+	if err := database.RunCommand(ctx, cmd).Err(); err != nil {
+		return nil, fmt.Errorf("backup: create %s: %w", coll.Name, err)
+	}
+
+	return &restoredCollection{coll: database.Collection(coll.Name), indexes: coll.Indexes}, nil
+}
+
+// add adds doc to the documents to insert, inserting a batch when it is full.
+func (c *restoredCollection) add(ctx context.Context, doc bson.Raw) error {
+	c.batch = append(c.batch, doc)
+	if len(c.batch) < restoreBatchSize {
+		return nil
+	}
+	return c.flush(ctx)
+}
+
+// flush inserts the pending documents.
+func (c *restoredCollection) flush(ctx context.Context) error {
+	if len(c.batch) == 0 {
+		return nil
+	}
+	// Validators of the dumped collection also applied to its documents, so skip them.
+	if _, err := c.coll.InsertMany(ctx, c.batch, options.InsertMany().SetBypassDocumentValidation(true)); err != nil {
+		return fmt.Errorf("backup: insert into %s: %w", c.coll.Name(), err)
+	}
+	c.batch = c.batch[:0]
+	return nil
+}
+
+// finish inserts the pending documents and creates the indexes, after the documents
+// so they are built once. It does nothing on a nil collection.
+func (c *restoredCollection) finish(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+	if err := c.flush(ctx); err != nil {
+		return err
+	}
+	if len(c.indexes) == 0 {
+		return nil
+	}
+
+	specs := make(bson.A, len(c.indexes))
+	for i, index := range c.indexes {
+		spec := bson.D{}
+		elems, err := index.Elements()
+		if err != nil {
+			return fmt.Errorf("backup: decode index of %s: %w", c.coll.Name(), err)
+		}
+		for _, elem := range elems {
+			if key := elem.Key(); key != "v" && key != "ns" {
+				spec = append(spec, bson.E{Key: key, Value: elem.Value()})
+			}
+		}
+		specs[i] = spec
+	}
+	cmd := bson.D{{Key: "createIndexes", Value: c.coll.Name()}, {Key: "indexes", Value: specs}}
+	if err := c.coll.Database().RunCommand(ctx, cmd).Err(); err != nil {
+		return fmt.Errorf("backup: create indexes of %s: %w", c.coll.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestDumpAndRestore_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database, restoredDatabase := testhelpers.IsolatedDatabase(t), testhelpers.IsolatedDatabase(t)
+
+	client, err := mongokit.New(mongokit.DefaultConfig(), mongokit.WithURI(container.URI), mongokit.WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	require.NoError(t, client.CreateCollection(ctx, "users", options.CreateCollection().SetValidator(bson.M{
+		"email": bson.M{"$type": "string"},
+	})))
+	_, err = client.CreateIndexes(ctx, "users", []mongo.IndexModel{
+		mongokit.NewIndexBuilder().Key("email", 1).Unique().Build(),
+	})
+	require.NoError(t, err)
+
+	users := mongokit.NewRepository[bson.M](client, "users")
+	orders := mongokit.NewRepository[bson.M](client, "orders")
+	for i := range 1500 {
+		_, err := orders.Create(ctx, bson.M{"_id": i, "total": i})
+		require.NoError(t, err)
+	}
+	_, err = users.Create(ctx, bson.M{"_id": 1, "email": "a@example.com"})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	stats, err := Dump(ctx, client, "", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Collections: 2, Documents: 1501}, stats)
+
+	archive := buf.Bytes()
+	stats, err = Restore(ctx, client, restoredDatabase, bytes.NewReader(archive))
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Collections: 2, Documents: 1501}, stats)
+
+	restored := client.Database().Client().Database(restoredDatabase)
+	count, err := restored.Collection("orders").CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1500), count)
+
+	// Options and indexes are restored with the documents.
+	_, err = restored.Collection("users").InsertOne(ctx, bson.M{"email": "a@example.com"})
+	assert.True(t, mongo.IsDuplicateKeyError(err))
+	_, err = restored.Collection("users").InsertOne(ctx, bson.M{"email": 42})
+	assert.Error(t, err)
+
+	// Restoring again fails instead of mixing documents.
+	_, err = Restore(ctx, client, restoredDatabase, bytes.NewReader(archive))
+	assert.ErrorContains(t, err, "already exists")
+}
@@ -0,0 +1,128 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func newClient(t *testing.T) (*testhelpers.MockClient, *mongokit.Client) {
+	t.Helper()
+	mock := testhelpers.NewMockClient(t)
+	client, err := mongokit.NewFromClient(mock.Client, mongokit.DefaultConfig(), mongokit.WithDatabase("shop"))
+	require.NoError(t, err)
+	return mock, client
+}
+
+// archive returns a gzip stream of records.
+func archive(t *testing.T, records ...any) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	for _, record := range records {
+		require.NoError(t, writeRecord(gz, record))
+	}
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestDumpAndRestore(t *testing.T) {
+	ctx := context.Background()
+	emailIndex := bson.D{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "email", Value: 1}}}, {Key: "name", Value: "email_1"}, {Key: "unique", Value: true}}
+
+	mock, client := newClient(t)
+	mock.AddResponses(
+		testhelpers.CursorResponse("shop.$cmd.listCollections",
+			bson.D{{Key: "name", Value: "users"}, {Key: "type", Value: "collection"}, {Key: "options", Value: bson.D{}}},
+			bson.D{{Key: "name", Value: "system.views"}, {Key: "type", Value: "collection"}, {Key: "options", Value: bson.D{}}},
+		),
+		testhelpers.CursorResponse("shop.users",
+			bson.D{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}, {Key: "name", Value: "_id_"}},
+			emailIndex,
+		),
+		testhelpers.CursorResponse("shop.users",
+			bson.D{{Key: "_id", Value: 1}, {Key: "email", Value: "a@example.com"}},
+			bson.D{{Key: "_id", Value: 2}, {Key: "email", Value: "b@example.com"}},
+		),
+	)
+
+	var buf bytes.Buffer
+	stats, err := Dump(ctx, client, "", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Collections: 1, Documents: 2}, stats)
+
+	mock, client = newClient(t)
+	mock.AddResponses(
+		testhelpers.CursorResponse("shop.$cmd.listCollections"), // users does not exist
+		testhelpers.SuccessResponse(),                           // create
+		testhelpers.SuccessResponse(bson.E{Key: "n", Value: 2}), // insert
+		testhelpers.SuccessResponse(),                           // createIndexes
+	)
+	stats, err = Restore(ctx, client, "shop_copy", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Collections: 1, Documents: 2}, stats)
+}
+
+func TestRestore_ExistingCollection(t *testing.T) {
+	mock, client := newClient(t)
+	mock.AddResponses(testhelpers.CursorResponse("shop.$cmd.listCollections",
+		bson.D{{Key: "name", Value: "users"}, {Key: "type", Value: "collection"}},
+	))
+
+	data := archive(t,
+		header{Type: recordHeader, Version: formatVersion},
+		collection{Type: recordCollection, Name: "users"},
+	)
+	_, err := Restore(context.Background(), client, "", bytes.NewReader(data))
+	assert.ErrorContains(t, err, "collection users already exists")
+}
+
+func TestRestore_InvalidArchives(t *testing.T) {
+	_, client := newClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"not gzip", []byte("plain text"), "read archive"},
+		{"missing header", archive(t, end{Type: recordEnd}), "expected header record"},
+		{"unsupported version", archive(t, header{Type: recordHeader, Version: 99}), "unsupported archive version 99"},
+		{"truncated", archive(t, header{Type: recordHeader, Version: formatVersion}), "archive is truncated"},
+		{"document before collection", archive(t,
+			header{Type: recordHeader, Version: formatVersion},
+			document{Type: recordDocument, Document: bson.Raw(archiveDoc(t))},
+		), "document record before any collection"},
+		{"unknown record", archive(t,
+			header{Type: recordHeader, Version: formatVersion},
+			bson.D{{Key: "type", Value: "mystery"}},
+		), `unknown record type "mystery"`},
+		{"count mismatch", archive(t,
+			header{Type: recordHeader, Version: formatVersion},
+			end{Type: recordEnd, Collections: 1, Documents: 5},
+		), "archive lists 1 collections and 5 documents"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Restore(ctx, client, "", bytes.NewReader(tt.data))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func archiveDoc(t *testing.T) []byte {
+	t.Helper()
+	data, err := bson.Marshal(bson.D{{Key: "_id", Value: 1}})
+	require.NoError(t, err)
+	return data
+}
@@ -0,0 +1,161 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Batched Operations
+//
+// This file provides large deletes and updates split into _id-ordered batches,
+// so a single huge operation does not block replication and progress can be
+// reported and cancelled.
+//
+// See docs/operations.md for detailed usage guide and examples.
+
+// batchID holds the _id of a document selected for a batch.
+type batchID struct {
+	ID any `bson:"_id"`
+}
+
+// nextBatch returns up to batchSize _id values of documents matching filter
+// with an _id greater than after (or from the start when after is nil), in _id order.
+func (c *Client) nextBatch(ctx context.Context, collection string, filter any, after any, batchSize int) ([]any, error) {
+	query := filter
+	if after != nil {
+		query = bson.D{{Key: "$and", Value: bson.A{filter, bson.M{"_id": bson.M{"$gt": after}}}}}
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(batchSize)).
+		SetProjection(bson.M{"_id": 1})
+
+	var docs []batchID
+	if err := c.find(ctx, collection, query, &docs, opts); err != nil {
+		return nil, err
+	}
+
+	ids := make([]any, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.ID
+	}
+
+	return ids, nil
+}
+
+// validateBatchArgs checks the shared arguments of batched operations.
+func (c *Client) validateBatchArgs(operation string, filter any, batchSize int) error {
+	if batchSize <= 0 {
+		return newOperationError(operation, errors.New("batch size must be greater than 0"))
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.checkFullCollection(operation, filter)
+}
+
+// DeleteManyBatched deletes all documents matching filter in batches of batchSize,
+// ordered by _id, and returns the number of deleted documents.
+// onProgress (optional) is called after each batch with the total deleted so far.
+// The operation stops between batches when ctx is cancelled, returning the count
+// deleted up to that point together with the context error.
+//
+// Example:
+//
+//	deleted, err := client.DeleteManyBatched(ctx, "events",
+//	    bson.M{"created_at": bson.M{"$lt": cutoff}}, 1000,
+//	    func(n int64) { log.Printf("deleted %d events", n) })
+func (c *Client) DeleteManyBatched(ctx context.Context, collection string, filter any, batchSize int, onProgress func(deleted int64)) (int64, error) {
+	if err := c.validateBatchArgs("delete many batched", filter, batchSize); err != nil {
+		return 0, err
+	}
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	var deleted int64
+	var last any
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+
+		ids, err := c.nextBatch(ctx, collection, filter, last, batchSize)
+		if err != nil {
+			return deleted, err
+		}
+		if len(ids) == 0 {
+			return deleted, nil
+		}
+		last = ids[len(ids)-1]
+
+		result, err := c.deleteMany(ctx, collection, bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			return deleted, err
+		}
+		deleted += result.DeletedCount
+
+		if onProgress != nil {
+			onProgress(deleted)
+		}
+		if len(ids) < batchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// UpdateManyBatched applies update to all documents matching filter in batches of
+// batchSize, ordered by _id, and returns the number of modified documents.
+// onProgress (optional) is called after each batch with the total modified so far.
+// The operation stops between batches when ctx is cancelled, returning the count
+// modified up to that point together with the context error.
+//
+// Example:
+//
+//	modified, err := client.UpdateManyBatched(ctx, "users",
+//	    bson.M{"plan": "legacy"},
+//	    bson.M{"$set": bson.M{"plan": "basic"}},
+//	    500, nil)
+func (c *Client) UpdateManyBatched(ctx context.Context, collection string, filter any, update any, batchSize int, onProgress func(modified int64)) (int64, error) {
+	if err := c.validateBatchArgs("update many batched", filter, batchSize); err != nil {
+		return 0, err
+	}
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	var modified int64
+	var last any
+	for {
+		if err := ctx.Err(); err != nil {
+			return modified, err
+		}
+
+		ids, err := c.nextBatch(ctx, collection, filter, last, batchSize)
+		if err != nil {
+			return modified, err
+		}
+		if len(ids) == 0 {
+			return modified, nil
+		}
+		last = ids[len(ids)-1]
+
+		batchFilter := bson.D{{Key: "$and", Value: bson.A{filter, bson.M{"_id": bson.M{"$in": ids}}}}}
+		result, err := c.updateMany(ctx, collection, batchFilter, update)
+		if err != nil {
+			return modified, err
+		}
+		modified += result.ModifiedCount
+
+		if onProgress != nil {
+			onProgress(modified)
+		}
+		if len(ids) < batchSize {
+			return modified, nil
+		}
+	}
+}
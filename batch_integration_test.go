@@ -0,0 +1,85 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestClient_Batched_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	cfg := DefaultConfig()
+	WithURI(container.URI)(&cfg)
+	WithDatabase(database)(&cfg)
+
+	client, err := New(cfg)
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := NewRepository[User](client, "users")
+
+	seed := func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		users := make([]User, 0, 7)
+		for i := range 7 {
+			users = append(users, User{Name: "User", Email: "user@test.com", Age: i, Active: i < 5})
+		}
+		_, err := repo.CreateMany(ctx, users)
+		require.NoError(t, err)
+	}
+
+	t.Run("DeleteManyBatched deletes matching documents", func(t *testing.T) {
+		seed(t)
+
+		var progress []int64
+		deleted, err := client.DeleteManyBatched(ctx, "users", bson.M{"active": true}, 2, func(n int64) {
+			progress = append(progress, n)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), deleted)
+		assert.Equal(t, []int64{2, 4, 5}, progress)
+
+		count, err := repo.CountAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("UpdateManyBatched updates matching documents", func(t *testing.T) {
+		seed(t)
+
+		var progress []int64
+		modified, err := client.UpdateManyBatched(ctx, "users", bson.M{"active": true},
+			bson.M{"$set": bson.M{"name": "Updated"}}, 3, func(n int64) {
+				progress = append(progress, n)
+			})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), modified)
+		assert.Equal(t, []int64{3, 5}, progress)
+
+		count, err := repo.Count(ctx, bson.M{"name": "Updated"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), count)
+	})
+
+	t.Run("UpdateManyBatched stops when context is cancelled", func(t *testing.T) {
+		seed(t)
+
+		cancelCtx, cancel := context.WithCancel(ctx)
+		modified, err := client.UpdateManyBatched(cancelCtx, "users", nil,
+			bson.M{"$inc": bson.M{"age": 100}}, 2, func(int64) { cancel() })
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, int64(2), modified)
+	})
+}
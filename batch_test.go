@@ -0,0 +1,50 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestClient_Batched_InvalidBatchSize(t *testing.T) {
+	client := &Client{}
+	ctx := context.Background()
+
+	t.Run("DeleteManyBatched", func(t *testing.T) {
+		_, err := client.DeleteManyBatched(ctx, "users", nil, 0, nil)
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "delete many batched", opErr.Op)
+	})
+
+	t.Run("UpdateManyBatched", func(t *testing.T) {
+		_, err := client.UpdateManyBatched(ctx, "users", nil, bson.M{}, -1, nil)
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "update many batched", opErr.Op)
+	})
+}
+
+func TestClient_Batched_ClosedClient(t *testing.T) {
+	client := &Client{closed: true}
+	ctx := context.Background()
+
+	_, err := client.DeleteManyBatched(ctx, "users", nil, 10, nil)
+	assert.ErrorIs(t, err, ErrClientClosed)
+
+	_, err = client.UpdateManyBatched(ctx, "users", nil, bson.M{"$set": bson.M{"a": 1}}, 10, nil)
+	assert.ErrorIs(t, err, ErrClientClosed)
+}
+
+func TestClient_Batched_CancelledContext(t *testing.T) {
+	client := &Client{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	deleted, err := client.DeleteManyBatched(ctx, "users", nil, 10, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, deleted)
+}
@@ -0,0 +1,125 @@
+package mongo_kit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Operation Budgets
+//
+// This file provides time budgets carried by contexts: a total time for a request that
+// is divided across its sequential operations, so one slow query cannot consume the
+// whole request deadline, and the server-side maxTimeMS derived from context deadlines.
+//
+// See docs/operations.md for detailed usage guide and examples.
+
+// budgetKey is the context key of a budget.
+type budgetKey struct{}
+
+// budget is the time left for the operations of a context.
+type budget struct {
+	deadline time.Time
+
+	mu         sync.Mutex
+	operations int // Operations still expected, 0 when not configured
+}
+
+// BudgetOption configures a budget.
+type BudgetOption func(*budget)
+
+// BudgetOperations splits the budget evenly across n expected operations. Time an
+// operation does not use carries over to the next ones, and operations beyond the n-th
+// may use all the time left.
+func BudgetOperations(n int) BudgetOption {
+	return func(b *budget) {
+		b.operations = max(n, 0)
+	}
+}
+
+// WithBudget returns a copy of ctx with a total time budget for the operations run with
+// it. Each repository read and write takes a share of the time left, as a context
+// deadline and, for finds, counts, distincts and aggregations, as the server-side
+// maxTimeMS, so the server abandons a slow query instead of letting it run on.
+//
+// By default an operation may use half of the time left, so a slow query always leaves
+// time for the next ones; BudgetOperations divides the budget evenly instead. A
+// maxTime set in the options of an operation overrides its share of the budget.
+// Once the budget is spent, operations fail with a TimeoutError.
+//
+// Example:
+//
+//	ctx = mongo_kit.WithBudget(ctx, 300*time.Millisecond, mongo_kit.BudgetOperations(3))
+//	user, err := users.FindByID(ctx, userID)       // up to 100ms
+//	orders, err := orders.Find(ctx, byUser)        // up to 100ms plus what FindByID left
+//	count, err := reviews.Count(ctx, byUser)       // all the time left
+func WithBudget(ctx context.Context, total time.Duration, opts ...BudgetOption) context.Context {
+	b := &budget{deadline: time.Now().Add(total)}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return context.WithValue(ctx, budgetKey{}, b)
+}
+
+// BudgetRemaining returns the time left in the budget of ctx. The boolean is false if
+// ctx has no budget.
+func BudgetRemaining(ctx context.Context) (time.Duration, bool) {
+	b, ok := ctx.Value(budgetKey{}).(*budget)
+	if !ok {
+		return 0, false
+	}
+	return max(time.Until(b.deadline), 0), true
+}
+
+// share returns the time the next operation may use.
+func (b *budget) share() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	remaining := time.Until(b.deadline)
+	if remaining <= 0 {
+		return 0
+	}
+	switch {
+	case b.operations > 1:
+		share := remaining / time.Duration(b.operations)
+		b.operations--
+		return share
+	case b.operations == 1:
+		return remaining
+	default:
+		return remaining / 2
+	}
+}
+
+// deadlineMargin is subtracted from the time left before a context deadline to derive
+// maxTimeMS, so the server gives up before the client stops waiting for the reply.
+const deadlineMargin = 50 * time.Millisecond
+
+// budgeted returns ctx bounded by the share of its budget the next operation may use,
+// and a server-side time limit: the time left before the deadline of ctx, less
+// deadlineMargin, or the share of the budget if smaller. A spent budget returns an
+// expired context. Without a budget, ctx is returned unchanged, and without a deadline
+// either the limit is zero. The caller must call the returned cancel function.
+func budgeted(ctx context.Context) (context.Context, time.Duration, context.CancelFunc) {
+	var maxTime time.Duration
+	deadline, hasDeadline := ctx.Deadline()
+	if hasDeadline {
+		maxTime = time.Until(deadline) - deadlineMargin
+	}
+
+	b, ok := ctx.Value(budgetKey{}).(*budget)
+	if !ok {
+		if !hasDeadline {
+			return ctx, 0, func() {}
+		}
+		// maxTimeMS has millisecond resolution and 0 means no limit.
+		return ctx, max(maxTime, time.Millisecond), func() {}
+	}
+	share := b.share()
+	ctx, cancel := context.WithTimeout(ctx, share)
+	if !hasDeadline || share < maxTime {
+		maxTime = share
+	}
+	return ctx, max(maxTime, time.Millisecond), cancel
+}
@@ -0,0 +1,106 @@
+package mongo_kit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestWithBudget_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	var mu sync.Mutex
+	var maxTimes []int64
+	monitor := &event.CommandMonitor{Started: func(_ context.Context, e *event.CommandStartedEvent) {
+		if e.CommandName != "find" {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if v, ok := e.Command.Lookup("maxTimeMS").AsInt64OK(); ok {
+			maxTimes = append(maxTimes, v)
+		}
+	}}
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database),
+		WithClientOptions(options.Client().SetMonitor(monitor)))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	repo := NewRepository[bson.M](client, "users")
+	ctx := WithBudget(context.Background(), 2*time.Second, BudgetOperations(2))
+
+	_, err = repo.Find(ctx, bson.M{})
+	require.NoError(t, err)
+	_, err = repo.Find(ctx, bson.M{})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, maxTimes, 2)
+	assert.InDelta(t, 1000, maxTimes[0], 100, "the first find gets half the budget")
+	assert.Greater(t, maxTimes[1], maxTimes[0], "the second find gets the time left")
+}
+
+func TestContextDeadline_MaxTime_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	var mu sync.Mutex
+	maxTimes := map[string]int64{}
+	monitor := &event.CommandMonitor{Started: func(_ context.Context, e *event.CommandStartedEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if v, ok := e.Command.Lookup("maxTimeMS").AsInt64OK(); ok {
+			maxTimes[e.CommandName] = v
+		}
+	}}
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database),
+		WithClientOptions(options.Client().SetMonitor(monitor)))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	repo := NewRepository[bson.M](client, "users")
+
+	_, err = repo.Find(context.Background(), bson.M{})
+	require.NoError(t, err)
+	mu.Lock()
+	assert.Empty(t, maxTimes, "no maxTimeMS without a deadline")
+	mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = repo.Find(ctx, bson.M{})
+	require.NoError(t, err)
+	_, err = repo.Count(ctx, bson.M{})
+	require.NoError(t, err)
+	_, err = repo.Aggregate(ctx, bson.A{})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, name := range []string{"find", "aggregate"} {
+		require.Contains(t, maxTimes, name)
+		assert.InDelta(t, 1950, maxTimes[name], 100, name)
+		assert.Less(t, maxTimes[name], int64(2000), "%s leaves a margin before the deadline", name)
+	}
+}
@@ -0,0 +1,105 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestBudget_Share(t *testing.T) {
+	t.Run("halves the time left by default", func(t *testing.T) {
+		b := &budget{deadline: time.Now().Add(time.Second)}
+		first := b.share()
+		assert.InDelta(t, 500*time.Millisecond, first, float64(20*time.Millisecond))
+		assert.InDelta(t, 500*time.Millisecond, b.share(), float64(20*time.Millisecond), "unused time carries over")
+	})
+
+	t.Run("splits evenly across expected operations", func(t *testing.T) {
+		b := &budget{deadline: time.Now().Add(300 * time.Millisecond)}
+		BudgetOperations(3)(b)
+		assert.InDelta(t, 100*time.Millisecond, b.share(), float64(10*time.Millisecond))
+		assert.InDelta(t, 150*time.Millisecond, b.share(), float64(10*time.Millisecond))
+		assert.InDelta(t, 300*time.Millisecond, b.share(), float64(10*time.Millisecond))
+		assert.InDelta(t, 300*time.Millisecond, b.share(), float64(10*time.Millisecond), "operations beyond n use all the time left")
+	})
+
+	t.Run("spent budget", func(t *testing.T) {
+		b := &budget{deadline: time.Now().Add(-time.Second)}
+		assert.Zero(t, b.share())
+	})
+}
+
+func TestWithBudget(t *testing.T) {
+	ctx := context.Background()
+
+	_, ok := BudgetRemaining(ctx)
+	assert.False(t, ok)
+	same, maxTime, cancel := budgeted(ctx)
+	cancel()
+	assert.Equal(t, ctx, same)
+	assert.Zero(t, maxTime)
+
+	ctx = WithBudget(ctx, 200*time.Millisecond)
+	remaining, ok := BudgetRemaining(ctx)
+	require.True(t, ok)
+	assert.InDelta(t, 200*time.Millisecond, remaining, float64(10*time.Millisecond))
+
+	opCtx, maxTime, cancel := budgeted(ctx)
+	defer cancel()
+	deadline, ok := opCtx.Deadline()
+	require.True(t, ok)
+	assert.InDelta(t, 100*time.Millisecond, time.Until(deadline), float64(10*time.Millisecond))
+	assert.InDelta(t, 100*time.Millisecond, maxTime, float64(10*time.Millisecond))
+}
+
+func TestBudgeted_ContextDeadline(t *testing.T) {
+	t.Run("derives maxTime from the deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		same, maxTime, opCancel := budgeted(ctx)
+		defer opCancel()
+		assert.Equal(t, ctx, same)
+		assert.InDelta(t, time.Second-deadlineMargin, maxTime, float64(10*time.Millisecond))
+	})
+
+	t.Run("keeps a minimum of one millisecond", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), deadlineMargin/2)
+		defer cancel()
+		_, maxTime, opCancel := budgeted(ctx)
+		defer opCancel()
+		assert.Equal(t, time.Millisecond, maxTime)
+	})
+
+	t.Run("uses the smaller of the deadline and the budget share", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_, maxTime, opCancel := budgeted(WithBudget(ctx, 200*time.Millisecond))
+		defer opCancel()
+		assert.InDelta(t, 100*time.Millisecond, maxTime, float64(10*time.Millisecond))
+
+		_, maxTime, opCancel = budgeted(WithBudget(ctx, time.Minute))
+		defer opCancel()
+		assert.InDelta(t, time.Second-deadlineMargin, maxTime, float64(10*time.Millisecond))
+	})
+}
+
+func TestWithBudget_Spent(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	repo := NewRepository[bson.M](client, "users")
+
+	ctx := WithBudget(context.Background(), 0)
+	mock.AddResponses(testhelpers.CursorResponse("testdb.users"))
+	_, err = repo.Find(ctx, bson.M{})
+
+	var timeoutErr *TimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, "find", timeoutErr.Op)
+}
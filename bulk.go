@@ -0,0 +1,170 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Bulk Writes
+//
+// This file provides bulk writes and the BulkError type that reports which writes of
+// a partially failed InsertMany or BulkWrite succeeded, so callers can resume or compensate.
+//
+// See docs/operations.md for detailed usage guide and examples.
+
+// BulkItemError describes the failure of a single write in a bulk operation.
+type BulkItemError struct {
+	Index   int    // Position of the failed write in the request
+	Code    int    // Server error code
+	Message string // Server error message
+	Cause   error  // The write error, as a DuplicateKeyError or ValidationError when applicable
+}
+
+// BulkError represents a bulk operation in which some writes failed.
+// With unordered writes every write is attempted, so writes not listed in Errors succeeded;
+// with ordered writes the operation stops at the first failure.
+// Errors of individual writes, such as a DuplicateKeyError, are available through errors.As.
+type BulkError struct {
+	Errors        []BulkItemError // Failed writes, in request order
+	InsertedIDs   []any           // The _id of every inserted document (InsertMany only)
+	UpsertedIDs   map[int64]any   // The _id of each upserted document by request index (BulkWrite only)
+	InsertedCount int64           // Number of documents inserted
+	MatchedCount  int64           // Number of documents matched by updates and replacements
+	ModifiedCount int64           // Number of documents modified by updates and replacements
+	DeletedCount  int64           // Number of documents deleted
+	UpsertedCount int64           // Number of documents upserted
+	Cause         error           // The underlying error from the MongoDB driver
+}
+
+func (e *BulkError) Error() string {
+	if len(e.Errors) == 0 {
+		return fmt.Sprintf("mongo: bulk write failed: %v", e.Cause)
+	}
+	first := e.Errors[0]
+	return fmt.Sprintf("mongo: bulk write failed for %d write(s), first at index %d: %s", len(e.Errors), first.Index, first.Message)
+}
+
+// Unwrap returns the underlying driver error followed by the errors of the failed writes.
+func (e *BulkError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors)+1)
+	errs = append(errs, e.Cause)
+	for _, item := range e.Errors {
+		errs = append(errs, item.Cause)
+	}
+	return errs
+}
+
+// FailedIndexes returns the request positions of the writes that failed.
+func (e *BulkError) FailedIndexes() []int {
+	indexes := make([]int, len(e.Errors))
+	for i, item := range e.Errors {
+		indexes[i] = item.Index
+	}
+	return indexes
+}
+
+// newBulkError returns a BulkError for err, or nil if err is not a bulk write failure.
+func newBulkError(err error) *BulkError {
+	var bwe mongo.BulkWriteException
+	if !errors.As(err, &bwe) {
+		return nil
+	}
+
+	bulkErr := &BulkError{Cause: err, Errors: make([]BulkItemError, len(bwe.WriteErrors))}
+	for i, writeErr := range bwe.WriteErrors {
+		bulkErr.Errors[i] = BulkItemError{
+			Index:   writeErr.Index,
+			Code:    writeErr.Code,
+			Message: writeErr.Message,
+			Cause:   classifyCause(mongo.WriteException{WriteErrors: mongo.WriteErrors{writeErr.WriteError}}),
+		}
+	}
+
+	return bulkErr
+}
+
+// newInsertManyError returns a BulkError for a failed InsertMany, with the IDs of the
+// documents that were inserted, or nil if err is not a bulk write failure.
+// ids are the _id values of all requested documents, in request order.
+func newInsertManyError(err error, ids []any, ordered bool) *BulkError {
+	bulkErr := newBulkError(err)
+	if bulkErr == nil {
+		return nil
+	}
+
+	failed := make(map[int]bool, len(bulkErr.Errors))
+	stop := len(ids)
+	for _, item := range bulkErr.Errors {
+		failed[item.Index] = true
+		stop = min(stop, item.Index)
+	}
+
+	bulkErr.InsertedIDs = make([]any, 0, len(ids))
+	for i, id := range ids {
+		if ordered && i >= stop {
+			break
+		}
+		if !failed[i] {
+			bulkErr.InsertedIDs = append(bulkErr.InsertedIDs, id)
+		}
+	}
+	bulkErr.InsertedCount = int64(len(bulkErr.InsertedIDs))
+
+	return bulkErr
+}
+
+// isOrderedInsert reports whether an InsertMany with opts stops at the first error.
+// Writes are ordered unless the last option setting Ordered disables it.
+func isOrderedInsert(opts []*options.InsertManyOptions) bool {
+	ordered := true
+	for _, opt := range opts {
+		if opt != nil && opt.Ordered != nil {
+			ordered = *opt.Ordered
+		}
+	}
+	return ordered
+}
+
+// bulkWrite executes write models against the collection.
+// When some writes fail the error wraps a BulkError with the counts of the writes that succeeded.
+func (c *Client) bulkWrite(ctx context.Context, collection string, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	if len(models) == 0 {
+		return nil, newOperationError("bulk write", errors.New("at least one write model must be provided"))
+	}
+
+	ctx, _, cancel := budgeted(ctx)
+	defer cancel()
+
+	coll := c.getCollection(collection)
+	result, err := coll.BulkWrite(ctx, models, opts...)
+	c.invalidateQueries(collection)
+	if err != nil {
+		if bulkErr := newBulkError(err); bulkErr != nil {
+			if result != nil {
+				bulkErr.InsertedCount = result.InsertedCount
+				bulkErr.MatchedCount = result.MatchedCount
+				bulkErr.ModifiedCount = result.ModifiedCount
+				bulkErr.DeletedCount = result.DeletedCount
+				bulkErr.UpsertedCount = result.UpsertedCount
+				bulkErr.UpsertedIDs = result.UpsertedIDs
+			}
+			err = bulkErr
+		}
+		return nil, c.collectionError(ctx, start, "bulk write", collection, err, nil, nil)
+	}
+
+	return result, nil
+}
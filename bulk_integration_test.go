@@ -0,0 +1,112 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestBulkError_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	cfg := DefaultConfig()
+	WithURI(container.URI)(&cfg)
+	WithDatabase(database)(&cfg)
+
+	client, err := New(cfg)
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := NewRepository[User](client, "users")
+
+	t.Run("unordered CreateMany reports inserted IDs", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		dup := primitive.NewObjectID()
+		users := []User{
+			{ID: primitive.NewObjectID(), Name: "A"},
+			{ID: dup, Name: "B"},
+			{ID: dup, Name: "C"},
+			{ID: primitive.NewObjectID(), Name: "D"},
+		}
+
+		_, err := repo.CreateMany(ctx, users, options.InsertMany().SetOrdered(false))
+		var bulkErr *BulkError
+		require.ErrorAs(t, err, &bulkErr)
+		assert.Equal(t, []int{2}, bulkErr.FailedIndexes())
+		assert.Equal(t, []any{users[0].ID, users[1].ID, users[3].ID}, bulkErr.InsertedIDs)
+		assert.Equal(t, int64(3), bulkErr.InsertedCount)
+
+		var dupErr *DuplicateKeyError
+		assert.ErrorAs(t, err, &dupErr)
+
+		count, err := repo.CountAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("ordered CreateMany stops at first failure", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		dup := primitive.NewObjectID()
+		users := []User{
+			{ID: dup, Name: "A"},
+			{ID: dup, Name: "B"},
+			{ID: primitive.NewObjectID(), Name: "C"},
+		}
+
+		_, err := repo.CreateMany(ctx, users)
+		var bulkErr *BulkError
+		require.ErrorAs(t, err, &bulkErr)
+		assert.Equal(t, []any{users[0].ID}, bulkErr.InsertedIDs)
+	})
+
+	t.Run("BulkWrite reports counts of successful writes", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		id := primitive.NewObjectID()
+		_, err := repo.Create(ctx, User{ID: id, Name: "Existing"})
+		require.NoError(t, err)
+
+		models := []mongo.WriteModel{
+			mongo.NewInsertOneModel().SetDocument(User{ID: primitive.NewObjectID(), Name: "New"}),
+			mongo.NewInsertOneModel().SetDocument(User{ID: id, Name: "Duplicate"}),
+			mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": id}).SetUpdate(bson.M{"$set": bson.M{"name": "Updated"}}),
+			mongo.NewUpdateOneModel().SetFilter(bson.M{"name": "Missing"}).SetUpdate(bson.M{"$set": bson.M{"age": 1}}).SetUpsert(true),
+		}
+
+		_, err = repo.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+		var bulkErr *BulkError
+		require.ErrorAs(t, err, &bulkErr)
+		assert.Equal(t, []int{1}, bulkErr.FailedIndexes())
+		assert.Equal(t, int64(1), bulkErr.InsertedCount)
+		assert.Equal(t, int64(1), bulkErr.ModifiedCount)
+		assert.Equal(t, int64(1), bulkErr.UpsertedCount)
+		assert.Contains(t, bulkErr.UpsertedIDs, int64(3))
+
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "bulk write", opErr.Op)
+	})
+
+	t.Run("BulkWrite succeeds", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		result, err := repo.BulkWrite(ctx, []mongo.WriteModel{
+			mongo.NewInsertOneModel().SetDocument(User{Name: "A"}),
+			mongo.NewInsertOneModel().SetDocument(User{Name: "B"}),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), result.InsertedCount)
+	})
+}
@@ -0,0 +1,98 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func bulkWriteException(indexes ...int) mongo.BulkWriteException {
+	bwe := mongo.BulkWriteException{}
+	for _, index := range indexes {
+		bwe.WriteErrors = append(bwe.WriteErrors, mongo.BulkWriteError{
+			WriteError: mongo.WriteError{Index: index, Code: 11000, Message: "E11000 duplicate key error collection: db.users index: _id_ dup key: { _id: 1 }"},
+		})
+	}
+	return bwe
+}
+
+func TestBulkError(t *testing.T) {
+	t.Run("wraps item errors", func(t *testing.T) {
+		bulkErr := newBulkError(bulkWriteException(1, 3))
+		require.NotNil(t, bulkErr)
+		assert.Equal(t, []int{1, 3}, bulkErr.FailedIndexes())
+		assert.Equal(t, 11000, bulkErr.Errors[0].Code)
+		assert.Equal(t, "mongo: bulk write failed for 2 write(s), first at index 1: E11000 duplicate key error collection: db.users index: _id_ dup key: { _id: 1 }", bulkErr.Error())
+
+		var dupErr *DuplicateKeyError
+		require.ErrorAs(t, bulkErr, &dupErr)
+		assert.Equal(t, "_id_", dupErr.Index)
+	})
+
+	t.Run("survives operation error wrapping", func(t *testing.T) {
+		err := newOperationError("bulk write", newBulkError(bulkWriteException(0)))
+
+		var bulkErr *BulkError
+		require.ErrorAs(t, err, &bulkErr)
+		assert.Equal(t, []int{0}, bulkErr.FailedIndexes())
+	})
+
+	t.Run("other errors are not wrapped", func(t *testing.T) {
+		assert.Nil(t, newBulkError(errors.New("boom")))
+	})
+}
+
+func TestNewInsertManyError(t *testing.T) {
+	ids := []any{"a", "b", "c", "d", "e"}
+
+	t.Run("ordered stops at first failure", func(t *testing.T) {
+		bulkErr := newInsertManyError(bulkWriteException(2), ids, true)
+		require.NotNil(t, bulkErr)
+		assert.Equal(t, []any{"a", "b"}, bulkErr.InsertedIDs)
+		assert.Equal(t, int64(2), bulkErr.InsertedCount)
+	})
+
+	t.Run("unordered skips failures", func(t *testing.T) {
+		bulkErr := newInsertManyError(bulkWriteException(1, 3), ids, false)
+		require.NotNil(t, bulkErr)
+		assert.Equal(t, []any{"a", "c", "e"}, bulkErr.InsertedIDs)
+		assert.Equal(t, int64(3), bulkErr.InsertedCount)
+	})
+
+	t.Run("other errors are not wrapped", func(t *testing.T) {
+		assert.Nil(t, newInsertManyError(errors.New("boom"), ids, false))
+	})
+}
+
+func TestIsOrderedInsert(t *testing.T) {
+	assert.True(t, isOrderedInsert(nil))
+	assert.True(t, isOrderedInsert([]*options.InsertManyOptions{nil, options.InsertMany()}))
+	assert.False(t, isOrderedInsert([]*options.InsertManyOptions{options.InsertMany().SetOrdered(false)}))
+	assert.True(t, isOrderedInsert([]*options.InsertManyOptions{
+		options.InsertMany().SetOrdered(false),
+		options.InsertMany().SetOrdered(true),
+	}))
+}
+
+func TestClient_BulkWrite_Validation(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("closed client", func(t *testing.T) {
+		client := &Client{closed: true}
+		_, err := client.bulkWrite(ctx, "users", []mongo.WriteModel{mongo.NewInsertOneModel()})
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("empty models", func(t *testing.T) {
+		client := &Client{}
+		_, err := client.bulkWrite(ctx, "users", nil)
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "bulk write", opErr.Op)
+	})
+}
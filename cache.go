@@ -0,0 +1,125 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TTL Cache
+//
+// This file provides a key-value cache stored in a collection, with typed values and
+// expiry enforced by a TTL index.
+//
+// See docs/coordination.md for detailed usage guide and examples.
+
+// Cache stores values of type V by string key in a collection, one document per key.
+// Entries expire after their TTL: reads ignore expired entries and a TTL index
+// removes them in the background.
+type Cache[V any] struct {
+	client     *Client
+	collection string
+	defaultTTL time.Duration
+	index      lazyIndex
+}
+
+// cacheEntry is the document stored for a cached value.
+type cacheEntry[V any] struct {
+	Key       string    `bson:"_id"`
+	Value     V         `bson:"value"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// NewCache returns a Cache storing values in collection of the client's database.
+// Entries set without an explicit TTL expire after defaultTTL.
+//
+// Example:
+//
+//	cache := mongo_kit.NewCache[Report](client, "report_cache", 10*time.Minute)
+func NewCache[V any](client *Client, collection string, defaultTTL time.Duration) *Cache[V] {
+	return &Cache[V]{client: client, collection: collection, defaultTTL: defaultTTL}
+}
+
+// Get returns the value cached for key, and false if there is none or it expired.
+//
+// Example:
+//
+//	report, ok, err := cache.Get(ctx, "daily:2024-03-01")
+func (c *Cache[V]) Get(ctx context.Context, key string) (V, bool, error) {
+	var entry cacheEntry[V]
+	filter := bson.D{
+		{Key: "_id", Value: key},
+		{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: c.client.Now()}}},
+	}
+	err := c.client.findOne(ctx, c.collection, filter, &entry)
+	if errors.Is(err, ErrNotFound) {
+		return entry.Value, false, nil
+	}
+	if err != nil {
+		return entry.Value, false, err
+	}
+	return entry.Value, true, nil
+}
+
+// Set caches value for key, replacing any previous value. The entry expires after
+// ttl, or after the default TTL of the cache if ttl is 0.
+//
+// Example:
+//
+//	err := cache.Set(ctx, "daily:2024-03-01", report, 0)
+func (c *Cache[V]) Set(ctx context.Context, key string, value V, ttl time.Duration) error {
+	if key == "" || ttl < 0 {
+		return newOperationError("cache set", errors.New("key is required and ttl cannot be negative"))
+	}
+	if ttl == 0 {
+		ttl = c.defaultTTL
+	}
+	if ttl <= 0 {
+		return newOperationError("cache set", errors.New("ttl is required when the cache has no default TTL"))
+	}
+	if err := c.index.ensure(ctx, c.client, c.collection, expiryIndex("expires_at")); err != nil {
+		return err
+	}
+
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "value", Value: value},
+		{Key: "expires_at", Value: c.client.Now().Add(ttl)},
+	}}}
+	_, err := c.client.updateOne(ctx, c.collection, bson.D{{Key: "_id", Value: key}}, update,
+		options.Update().SetUpsert(true))
+	return err
+}
+
+// Delete removes the value cached for key. Deleting a missing key is not an error.
+func (c *Cache[V]) Delete(ctx context.Context, key string) error {
+	_, err := c.client.deleteOne(ctx, c.collection, bson.D{{Key: "_id", Value: key}})
+	return err
+}
+
+// GetOrLoad returns the value cached for key, or calls load, caches its result with
+// the default TTL and returns it. Errors of load are returned as is and nothing is
+// cached. Concurrent misses of the same key each call load.
+//
+// Example:
+//
+//	report, err := cache.GetOrLoad(ctx, "daily:"+day, func(ctx context.Context) (Report, error) {
+//	    return buildReport(ctx, day)
+//	})
+func (c *Cache[V]) GetOrLoad(ctx context.Context, key string, load func(ctx context.Context) (V, error)) (V, error) {
+	value, ok, err := c.Get(ctx, key)
+	if err != nil || ok {
+		return value, err
+	}
+
+	value, err = load(ctx)
+	if err != nil {
+		return value, err
+	}
+	if err := c.Set(ctx, key, value, 0); err != nil {
+		return value, err
+	}
+	return value, nil
+}
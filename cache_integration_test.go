@@ -0,0 +1,62 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestCache_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	clock := testhelpers.NewFakeClock(time.Now())
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database), WithClock(clock))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	cache := NewCache[cachedReport](client, "reports", time.Minute)
+
+	require.NoError(t, cache.Set(ctx, "daily", cachedReport{Total: 5}, 0))
+	value, ok, err := cache.Get(ctx, "daily")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 5, value.Total)
+
+	require.NoError(t, cache.Set(ctx, "daily", cachedReport{Total: 6}, 0))
+	value, _, err = cache.Get(ctx, "daily")
+	require.NoError(t, err)
+	assert.Equal(t, 6, value.Total)
+
+	clock.Advance(2 * time.Minute)
+	_, ok, err = cache.Get(ctx, "daily")
+	require.NoError(t, err)
+	assert.False(t, ok, "expired entries are ignored before the TTL monitor removes them")
+
+	calls := 0
+	load := func(context.Context) (cachedReport, error) {
+		calls++
+		return cachedReport{Total: 9}, nil
+	}
+	_, err = cache.GetOrLoad(ctx, "weekly", load)
+	require.NoError(t, err)
+	value, err = cache.GetOrLoad(ctx, "weekly", load)
+	require.NoError(t, err)
+	assert.Equal(t, 9, value.Total)
+	assert.Equal(t, 1, calls)
+
+	require.NoError(t, cache.Delete(ctx, "weekly"))
+	_, ok, err = cache.Get(ctx, "weekly")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
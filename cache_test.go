@@ -0,0 +1,79 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type cachedReport struct {
+	Total int `bson:"total"`
+}
+
+func TestCache(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	cache := NewCache[cachedReport](client, "cache", time.Minute)
+
+	t.Run("Get hit and miss", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CursorResponse("testdb.cache",
+			bson.D{{Key: "_id", Value: "a"}, {Key: "value", Value: bson.D{{Key: "total", Value: 3}}}}))
+		value, ok, err := cache.Get(ctx, "a")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, 3, value.Total)
+
+		mock.AddResponses(testhelpers.CursorResponse("testdb.cache"))
+		_, ok, err = cache.Get(ctx, "b")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("GetOrLoad caches loaded values", func(t *testing.T) {
+		mock.AddResponses(
+			testhelpers.CursorResponse("testdb.cache"),
+			testhelpers.SuccessResponse(), // createIndexes
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+		calls := 0
+		value, err := cache.GetOrLoad(ctx, "c", func(context.Context) (cachedReport, error) {
+			calls++
+			return cachedReport{Total: 7}, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 7, value.Total)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("GetOrLoad returns load errors", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CursorResponse("testdb.cache"))
+		loadErr := errors.New("boom")
+		_, err := cache.GetOrLoad(ctx, "d", func(context.Context) (cachedReport, error) {
+			return cachedReport{}, loadErr
+		})
+		assert.ErrorIs(t, err, loadErr)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 0}))
+		assert.NoError(t, cache.Delete(ctx, "missing"))
+	})
+}
+
+func TestCache_Set_InvalidArguments(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Error(t, NewCache[int](&Client{}, "cache", time.Minute).Set(ctx, "", 1, 0))
+	assert.Error(t, NewCache[int](&Client{}, "cache", time.Minute).Set(ctx, "k", 1, -time.Second))
+	assert.Error(t, NewCache[int](&Client{}, "cache", 0).Set(ctx, "k", 1, 0))
+}
@@ -0,0 +1,320 @@
+// Package cdc publishes the change stream of a collection to a message bus, turning
+// MongoDB changes into integration events with resume tokens, per-document ordering
+// and dead-letter handling.
+//
+// See docs/cdc.md for detailed usage guide and examples.
+package cdc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+const (
+	defaultBatchSize    = 100
+	defaultMaxAttempts  = 3
+	defaultRetryBackoff = 100 * time.Millisecond
+)
+
+// Message is what a Publisher sends to the bus.
+type Message struct {
+	Topic   string            // Destination, e.g. a Kafka topic or NATS subject
+	Key     []byte            // Partitioning key; the default mapper uses the document key
+	Value   []byte            // Payload
+	Headers map[string]string // Optional headers
+}
+
+// Publisher sends messages to a message bus. Adapters for Kafka, NATS, SNS or others
+// implement it; Publish must return only once the bus accepted the message.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+}
+
+// PublisherFunc adapts a function to a Publisher.
+type PublisherFunc func(ctx context.Context, msg Message) error
+
+// Publish calls f(ctx, msg).
+func (f PublisherFunc) Publish(ctx context.Context, msg Message) error {
+	return f(ctx, msg)
+}
+
+// Mapper turns a change event into the messages to publish. It may return no messages
+// to skip the event.
+type Mapper func(event mongokit.ChangeEvent) ([]Message, error)
+
+// DefaultMapper publishes each event to the topic "database.collection", keyed by the
+// document key, with the event as relaxed Extended JSON and an "operation" header.
+func DefaultMapper(event mongokit.ChangeEvent) ([]Message, error) {
+	key, err := bson.MarshalExtJSON(event.DocumentKey, false, false)
+	if err != nil {
+		return nil, fmt.Errorf("cdc: encode document key: %w", err)
+	}
+	value, err := bson.MarshalExtJSON(event.Raw, false, false)
+	if err != nil {
+		return nil, fmt.Errorf("cdc: encode event: %w", err)
+	}
+	return []Message{{
+		Topic:   event.Namespace.String(),
+		Key:     key,
+		Value:   value,
+		Headers: map[string]string{"operation": event.OperationType},
+	}}, nil
+}
+
+// Bridge describes a change stream published to a message bus.
+type Bridge struct {
+	Name         string          // Identifies the bridge in the token and dead-letter stores (required with Tokens)
+	Collection   string          // Collection to watch (required)
+	Pipeline     any             // Filters the change events (default: all events)
+	FullDocument bool            // Look up the current document for update events
+	Publisher    Publisher       // Destination of the messages (required)
+	Map          Mapper          // Maps events to messages (default: DefaultMapper)
+	Tokens       TokenStore      // Persists the resume token after each batch (default: none, start from now)
+	DeadLetters  DeadLetterStore // Receives events that cannot be mapped or published (default: none, stop with an error)
+	Workers      int             // Events published in parallel, keeping the order per document (default: 1)
+	BatchSize    int             // Maximum events published between two token saves (default: 100)
+	MaxAttempts  int             // Publish attempts per message before dead-lettering (default: 3)
+	RetryBackoff time.Duration   // Wait before the second attempt, doubled for each next one (default: 100ms)
+}
+
+// validate checks that the bridge can run.
+func (b *Bridge) validate() error {
+	if b.Collection == "" {
+		return errors.New("cdc: Collection is required")
+	}
+	if b.Publisher == nil {
+		return errors.New("cdc: Publisher is required")
+	}
+	if b.Tokens != nil && b.Name == "" {
+		return errors.New("cdc: Name is required with Tokens")
+	}
+	if b.Workers < 0 || b.BatchSize < 0 || b.MaxAttempts < 0 || b.RetryBackoff < 0 {
+		return errors.New("cdc: Workers, BatchSize, MaxAttempts and RetryBackoff cannot be negative")
+	}
+	return nil
+}
+
+// withDefaults returns a copy of b with defaults applied.
+func (b Bridge) withDefaults() Bridge {
+	if b.Map == nil {
+		b.Map = DefaultMapper
+	}
+	if b.Workers == 0 {
+		b.Workers = 1
+	}
+	if b.BatchSize == 0 {
+		b.BatchSize = defaultBatchSize
+	}
+	if b.MaxAttempts == 0 {
+		b.MaxAttempts = defaultMaxAttempts
+	}
+	if b.RetryBackoff == 0 {
+		b.RetryBackoff = defaultRetryBackoff
+	}
+	return b
+}
+
+// Run watches bridge.Collection and publishes its change events until ctx is done or
+// an event can be neither published nor dead-lettered. It returns nil when ctx is
+// cancelled.
+//
+// Events are read in batches of up to BatchSize and spread over Workers by document
+// key, so changes of one document are published in order while different documents
+// are published in parallel. After a batch is published the resume token of its last
+// event is saved to Tokens, and a restarted bridge resumes after it. Events of a batch
+// interrupted by a failure are published again, so consumers should be idempotent.
+//
+// Example:
+//
+//	err := cdc.Run(ctx, client, cdc.Bridge{
+//	    Name:        "orders-to-kafka",
+//	    Collection:  "orders",
+//	    Publisher:   kafkaPublisher,
+//	    Tokens:      cdc.MongoTokens(client, "cdc_tokens"),
+//	    DeadLetters: cdc.MongoDeadLetters(client, "cdc_dead_letters"),
+//	    Workers:     8,
+//	})
+func Run(ctx context.Context, client *mongokit.Client, bridge Bridge) error {
+	if err := bridge.validate(); err != nil {
+		return err
+	}
+	bridge = bridge.withDefaults()
+
+	opts := options.ChangeStream()
+	if bridge.FullDocument {
+		opts.SetFullDocument(options.UpdateLookup)
+	}
+	if bridge.Tokens != nil {
+		token, found, err := bridge.Tokens.Load(ctx, bridge.Name)
+		if err != nil {
+			return fmt.Errorf("cdc: load resume token: %w", err)
+		}
+		if found {
+			opts.SetResumeAfter(token)
+		}
+	}
+
+	stream, err := client.Watch(ctx, bridge.Collection, bridge.Pipeline, opts)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stream.Close(context.Background()) }()
+
+	for {
+		batch, err := nextBatch(ctx, stream, bridge.BatchSize)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if err := publishBatch(ctx, bridge, batch); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		if bridge.Tokens != nil {
+			if err := bridge.Tokens.Save(ctx, bridge.Name, batch[len(batch)-1].ResumeToken); err != nil {
+				return fmt.Errorf("cdc: save resume token: %w", err)
+			}
+		}
+	}
+}
+
+// nextBatch waits for the next event and returns it with the events already received,
+// up to size events.
+func nextBatch(ctx context.Context, stream *mongo.ChangeStream, size int) ([]mongokit.ChangeEvent, error) {
+	var batch []mongokit.ChangeEvent
+	for len(batch) < size {
+		if len(batch) > 0 && stream.RemainingBatchLength() == 0 {
+			break
+		}
+		if !stream.Next(ctx) {
+			if err := stream.Err(); err != nil {
+				return nil, err
+			}
+			return nil, ctx.Err()
+		}
+		event, err := mongokit.DecodeChangeEvent(stream)
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, event)
+	}
+	return batch, nil
+}
+
+// publishBatch publishes the events of batch, spread over the bridge's workers by
+// document key. It returns the first error of a worker.
+func publishBatch(ctx context.Context, bridge Bridge, batch []mongokit.ChangeEvent) error {
+	partitions := make([][]mongokit.ChangeEvent, bridge.Workers)
+	for _, event := range batch {
+		p := partition(event.DocumentKey, bridge.Workers)
+		partitions[p] = append(partitions[p], event)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for _, events := range partitions {
+		if len(events) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, event := range events {
+				if err := handleEvent(ctx, bridge, event); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// partition returns the worker of a document key.
+func partition(key bson.Raw, workers int) int {
+	if workers <= 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+	return int(h.Sum32() % uint32(workers))
+}
+
+// handleEvent maps and publishes event, and dead-letters it if that fails.
+func handleEvent(ctx context.Context, bridge Bridge, event mongokit.ChangeEvent) error {
+	err := mapAndPublish(ctx, bridge, event)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	if bridge.DeadLetters == nil {
+		return err
+	}
+	if dlErr := bridge.DeadLetters.Put(ctx, bridge.Name, event, err); dlErr != nil {
+		return fmt.Errorf("cdc: dead-letter event: %w (after: %v)", dlErr, err)
+	}
+	return nil
+}
+
+// mapAndPublish maps event and publishes its messages with retries.
+func mapAndPublish(ctx context.Context, bridge Bridge, event mongokit.ChangeEvent) error {
+	messages, err := bridge.Map(event)
+	if err != nil {
+		return fmt.Errorf("cdc: map %s event: %w", event.OperationType, err)
+	}
+	for _, msg := range messages {
+		if err := publish(ctx, bridge, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// publish sends msg, retrying with exponential backoff up to bridge.MaxAttempts times.
+func publish(ctx context.Context, bridge Bridge, msg Message) error {
+	backoff := bridge.RetryBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = bridge.Publisher.Publish(ctx, msg); err == nil {
+			return nil
+		}
+		if attempt >= bridge.MaxAttempts {
+			return fmt.Errorf("cdc: publish to %s failed after %d attempts: %w", msg.Topic, attempt, err)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
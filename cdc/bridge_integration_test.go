@@ -0,0 +1,92 @@
+package cdc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestRun_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := mongokit.New(mongokit.DefaultConfig(), mongokit.WithURI(container.URI), mongokit.WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	repo := mongokit.NewRepository[bson.M](client, "orders")
+	tokens := MongoTokens(client, "cdc_tokens")
+
+	run := func(publisher Publisher) (stop func()) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, Run(ctx, client, Bridge{
+				Name:       "orders",
+				Collection: "orders",
+				Publisher:  publisher,
+				Tokens:     tokens,
+				Workers:    2,
+			}))
+		}()
+		return func() {
+			cancel()
+			wg.Wait()
+		}
+	}
+
+	publisher := &recordingPublisher{}
+	published := func() int {
+		publisher.mu.Lock()
+		defer publisher.mu.Unlock()
+		return len(publisher.messages)
+	}
+
+	stop := run(publisher)
+	time.Sleep(500 * time.Millisecond) // let the stream open before writing
+	ctx := context.Background()
+	for i := range 3 {
+		_, err := repo.Create(ctx, bson.M{"_id": i, "status": "new"})
+		require.NoError(t, err)
+	}
+	testhelpers.AssertEventually(t, func() error {
+		if published() < 3 {
+			return assert.AnError
+		}
+		return nil
+	}, 10*time.Second)
+	stop()
+
+	// Changes made while the bridge is down are published after a restart.
+	_, err = repo.UpdateByID(ctx, 0, bson.M{"$set": bson.M{"status": "paid"}})
+	require.NoError(t, err)
+
+	stop = run(publisher)
+	defer stop()
+	testhelpers.AssertEventually(t, func() error {
+		if published() < 4 {
+			return assert.AnError
+		}
+		return nil
+	}, 10*time.Second)
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+	assert.Len(t, publisher.messages, 4)
+	assert.Equal(t, database+".orders", publisher.messages[0].Topic)
+	assert.Equal(t, "update", publisher.messages[3].Headers["operation"])
+}
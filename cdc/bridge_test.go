@@ -0,0 +1,216 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func testEvent(t *testing.T, id int, op string) mongokit.ChangeEvent {
+	t.Helper()
+	key, err := bson.Marshal(bson.M{"_id": id})
+	require.NoError(t, err)
+	raw, err := bson.Marshal(bson.M{"operationType": op, "documentKey": bson.M{"_id": id}})
+	require.NoError(t, err)
+	return mongokit.ChangeEvent{
+		OperationType: op,
+		Namespace:     mongokit.ChangeNamespace{Database: "shop", Collection: "orders"},
+		DocumentKey:   key,
+		Raw:           raw,
+	}
+}
+
+type recordingPublisher struct {
+	mu       sync.Mutex
+	messages []Message
+	failures int
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, msg Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("bus unavailable")
+	}
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+type recordingDeadLetters struct {
+	mu     sync.Mutex
+	events []mongokit.ChangeEvent
+}
+
+func (d *recordingDeadLetters) Put(_ context.Context, _ string, event mongokit.ChangeEvent, _ error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.events = append(d.events, event)
+	return nil
+}
+
+func TestBridge_Validate(t *testing.T) {
+	publisher := &recordingPublisher{}
+	tests := []struct {
+		name   string
+		bridge Bridge
+		want   string
+	}{
+		{"valid", Bridge{Collection: "orders", Publisher: publisher}, ""},
+		{"missing collection", Bridge{Publisher: publisher}, "Collection is required"},
+		{"missing publisher", Bridge{Collection: "orders"}, "Publisher is required"},
+		{"tokens without name", Bridge{Collection: "orders", Publisher: publisher, Tokens: &mongoTokens{}}, "Name is required"},
+		{"negative workers", Bridge{Collection: "orders", Publisher: publisher, Workers: -1}, "cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.bridge.validate()
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestBridge_WithDefaults(t *testing.T) {
+	b := Bridge{}.withDefaults()
+	assert.NotNil(t, b.Map)
+	assert.Equal(t, 1, b.Workers)
+	assert.Equal(t, defaultBatchSize, b.BatchSize)
+	assert.Equal(t, defaultMaxAttempts, b.MaxAttempts)
+	assert.Equal(t, defaultRetryBackoff, b.RetryBackoff)
+}
+
+func TestDefaultMapper(t *testing.T) {
+	messages, err := DefaultMapper(testEvent(t, 7, "insert"))
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+
+	msg := messages[0]
+	assert.Equal(t, "shop.orders", msg.Topic)
+	assert.JSONEq(t, `{"_id": 7}`, string(msg.Key))
+	assert.JSONEq(t, `{"operationType": "insert", "documentKey": {"_id": 7}}`, string(msg.Value))
+	assert.Equal(t, "insert", msg.Headers["operation"])
+}
+
+func TestPartition(t *testing.T) {
+	key, _ := bson.Marshal(bson.M{"_id": 1})
+	assert.Equal(t, 0, partition(key, 1))
+	assert.Equal(t, partition(key, 8), partition(key, 8))
+	assert.Less(t, partition(key, 8), 8)
+}
+
+func TestPublish_Retries(t *testing.T) {
+	ctx := context.Background()
+	bridge := Bridge{MaxAttempts: 3, RetryBackoff: time.Millisecond}
+
+	publisher := &recordingPublisher{failures: 2}
+	bridge.Publisher = publisher
+	require.NoError(t, publish(ctx, bridge, Message{Topic: "t"}))
+	assert.Len(t, publisher.messages, 1)
+
+	publisher = &recordingPublisher{failures: 3}
+	bridge.Publisher = publisher
+	err := publish(ctx, bridge, Message{Topic: "t"})
+	assert.ErrorContains(t, err, "failed after 3 attempts")
+}
+
+func TestPublishBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keeps the order of each document", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+		bridge := Bridge{Publisher: publisher, Workers: 4}.withDefaults()
+
+		var batch []mongokit.ChangeEvent
+		for i := range 20 {
+			batch = append(batch, testEvent(t, i%3, strconv.Itoa(i)))
+		}
+		require.NoError(t, publishBatch(ctx, bridge, batch))
+		require.Len(t, publisher.messages, 20)
+
+		last := map[string]int{}
+		for _, msg := range publisher.messages {
+			seq, err := strconv.Atoi(msg.Headers["operation"])
+			require.NoError(t, err)
+			if prev, ok := last[string(msg.Key)]; ok {
+				assert.Greater(t, seq, prev, "events of %s are published in order", msg.Key)
+			}
+			last[string(msg.Key)] = seq
+		}
+	})
+
+	t.Run("dead-letters failing events", func(t *testing.T) {
+		deadLetters := &recordingDeadLetters{}
+		bridge := Bridge{
+			Publisher:   &recordingPublisher{},
+			DeadLetters: deadLetters,
+			Map: func(event mongokit.ChangeEvent) ([]Message, error) {
+				if event.OperationType == "delete" {
+					return nil, errors.New("unsupported")
+				}
+				return DefaultMapper(event)
+			},
+		}.withDefaults()
+
+		require.NoError(t, publishBatch(ctx, bridge, []mongokit.ChangeEvent{
+			testEvent(t, 1, "insert"),
+			testEvent(t, 1, "delete"),
+		}))
+		require.Len(t, deadLetters.events, 1)
+		assert.Equal(t, "delete", deadLetters.events[0].OperationType)
+	})
+
+	t.Run("fails without a dead-letter store", func(t *testing.T) {
+		bridge := Bridge{
+			Publisher:    &recordingPublisher{failures: 10},
+			MaxAttempts:  1,
+			RetryBackoff: time.Millisecond,
+		}.withDefaults()
+
+		err := publishBatch(ctx, bridge, []mongokit.ChangeEvent{testEvent(t, 1, "insert")})
+		assert.ErrorContains(t, err, "failed after 1 attempts")
+	})
+}
+
+func TestMongoTokens(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := mongokit.NewFromClient(mock.Client, mongokit.DefaultConfig(), mongokit.WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	tokens := MongoTokens(client, "cdc_tokens")
+	token, err := bson.Marshal(bson.M{"_data": "8263"})
+	require.NoError(t, err)
+
+	t.Run("loads by the bridge name", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CursorResponse("testdb.cdc_tokens", bson.D{
+			{Key: "_id", Value: "orders-to-kafka"},
+			{Key: "token", Value: bson.Raw(token)},
+		}))
+		got, found, err := tokens.Load(ctx, "orders-to-kafka")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, bson.Raw(token), got)
+	})
+
+	t.Run("missing token", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CursorResponse("testdb.cdc_tokens"))
+		_, found, err := tokens.Load(ctx, "orders-to-kafka")
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+}
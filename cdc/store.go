@@ -0,0 +1,103 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// TokenStore persists the resume token of each bridge.
+type TokenStore interface {
+	// Load returns the resume token of bridge, and false if the bridge has none.
+	Load(ctx context.Context, bridge string) (token bson.Raw, found bool, err error)
+	// Save records token as the resume token of bridge.
+	Save(ctx context.Context, bridge string, token bson.Raw) error
+}
+
+// DeadLetterStore receives the events a bridge could not map or publish.
+type DeadLetterStore interface {
+	// Put records event with the error that made it fail.
+	Put(ctx context.Context, bridge string, event mongokit.ChangeEvent, cause error) error
+}
+
+// resumeToken is the document stored by MongoTokens, one per bridge.
+type resumeToken struct {
+	Bridge    string    `bson:"_id"`
+	Token     bson.Raw  `bson:"token"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+// mongoTokens stores resume tokens in a collection of the client's database.
+type mongoTokens struct {
+	client *mongokit.Client
+	repo   *mongokit.Repository[resumeToken]
+}
+
+// MongoTokens returns a TokenStore that keeps one document per bridge in collection,
+// with the resume token and the time of the last update.
+//
+// Example:
+//
+//	bridge.Tokens = cdc.MongoTokens(client, "cdc_tokens")
+func MongoTokens(client *mongokit.Client, collection string) TokenStore {
+	return &mongoTokens{client: client, repo: mongokit.NewRepository[resumeToken](client, collection)}
+}
+
+func (s *mongoTokens) Load(ctx context.Context, bridge string) (bson.Raw, bool, error) {
+	doc, err := s.repo.FindOne(ctx, bson.M{"_id": bridge})
+	if errors.Is(err, mongokit.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return doc.Token, true, nil
+}
+
+func (s *mongoTokens) Save(ctx context.Context, bridge string, token bson.Raw) error {
+	_, err := s.repo.UpdateOne(ctx,
+		bson.M{"_id": bridge},
+		bson.M{"$set": bson.M{"token": token, "updated_at": s.client.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// DeadLetter is a document stored by MongoDeadLetters.
+type DeadLetter struct {
+	Bridge   string    `bson:"bridge"`    // Bridge that failed to publish the event
+	Event    bson.Raw  `bson:"event"`     // The change event, as received
+	Error    string    `bson:"error"`     // Why the event failed
+	FailedAt time.Time `bson:"failed_at"` // When the event was dead-lettered
+}
+
+// mongoDeadLetters stores failed events in a collection of the client's database.
+type mongoDeadLetters struct {
+	client *mongokit.Client
+	repo   *mongokit.Repository[DeadLetter]
+}
+
+// MongoDeadLetters returns a DeadLetterStore that inserts one DeadLetter document per
+// failed event into collection, for inspection and replay.
+//
+// Example:
+//
+//	bridge.DeadLetters = cdc.MongoDeadLetters(client, "cdc_dead_letters")
+func MongoDeadLetters(client *mongokit.Client, collection string) DeadLetterStore {
+	return &mongoDeadLetters{client: client, repo: mongokit.NewRepository[DeadLetter](client, collection)}
+}
+
+func (s *mongoDeadLetters) Put(ctx context.Context, bridge string, event mongokit.ChangeEvent, cause error) error {
+	_, err := s.repo.Create(ctx, DeadLetter{
+		Bridge:   bridge,
+		Event:    event.Raw,
+		Error:    cause.Error(),
+		FailedAt: s.client.Now(),
+	})
+	return err
+}
@@ -0,0 +1,204 @@
+package mongo_kit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Change Streams
+//
+// This file provides change streams on a collection, a database or the whole
+// deployment, and the ChangeEvent type decoding their events. Change streams require a replica set or
+// sharded cluster.
+//
+// See docs/operations.md for detailed usage guide and examples.
+
+// ChangeNamespace identifies the collection of a change event.
+type ChangeNamespace struct {
+	Database   string `bson:"db"`
+	Collection string `bson:"coll"`
+}
+
+// String returns the namespace as "database.collection".
+func (n ChangeNamespace) String() string {
+	return n.Database + "." + n.Collection
+}
+
+// UpdateDescription lists the fields changed by an update event.
+type UpdateDescription struct {
+	UpdatedFields bson.Raw `bson:"updatedFields"`
+	RemovedFields []string `bson:"removedFields"`
+}
+
+// ChangeEvent is a change stream event. Documents are kept as bson.Raw, so events of
+// any collection can be decoded.
+type ChangeEvent struct {
+	ResumeToken       bson.Raw            `bson:"_id"`                         // Token to resume the stream after this event
+	OperationType     string              `bson:"operationType"`               // "insert", "update", "replace", "delete", ...
+	Namespace         ChangeNamespace     `bson:"ns"`                          // Collection of the changed document
+	DocumentKey       bson.Raw            `bson:"documentKey,omitempty"`       // _id (and shard key) of the changed document
+	FullDocument      bson.Raw            `bson:"fullDocument,omitempty"`      // Document after the change, when available
+	UpdateDescription *UpdateDescription  `bson:"updateDescription,omitempty"` // Changed fields of update events
+	ClusterTime       primitive.Timestamp `bson:"clusterTime"`                 // Time of the change in the oplog
+	Raw               bson.Raw            `bson:"-"`                           // The whole event, as received
+}
+
+// WatchOptions configures a change stream opened by Watch, WatchDatabase or
+// WatchCluster, as an alternative to building driver options.
+type WatchOptions struct {
+	UpdateLookup         bool      // Include the current document in update events (fullDocument: updateLookup)
+	StartAtOperationTime time.Time // Replay changes made since this time, within the oplog window (default: now)
+	ResumeAfter          bson.Raw  // Resume after the event with this token (default: none)
+	BatchSize            int32     // Events per batch (default: server default)
+}
+
+// ChangeStreamOptions returns the driver options of o.
+//
+// Example:
+//
+//	stream, err := client.WatchDatabase(ctx, "", nil, mongo_kit.WatchOptions{
+//	    UpdateLookup:         true,
+//	    StartAtOperationTime: time.Now().Add(-10 * time.Minute),
+//	}.ChangeStreamOptions())
+func (o WatchOptions) ChangeStreamOptions() *options.ChangeStreamOptions {
+	opts := options.ChangeStream()
+	if o.UpdateLookup {
+		opts.SetFullDocument(options.UpdateLookup)
+	}
+	if !o.StartAtOperationTime.IsZero() {
+		opts.SetStartAtOperationTime(&primitive.Timestamp{T: uint32(o.StartAtOperationTime.Unix())})
+	}
+	if len(o.ResumeAfter) > 0 {
+		opts.SetResumeAfter(o.ResumeAfter)
+	}
+	if o.BatchSize > 0 {
+		opts.SetBatchSize(o.BatchSize)
+	}
+	return opts
+}
+
+// DecodeChangeEvent decodes the current event of stream.
+//
+// Example:
+//
+//	for stream.Next(ctx) {
+//	    event, err := mongo_kit.DecodeChangeEvent(stream)
+//	    ...
+//	}
+func DecodeChangeEvent(stream *mongo.ChangeStream) (ChangeEvent, error) {
+	var event ChangeEvent
+	if err := bson.Unmarshal(stream.Current, &event); err != nil {
+		return event, fmt.Errorf("mongo: decode change event: %w", err)
+	}
+	event.Raw = append(bson.Raw(nil), stream.Current...)
+	return event, nil
+}
+
+// Watch opens a change stream on collection of the default database. pipeline filters
+// or reshapes the events (nil for all events). The caller must close the stream.
+//
+// Example:
+//
+//	stream, err := client.Watch(ctx, "orders", mongo.Pipeline{
+//	    {{Key: "$match", Value: bson.M{"operationType": "insert"}}},
+//	}, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+//	if err != nil {
+//	    return err
+//	}
+//	defer stream.Close(ctx)
+func (c *Client) Watch(ctx context.Context, collection string, pipeline any, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	if pipeline == nil {
+		pipeline = mongo.Pipeline{}
+	}
+
+	start := time.Now()
+	coll := c.getCollection(collection)
+	stream, err := coll.Watch(ctx, pipeline, opts...)
+	if err != nil {
+		return nil, c.collectionError(ctx, start, "watch", collection, err, nil, nil)
+	}
+
+	return stream, nil
+}
+
+// WatchDatabase opens a change stream on every collection of database db (the default
+// database if db is empty). pipeline filters or reshapes the events (nil for all
+// events). The caller must close the stream.
+//
+// Example:
+//
+//	stream, err := client.WatchDatabase(ctx, "", mongo.Pipeline{
+//	    {{Key: "$match", Value: bson.M{"ns.coll": bson.M{"$in": bson.A{"orders", "payments"}}}}},
+//	}, mongo_kit.WatchOptions{UpdateLookup: true}.ChangeStreamOptions())
+//	if err != nil {
+//	    return err
+//	}
+//	defer stream.Close(ctx)
+func (c *Client) WatchDatabase(ctx context.Context, db string, pipeline any, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	if pipeline == nil {
+		pipeline = mongo.Pipeline{}
+	}
+
+	database := c.defaultDB
+	if db != "" {
+		database = c.client.Database(db)
+	}
+
+	start := time.Now()
+	stream, err := database.Watch(ctx, pipeline, opts...)
+	if err != nil {
+		return nil, c.operationError(ctx, start, "watch database", err)
+	}
+
+	return stream, nil
+}
+
+// WatchCluster opens a change stream on every collection of every database of the
+// deployment, except the admin, local and config databases. pipeline filters or
+// reshapes the events (nil for all events). The caller must close the stream.
+//
+// Example:
+//
+//	stream, err := client.WatchCluster(ctx, mongo.Pipeline{
+//	    {{Key: "$match", Value: bson.M{"operationType": "drop"}}},
+//	})
+func (c *Client) WatchCluster(ctx context.Context, pipeline any, opts ...*options.ChangeStreamOptions) (*mongo.ChangeStream, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	if pipeline == nil {
+		pipeline = mongo.Pipeline{}
+	}
+
+	start := time.Now()
+	stream, err := c.client.Watch(ctx, pipeline, opts...)
+	if err != nil {
+		return nil, c.operationError(ctx, start, "watch cluster", err)
+	}
+
+	return stream, nil
+}
@@ -0,0 +1,113 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestClient_Watch_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	stream, err := client.Watch(ctx, "orders", mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": bson.A{"insert", "update"}}}}},
+	}, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	require.NoError(t, err)
+	defer func() { _ = stream.Close(context.Background()) }()
+
+	repo := NewRepository[bson.M](client, "orders")
+	_, err = repo.Create(ctx, bson.M{"_id": 1, "status": "new"})
+	require.NoError(t, err)
+	_, err = repo.UpdateByID(ctx, 1, bson.M{"$set": bson.M{"status": "paid"}})
+	require.NoError(t, err)
+
+	require.True(t, stream.Next(ctx))
+	event, err := DecodeChangeEvent(stream)
+	require.NoError(t, err)
+	assert.Equal(t, "insert", event.OperationType)
+	assert.Equal(t, database+".orders", event.Namespace.String())
+	assert.NotEmpty(t, event.ResumeToken)
+	assert.NotEmpty(t, event.Raw)
+
+	require.True(t, stream.Next(ctx))
+	event, err = DecodeChangeEvent(stream)
+	require.NoError(t, err)
+	assert.Equal(t, "update", event.OperationType)
+	assert.Equal(t, "paid", event.FullDocument.Lookup("status").StringValue())
+	assert.Equal(t, "paid", event.UpdateDescription.UpdatedFields.Lookup("status").StringValue())
+}
+
+func TestClient_WatchDatabase_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	t.Run("WatchDatabase streams every collection", func(t *testing.T) {
+		stream, err := client.WatchDatabase(ctx, "", nil, WatchOptions{UpdateLookup: true}.ChangeStreamOptions())
+		require.NoError(t, err)
+		defer func() { _ = stream.Close(context.Background()) }()
+
+		_, err = NewRepository[bson.M](client, "orders").Create(ctx, bson.M{"_id": 1})
+		require.NoError(t, err)
+		_, err = NewRepository[bson.M](client, "payments").Create(ctx, bson.M{"_id": 1})
+		require.NoError(t, err)
+
+		var namespaces []string
+		for range 2 {
+			require.True(t, stream.Next(ctx))
+			event, err := DecodeChangeEvent(stream)
+			require.NoError(t, err)
+			namespaces = append(namespaces, event.Namespace.String())
+		}
+		assert.Equal(t, []string{database + ".orders", database + ".payments"}, namespaces)
+	})
+
+	t.Run("WatchCluster replays from StartAtOperationTime", func(t *testing.T) {
+		since := time.Now().Add(-time.Second)
+		otherDatabase := testhelpers.IsolatedDatabase(t)
+		other := client.Database().Client().Database(otherDatabase).Collection("events")
+		_, err := other.InsertOne(ctx, bson.M{"_id": 1})
+		require.NoError(t, err)
+
+		stream, err := client.WatchCluster(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"ns.db": otherDatabase}}},
+		}, WatchOptions{StartAtOperationTime: since}.ChangeStreamOptions())
+		require.NoError(t, err)
+		defer func() { _ = stream.Close(context.Background()) }()
+
+		require.True(t, stream.Next(ctx))
+		event, err := DecodeChangeEvent(stream)
+		require.NoError(t, err)
+		assert.Equal(t, otherDatabase+".events", event.Namespace.String())
+	})
+}
@@ -0,0 +1,63 @@
+package mongo_kit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestChangeEvent_Decode(t *testing.T) {
+	raw, err := bson.Marshal(bson.M{
+		"_id":           bson.M{"_data": "826"},
+		"operationType": "update",
+		"ns":            bson.M{"db": "shop", "coll": "orders"},
+		"documentKey":   bson.M{"_id": 1},
+		"updateDescription": bson.M{
+			"updatedFields": bson.M{"status": "paid"},
+			"removedFields": bson.A{"draft"},
+		},
+	})
+	require.NoError(t, err)
+
+	var event ChangeEvent
+	require.NoError(t, bson.Unmarshal(raw, &event))
+	assert.Equal(t, "update", event.OperationType)
+	assert.Equal(t, "shop.orders", event.Namespace.String())
+	assert.Equal(t, int32(1), event.DocumentKey.Lookup("_id").Int32())
+	require.NotNil(t, event.UpdateDescription)
+	assert.Equal(t, "paid", event.UpdateDescription.UpdatedFields.Lookup("status").StringValue())
+	assert.Equal(t, []string{"draft"}, event.UpdateDescription.RemovedFields)
+	assert.Equal(t, "826", event.ResumeToken.Lookup("_data").StringValue())
+}
+
+func TestWatchOptions_ChangeStreamOptions(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		opts := WatchOptions{}.ChangeStreamOptions()
+		assert.Nil(t, opts.FullDocument)
+		assert.Nil(t, opts.StartAtOperationTime)
+		assert.Nil(t, opts.ResumeAfter)
+		assert.Nil(t, opts.BatchSize)
+	})
+
+	t.Run("all options", func(t *testing.T) {
+		token, err := bson.Marshal(bson.D{{Key: "_data", Value: "826"}})
+		require.NoError(t, err)
+		opts := WatchOptions{
+			UpdateLookup:         true,
+			StartAtOperationTime: time.Unix(1700000000, 0),
+			ResumeAfter:          token,
+			BatchSize:            50,
+		}.ChangeStreamOptions()
+
+		require.NotNil(t, opts.FullDocument)
+		assert.Equal(t, options.UpdateLookup, *opts.FullDocument)
+		assert.Equal(t, &primitive.Timestamp{T: 1700000000}, opts.StartAtOperationTime)
+		assert.Equal(t, bson.Raw(token), opts.ResumeAfter)
+		assert.Equal(t, int32(50), *opts.BatchSize)
+	})
+}
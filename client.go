@@ -7,7 +7,10 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -22,8 +25,11 @@ type Client struct {
 	config    Config
 	client    *mongo.Client
 	defaultDB *mongo.Database
+	topology  *topologyMonitor
 	mu        sync.RWMutex
 	closed    bool
+
+	queryGenerations sync.Map // Namespace to *atomic.Uint64, incremented when its cached queries are invalidated
 }
 
 // New creates a new MongoDB client with the given configuration.
@@ -52,36 +58,99 @@ type Client struct {
 //   - Connection to MongoDB fails
 //   - Ping verification fails
 func New(cfg Config, opts ...Option) (*Client, error) {
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
 	if err := cfg.validate(); err != nil {
 		return nil, err
 	}
 
+	topology := &topologyMonitor{}
+	mongoClient, err := connect(context.Background(), cfg, topology)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		config:    cfg,
+		client:    mongoClient,
+		defaultDB: mongoClient.Database(cfg.Database),
+		topology:  topology,
+		closed:    false,
+	}, nil
+}
+
+// NewFromClient creates a client that wraps an already connected driver client instead
+// of connecting with the URI of the configuration. It lets tests run against a driver
+// client backed by a mock deployment, such as testing.NewMockClient, without a server.
+//
+// Only the Database field of the configuration is required and no ping is sent.
+// Close disconnects mongoClient. ApplyConfig and Reconnect need a configuration with
+// a URI, as they connect a new driver client.
+//
+// Example:
+//
+//	mock := testhelpers.NewMockClient(t)
+//	client, err := mongo_kit.NewFromClient(mock.Client, mongo_kit.DefaultConfig(),
+//	    mongo_kit.WithDatabase("testdb"),
+//	)
+func NewFromClient(mongoClient *mongo.Client, cfg Config, opts ...Option) (*Client, error) {
 	for _, opt := range opts {
 		opt(&cfg)
 	}
 
-	var clientOpts *options.ClientOptions
-	// If user provided custom ClientOptions, use them as base
-	if cfg.ClientOptions != nil {
-		clientOpts = cfg.ClientOptions
-	} else {
-		clientOpts = options.Client()
+	if mongoClient == nil {
+		return nil, newConfigFieldError("client", "is required")
 	}
 
-	clientOpts.ApplyURI(cfg.URI)
-	clientOpts.SetMaxPoolSize(cfg.MaxPoolSize)
-	clientOpts.SetRetryWrites(true)
-	clientOpts.SetRetryReads(true)
+	if cfg.Database == "" {
+		return nil, newConfigFieldError("Database", "is required")
+	}
+
+	return &Client{
+		config:    cfg,
+		client:    mongoClient,
+		defaultDB: mongoClient.Database(cfg.Database),
+		topology:  &topologyMonitor{},
+		closed:    false,
+	}, nil
+}
+
+// connect creates a driver client for cfg and verifies the connection with a ping.
+// Credentials are fetched from the configured CredentialProvider, if any.
+// Topology events of the driver client are published to topology.
+// The configuration must already be validated.
+func connect(parent context.Context, cfg Config, topology *topologyMonitor) (*mongo.Client, error) {
+	clientOpts := cfg.clientOptions()
+	clientOpts.SetServerMonitor(topology.serverMonitor(cfg.now, clientOpts.ServerMonitor))
 
-	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	ctx, cancel := context.WithTimeout(parent, cfg.Timeout)
 	defer cancel()
 
+	if cfg.CredentialProvider != nil {
+		username, password, err := cfg.CredentialProvider(ctx)
+		if err != nil {
+			return nil, newConnectionError(fmt.Errorf("credential provider: %w", err))
+		}
+
+		// Keep auth settings from the URI or ClientOptions, such as the auth source or mechanism.
+		credential := options.Credential{}
+		if clientOpts.Auth != nil {
+			credential = *clientOpts.Auth
+		}
+		credential.Username = username
+		credential.Password = password
+		credential.PasswordSet = password != ""
+		clientOpts.SetAuth(credential)
+	}
+
 	mongoClient, err := mongo.Connect(ctx, clientOpts)
 	if err != nil {
 		return nil, newConnectionError(err)
 	}
 
-	ctx, cancel = context.WithTimeout(context.Background(), cfg.Timeout)
+	ctx, cancel = context.WithTimeout(parent, cfg.Timeout)
 	defer cancel()
 
 	if err := mongoClient.Ping(ctx, nil); err != nil {
@@ -91,12 +160,133 @@ func New(cfg Config, opts ...Option) (*Client, error) {
 		return nil, newConnectionError(err)
 	}
 
-	return &Client{
-		config:    cfg,
-		client:    mongoClient,
-		defaultDB: mongoClient.Database(cfg.Database),
-		closed:    false,
-	}, nil
+	if err := warmPool(ctx, mongoClient, cfg.WarmPool); err != nil {
+		if disconnectErr := mongoClient.Disconnect(context.Background()); disconnectErr != nil {
+			return nil, newConnectionError(fmt.Errorf("warm pool failed: %w, disconnect also failed: %w", err, disconnectErr))
+		}
+		return nil, newConnectionError(fmt.Errorf("warm pool: %w", err))
+	}
+
+	return mongoClient, nil
+}
+
+// warmPool issues n pings in parallel so the pool of mongoClient establishes up to n
+// connections. Returns the errors of the pings that failed.
+func warmPool(ctx context.Context, mongoClient *mongo.Client, n uint64) error {
+	if n <= 1 {
+		return nil // the ping of connect already established a connection
+	}
+
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = mongoClient.Ping(ctx, nil)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// ApplyConfig replaces the client configuration at runtime, e.g. to tune the pool
+// size from a config service without restarting the process.
+// A new driver client is connected and verified with a ping before it replaces the
+// current one; operations started afterwards use the new client, operations in
+// progress finish first, and the previous client is then disconnected.
+// If the new configuration is invalid or the connection fails, the current client
+// keeps running unchanged.
+//
+// Cursors and iterators opened before the swap belong to the previous client and
+// stop working once it is disconnected, so they should be drained first.
+//
+// Example:
+//
+//	cfg := mongo_kit.DefaultConfig()
+//	mongo_kit.WithURI(uri)(&cfg)
+//	mongo_kit.WithMaxPoolSize(400)(&cfg)
+//	if err := client.ApplyConfig(ctx, cfg); err != nil {
+//	    log.Printf("config not applied: %v", err)
+//	}
+func (c *Client) ApplyConfig(ctx context.Context, cfg Config) error {
+	c.mu.RLock()
+	closed := c.closed
+	c.mu.RUnlock()
+	if closed {
+		return ErrClientClosed
+	}
+
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	mongoClient, err := connect(ctx, cfg, c.topology)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		_ = mongoClient.Disconnect(ctx)
+		return ErrClientClosed
+	}
+	previous := c.client
+	c.config = cfg
+	c.client = mongoClient
+	c.defaultDB = mongoClient.Database(cfg.Database)
+	c.mu.Unlock()
+
+	if err := previous.Disconnect(ctx); err != nil {
+		return cfg.reportError(ctx, newOperationError("apply config", fmt.Errorf("disconnect previous client: %w", err)))
+	}
+	return nil
+}
+
+// Reconnect rebuilds the driver client with the current configuration, fetching
+// fresh credentials from the CredentialProvider. Use it after rotating a password.
+// See ApplyConfig for how the swap is performed.
+//
+// Example:
+//
+//	if err := client.Reconnect(ctx); err != nil {
+//	    log.Printf("reconnect failed: %v", err)
+//	}
+func (c *Client) Reconnect(ctx context.Context) error {
+	return c.ApplyConfig(ctx, c.Config())
+}
+
+// SetMaxPoolSize changes the maximum connection pool size at runtime.
+// It is a shorthand for ApplyConfig with the current configuration and the new size.
+//
+// Example:
+//
+//	err := client.SetMaxPoolSize(ctx, 400)
+func (c *Client) SetMaxPoolSize(ctx context.Context, size uint64) error {
+	cfg := c.Config()
+	cfg.MaxPoolSize = size
+	return c.ApplyConfig(ctx, cfg)
+}
+
+// Database returns the driver handle of the default database, as an escape hatch for
+// driver features the client does not cover. The handle belongs to the current driver
+// client, so fetch it again after ApplyConfig or Reconnect.
+//
+// Example:
+//
+//	names, err := client.Database().ListCollectionNames(ctx, bson.M{})
+func (c *Client) Database() *mongo.Database {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.defaultDB
+}
+
+// Config returns a copy of the configuration the client currently uses.
+func (c *Client) Config() Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config
 }
 
 // Close closes the MongoDB client connection gracefully.
@@ -115,7 +305,154 @@ func (c *Client) Close(ctx context.Context) error {
 	}
 
 	c.closed = true
-	return c.client.Disconnect(ctx)
+	err := c.client.Disconnect(ctx)
+	if c.topology != nil {
+		c.topology.close()
+	}
+	return err
+}
+
+// StartSession starts a client session for causally consistent reads or transactions.
+// Operations run with a context from ContextWithSession use the session.
+// The caller must call EndSession when done.
+//
+// Example:
+//
+//	sess, err := client.StartSession()
+//	if err != nil {
+//	    return err
+//	}
+//	defer sess.EndSession(ctx)
+//	_, err = userRepo.Create(mongo_kit.ContextWithSession(ctx, sess), user)
+func (c *Client) StartSession(opts ...*options.SessionOptions) (mongo.Session, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	sess, err := c.client.StartSession(opts...)
+	if err != nil {
+		return nil, newOperationError("start session", err)
+	}
+
+	return sess, nil
+}
+
+// transactionKey marks the contexts passed to WithTransaction callbacks.
+type transactionKey struct{}
+
+// ContextWithSession returns a copy of ctx carrying sess. Every Client and Repository
+// operation run with the returned context, or a context derived from it, uses sess, so
+// code paths can run inside or outside a session with the same method signatures.
+// A session must not be used by concurrent operations.
+//
+// Example:
+//
+//	sess, err := client.StartSession(options.Session().SetCausalConsistency(true))
+//	if err != nil {
+//	    return err
+//	}
+//	defer sess.EndSession(ctx)
+//
+//	ctx = mongo_kit.ContextWithSession(ctx, sess)
+//	_, err = orders.Create(ctx, order)
+//	order, err = orders.FindByID(ctx, id) // reads its own write
+func ContextWithSession(ctx context.Context, sess mongo.Session) context.Context {
+	return mongo.NewSessionContext(ctx, sess)
+}
+
+// ContextWithTransaction returns a copy of ctx carrying sess, on which the caller has
+// already started a transaction and remains responsible for committing or aborting it.
+// Operations run with the returned context join the transaction, and WithTransaction
+// called with it runs its callback in the transaction instead of starting another.
+//
+// Example:
+//
+//	if err := sess.StartTransaction(); err != nil {
+//	    return err
+//	}
+//	ctx = mongo_kit.ContextWithTransaction(ctx, sess)
+//	err = placeOrder(ctx) // may call client.WithTransaction
+//	if err != nil {
+//	    _ = sess.AbortTransaction(context.Background())
+//	    return err
+//	}
+//	return sess.CommitTransaction(ctx)
+func ContextWithTransaction(ctx context.Context, sess mongo.Session) context.Context {
+	return context.WithValue(ContextWithSession(ctx, sess), transactionKey{}, true)
+}
+
+// SessionFromContext returns the session carried by ctx, stored by ContextWithSession
+// or WithTransaction. The boolean is false if ctx carries no session.
+func SessionFromContext(ctx context.Context) (mongo.Session, bool) {
+	sess := mongo.SessionFromContext(ctx)
+	return sess, sess != nil
+}
+
+// WithTransaction runs fn in a transaction, committing it when fn returns nil and
+// aborting it otherwise. Operations run with the context passed to fn join the
+// transaction. Transient transaction errors and unknown commit results are retried, so
+// fn may run more than once and must be safe to repeat. Transactions require a
+// replica set or sharded cluster.
+//
+// The transaction runs on the session of ctx, if any (see ContextWithSession), or on a
+// new session. Called with the context of another WithTransaction callback, fn joins
+// the enclosing transaction instead of starting one, so transactional helpers compose.
+//
+// opts control the transaction semantics: read concern, write concern, read preference
+// and the maximum commit time. Unset values use the client defaults.
+//
+// The error returned by fn is returned unchanged; other failures, such as a failed
+// commit, are returned as an OperationError.
+//
+// Example:
+//
+//	err := client.WithTransaction(ctx, func(ctx context.Context) error {
+//	    if _, err := orders.Create(ctx, order); err != nil {
+//	        return err
+//	    }
+//	    _, err := stock.UpdateOne(ctx, bson.M{"sku": order.SKU}, bson.M{"$inc": bson.M{"qty": -1}})
+//	    return err
+//	}, options.Transaction().
+//	    SetWriteConcern(writeconcern.Majority()).
+//	    SetReadConcern(readconcern.Snapshot()).
+//	    SetMaxCommitTime(&maxCommit))
+func (c *Client) WithTransaction(ctx context.Context, fn func(ctx context.Context) error, opts ...*options.TransactionOptions) error {
+	if ctx.Value(transactionKey{}) != nil {
+		return fn(ctx)
+	}
+
+	sess, ok := SessionFromContext(ctx)
+	if !ok {
+		var err error
+		if sess, err = c.StartSession(); err != nil {
+			return err
+		}
+		defer sess.EndSession(context.Background())
+	} else {
+		c.mu.RLock()
+		err := c.checkState()
+		c.mu.RUnlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	var fnErr error
+	start := time.Now()
+	_, err := sess.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+		fnErr = fn(context.WithValue(sc, transactionKey{}, true))
+		return nil, fnErr
+	}, opts...)
+	if err == nil || (fnErr != nil && errors.Is(err, fnErr)) {
+		return err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.operationError(ctx, start, "with transaction", err)
 }
 
 // getCollection returns a handle to the specified collection in the default database.
@@ -136,6 +473,64 @@ func (c *Client) checkState() error {
 	return nil
 }
 
+// collectionError creates an OperationError for an operation on collection in the
+// default database. Redacted filter and update summaries are attached when
+// Config.ErrorQuerySummary is enabled; pass nil when the operation has none.
+// The error is passed through reportError; start is when the operation began.
+// The caller must hold c.mu.RLock().
+func (c *Client) collectionError(ctx context.Context, start time.Time, operation, collection string, cause error, filter, update any) error {
+	opErr := &OperationError{
+		Op:         operation,
+		Cause:      classifyCause(cause),
+		Database:   c.defaultDB.Name(),
+		Collection: collection,
+	}
+
+	if c.config.ErrorQuerySummary {
+		opErr.Filter = redactQuery(filter)
+		opErr.Update = redactQuery(update)
+	}
+
+	return c.reportError(ctx, start, opErr)
+}
+
+// operationError returns an OperationError for a failed operation that is not tied to
+// a collection. The error is passed through reportError; start is when the operation began.
+// The caller MUST hold c.mu.RLock().
+func (c *Client) operationError(ctx context.Context, start time.Time, operation string, cause error) error {
+	return c.reportError(ctx, start, newOperationError(operation, cause))
+}
+
+// reportError wraps the cause of a timed out OperationError in a TimeoutError recording
+// the time elapsed since start, then reports err to the ErrorHook.
+// The caller MUST hold c.mu.RLock().
+func (c *Client) reportError(ctx context.Context, start time.Time, err error) error {
+	var opErr *OperationError
+	if errors.As(err, &opErr) && isTimeout(opErr.Cause) {
+		var timeoutErr *TimeoutError
+		if !errors.As(opErr.Cause, &timeoutErr) {
+			opErr.Cause = &TimeoutError{Op: opErr.Op, Elapsed: time.Since(start), Cause: opErr.Cause}
+		}
+	}
+
+	return c.config.reportError(ctx, err)
+}
+
+// bsonRegistry returns the codec registry the client encodes and decodes with.
+// This method acquires c.mu.RLock() and must not be called while holding the lock.
+func (c *Client) bsonRegistry() *bsoncodec.Registry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.config.BSONRegistry != nil {
+		return c.config.BSONRegistry
+	}
+	if c.config.ClientOptions != nil && c.config.ClientOptions.Registry != nil {
+		return c.config.ClientOptions.Registry
+	}
+	return bson.DefaultRegistry
+}
+
 // CreateCollection creates a new collection with optional configuration.
 // If the collection already exists, this is a no-op (no error is returned).
 //
@@ -150,6 +545,7 @@ func (c *Client) CreateCollection(ctx context.Context, name string, opts ...*opt
 		return err
 	}
 
+	start := time.Now()
 	err := c.defaultDB.CreateCollection(ctx, name, opts...)
 	if err != nil {
 		// Check if collection already exists (MongoDB error code 48: NamespaceExists)
@@ -157,7 +553,7 @@ func (c *Client) CreateCollection(ctx context.Context, name string, opts ...*opt
 		if errors.As(err, &cmdErr) && cmdErr.Code == 48 {
 			return nil
 		}
-		return newOperationError("create collection", err)
+		return c.operationError(ctx, start, "create collection", err)
 	}
 
 	return nil
@@ -186,6 +582,7 @@ func (c *Client) CreateIndexes(ctx context.Context, collection string, indexes [
 		return nil, err
 	}
 
+	start := time.Now()
 	if len(indexes) == 0 {
 		return nil, newOperationError("create indexes", errors.New("at least one index model must be provided"))
 	}
@@ -193,8 +590,196 @@ func (c *Client) CreateIndexes(ctx context.Context, collection string, indexes [
 	coll := c.getCollection(collection)
 	names, err := coll.Indexes().CreateMany(ctx, indexes)
 	if err != nil {
-		return nil, newOperationError("create indexes", err)
+		return nil, c.operationError(ctx, start, "create indexes", err)
 	}
 
 	return names, nil
 }
+
+// CreateSearchIndex creates an Atlas Search or Atlas Vector Search index on the
+// specified collection and returns its name. The index is built asynchronously.
+// Search indexes require MongoDB Atlas or a local Atlas deployment.
+//
+// Example:
+//
+//	name, err := client.CreateSearchIndex(ctx, "products", mongo.SearchIndexModel{
+//	    Definition: bson.D{{Key: "mappings", Value: bson.D{{Key: "dynamic", Value: true}}}},
+//	    Options:    options.SearchIndexes().SetName("products_search"),
+//	})
+func (c *Client) CreateSearchIndex(ctx context.Context, collection string, model mongo.SearchIndexModel) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	coll := c.getCollection(collection)
+	name, err := coll.SearchIndexes().CreateOne(ctx, model)
+	if err != nil {
+		return "", c.operationError(ctx, start, "create search index", err)
+	}
+
+	return name, nil
+}
+
+// Ping verifies that the server is reachable using the primary read preference.
+//
+// Example:
+//
+//	if err := client.Ping(ctx); err != nil {
+//	    log.Printf("mongo unavailable: %v", err)
+//	}
+func (c *Client) Ping(ctx context.Context) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := c.client.Ping(ctx, nil); err != nil {
+		return c.operationError(ctx, start, "ping", err)
+	}
+
+	return nil
+}
+
+// ServerVersion returns the version of the MongoDB server, e.g. "7.0.12".
+//
+// Example:
+//
+//	version, err := client.ServerVersion(ctx)
+func (c *Client) ServerVersion(ctx context.Context) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	var info struct {
+		Version string `bson:"version"`
+	}
+	if err := c.defaultDB.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info); err != nil {
+		return "", c.operationError(ctx, start, "server version", err)
+	}
+
+	return info.Version, nil
+}
+
+// ListDatabases returns the databases on the server matching filter.
+// Use nil or bson.M{} to list all databases.
+//
+// Example:
+//
+//	dbs, err := client.ListDatabases(ctx, bson.M{"empty": false})
+//	for _, db := range dbs {
+//	    fmt.Println(db.Name, db.SizeOnDisk)
+//	}
+func (c *Client) ListDatabases(ctx context.Context, filter any) ([]mongo.DatabaseSpecification, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	result, err := c.client.ListDatabases(ctx, filter)
+	if err != nil {
+		return nil, c.operationError(ctx, start, "list databases", err)
+	}
+
+	return result.Databases, nil
+}
+
+// DatabaseExists reports whether a database with the given name exists on the server.
+//
+// Example:
+//
+//	exists, err := client.DatabaseExists(ctx, "analytics")
+func (c *Client) DatabaseExists(ctx context.Context, name string) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return false, err
+	}
+
+	start := time.Now()
+	names, err := c.client.ListDatabaseNames(ctx, bson.M{"name": name})
+	if err != nil {
+		return false, c.operationError(ctx, start, "database exists", err)
+	}
+
+	return len(names) > 0, nil
+}
+
+// CollectionExists reports whether a collection (or view) with the given name
+// exists in the default database.
+//
+// Example:
+//
+//	exists, err := client.CollectionExists(ctx, "users")
+func (c *Client) CollectionExists(ctx context.Context, name string) (bool, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return false, err
+	}
+
+	start := time.Now()
+	names, err := c.defaultDB.ListCollectionNames(ctx, bson.M{"name": name})
+	if err != nil {
+		return false, c.operationError(ctx, start, "collection exists", err)
+	}
+
+	return len(names) > 0, nil
+}
+
+// EstimatedCount returns an estimated count of the documents in the collection using
+// collection metadata. A positive maxTime limits how long the server may spend on the count.
+//
+// Example:
+//
+//	count, err := client.EstimatedCount(ctx, "events", 500*time.Millisecond)
+func (c *Client) EstimatedCount(ctx context.Context, collection string, maxTime time.Duration) (int64, error) {
+	opts := options.EstimatedDocumentCount()
+	if maxTime > 0 {
+		opts.SetMaxTime(maxTime)
+	}
+	return c.estimatedDocumentCount(ctx, collection, opts)
+}
+
+// AggregateIter runs an aggregation pipeline and returns an iterator over the results
+// decoded as bson.M. Results are streamed from the server in batches instead of being
+// loaded into memory at once. The caller must close the iterator.
+//
+// Example:
+//
+//	it, err := client.AggregateIter(ctx, "events", pipeline)
+//	if err != nil {
+//	    return err
+//	}
+//	defer it.Close(ctx)
+//
+//	for it.Next(ctx) {
+//	    fmt.Println(it.Current()["_id"])
+//	}
+//	return it.Err()
+func (c *Client) AggregateIter(ctx context.Context, collection string, pipeline any, opts ...*options.AggregateOptions) (*Iterator[bson.M], error) {
+	cursor, err := c.aggregateCursor(ctx, collection, pipeline, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return newIterator[bson.M](cursor, "aggregate"), nil
+}
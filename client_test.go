@@ -0,0 +1,488 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestClient_ClosedClient(t *testing.T) {
+	client := &Client{closed: true}
+	ctx := context.Background()
+
+	t.Run("ListDatabases", func(t *testing.T) {
+		_, err := client.ListDatabases(ctx, nil)
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("DatabaseExists", func(t *testing.T) {
+		_, err := client.DatabaseExists(ctx, "testdb")
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("CollectionExists", func(t *testing.T) {
+		_, err := client.CollectionExists(ctx, "users")
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("EstimatedCount", func(t *testing.T) {
+		_, err := client.EstimatedCount(ctx, "users", time.Second)
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("DistinctValues", func(t *testing.T) {
+		_, err := DistinctValues[string](ctx, client, "users", "name", nil)
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("Repository operations", func(t *testing.T) {
+		repo := NewRepository[bson.M](client, "users")
+
+		_, err := repo.Create(ctx, bson.M{"name": "Alice"})
+		assert.ErrorIs(t, err, ErrClientClosed)
+
+		_, err = repo.FindOne(ctx, bson.M{})
+		assert.ErrorIs(t, err, ErrClientClosed)
+
+		_, err = repo.BulkWrite(ctx, []mongo.WriteModel{mongo.NewInsertOneModel().SetDocument(bson.M{})})
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("ApplyConfig", func(t *testing.T) {
+		err := client.ApplyConfig(ctx, DefaultConfig())
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("SetMaxPoolSize", func(t *testing.T) {
+		err := client.SetMaxPoolSize(ctx, 200)
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		assert.ErrorIs(t, client.Ping(ctx), ErrClientClosed)
+	})
+
+	t.Run("ServerVersion", func(t *testing.T) {
+		_, err := client.ServerVersion(ctx)
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("StartSession", func(t *testing.T) {
+		_, err := client.StartSession()
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("ApplyIndexPlan", func(t *testing.T) {
+		err := client.ApplyIndexPlan(ctx, &IndexSyncPlan{})
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("Watch", func(t *testing.T) {
+		_, err := client.Watch(ctx, "users", nil)
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("WatchDatabase", func(t *testing.T) {
+		_, err := client.WatchDatabase(ctx, "", nil)
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("WatchCluster", func(t *testing.T) {
+		_, err := client.WatchCluster(ctx, nil)
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("CreateSearchIndex", func(t *testing.T) {
+		_, err := client.CreateSearchIndex(ctx, "docs", mongo.SearchIndexModel{Definition: bson.D{}})
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("Locker", func(t *testing.T) {
+		_, err := NewLocker(client, "locks").Acquire(ctx, "job", time.Minute)
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("Close is idempotent", func(t *testing.T) {
+		assert.NoError(t, client.Close(ctx))
+	})
+}
+
+func TestClient_BSONRegistry(t *testing.T) {
+	t.Run("defaults to bson.DefaultRegistry", func(t *testing.T) {
+		client := &Client{config: DefaultConfig()}
+		assert.Same(t, bson.DefaultRegistry, client.bsonRegistry())
+	})
+
+	t.Run("uses registry from ClientOptions", func(t *testing.T) {
+		registry := bson.NewRegistry()
+		cfg := withOptions(DefaultConfig(), WithClientOptions(options.Client().SetRegistry(registry)))
+		client := &Client{config: cfg}
+		assert.Same(t, registry, client.bsonRegistry())
+	})
+
+	t.Run("WithBSONRegistry takes precedence", func(t *testing.T) {
+		registry := bson.NewRegistry()
+		cfg := withOptions(DefaultConfig(),
+			WithClientOptions(options.Client().SetRegistry(bson.NewRegistry())),
+			WithBSONRegistry(registry),
+		)
+		client := &Client{config: cfg}
+		assert.Same(t, registry, client.bsonRegistry())
+	})
+}
+
+func TestClient_ApplyConfig_InvalidConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	client := &Client{config: cfg}
+
+	err := client.SetMaxPoolSize(context.Background(), 0)
+
+	var configErr *ConfigError
+	require.ErrorAs(t, err, &configErr)
+	assert.Equal(t, "MaxPoolSize", configErr.Field)
+	assert.Equal(t, uint64(100), client.Config().MaxPoolSize)
+}
+
+func TestNew_CredentialProviderError(t *testing.T) {
+	providerErr := errors.New("vault unavailable")
+
+	_, err := New(DefaultConfig(), WithCredentialProvider(func(context.Context) (string, string, error) {
+		return "", "", providerErr
+	}))
+
+	var connErr *ConnectionError
+	require.ErrorAs(t, err, &connErr)
+	assert.ErrorIs(t, err, providerErr)
+}
+
+func TestWarmPool(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	ctx := context.Background()
+
+	t.Run("pings in parallel", func(t *testing.T) {
+		ok := testhelpers.SuccessResponse()
+		mock.AddResponses(ok, ok, ok)
+		assert.NoError(t, warmPool(ctx, mock.Client, 3))
+	})
+
+	t.Run("returns failed pings", func(t *testing.T) {
+		failed := testhelpers.CommandErrorResponse(11600, "InterruptedAtShutdown", "shutting down")
+		mock.AddResponses(failed, failed)
+		err := warmPool(ctx, mock.Client, 2)
+		var cmdErr mongo.CommandError
+		require.ErrorAs(t, err, &cmdErr)
+		assert.Equal(t, int32(11600), cmdErr.Code)
+	})
+
+	t.Run("skips a single connection", func(t *testing.T) {
+		// connect already pinged once: the mock has no response for another ping
+		assert.NoError(t, warmPool(ctx, mock.Client, 1))
+		assert.NoError(t, warmPool(ctx, mock.Client, 0))
+	})
+}
+
+func TestClient_CollectionError(t *testing.T) {
+	mongoClient, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+	defer func() { _ = mongoClient.Disconnect(context.Background()) }()
+
+	cause := errors.New("write conflict")
+	filter := bson.M{"email": "a@b.com"}
+	update := bson.M{"$set": bson.M{"name": "Bob"}}
+
+	t.Run("identifies namespace without summaries by default", func(t *testing.T) {
+		client := &Client{config: DefaultConfig(), client: mongoClient, defaultDB: mongoClient.Database("testdb")}
+
+		var opErr *OperationError
+		require.ErrorAs(t, client.collectionError(context.Background(), time.Now(), "update one", "users", cause, filter, update), &opErr)
+		assert.Equal(t, "testdb", opErr.Database)
+		assert.Equal(t, "users", opErr.Collection)
+		assert.Empty(t, opErr.Filter)
+		assert.Empty(t, opErr.Update)
+		assert.ErrorIs(t, opErr, cause)
+	})
+
+	t.Run("attaches redacted summaries when enabled", func(t *testing.T) {
+		cfg := withOptions(DefaultConfig(), WithErrorQuerySummary(true))
+		client := &Client{config: cfg, client: mongoClient, defaultDB: mongoClient.Database("testdb")}
+
+		var opErr *OperationError
+		require.ErrorAs(t, client.collectionError(context.Background(), time.Now(), "update one", "users", cause, filter, update), &opErr)
+		assert.Equal(t, `{"email": ?}`, opErr.Filter)
+		assert.Equal(t, `{"$set": {"name": ?}}`, opErr.Update)
+		assert.NotContains(t, opErr.Error(), "a@b.com")
+		assert.NotContains(t, opErr.Error(), "Bob")
+	})
+}
+
+func TestClient_Database(t *testing.T) {
+	mongoClient, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+	defer func() { _ = mongoClient.Disconnect(context.Background()) }()
+
+	client := &Client{config: DefaultConfig(), client: mongoClient, defaultDB: mongoClient.Database("testdb")}
+	assert.Equal(t, "testdb", client.Database().Name())
+}
+
+func TestNewFromClient(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+
+	t.Run("requires a client", func(t *testing.T) {
+		_, err := NewFromClient(nil, DefaultConfig(), WithDatabase("testdb"))
+		var cfgErr *ConfigError
+		require.ErrorAs(t, err, &cfgErr)
+		assert.Equal(t, "client", cfgErr.Field)
+	})
+
+	t.Run("requires a database", func(t *testing.T) {
+		_, err := NewFromClient(mock.Client, Config{})
+		var cfgErr *ConfigError
+		require.ErrorAs(t, err, &cfgErr)
+		assert.Equal(t, "Database", cfgErr.Field)
+	})
+
+	t.Run("runs operations against the mock", func(t *testing.T) {
+		client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+		require.NoError(t, err)
+		repo := NewRepository[bson.M](client, "users")
+		ctx := context.Background()
+
+		mock.AddResponses(testhelpers.CursorResponse("testdb.users", bson.D{{Key: "name", Value: "Alice"}}))
+		doc, err := repo.FindOne(ctx, bson.M{"name": "Alice"})
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", (*doc)["name"])
+
+		mock.AddResponses(testhelpers.WriteErrorResponse(0, 11000, "E11000 duplicate key error"))
+		_, err = repo.Create(ctx, bson.M{"name": "Alice"})
+		var dupErr *DuplicateKeyError
+		assert.ErrorAs(t, err, &dupErr)
+
+		mock.AddResponses(testhelpers.CursorResponse("testdb.users"))
+		_, err = repo.FindOne(ctx, bson.M{"name": "Bob"})
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestClient_ErrorHook(t *testing.T) {
+	mongoClient, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:27017"))
+	require.NoError(t, err)
+	defer func() { _ = mongoClient.Disconnect(context.Background()) }()
+
+	type report struct {
+		ctx context.Context
+		op  string
+		err error
+	}
+	var reports []report
+	cfg := withOptions(DefaultConfig(), WithErrorHook(func(ctx context.Context, op string, err error) {
+		reports = append(reports, report{ctx: ctx, op: op, err: err})
+	}))
+	client := &Client{config: cfg, client: mongoClient, defaultDB: mongoClient.Database("testdb")}
+
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request-1")
+	cause := errors.New("write conflict")
+
+	t.Run("reports collection errors", func(t *testing.T) {
+		reports = nil
+		err := client.collectionError(ctx, time.Now(), "update one", "users", cause, nil, nil)
+
+		require.Len(t, reports, 1)
+		assert.Equal(t, "update one", reports[0].op)
+		assert.Equal(t, err, reports[0].err)
+		assert.Equal(t, "request-1", reports[0].ctx.Value(ctxKey{}))
+	})
+
+	t.Run("reports operation errors", func(t *testing.T) {
+		reports = nil
+		err := client.operationError(ctx, time.Now(), "list databases", cause)
+
+		require.Len(t, reports, 1)
+		assert.Equal(t, "list databases", reports[0].op)
+		assert.ErrorIs(t, reports[0].err, cause)
+		assert.Equal(t, err, reports[0].err)
+	})
+
+	t.Run("skips argument and state errors", func(t *testing.T) {
+		reports = nil
+		_, err := client.bulkWrite(ctx, "users", nil)
+		require.Error(t, err)
+
+		closed := &Client{config: cfg, closed: true}
+		_, err = closed.insertOne(ctx, "users", bson.M{})
+		require.ErrorIs(t, err, ErrClientClosed)
+
+		assert.Empty(t, reports)
+	})
+}
+
+func TestClient_ReportError_Timeout(t *testing.T) {
+	client := &Client{config: DefaultConfig()}
+	start := time.Now().Add(-2 * time.Second)
+
+	t.Run("wraps deadline errors in TimeoutError", func(t *testing.T) {
+		err := client.operationError(context.Background(), start, "list databases", context.DeadlineExceeded)
+
+		var timeoutErr *TimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, "list databases", timeoutErr.Op)
+		assert.GreaterOrEqual(t, timeoutErr.Elapsed, 2*time.Second)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "list databases", opErr.Op)
+	})
+
+	t.Run("wraps maxTimeMS expirations in TimeoutError", func(t *testing.T) {
+		cause := mongo.CommandError{Code: 50, Name: "MaxTimeMSExpired", Message: "operation exceeded time limit"}
+
+		var timeoutErr *TimeoutError
+		require.ErrorAs(t, client.operationError(context.Background(), start, "aggregate", cause), &timeoutErr)
+		assert.Equal(t, cause, timeoutErr.Cause)
+	})
+
+	t.Run("does not wrap other errors", func(t *testing.T) {
+		var timeoutErr *TimeoutError
+		assert.False(t, errors.As(client.operationError(context.Background(), start, "find", context.Canceled), &timeoutErr))
+		assert.False(t, errors.As(client.operationError(context.Background(), start, "find", errors.New("boom")), &timeoutErr))
+	})
+}
+
+func TestClient_WithTransaction(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	repo := NewRepository[bson.M](client, "orders")
+	ctx := context.Background()
+
+	t.Run("commits when fn succeeds", func(t *testing.T) {
+		mock.AddResponses(
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}),
+			testhelpers.SuccessResponse(),
+		)
+		var runs int
+		maxCommit := time.Second
+		err := client.WithTransaction(ctx, func(ctx context.Context) error {
+			runs++
+			_, err := repo.Create(ctx, bson.M{"_id": 1})
+			return err
+		}, options.Transaction().SetMaxCommitTime(&maxCommit))
+		require.NoError(t, err)
+		assert.Equal(t, 1, runs)
+	})
+
+	t.Run("returns the error of fn unchanged", func(t *testing.T) {
+		mock.AddResponses(
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}),
+			testhelpers.SuccessResponse(),
+		)
+		errOutOfStock := errors.New("out of stock")
+		err := client.WithTransaction(ctx, func(ctx context.Context) error {
+			if _, err := repo.Create(ctx, bson.M{"_id": 2}); err != nil {
+				return err
+			}
+			return errOutOfStock
+		})
+		assert.Equal(t, errOutOfStock, err)
+	})
+
+	t.Run("wraps commit failures", func(t *testing.T) {
+		mock.AddResponses(
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}),
+			testhelpers.CommandErrorResponse(2, "BadValue", "commit failed"),
+		)
+		err := client.WithTransaction(ctx, func(ctx context.Context) error {
+			_, err := repo.Create(ctx, bson.M{"_id": 3})
+			return err
+		})
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "with transaction", opErr.Op)
+	})
+
+	t.Run("joins an enclosing transaction", func(t *testing.T) {
+		mock.AddResponses(
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}),
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}),
+			testhelpers.SuccessResponse(),
+		)
+		err := client.WithTransaction(ctx, func(ctx context.Context) error {
+			outer, _ := SessionFromContext(ctx)
+			if _, err := repo.Create(ctx, bson.M{"_id": 4}); err != nil {
+				return err
+			}
+			return client.WithTransaction(ctx, func(ctx context.Context) error {
+				inner, _ := SessionFromContext(ctx)
+				assert.Same(t, outer, inner)
+				_, err := repo.Create(ctx, bson.M{"_id": 5})
+				return err
+			})
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("uses the session of the context", func(t *testing.T) {
+		sess, err := client.StartSession()
+		require.NoError(t, err)
+		defer sess.EndSession(ctx)
+
+		mock.AddResponses(
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}),
+			testhelpers.SuccessResponse(),
+		)
+		err = client.WithTransaction(ContextWithSession(ctx, sess), func(ctx context.Context) error {
+			used, ok := SessionFromContext(ctx)
+			require.True(t, ok)
+			assert.Equal(t, sess.ID(), used.ID())
+			_, err := repo.Create(ctx, bson.M{"_id": 6})
+			return err
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("closed client", func(t *testing.T) {
+		closed := &Client{closed: true}
+		err := closed.WithTransaction(ctx, func(context.Context) error { return nil })
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+}
+
+func TestContextWithSession(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, ok := SessionFromContext(ctx)
+	assert.False(t, ok)
+
+	sess, err := client.StartSession()
+	require.NoError(t, err)
+	defer sess.EndSession(ctx)
+
+	sessCtx := ContextWithSession(ctx, sess)
+	derived, cancel := context.WithTimeout(sessCtx, time.Second)
+	defer cancel()
+
+	got, ok := SessionFromContext(derived)
+	require.True(t, ok)
+	assert.Equal(t, sess.ID(), got.ID())
+
+	t.Run("ContextWithTransaction joins WithTransaction", func(t *testing.T) {
+		txCtx := ContextWithTransaction(ctx, sess)
+		err := client.WithTransaction(txCtx, func(inner context.Context) error {
+			assert.Equal(t, txCtx, inner, "runs in the caller's transaction")
+			return nil
+		})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,55 @@
+package mongo_kit
+
+import "time"
+
+// Clock
+//
+// This file provides the Clock used by features that read the current time, such as
+// timestamps, expirations and lock leases, so tests can freeze and advance time.
+
+// Clock tells the current time.
+// testing.FakeClock implements it for tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the Clock backed by time.Now. It is used when Config.Clock is nil.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// WithClock sets the clock used by features that read the current time.
+// Operation timings, such as TimeoutError.Elapsed, always use the system clock.
+//
+// Example:
+//
+//	clock := testhelpers.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	client, err := mongo_kit.New(cfg, mongo_kit.WithClock(clock))
+//	clock.Advance(time.Hour)
+func WithClock(clock Clock) Option {
+	return func(c *Config) {
+		c.Clock = clock
+	}
+}
+
+// now returns the current time of the configured clock.
+func (c *Config) now() time.Time {
+	if c.Clock == nil {
+		return SystemClock.Now()
+	}
+	return c.Clock.Now()
+}
+
+// Now returns the current time of the client's clock. Use it for timestamps written
+// by application code so tests that freeze the clock see consistent values.
+//
+// Example:
+//
+//	user.CreatedAt = client.Now()
+func (c *Client) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config.now()
+}
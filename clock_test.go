@@ -0,0 +1,27 @@
+package mongo_kit
+
+import (
+	"testing"
+	"time"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Now(t *testing.T) {
+	t.Run("uses the system clock by default", func(t *testing.T) {
+		client := &Client{config: DefaultConfig()}
+		before := time.Now()
+		assert.WithinRange(t, client.Now(), before, time.Now())
+	})
+
+	t.Run("uses the configured clock", func(t *testing.T) {
+		frozen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		clock := testhelpers.NewFakeClock(frozen)
+		client := &Client{config: withOptions(DefaultConfig(), WithClock(clock))}
+
+		assert.Equal(t, frozen, client.Now())
+		clock.Advance(time.Hour)
+		assert.Equal(t, frozen.Add(time.Hour), client.Now())
+	})
+}
@@ -0,0 +1,315 @@
+// Command mongokit-gen generates typed BSON field names, projection and sort helpers
+// from the bson tags of Go structs, so builders do not repeat field names as strings.
+//
+// Usage, next to the struct declaration:
+//
+//	//go:generate go run github.com/edaniel30/mongo-kit-go/cmd/mongokit-gen -type=User,Order
+//
+// For a type User, the generated file declares UserFields, with one string per encoded
+// field (e.g. UserFields.Email == "email"), UserProjection and UserSort.
+//
+// See docs/codegen.md for detailed usage guide and examples.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of struct type names (required)")
+	output := flag.String("output", "", "output file name (default: <first type>_fields_gen.go)")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: mongokit-gen -type=T[,T...] [-output=file] [dir]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	names := strings.Split(*typeNames, ",")
+	src, err := generate(dir, names)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mongokit-gen: %v\n", err)
+		os.Exit(1)
+	}
+
+	file := *output
+	if file == "" {
+		file = strings.ToLower(names[0]) + "_fields_gen.go"
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	if err := os.WriteFile(file, src, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "mongokit-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// field is a generated field constant: the Go name and the encoded BSON name.
+type field struct {
+	Name string
+	Key  string
+}
+
+// typeFields lists the fields of one generated type.
+type typeFields struct {
+	Name   string
+	Fields []field
+}
+
+// generate returns the formatted source of the field helpers of the named struct types
+// declared in the package in dir.
+func generate(dir string, names []string) ([]byte, error) {
+	pkg, structs, err := parsePackage(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]typeFields, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		st, ok := structs[name]
+		if !ok {
+			return nil, fmt.Errorf("struct type %s not found in %s", name, dir)
+		}
+		fields, err := collectFields(st, structs, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%s has no encoded fields", name)
+		}
+		types = append(types, typeFields{Name: name, Fields: fields})
+	}
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, struct {
+		Package string
+		Types   []typeFields
+	}{pkg, types}); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// parsePackage parses the non-test Go files of dir and returns the package name and
+// its struct types by name. Generated files of a previous run are ignored.
+func parsePackage(dir string) (string, map[string]*ast.StructType, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, err
+	}
+
+	fset := token.NewFileSet()
+	pkg := ""
+	structs := make(map[string]*ast.StructType)
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") || strings.HasSuffix(path, "_fields_gen.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return "", nil, err
+		}
+		if pkg == "" {
+			pkg = file.Name.Name
+		} else if file.Name.Name != pkg {
+			continue
+		}
+
+		ast.Inspect(file, func(n ast.Node) bool {
+			spec, ok := n.(*ast.TypeSpec)
+			if !ok {
+				return true
+			}
+			if st, ok := spec.Type.(*ast.StructType); ok {
+				structs[spec.Name.Name] = st
+			}
+			return false
+		})
+	}
+
+	if pkg == "" {
+		return "", nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	return pkg, structs, nil
+}
+
+// collectFields returns the encoded fields of st, following the rules of the driver:
+// the key is the bson tag name, or the lowercased field name without one; "-" and
+// unexported fields are skipped; fields tagged inline are flattened when their type is
+// a struct of the same package. seen guards against recursive inlining.
+func collectFields(st *ast.StructType, structs map[string]*ast.StructType, seen []*ast.StructType) ([]field, error) {
+	for _, s := range seen {
+		if s == st {
+			return nil, errors.New("recursive inline struct")
+		}
+	}
+	seen = append(seen, st)
+
+	var fields []field
+	for _, f := range st.Fields.List {
+		tag := ""
+		if f.Tag != nil {
+			unquoted, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(unquoted).Get("bson")
+		}
+		if tag == "-" {
+			continue
+		}
+		key, flags, _ := strings.Cut(tag, ",")
+
+		names := f.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{ast.NewIdent(typeName(f.Type))}
+		}
+
+		if hasFlag(flags, "inline") {
+			inner, ok := structs[typeName(f.Type)]
+			if !ok {
+				return nil, fmt.Errorf("inline field %s: type is not a struct of this package", names[0].Name)
+			}
+			innerFields, err := collectFields(inner, structs, seen)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, innerFields...)
+			continue
+		}
+
+		for _, name := range names {
+			if name.Name == "" || !name.IsExported() {
+				continue
+			}
+			k := key
+			if k == "" {
+				k = strings.ToLower(name.Name)
+			}
+			fields = append(fields, field{Name: name.Name, Key: k})
+		}
+	}
+
+	if err := checkDuplicates(fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// checkDuplicates reports fields that share a Go name or a BSON key.
+func checkDuplicates(fields []field) error {
+	names := make(map[string]bool, len(fields))
+	keys := make(map[string]bool, len(fields))
+	var dups []string
+	for _, f := range fields {
+		if names[f.Name] || keys[f.Key] {
+			dups = append(dups, f.Name)
+		}
+		names[f.Name] = true
+		keys[f.Key] = true
+	}
+	if len(dups) > 0 {
+		sort.Strings(dups)
+		return fmt.Errorf("duplicate fields: %s", strings.Join(dups, ", "))
+	}
+	return nil
+}
+
+// typeName returns the name of a field type, without pointer or package qualifier.
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return typeName(t.X)
+	case *ast.IndexListExpr:
+		return typeName(t.X)
+	default:
+		return ""
+	}
+}
+
+// hasFlag reports whether the comma-separated tag flags contain flag.
+func hasFlag(flags, flag string) bool {
+	for f := range strings.SplitSeq(flags, ",") {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+var fileTemplate = template.Must(template.New("fields").Parse(`// Code generated by mongokit-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+{{range .Types}}{{$type := .Name}}
+// {{$type}}Fields holds the BSON field names of {{$type}}.
+var {{$type}}Fields = struct {
+{{- range .Fields}}
+	{{.Name}} string
+{{- end}}
+}{
+{{- range .Fields}}
+	{{.Name}}: {{printf "%q" .Key}},
+{{- end}}
+}
+
+// {{$type}}Projection returns a projection that includes only fields,
+// e.g. {{$type}}Projection({{$type}}Fields.{{(index .Fields 0).Name}}).
+func {{$type}}Projection(fields ...string) bson.D {
+	projection := make(bson.D, len(fields))
+	for i, f := range fields {
+		projection[i] = bson.E{Key: f, Value: 1}
+	}
+	return projection
+}
+
+// {{$type}}Sort returns a sort document of fields in order, ascending unless the
+// field is prefixed with "-", e.g. {{$type}}Sort("-"+{{$type}}Fields.{{(index .Fields 0).Name}}).
+func {{$type}}Sort(fields ...string) bson.D {
+	sort := make(bson.D, len(fields))
+	for i, f := range fields {
+		if key, desc := strings.CutPrefix(f, "-"); desc {
+			sort[i] = bson.E{Key: key, Value: -1}
+		} else {
+			sort[i] = bson.E{Key: f, Value: 1}
+		}
+	}
+	return sort
+}
+{{end}}`))
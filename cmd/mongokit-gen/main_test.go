@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const modelsSource = `package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Timestamps struct {
+	CreatedAt time.Time ` + "`bson:\"created_at\"`" + `
+	UpdatedAt time.Time ` + "`bson:\"updated_at,omitempty\"`" + `
+}
+
+type User struct {
+	ID         primitive.ObjectID ` + "`bson:\"_id,omitempty\"`" + `
+	Email      string             ` + "`bson:\"email\"`" + `
+	FirstName  string
+	Password   string ` + "`bson:\"-\"`" + `
+	internal   int
+	Timestamps ` + "`bson:\",inline\"`" + `
+}
+
+type Broken struct {
+	Name  string ` + "`bson:\"name\"`" + `
+	Other string ` + "`bson:\"name\"`" + `
+}
+
+type Loose struct {
+	Meta map[string]any ` + "`bson:\",inline\"`" + `
+}
+`
+
+func writeModels(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "models.go"), []byte(modelsSource), 0o644))
+	return dir
+}
+
+func TestCollectFields(t *testing.T) {
+	_, structs, err := parsePackage(writeModels(t))
+	require.NoError(t, err)
+
+	fields, err := collectFields(structs["User"], structs, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []field{
+		{Name: "ID", Key: "_id"},
+		{Name: "Email", Key: "email"},
+		{Name: "FirstName", Key: "firstname"},
+		{Name: "CreatedAt", Key: "created_at"},
+		{Name: "UpdatedAt", Key: "updated_at"},
+	}, fields)
+
+	_, err = collectFields(structs["Broken"], structs, nil)
+	assert.ErrorContains(t, err, "duplicate fields: Other")
+
+	_, err = collectFields(structs["Loose"], structs, nil)
+	assert.ErrorContains(t, err, "inline field Meta")
+}
+
+func TestGenerate(t *testing.T) {
+	dir := writeModels(t)
+
+	src, err := generate(dir, []string{"User", "Timestamps"})
+	require.NoError(t, err)
+
+	out := string(src)
+	assert.Contains(t, out, "// Code generated by mongokit-gen. DO NOT EDIT.")
+	assert.Contains(t, out, "package models")
+	assert.Contains(t, out, "var UserFields = struct {")
+	assert.Contains(t, out, `ID:        "_id",`)
+	assert.Contains(t, out, `FirstName: "firstname",`)
+	assert.Contains(t, out, "func UserProjection(fields ...string) bson.D {")
+	assert.Contains(t, out, "func UserSort(fields ...string) bson.D {")
+	assert.Contains(t, out, "var TimestampsFields = struct {")
+	assert.NotContains(t, out, "Password")
+
+	// A previous output does not break the next run.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "user_fields_gen.go"), src, 0o644))
+	_, err = generate(dir, []string{"User"})
+	require.NoError(t, err)
+
+	_, err = generate(dir, []string{"Missing"})
+	assert.ErrorContains(t, err, "struct type Missing not found")
+
+	_, err = generate(t.TempDir(), []string{"User"})
+	assert.ErrorContains(t, err, "no Go files")
+}
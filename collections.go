@@ -0,0 +1,439 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection Management
+//
+// This file provides collection-level administration on the Client.
+//
+// See docs/operations.md for detailed usage guide and examples.
+
+// CreateView creates a read-only view named viewName in the default database.
+// The view is defined by running pipeline against the source collection.
+// Pipeline must be []bson.M, []bson.D, mongo.Pipeline, or bson.A.
+//
+// Example:
+//
+//	pipeline := mongo_kit.NewAggregationBuilder().
+//	    Match(bson.M{"active": true}).
+//	    Project(bson.M{"name": 1, "email": 1}).
+//	    Build()
+//	err := client.CreateView(ctx, "active_users", "users", pipeline)
+func (c *Client) CreateView(ctx context.Context, viewName, source string, pipeline any, opts ...*options.CreateViewOptions) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := validatePipeline("create view", pipeline); err != nil {
+		return err
+	}
+
+	if err := c.defaultDB.CreateView(ctx, viewName, source, pipeline, opts...); err != nil {
+		return c.operationError(ctx, start, "create view", err)
+	}
+
+	return nil
+}
+
+// DropView drops the view named viewName from the default database.
+// Dropping a view that does not exist is a no-op (no error is returned).
+// The documents of the source collection are not affected. Collections are never
+// dropped: an error is returned when viewName names a collection instead of a view.
+//
+// Example:
+//
+//	err := client.DropView(ctx, "active_users")
+func (c *Client) DropView(ctx context.Context, viewName string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	specs, err := c.defaultDB.ListCollectionSpecifications(ctx, bson.D{{Key: "name", Value: viewName}})
+	if err != nil {
+		return c.operationError(ctx, start, "drop view", err)
+	}
+	if len(specs) == 0 {
+		return nil
+	}
+	if specs[0].Type != "view" {
+		return newOperationError("drop view", fmt.Errorf("%q is a %s, not a view", viewName, specs[0].Type))
+	}
+
+	if err := c.getCollection(viewName).Drop(ctx); err != nil {
+		return c.operationError(ctx, start, "drop view", err)
+	}
+
+	return nil
+}
+
+// CollModOptions describes the changes applied by ModifyCollection.
+// Only non-zero fields are sent to the server.
+type CollModOptions struct {
+	Validator        any    // Document validator, e.g. bson.M{"$jsonSchema": ...}
+	ValidationLevel  string // Validation level: "off", "strict" or "moderate"
+	ValidationAction string // Validation action: "error" or "warn"
+	TTLIndexName     string // Name of the TTL index to modify (required with TTLIndexSeconds)
+	TTLIndexSeconds  *int64 // New expireAfterSeconds value for the TTL index
+}
+
+// buildCommand assembles the collMod command document for the given collection.
+// Returns an error if no change is requested or the TTL settings are incomplete.
+func (o CollModOptions) buildCommand(collection string) (bson.D, error) {
+	cmd := bson.D{{Key: "collMod", Value: collection}}
+
+	if o.Validator != nil {
+		cmd = append(cmd, bson.E{Key: "validator", Value: o.Validator})
+	}
+	if o.ValidationLevel != "" {
+		cmd = append(cmd, bson.E{Key: "validationLevel", Value: o.ValidationLevel})
+	}
+	if o.ValidationAction != "" {
+		cmd = append(cmd, bson.E{Key: "validationAction", Value: o.ValidationAction})
+	}
+
+	if o.TTLIndexSeconds != nil {
+		if o.TTLIndexName == "" {
+			return nil, errors.New("TTLIndexName is required when TTLIndexSeconds is set")
+		}
+		cmd = append(cmd, bson.E{Key: "index", Value: bson.D{
+			{Key: "name", Value: o.TTLIndexName},
+			{Key: "expireAfterSeconds", Value: *o.TTLIndexSeconds},
+		}})
+	} else if o.TTLIndexName != "" {
+		return nil, errors.New("TTLIndexSeconds is required when TTLIndexName is set")
+	}
+
+	if len(cmd) == 1 {
+		return nil, errors.New("at least one modification must be provided")
+	}
+
+	return cmd, nil
+}
+
+// ModifyCollection changes collection settings such as the document validator
+// or the expiration of a TTL index using the collMod command.
+//
+// Example:
+//
+//	ttl := int64(3600)
+//	err := client.ModifyCollection(ctx, "sessions", mongo_kit.CollModOptions{
+//	    Validator:       bson.M{"$jsonSchema": schema},
+//	    ValidationLevel: "moderate",
+//	    TTLIndexName:    "expires_at_ttl",
+//	    TTLIndexSeconds: &ttl,
+//	})
+func (c *Client) ModifyCollection(ctx context.Context, collection string, opts CollModOptions) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	cmd, err := opts.buildCommand(collection)
+	if err != nil {
+		return newOperationError("modify collection", err)
+	}
+
+	if err := c.defaultDB.RunCommand(ctx, cmd).Err(); err != nil {
+		return c.operationError(ctx, start, "modify collection", err)
+	}
+
+	return nil
+}
+
+// RenameCollection renames collection from to collection to within database db.
+// If db is empty the default database is used. When dropTarget is true an
+// existing collection named to is dropped first; otherwise renaming onto an
+// existing collection returns an error.
+//
+// Example:
+//
+//	err := client.RenameCollection(ctx, "", "orders_tmp", "orders", true)
+func (c *Client) RenameCollection(ctx context.Context, db, from, to string, dropTarget bool) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if from == "" || to == "" {
+		return newOperationError("rename collection", errors.New("source and target collection names are required"))
+	}
+
+	if db == "" {
+		db = c.defaultDB.Name()
+	}
+
+	cmd := bson.D{
+		{Key: "renameCollection", Value: db + "." + from},
+		{Key: "to", Value: db + "." + to},
+		{Key: "dropTarget", Value: dropTarget},
+	}
+
+	if err := c.client.Database("admin").RunCommand(ctx, cmd).Err(); err != nil {
+		return c.operationError(ctx, start, "rename collection", err)
+	}
+
+	return nil
+}
+
+// defaultCopyBatchSize is the number of documents written per BulkWrite by CopyCollection.
+const defaultCopyBatchSize = 1000
+
+// CopyOptions configures CopyCollection.
+type CopyOptions struct {
+	BatchSize  int32              // Documents read and written per batch (default: 1000)
+	UseMerge   bool               // Copy server-side with an aggregation $merge stage instead of client-side batches
+	OnProgress func(copied int64) // Called after each batch with the total number of documents copied so far
+	// Transform rewrites each document before it is written, e.g. to mask personal data
+	// with anonymize.Rules.Apply. It must keep the _id; returning nil skips the document.
+	// Not supported with UseMerge.
+	Transform func(doc bson.Raw) (bson.Raw, error)
+}
+
+// CopyCollection copies the documents matching filter from srcDB.srcColl to dstDB.dstColl
+// and returns the number of documents copied. Empty database names use the default database.
+//
+// By default documents are read in batches and written with unordered BulkWrite
+// upserts keyed on _id, so an interrupted copy can safely be re-run. With UseMerge
+// the copy runs entirely on the server using a $merge stage, which is faster but only
+// reports progress once, when the copy has completed; its count is approximate, as the
+// matching documents are counted before the merge and may change while it runs.
+// Cached query results of the target collection are invalidated after each write, as
+// with other writes, including writes that fail.
+//
+// Example:
+//
+//	copied, err := client.CopyCollection(ctx, "", "orders", "archive", "orders_2024",
+//	    bson.M{"created_at": bson.M{"$lt": cutoff}},
+//	    mongo_kit.CopyOptions{
+//	        BatchSize:  500,
+//	        OnProgress: func(n int64) { log.Printf("copied %d orders", n) },
+//	    })
+func (c *Client) CopyCollection(ctx context.Context, srcDB, srcColl, dstDB, dstColl string, filter any, opts CopyOptions) (int64, error) {
+	srcDB, dstDB, err := c.copyTargets(srcDB, srcColl, dstDB, dstColl, opts)
+	if err != nil {
+		return 0, err
+	}
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	start := time.Now()
+	var copied int64
+	if opts.UseMerge {
+		copied, err = c.copyWithMerge(ctx, srcDB, srcColl, dstDB, dstColl, filter, opts)
+	} else {
+		copied, err = c.copyInBatches(ctx, srcDB, srcColl, dstDB, dstColl, filter, opts)
+	}
+	if err == nil {
+		return copied, nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return copied, c.reportError(ctx, start, err)
+}
+
+// copyTargets validates the arguments of CopyCollection and returns the source and
+// target database names, with empty names resolved to the default database.
+func (c *Client) copyTargets(srcDB, srcColl, dstDB, dstColl string, opts CopyOptions) (string, string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return "", "", err
+	}
+
+	if srcColl == "" || dstColl == "" {
+		return "", "", newOperationError("copy collection", errors.New("source and target collection names are required"))
+	}
+
+	if srcDB == "" {
+		srcDB = c.defaultDB.Name()
+	}
+	if dstDB == "" {
+		dstDB = c.defaultDB.Name()
+	}
+	if srcDB == dstDB && srcColl == dstColl {
+		return "", "", newOperationError("copy collection", errors.New("source and target collections must differ"))
+	}
+	if opts.UseMerge && opts.Transform != nil {
+		return "", "", newOperationError("copy collection", errors.New("Transform cannot be used with UseMerge"))
+	}
+
+	return srcDB, dstDB, nil
+}
+
+// copyWithMerge copies documents server-side using an aggregation $merge stage.
+func (c *Client) copyWithMerge(ctx context.Context, srcDB, srcColl, dstDB, dstColl string, filter any, opts CopyOptions) (int64, error) {
+	count, err := c.mergeCopy(ctx, srcDB, srcColl, dstDB, dstColl, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	if opts.OnProgress != nil {
+		opts.OnProgress(count)
+	}
+
+	return count, nil
+}
+
+// mergeCopy runs the $merge aggregation of copyWithMerge and returns the number of
+// documents matching filter, counted before the merge. The cached queries of the target
+// are invalidated even when the merge fails, as it may have written some documents.
+func (c *Client) mergeCopy(ctx context.Context, srcDB, srcColl, dstDB, dstColl string, filter any) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return 0, err
+	}
+
+	src := c.client.Database(srcDB).Collection(srcColl)
+	count, err := src.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, newOperationError("copy collection", err)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$merge", Value: bson.D{
+			{Key: "into", Value: bson.D{{Key: "db", Value: dstDB}, {Key: "coll", Value: dstColl}}},
+			{Key: "on", Value: "_id"},
+			{Key: "whenMatched", Value: "replace"},
+			{Key: "whenNotMatched", Value: "insert"},
+		}}},
+	}
+
+	defer c.invalidateNamespace(dstDB + "." + dstColl) // runs before the lock is released
+	cursor, err := src.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, newOperationError("copy collection", err)
+	}
+	_ = cursor.Close(ctx)
+
+	return count, nil
+}
+
+// copyInBatches copies documents by reading batches from the source collection and
+// upserting them into the target collection. The client lock is only held while a
+// batch is written, so a long copy does not hold off Close or ApplyConfig.
+func (c *Client) copyInBatches(ctx context.Context, srcDB, srcColl, dstDB, dstColl string, filter any, opts CopyOptions) (int64, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultCopyBatchSize
+	}
+
+	cursor, err := c.copyCursor(ctx, srcDB, srcColl, filter, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	var copied int64
+	models := make([]mongo.WriteModel, 0, batchSize)
+
+	flush := func() error {
+		if len(models) == 0 {
+			return nil
+		}
+		if err := c.writeCopyBatch(ctx, dstDB, dstColl, models); err != nil {
+			return err
+		}
+		copied += int64(len(models))
+		models = models[:0]
+		if opts.OnProgress != nil {
+			opts.OnProgress(copied)
+		}
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		doc := bson.Raw(append([]byte(nil), cursor.Current...))
+		id := doc.Lookup("_id")
+		if opts.Transform != nil {
+			if doc, err = opts.Transform(doc); err != nil {
+				return copied, newOperationError("copy collection", err)
+			}
+			if doc == nil {
+				continue
+			}
+		}
+		models = append(models, mongo.NewReplaceOneModel().
+			SetFilter(bson.D{{Key: "_id", Value: id}}).
+			SetReplacement(doc).
+			SetUpsert(true))
+
+		if len(models) >= int(batchSize) {
+			if err := flush(); err != nil {
+				return copied, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return copied, newOperationError("copy collection", err)
+	}
+
+	if err := flush(); err != nil {
+		return copied, err
+	}
+
+	return copied, nil
+}
+
+// copyCursor opens the cursor over the documents copied by copyInBatches.
+func (c *Client) copyCursor(ctx context.Context, srcDB, srcColl string, filter any, batchSize int32) (*mongo.Cursor, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	cursor, err := c.client.Database(srcDB).Collection(srcColl).Find(ctx, filter, options.Find().SetBatchSize(batchSize))
+	if err != nil {
+		return nil, newOperationError("copy collection", err)
+	}
+	return cursor, nil
+}
+
+// writeCopyBatch upserts a batch of copied documents into the target collection and
+// invalidates its cached query results.
+func (c *Client) writeCopyBatch(ctx context.Context, dstDB, dstColl string, models []mongo.WriteModel) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	dst := c.client.Database(dstDB).Collection(dstColl)
+	if _, err := dst.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return newOperationError("copy collection", err)
+	}
+	c.invalidateNamespace(dstDB + "." + dstColl)
+
+	return nil
+}
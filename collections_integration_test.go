@@ -0,0 +1,287 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestClient_CollectionManagement_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	cfg := DefaultConfig()
+	WithURI(container.URI)(&cfg)
+	WithDatabase(database)(&cfg)
+
+	client, err := New(cfg)
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := NewRepository[User](client, "users")
+
+	t.Run("CreateView exposes pipeline results", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		_, _ = repo.CreateMany(ctx, []User{
+			{Name: "Active", Email: "active@test.com", Age: 30, Active: true},
+			{Name: "Inactive", Email: "inactive@test.com", Age: 40, Active: false},
+		})
+
+		pipeline := NewAggregationBuilder().Match(bson.M{"active": true}).Build()
+		err := client.CreateView(ctx, "active_users", "users", pipeline)
+		require.NoError(t, err)
+
+		view := NewRepository[User](client, "active_users")
+		found, err := view.FindAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, "Active", found[0].Name)
+	})
+
+	t.Run("DropView removes view", func(t *testing.T) {
+		err := client.DropView(ctx, "active_users")
+		require.NoError(t, err)
+
+		view := NewRepository[User](client, "active_users")
+		found, err := view.FindAll(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, found)
+
+		count, err := repo.CountAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("DropView on missing view is a no-op", func(t *testing.T) {
+		err := client.DropView(ctx, "missing_view")
+		assert.NoError(t, err)
+	})
+
+	t.Run("DropView on a collection fails and keeps its documents", func(t *testing.T) {
+		err := client.DropView(ctx, "users")
+		assert.ErrorContains(t, err, "not a view")
+
+		count, err := repo.CountAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("ModifyCollection updates validator", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		require.NoError(t, client.CreateCollection(ctx, "users"))
+
+		err := client.ModifyCollection(ctx, "users", CollModOptions{
+			Validator:        bson.M{"age": bson.M{"$gte": 0}},
+			ValidationLevel:  "strict",
+			ValidationAction: "error",
+		})
+		require.NoError(t, err)
+
+		_, err = repo.Create(ctx, User{Name: "Invalid", Email: "invalid@test.com", Age: -1})
+		assert.Error(t, err)
+
+		_, err = repo.Create(ctx, User{Name: "Valid", Email: "valid@test.com", Age: 1})
+		assert.NoError(t, err)
+	})
+
+	t.Run("validation failures are reported as ValidationError", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		require.NoError(t, client.CreateCollection(ctx, "users"))
+		require.NoError(t, client.ModifyCollection(ctx, "users", CollModOptions{
+			Validator: bson.M{"$jsonSchema": bson.M{
+				"bsonType":   "object",
+				"required":   bson.A{"email"},
+				"properties": bson.M{"age": bson.M{"bsonType": "int", "minimum": 0}},
+			}},
+		}))
+
+		id := primitive.NewObjectID()
+		_, err := repo.Create(ctx, User{ID: id, Name: "Invalid", Email: "invalid@test.com", Age: -1})
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, id, validationErr.DocumentID)
+		require.NotNil(t, validationErr.Details)
+		assert.Equal(t, "$jsonSchema", validationErr.Details["operatorName"])
+		assert.Contains(t, validationErr.Details, "schemaRulesNotSatisfied")
+
+		_, err = repo.UpdateByID(ctx, primitive.NewObjectID(), bson.M{"$set": bson.M{"age": -5}}, options.Update().SetUpsert(true))
+		assert.ErrorAs(t, err, &validationErr)
+	})
+
+	t.Run("ModifyCollection changes TTL expiration", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		_, err := client.CreateIndexes(ctx, "users", []mongo.IndexModel{{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("expires_at_ttl").SetExpireAfterSeconds(3600),
+		}})
+		require.NoError(t, err)
+
+		ttl := int64(60)
+		err = client.ModifyCollection(ctx, "users", CollModOptions{TTLIndexName: "expires_at_ttl", TTLIndexSeconds: &ttl})
+		require.NoError(t, err)
+
+		specs, err := client.getCollection("users").Indexes().ListSpecifications(ctx)
+		require.NoError(t, err)
+		var found bool
+		for _, spec := range specs {
+			if spec.Name == "expires_at_ttl" {
+				found = true
+				require.NotNil(t, spec.ExpireAfterSeconds)
+				assert.Equal(t, int32(60), *spec.ExpireAfterSeconds)
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("ModifyCollection on missing collection returns error", func(t *testing.T) {
+		err := client.ModifyCollection(ctx, "missing_collection", CollModOptions{ValidationLevel: "off"})
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "modify collection", opErr.Op)
+	})
+
+	t.Run("RenameCollection moves documents", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		_, _ = repo.Create(ctx, User{Name: "Renamed", Email: "renamed@test.com", Age: 30})
+
+		err := client.RenameCollection(ctx, "", "users", "users_renamed", false)
+		require.NoError(t, err)
+
+		renamed := NewRepository[User](client, "users_renamed")
+		count, err := renamed.CountAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		count, err = repo.CountAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("RenameCollection fails when target exists without dropTarget", func(t *testing.T) {
+		_, _ = repo.Create(ctx, User{Name: "Source", Email: "source@test.com", Age: 30})
+
+		err := client.RenameCollection(ctx, database, "users", "users_renamed", false)
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "rename collection", opErr.Op)
+	})
+
+	t.Run("RenameCollection replaces target with dropTarget", func(t *testing.T) {
+		err := client.RenameCollection(ctx, database, "users", "users_renamed", true)
+		require.NoError(t, err)
+
+		renamed := NewRepository[User](client, "users_renamed")
+		found, err := renamed.FindAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+		assert.Equal(t, "Source", found[0].Name)
+	})
+
+	t.Run("CopyCollection copies matching documents in batches", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		archive := NewRepository[User](client, "users_archive")
+		_ = archive.Drop(ctx)
+
+		users := make([]User, 0, 5)
+		for i := range 5 {
+			users = append(users, User{Name: "User", Email: "user@test.com", Age: 20 + i, Active: i%2 == 0})
+		}
+		_, _ = repo.CreateMany(ctx, users)
+
+		var progress []int64
+		copied, err := client.CopyCollection(ctx, "", "users", "", "users_archive", bson.M{"active": true}, CopyOptions{
+			BatchSize:  2,
+			OnProgress: func(n int64) { progress = append(progress, n) },
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), copied)
+		assert.Equal(t, []int64{2, 3}, progress)
+
+		count, err := archive.CountAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+
+		// Re-running the copy is idempotent
+		copied, err = client.CopyCollection(ctx, "", "users", "", "users_archive", bson.M{"active": true}, CopyOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), copied)
+
+		count, err = archive.CountAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("CopyCollection applies Transform to each document", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		archive := NewRepository[User](client, "users_masked")
+		_ = archive.Drop(ctx)
+		_, _ = repo.CreateMany(ctx, []User{
+			{Name: "A", Email: "a@test.com", Age: 20},
+			{Name: "B", Email: "b@test.com", Age: 30},
+			{Name: "C", Email: "c@test.com", Age: 40},
+		})
+
+		copied, err := client.CopyCollection(ctx, "", "users", "", "users_masked", nil, CopyOptions{
+			Transform: func(doc bson.Raw) (bson.Raw, error) {
+				if doc.Lookup("name").StringValue() == "C" {
+					return nil, nil
+				}
+				var d bson.D
+				if err := bson.Unmarshal(doc, &d); err != nil {
+					return nil, err
+				}
+				for i := range d {
+					if d[i].Key == "email" {
+						d[i].Value = "redacted"
+					}
+				}
+				return bson.Marshal(d)
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), copied)
+
+		masked, err := archive.FindAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, masked, 2)
+		for _, u := range masked {
+			assert.Equal(t, "redacted", u.Email)
+		}
+	})
+
+	t.Run("CopyCollection with merge copies across databases", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		_, _ = repo.CreateMany(ctx, []User{
+			{Name: "A", Email: "a@test.com", Age: 20},
+			{Name: "B", Email: "b@test.com", Age: 30},
+		})
+
+		var reported int64
+		archive := testhelpers.IsolatedDatabase(t)
+		copied, err := client.CopyCollection(ctx, database, "users", archive, "users", nil, CopyOptions{
+			UseMerge:   true,
+			OnProgress: func(n int64) { reported = n },
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), copied)
+		assert.Equal(t, int64(2), reported)
+
+		count, err := client.client.Database(archive).Collection("users").CountDocuments(ctx, bson.M{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+}
@@ -0,0 +1,271 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestClient_CollectionManagement_ClosedClient(t *testing.T) {
+	client := &Client{closed: true}
+	ctx := context.Background()
+
+	t.Run("CreateView", func(t *testing.T) {
+		err := client.CreateView(ctx, "view", "source", []bson.M{})
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("DropView", func(t *testing.T) {
+		err := client.DropView(ctx, "view")
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("ModifyCollection", func(t *testing.T) {
+		err := client.ModifyCollection(ctx, "users", CollModOptions{ValidationLevel: "off"})
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("RenameCollection", func(t *testing.T) {
+		err := client.RenameCollection(ctx, "", "from", "to", false)
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+
+	t.Run("CopyCollection", func(t *testing.T) {
+		_, err := client.CopyCollection(ctx, "", "from", "", "to", nil, CopyOptions{})
+		assert.ErrorIs(t, err, ErrClientClosed)
+	})
+}
+
+func TestClient_CreateView_InvalidPipeline(t *testing.T) {
+	client := &Client{}
+
+	err := client.CreateView(context.Background(), "view", "source", "invalid")
+	var opErr *OperationError
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "create view", opErr.Op)
+}
+
+func TestClient_DropView(t *testing.T) {
+	ctx := context.Background()
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	t.Run("drops views", func(t *testing.T) {
+		mock.AddResponses(
+			testhelpers.CursorResponse("testdb.$cmd.listCollections", bson.D{{Key: "name", Value: "active_users"}, {Key: "type", Value: "view"}}),
+			testhelpers.SuccessResponse(),
+		)
+		assert.NoError(t, client.DropView(ctx, "active_users"))
+	})
+
+	t.Run("missing view is a no-op", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CursorResponse("testdb.$cmd.listCollections"))
+		assert.NoError(t, client.DropView(ctx, "missing_view"))
+	})
+
+	t.Run("rejects collections", func(t *testing.T) {
+		mock.AddResponses(
+			testhelpers.CursorResponse("testdb.$cmd.listCollections", bson.D{{Key: "name", Value: "users"}, {Key: "type", Value: "collection"}}),
+			testhelpers.SuccessResponse(), // would acknowledge a drop
+		)
+		err := client.DropView(ctx, "users")
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.ErrorContains(t, err, `"users" is a collection, not a view`)
+		mock.ClearResponses()
+	})
+}
+
+func TestCollModOptions_BuildCommand(t *testing.T) {
+	ttl := int64(60)
+
+	tests := []struct {
+		name     string
+		opts     CollModOptions
+		expected bson.D
+		errorMsg string
+	}{
+		{
+			name: "validator settings",
+			opts: CollModOptions{
+				Validator:        bson.M{"age": bson.M{"$gte": 0}},
+				ValidationLevel:  "moderate",
+				ValidationAction: "warn",
+			},
+			expected: bson.D{
+				{Key: "collMod", Value: "users"},
+				{Key: "validator", Value: bson.M{"age": bson.M{"$gte": 0}}},
+				{Key: "validationLevel", Value: "moderate"},
+				{Key: "validationAction", Value: "warn"},
+			},
+		},
+		{
+			name: "TTL index",
+			opts: CollModOptions{TTLIndexName: "expires_idx", TTLIndexSeconds: &ttl},
+			expected: bson.D{
+				{Key: "collMod", Value: "users"},
+				{Key: "index", Value: bson.D{
+					{Key: "name", Value: "expires_idx"},
+					{Key: "expireAfterSeconds", Value: int64(60)},
+				}},
+			},
+		},
+		{
+			name:     "no modifications",
+			opts:     CollModOptions{},
+			errorMsg: "at least one modification must be provided",
+		},
+		{
+			name:     "TTL seconds without index name",
+			opts:     CollModOptions{TTLIndexSeconds: &ttl},
+			errorMsg: "TTLIndexName is required",
+		},
+		{
+			name:     "TTL index name without seconds",
+			opts:     CollModOptions{TTLIndexName: "expires_idx"},
+			errorMsg: "TTLIndexSeconds is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := tt.opts.buildCommand("users")
+
+			if tt.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cmd)
+		})
+	}
+}
+
+func TestClient_ModifyCollection_InvalidOptions(t *testing.T) {
+	client := &Client{}
+
+	err := client.ModifyCollection(context.Background(), "users", CollModOptions{})
+	var opErr *OperationError
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "modify collection", opErr.Op)
+}
+
+func TestClient_RenameCollection_MissingNames(t *testing.T) {
+	client := &Client{}
+
+	err := client.RenameCollection(context.Background(), "testdb", "", "to", false)
+	var opErr *OperationError
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "rename collection", opErr.Op)
+}
+
+func TestClient_CopyCollection_InvalidArguments(t *testing.T) {
+	client := &Client{}
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		srcDB    string
+		srcColl  string
+		dstDB    string
+		dstColl  string
+		opts     CopyOptions
+		errorMsg string
+	}{
+		{name: "missing source", srcDB: "db", dstDB: "db", dstColl: "to", errorMsg: "collection names are required"},
+		{name: "missing target", srcDB: "db", srcColl: "from", dstDB: "db", errorMsg: "collection names are required"},
+		{name: "same collection", srcDB: "db", srcColl: "orders", dstDB: "db", dstColl: "orders", errorMsg: "must differ"},
+		{
+			name: "transform with merge", srcDB: "db", srcColl: "from", dstDB: "db", dstColl: "to",
+			opts:     CopyOptions{UseMerge: true, Transform: func(doc bson.Raw) (bson.Raw, error) { return doc, nil }},
+			errorMsg: "cannot be used with UseMerge",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.CopyCollection(ctx, tt.srcDB, tt.srcColl, tt.dstDB, tt.dstColl, nil, tt.opts)
+			var opErr *OperationError
+			require.ErrorAs(t, err, &opErr)
+			assert.Equal(t, "copy collection", opErr.Op)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestClient_CopyCollection_Batches(t *testing.T) {
+	ctx := context.Background()
+	newClient := func(t *testing.T) (*testhelpers.MockClient, *Client) {
+		mock := testhelpers.NewMockClient(t)
+		client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"), WithQueryCache(NewMemoryQueryCache()))
+		require.NoError(t, err)
+		return mock, client
+	}
+
+	t.Run("invalidates cached queries of the target", func(t *testing.T) {
+		mock, client := newClient(t)
+		archive := NewRepository[bson.M](client, "archive", WithQueryCaching(time.Minute))
+
+		mock.AddResponses(testhelpers.CursorResponse("testdb.archive"))
+		_, err := archive.Find(ctx, bson.M{})
+		require.NoError(t, err)
+
+		mock.AddResponses(
+			testhelpers.CursorResponse("testdb.orders", bson.D{{Key: "_id", Value: 1}}),
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 0}),
+		)
+		copied, err := client.CopyCollection(ctx, "", "orders", "", "archive", nil, CopyOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), copied)
+
+		mock.AddResponses(testhelpers.CursorResponse("testdb.archive", bson.D{{Key: "_id", Value: 1}}))
+		docs, err := archive.Find(ctx, bson.M{})
+		require.NoError(t, err)
+		assert.Len(t, docs, 1, "the cached empty result was dropped")
+	})
+
+	t.Run("releases the client between batches", func(t *testing.T) {
+		mock, client := newClient(t)
+		mock.AddResponses(
+			testhelpers.CursorResponse("testdb.orders", bson.D{{Key: "_id", Value: 1}}, bson.D{{Key: "_id", Value: 2}}),
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 0}),
+		)
+
+		// Close waits for the client lock, so it would block if the copy held it.
+		copied, err := client.CopyCollection(ctx, "", "orders", "", "archive", nil, CopyOptions{
+			BatchSize:  1,
+			OnProgress: func(int64) { require.NoError(t, client.Close(ctx)) },
+		})
+		assert.ErrorIs(t, err, ErrClientClosed)
+		assert.Equal(t, int64(1), copied)
+	})
+
+	t.Run("invalidates the target when a merge fails", func(t *testing.T) {
+		mock, client := newClient(t)
+		archive := NewRepository[bson.M](client, "archive", WithQueryCaching(time.Minute))
+
+		mock.AddResponses(testhelpers.CursorResponse("testdb.archive"))
+		_, err := archive.Find(ctx, bson.M{})
+		require.NoError(t, err)
+
+		mock.AddResponses(
+			testhelpers.CursorResponse("testdb.orders", bson.D{{Key: "n", Value: 2}}),
+			testhelpers.CommandErrorResponse(11000, "DuplicateKey", "E11000 duplicate key error"),
+		)
+		_, err = client.CopyCollection(ctx, "", "orders", "", "archive", nil, CopyOptions{UseMerge: true})
+		require.Error(t, err)
+
+		mock.AddResponses(testhelpers.CursorResponse("testdb.archive", bson.D{{Key: "_id", Value: 1}}))
+		docs, err := archive.Find(ctx, bson.M{})
+		require.NoError(t, err)
+		assert.Len(t, docs, 1, "documents merged before the failure are read")
+	})
+}
@@ -0,0 +1,60 @@
+package mongo_kit
+
+import (
+	"context"
+)
+
+// Request Comments
+//
+// This file provides the request ID carried by contexts, sent as the server-side
+// comment of finds, aggregations and updates so the operations of a request can be
+// found in the MongoDB profiler, currentOp and slow query logs.
+//
+// See docs/operations.md for detailed usage guide and examples.
+
+// requestIDKey is the context key of a request ID.
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the ID of the request the operations
+// run with it belong to. Finds, aggregations and updates send it as their comment.
+//
+// Example:
+//
+//	// In an HTTP middleware
+//	ctx := mongo_kit.WithRequestID(r.Context(), r.Header.Get("X-Request-ID"))
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID set on ctx with WithRequestID. The
+// boolean is false if ctx has no request ID.
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// WithRequestIDFunc sets the function returning the request ID of a context, for
+// request or trace IDs carried by other libraries, such as OpenTelemetry spans.
+// The default returns the ID set with WithRequestID.
+//
+// Example:
+//
+//	mongo_kit.WithRequestIDFunc(func(ctx context.Context) string {
+//	    return trace.SpanContextFromContext(ctx).TraceID().String()
+//	})
+func WithRequestIDFunc(fn func(ctx context.Context) string) Option {
+	return func(c *Config) {
+		c.RequestID = fn
+	}
+}
+
+// comment returns the comment to send with the operations of ctx, or "" if ctx has
+// no request ID.
+// The caller MUST hold c.mu.RLock().
+func (c *Client) comment(ctx context.Context) string {
+	if c.config.RequestID != nil {
+		return c.config.RequestID(ctx)
+	}
+	id, _ := RequestIDFromContext(ctx)
+	return id
+}
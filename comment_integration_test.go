@@ -0,0 +1,71 @@
+package mongo_kit
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestWithRequestID_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	var mu sync.Mutex
+	comments := make(map[string]string)
+	monitor := &event.CommandMonitor{Started: func(_ context.Context, e *event.CommandStartedEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if comment, ok := e.Command.Lookup("comment").StringValueOK(); ok {
+			comments[e.CommandName] = comment
+		}
+	}}
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database),
+		WithClientOptions(options.Client().SetMonitor(monitor)))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	repo := NewRepository[bson.M](client, "orders")
+	ctx := WithRequestID(context.Background(), "req-42")
+
+	_, err = repo.Create(ctx, bson.M{"status": "new"})
+	require.NoError(t, err)
+	_, err = repo.Find(ctx, bson.M{"status": "new"})
+	require.NoError(t, err)
+	_, err = repo.Aggregate(ctx, bson.A{bson.M{"$match": bson.M{"status": "new"}}})
+	require.NoError(t, err)
+	_, err = repo.UpdateMany(ctx, bson.M{"status": "new"}, bson.M{"$set": bson.M{"status": "paid"}})
+	require.NoError(t, err)
+	_, err = repo.UpsertWithBuilders(ctx, NewQueryBuilder().Equals("status", "paid"), NewUpdateBuilder().Set("status", "shipped"))
+	require.NoError(t, err)
+
+	mu.Lock()
+	assert.Equal(t, map[string]string{
+		"find":          "req-42",
+		"aggregate":     "req-42",
+		"update":        "req-42",
+		"findAndModify": "req-42",
+	}, comments)
+	mu.Unlock()
+
+	t.Run("an explicit comment wins", func(t *testing.T) {
+		_, err := repo.Find(ctx, bson.M{}, options.Find().SetComment("report"))
+		require.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, "report", comments["find"])
+	})
+}
@@ -0,0 +1,37 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDFromContext(t *testing.T) {
+	_, ok := RequestIDFromContext(context.Background())
+	assert.False(t, ok)
+
+	id, ok := RequestIDFromContext(WithRequestID(context.Background(), "req-1"))
+	assert.True(t, ok)
+	assert.Equal(t, "req-1", id)
+
+	_, ok = RequestIDFromContext(WithRequestID(context.Background(), ""))
+	assert.False(t, ok, "an empty ID is no ID")
+}
+
+func TestClient_Comment(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	t.Run("uses the request ID of the context by default", func(t *testing.T) {
+		client := &Client{config: DefaultConfig()}
+		assert.Equal(t, "req-1", client.comment(ctx))
+		assert.Empty(t, client.comment(context.Background()))
+	})
+
+	t.Run("uses the configured function", func(t *testing.T) {
+		client := &Client{config: withOptions(DefaultConfig(), WithRequestIDFunc(func(ctx context.Context) string {
+			return "trace-1"
+		}))}
+		assert.Equal(t, "trace-1", client.comment(ctx))
+	})
+}
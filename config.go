@@ -1,9 +1,16 @@
 package mongo_kit
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
 // Config holds the MongoDB client configuration.
@@ -15,8 +22,56 @@ type Config struct {
 	MaxPoolSize   uint64                 // Maximum number of connections in the connection pool (default: 100)
 	Timeout       time.Duration          // Default timeout for all operations (default: 10s)
 	ClientOptions *options.ClientOptions // Direct access to MongoDB driver options for advanced use cases
+
+	MinPoolSize            uint64             // Minimum number of connections kept in the connection pool (default: 0)
+	WarmPool               uint64             // Connections established by parallel pings before New returns (default: 0)
+	MaxConnIdleTime        time.Duration      // Maximum time a pooled connection may stay idle (default: driver default, no limit)
+	ConnectTimeout         time.Duration      // Timeout for establishing a connection to a server (default: driver default, 30s)
+	ServerSelectionTimeout time.Duration      // Timeout for selecting a server for an operation (default: driver default, 30s)
+	RetryWrites            *bool              // Retry supported write operations once on transient errors (default: driver default, true)
+	RetryReads             *bool              // Retry supported read operations once on transient errors (default: driver default, true)
+	ReadPreference         *readpref.ReadPref // Default read preference for all operations (default: primary)
+	ReplicaSet             string             // Name of the replica set to connect to (default: from URI)
+	AppName                string             // Application name reported to the server in logs and profiling (default: none)
+	Compressors            []string           // Wire compressors in order of preference: "zstd", "snappy", "zlib" (default: none)
+	LoadBalanced           bool               // Connect through a load balancer, e.g. Atlas serverless (default: false)
+	SRVMaxHosts            int                // Maximum number of hosts selected from the SRV record, 0 for all (default: 0)
+	SRVServiceName         string             // SRV service name used for mongodb+srv lookups (default: "mongodb")
+
+	WriteConcern *writeconcern.WriteConcern // Default write concern for all operations (default: server default)
+	ReadConcern  *readconcern.ReadConcern   // Default read concern for all operations (default: server default)
+
+	ServerAPI *options.ServerAPIOptions // Stable API version declared to the server (default: none)
+
+	BSONRegistry *bsoncodec.Registry // Codec registry used to encode and decode documents (default: bson.DefaultRegistry)
+
+	CredentialProvider CredentialProvider // Fetches credentials on connect and reconnect (default: credentials from URI)
+
+	ErrorQuerySummary bool // Attach redacted filter/update summaries to OperationError (default: false)
+
+	ErrorHook ErrorHook // Called for every failed operation (default: nil)
+
+	Clock Clock // Source of the current time for timestamps, expirations and leases (default: SystemClock)
+
+	QueryCache QueryCache // Stores results of repositories created WithQueryCaching (default: nil)
+
+	SafeWrites bool // Reject updates and deletes of many documents with empty filters (default: false)
+
+	RequestID func(ctx context.Context) string // Returns the request ID sent as the comment of operations (default: RequestIDFromContext)
+
+	uuidRegistry *bsoncodec.Registry // Registry built by WithUUIDCodec, which BSONRegistry must be
 }
 
+// ErrorHook is called with the operation name and error of every operation that
+// fails against the server, so error reporting and metrics can be centralized.
+// It runs synchronously on the calling goroutine and must not call back into the Client.
+type ErrorHook func(ctx context.Context, op string, err error)
+
+// CredentialProvider returns the username and password used to authenticate.
+// It is called each time the client connects, so secrets can be fetched from a
+// secret manager instead of being embedded in the URI or environment.
+type CredentialProvider func(ctx context.Context) (username, password string, err error)
+
 // DefaultConfig returns a Config with sensible default values.
 // This is the recommended starting point for most applications.
 //
@@ -26,6 +81,9 @@ type Config struct {
 //   - MaxPoolSize: 100
 //   - Timeout: 10 seconds
 //
+// Retryable reads and writes are left to the driver default (enabled), so the
+// retryWrites and retryReads options of the URI apply.
+//
 // Example:
 //
 //	cfg := mongo_kit.DefaultConfig()
@@ -39,6 +97,83 @@ func DefaultConfig() Config {
 	}
 }
 
+// ProductionConfig returns a Config hardened for production workloads:
+// a warm connection pool, bounded connection and server selection timeouts,
+// retryable reads and writes, wire compression and majority journaled writes.
+//
+// Values (in addition to DefaultConfig):
+//   - MaxPoolSize: 100, MinPoolSize: 10
+//   - MaxConnIdleTime: 5 minutes
+//   - ConnectTimeout: 10 seconds, ServerSelectionTimeout: 15 seconds
+//   - Compressors: zstd, snappy
+//   - ReadPreference: primary
+//   - WriteConcern: majority, journaled
+//
+// Example:
+//
+//	client, err := mongo_kit.New(mongo_kit.ProductionConfig(),
+//	    mongo_kit.WithURI(os.Getenv("MONGO_URI")),
+//	    mongo_kit.WithDatabase("myapp"))
+func ProductionConfig() Config {
+	cfg := DefaultConfig()
+	cfg.MinPoolSize = 10
+	cfg.MaxConnIdleTime = 5 * time.Minute
+	cfg.ConnectTimeout = 10 * time.Second
+	cfg.ServerSelectionTimeout = 15 * time.Second
+	cfg.Compressors = []string{"zstd", "snappy"}
+	cfg.ReadPreference = readpref.Primary()
+	journal := true
+	cfg.WriteConcern = &writeconcern.WriteConcern{W: "majority", Journal: &journal}
+	return cfg
+}
+
+// DevelopmentConfig returns a Config suited to local development: a small pool,
+// generous operation timeout for debugging, and fast failure when the local server
+// is not running.
+//
+// Values (in addition to DefaultConfig):
+//   - MaxPoolSize: 10
+//   - Timeout: 30 seconds
+//   - ConnectTimeout: 2 seconds, ServerSelectionTimeout: 2 seconds
+//
+// Example:
+//
+//	client, err := mongo_kit.New(mongo_kit.DevelopmentConfig(), mongo_kit.WithDatabase("myapp_dev"))
+func DevelopmentConfig() Config {
+	cfg := DefaultConfig()
+	cfg.MaxPoolSize = 10
+	cfg.Timeout = 30 * time.Second
+	cfg.ConnectTimeout = 2 * time.Second
+	cfg.ServerSelectionTimeout = 2 * time.Second
+	return cfg
+}
+
+// LowLatencyConfig returns a Config for latency-sensitive services: a large warm
+// pool, short timeouts so slow requests fail fast, reads from the nearest member
+// and cheap wire compression.
+//
+// Values (in addition to DefaultConfig):
+//   - MaxPoolSize: 200, MinPoolSize: 50
+//   - Timeout: 2 seconds
+//   - ConnectTimeout: 2 seconds, ServerSelectionTimeout: 2 seconds
+//   - Compressors: snappy
+//   - ReadPreference: nearest
+//
+// Example:
+//
+//	client, err := mongo_kit.New(mongo_kit.LowLatencyConfig(), mongo_kit.WithURI(uri))
+func LowLatencyConfig() Config {
+	cfg := DefaultConfig()
+	cfg.MaxPoolSize = 200
+	cfg.MinPoolSize = 50
+	cfg.Timeout = 2 * time.Second
+	cfg.ConnectTimeout = 2 * time.Second
+	cfg.ServerSelectionTimeout = 2 * time.Second
+	cfg.Compressors = []string{"snappy"}
+	cfg.ReadPreference = readpref.Nearest()
+	return cfg
+}
+
 // Option is a function that modifies a Config.
 // Use Option functions with New() to customize the configuration.
 type Option func(*Config)
@@ -92,27 +227,306 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithMinPoolSize sets the minimum number of connections kept open in the connection pool.
+// Must not exceed MaxPoolSize.
+//
+// Example:
+//
+//	mongo_kit.WithMinPoolSize(10)
+func WithMinPoolSize(size uint64) Option {
+	return func(c *Config) {
+		c.MinPoolSize = size
+	}
+}
+
+// WithWarmPool makes New, ApplyConfig and Reconnect issue n pings in parallel once
+// connected, so the pool establishes connections upfront instead of on the first
+// requests after a cold start. The pool opens a connection for every ping that finds no
+// idle one, so up to n connections are established; combine it with WithMinPoolSize to
+// keep them open. Must not exceed MaxPoolSize.
+//
+// Example:
+//
+//	mongo_kit.WithWarmPool(20)
+func WithWarmPool(n uint64) Option {
+	return func(c *Config) {
+		c.WarmPool = n
+	}
+}
+
+// WithMaxConnIdleTime sets how long a pooled connection may stay idle before it is closed.
+//
+// Example:
+//
+//	mongo_kit.WithMaxConnIdleTime(5 * time.Minute)
+func WithMaxConnIdleTime(d time.Duration) Option {
+	return func(c *Config) {
+		c.MaxConnIdleTime = d
+	}
+}
+
+// WithConnectTimeout sets the timeout for establishing a connection to a server.
+//
+// Example:
+//
+//	mongo_kit.WithConnectTimeout(5 * time.Second)
+func WithConnectTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.ConnectTimeout = timeout
+	}
+}
+
+// WithServerSelectionTimeout sets how long to wait for a suitable server before an
+// operation fails.
+//
+// Example:
+//
+//	mongo_kit.WithServerSelectionTimeout(5 * time.Second)
+func WithServerSelectionTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.ServerSelectionTimeout = timeout
+	}
+}
+
+// WithRetryWrites enables or disables retrying supported write operations once
+// on transient errors. Enabled by default.
+//
+// Example:
+//
+//	mongo_kit.WithRetryWrites(false)
+func WithRetryWrites(enabled bool) Option {
+	return func(c *Config) {
+		c.RetryWrites = &enabled
+	}
+}
+
+// WithRetryReads enables or disables retrying supported read operations once
+// on transient errors. Enabled by default.
+//
+// Example:
+//
+//	mongo_kit.WithRetryReads(false)
+func WithRetryReads(enabled bool) Option {
+	return func(c *Config) {
+		c.RetryReads = &enabled
+	}
+}
+
+// WithReadPreference sets the default read preference for all read operations.
+//
+// Example:
+//
+//	mongo_kit.WithReadPreference(readpref.SecondaryPreferred())
+func WithReadPreference(rp *readpref.ReadPref) Option {
+	return func(c *Config) {
+		c.ReadPreference = rp
+	}
+}
+
+// WithReplicaSet sets the name of the replica set to connect to.
+//
+// Example:
+//
+//	mongo_kit.WithReplicaSet("rs0")
+func WithReplicaSet(name string) Option {
+	return func(c *Config) {
+		c.ReplicaSet = name
+	}
+}
+
+// WithAppName sets the application name reported to the server, which appears
+// in server logs, the profiler and currentOp output.
+//
+// Example:
+//
+//	mongo_kit.WithAppName("billing-service")
+func WithAppName(name string) Option {
+	return func(c *Config) {
+		c.AppName = name
+	}
+}
+
+// WithCompressors sets the wire compressors to negotiate with the server, in order
+// of preference. Supported values are "zstd", "snappy" and "zlib".
+//
+// Example:
+//
+//	mongo_kit.WithCompressors("zstd", "snappy")
+func WithCompressors(compressors ...string) Option {
+	return func(c *Config) {
+		c.Compressors = compressors
+	}
+}
+
+// WithLoadBalanced enables load-balanced mode, required when connecting through a
+// load balancer or proxy such as Atlas serverless instances. The URI must contain a
+// single host and no replica set name.
+//
+// Example:
+//
+//	mongo_kit.WithLoadBalanced(true)
+func WithLoadBalanced(enabled bool) Option {
+	return func(c *Config) {
+		c.LoadBalanced = enabled
+	}
+}
+
+// WithSRVMaxHosts limits how many hosts are selected at random from the SRV record
+// of a mongodb+srv URI. SRV records are polled and the selection refreshed as hosts
+// are added or removed. 0 uses all hosts.
+//
+// Example:
+//
+//	mongo_kit.WithSRVMaxHosts(3)
+func WithSRVMaxHosts(n int) Option {
+	return func(c *Config) {
+		c.SRVMaxHosts = n
+	}
+}
+
+// WithSRVServiceName sets a custom SRV service name for mongodb+srv lookups,
+// for deployments that publish their SRV records under a name other than "mongodb".
+//
+// Example:
+//
+//	mongo_kit.WithSRVServiceName("customname")
+func WithSRVServiceName(name string) Option {
+	return func(c *Config) {
+		c.SRVServiceName = name
+	}
+}
+
+// WithWriteConcern sets the default write concern for all write operations.
+// w is either the number of nodes that must acknowledge the write (e.g. 1) or a
+// string such as "majority" or a custom tag set name. journal requests acknowledgment
+// that the write has been written to the on-disk journal. wtimeout limits how long
+// to wait for the acknowledgment; zero means no limit.
+//
+// Example:
+//
+//	mongo_kit.WithWriteConcern("majority", true, 5*time.Second)
+//	mongo_kit.WithWriteConcern(1, false, 0)
+func WithWriteConcern(w any, journal bool, wtimeout time.Duration) Option {
+	return func(c *Config) {
+		c.WriteConcern = &writeconcern.WriteConcern{
+			W:        w,
+			Journal:  &journal,
+			WTimeout: wtimeout,
+		}
+	}
+}
+
+// WithReadConcern sets the default read concern level for all read operations.
+// Supported levels are "local", "available", "majority", "linearizable" and "snapshot".
+//
+// Example:
+//
+//	mongo_kit.WithReadConcern("majority")
+func WithReadConcern(level string) Option {
+	return func(c *Config) {
+		c.ReadConcern = &readconcern.ReadConcern{Level: level}
+	}
+}
+
+// WithServerAPI declares the Stable API version the application targets.
+// strict makes the server reject commands that are not part of the declared version,
+// and deprecationErrors makes it reject commands deprecated in that version.
+// The only supported version is "1".
+//
+// Example:
+//
+//	mongo_kit.WithServerAPI("1", true, true)
+func WithServerAPI(version string, strict, deprecationErrors bool) Option {
+	return func(c *Config) {
+		c.ServerAPI = options.ServerAPI(options.ServerAPIVersion(version)).
+			SetStrict(strict).
+			SetDeprecationErrors(deprecationErrors)
+	}
+}
+
+// WithBSONRegistry sets the codec registry used to encode and decode documents
+// in all operations, so custom encoders and decoders (e.g. for uuid.UUID, decimal
+// types or custom enums) apply everywhere.
+//
+// Example:
+//
+//	rb := bson.NewRegistryBuilder()
+//	rb.RegisterTypeEncoder(reflect.TypeOf(uuid.UUID{}), uuidCodec)
+//	rb.RegisterTypeDecoder(reflect.TypeOf(uuid.UUID{}), uuidCodec)
+//	mongo_kit.WithBSONRegistry(rb.Build())
+func WithBSONRegistry(registry *bsoncodec.Registry) Option {
+	return func(c *Config) {
+		c.BSONRegistry = registry
+	}
+}
+
+// WithCredentialProvider sets a function that supplies credentials whenever the client
+// connects, including on Reconnect and ApplyConfig, so rotated secrets are picked up.
+// Credentials from the provider replace those in the URI; other auth settings such as
+// authSource or authMechanism are kept.
+//
+// Example:
+//
+//	mongo_kit.WithCredentialProvider(func(ctx context.Context) (string, string, error) {
+//	    secret, err := vault.Read(ctx, "secret/mongo")
+//	    if err != nil {
+//	        return "", "", err
+//	    }
+//	    return secret.Username, secret.Password, nil
+//	})
+func WithCredentialProvider(provider CredentialProvider) Option {
+	return func(c *Config) {
+		c.CredentialProvider = provider
+	}
+}
+
+// WithErrorQuerySummary attaches redacted summaries of the filter and update to
+// OperationError, so production logs show the shape of the failed query. Field names
+// and operators are kept and every value is replaced by "?", e.g. {"age": {"$gt": ?}}.
+// Disabled by default because field names may themselves be sensitive.
+//
+// Example:
+//
+//	mongo_kit.WithErrorQuerySummary(true)
+func WithErrorQuerySummary(enabled bool) Option {
+	return func(c *Config) {
+		c.ErrorQuerySummary = enabled
+	}
+}
+
+// WithErrorHook sets a function invoked for every operation that fails against the server,
+// e.g. to report errors to Sentry or count failures by operation. Argument validation
+// errors and ErrClientClosed are returned without invoking the hook.
+//
+// Example:
+//
+//	mongo_kit.WithErrorHook(func(ctx context.Context, op string, err error) {
+//	    sentry.CaptureException(err)
+//	    failures.WithLabelValues(op).Inc()
+//	})
+func WithErrorHook(hook ErrorHook) Option {
+	return func(c *Config) {
+		c.ErrorHook = hook
+	}
+}
+
 // WithClientOptions allows you to directly configure the underlying MongoDB driver options.
 // This is an escape hatch for advanced configurations not covered by the basic options.
 //
 // Use this when you need fine-grained control over:
-//   - Minimum pool size
-//   - Retry behavior
 //   - TLS/SSL settings
 //   - Compression
-//   - Read preferences
-//   - Write concerns
+//   - Monitoring
 //   - And more...
 //
 // Example:
 //
 //	clientOpts := options.Client()
-//	clientOpts.SetMinPoolSize(10)
-//	clientOpts.SetRetryWrites(true)
-//	clientOpts.SetReadPreference(readpref.Secondary())
+//	clientOpts.SetCompressors([]string{"zstd"})
 //	mongo_kit.WithClientOptions(clientOpts)
 //
-// Note: Settings applied via WithClientOptions will override basic options like MaxPoolSize.
+// Note: The options set on Config (URI, pool sizes, timeouts, retry flags, read
+// preference, concerns, ...) are applied on top of these options and take precedence.
 func WithClientOptions(opts *options.ClientOptions) Option {
 	return func(c *Config) {
 		c.ClientOptions = opts
@@ -138,5 +552,176 @@ func (c *Config) validate() error {
 		return newConfigFieldError("Timeout", "must be greater than 0")
 	}
 
+	if c.MinPoolSize > c.MaxPoolSize {
+		return newConfigFieldError("MinPoolSize", "cannot be greater than MaxPoolSize")
+	}
+
+	if c.WarmPool > c.MaxPoolSize {
+		return newConfigFieldError("WarmPool", "cannot be greater than MaxPoolSize")
+	}
+
+	if c.MaxConnIdleTime < 0 {
+		return newConfigFieldError("MaxConnIdleTime", "cannot be negative")
+	}
+
+	if c.ConnectTimeout < 0 {
+		return newConfigFieldError("ConnectTimeout", "cannot be negative")
+	}
+
+	if c.ServerSelectionTimeout < 0 {
+		return newConfigFieldError("ServerSelectionTimeout", "cannot be negative")
+	}
+
+	if c.LoadBalanced && c.ReplicaSet != "" {
+		return newConfigFieldError("LoadBalanced", "cannot be used with a replica set name")
+	}
+
+	if c.SRVMaxHosts < 0 {
+		return newConfigFieldError("SRVMaxHosts", "cannot be negative")
+	}
+
+	if c.SRVMaxHosts > 0 && c.ReplicaSet != "" {
+		return newConfigFieldError("SRVMaxHosts", "cannot be used with a replica set name")
+	}
+
+	for _, compressor := range c.Compressors {
+		switch compressor {
+		case "zstd", "snappy", "zlib":
+		default:
+			return newConfigFieldError("Compressors", fmt.Sprintf("unsupported compressor %q", compressor))
+		}
+	}
+
+	if c.WriteConcern != nil {
+		if err := validateWriteConcern(c.WriteConcern); err != nil {
+			return err
+		}
+	}
+
+	if c.ReadConcern != nil {
+		switch c.ReadConcern.Level {
+		case "local", "available", "majority", "linearizable", "snapshot":
+		default:
+			return newConfigFieldError("ReadConcern", fmt.Sprintf("unsupported level %q", c.ReadConcern.Level))
+		}
+	}
+
+	if c.ServerAPI != nil {
+		if err := c.ServerAPI.ServerAPIVersion.Validate(); err != nil {
+			return newConfigFieldError("ServerAPI", err.Error())
+		}
+	}
+
+	if c.uuidRegistry != nil && c.BSONRegistry != c.uuidRegistry {
+		return newConfigFieldError("BSONRegistry", "cannot be used with WithUUIDCodec, call RegisterUUIDCodec on the registry instead")
+	}
+
+	return nil
+}
+
+// validateWriteConcern checks that w is a non-negative number or a non-empty string
+// and that wtimeout is not negative.
+func validateWriteConcern(wc *writeconcern.WriteConcern) error {
+	switch w := wc.W.(type) {
+	case int:
+		if w < 0 {
+			return newConfigFieldError("WriteConcern", "w cannot be negative")
+		}
+	case string:
+		if w == "" {
+			return newConfigFieldError("WriteConcern", "w cannot be empty")
+		}
+	case nil:
+	default:
+		return newConfigFieldError("WriteConcern", fmt.Sprintf("w must be an int or a string, got %T", w))
+	}
+
+	if wc.WTimeout < 0 {
+		return newConfigFieldError("WriteConcern", "wtimeout cannot be negative")
+	}
+
 	return nil
 }
+
+// clientOptions builds the driver client options for the configuration.
+// A copy of ClientOptions, when set, is used as the base and the Config fields are
+// applied on top, so the caller's options are never modified.
+func (c *Config) clientOptions() *options.ClientOptions {
+	clientOpts := options.Client()
+	if c.ClientOptions != nil {
+		base := *c.ClientOptions
+		clientOpts = &base
+	}
+
+	clientOpts.ApplyURI(c.URI)
+	clientOpts.SetMaxPoolSize(c.MaxPoolSize)
+	if c.RetryWrites != nil {
+		clientOpts.SetRetryWrites(*c.RetryWrites)
+	}
+	if c.RetryReads != nil {
+		clientOpts.SetRetryReads(*c.RetryReads)
+	}
+	if c.MinPoolSize > 0 {
+		clientOpts.SetMinPoolSize(c.MinPoolSize)
+	}
+	if c.MaxConnIdleTime > 0 {
+		clientOpts.SetMaxConnIdleTime(c.MaxConnIdleTime)
+	}
+	if c.ConnectTimeout > 0 {
+		clientOpts.SetConnectTimeout(c.ConnectTimeout)
+	}
+	if c.ServerSelectionTimeout > 0 {
+		clientOpts.SetServerSelectionTimeout(c.ServerSelectionTimeout)
+	}
+	if c.ReadPreference != nil {
+		clientOpts.SetReadPreference(c.ReadPreference)
+	}
+	if c.ReplicaSet != "" {
+		clientOpts.SetReplicaSet(c.ReplicaSet)
+	}
+	if c.AppName != "" {
+		clientOpts.SetAppName(c.AppName)
+	}
+	if c.LoadBalanced {
+		clientOpts.SetLoadBalanced(true)
+	}
+	if c.SRVMaxHosts > 0 {
+		clientOpts.SetSRVMaxHosts(c.SRVMaxHosts)
+	}
+	if c.SRVServiceName != "" {
+		clientOpts.SetSRVServiceName(c.SRVServiceName)
+	}
+	if len(c.Compressors) > 0 {
+		clientOpts.SetCompressors(c.Compressors)
+	}
+	if c.WriteConcern != nil {
+		clientOpts.SetWriteConcern(c.WriteConcern)
+	}
+	if c.ReadConcern != nil {
+		clientOpts.SetReadConcern(c.ReadConcern)
+	}
+	if c.ServerAPI != nil {
+		clientOpts.SetServerAPIOptions(c.ServerAPI)
+	}
+	if c.BSONRegistry != nil {
+		clientOpts.SetRegistry(c.BSONRegistry)
+	}
+
+	return clientOpts
+}
+
+// reportError invokes the ErrorHook, if any, for err and returns err unchanged.
+func (c *Config) reportError(ctx context.Context, err error) error {
+	if c.ErrorHook == nil || err == nil {
+		return err
+	}
+
+	var op string
+	var opErr *OperationError
+	if errors.As(err, &opErr) {
+		op = opErr.Op
+	}
+	c.ErrorHook(ctx, op, err)
+
+	return err
+}
@@ -1,12 +1,15 @@
 package mongo_kit
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -17,6 +20,55 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, uint64(100), cfg.MaxPoolSize)
 	assert.Equal(t, 10*time.Second, cfg.Timeout)
 	assert.Nil(t, cfg.ClientOptions)
+	assert.Nil(t, cfg.WriteConcern)
+	assert.Nil(t, cfg.ReadConcern)
+	assert.Nil(t, cfg.RetryWrites)
+	assert.Nil(t, cfg.RetryReads)
+	assert.Zero(t, cfg.MinPoolSize)
+}
+
+func TestConfigPresets(t *testing.T) {
+	t.Run("ProductionConfig", func(t *testing.T) {
+		cfg := ProductionConfig()
+
+		require.NoError(t, cfg.validate())
+		assert.Equal(t, "mongodb://localhost:27017", cfg.URI)
+		assert.Equal(t, uint64(100), cfg.MaxPoolSize)
+		assert.Equal(t, uint64(10), cfg.MinPoolSize)
+		assert.Equal(t, []string{"zstd", "snappy"}, cfg.Compressors)
+		assert.Equal(t, readpref.PrimaryMode, cfg.ReadPreference.Mode())
+		require.NotNil(t, cfg.WriteConcern)
+		assert.Equal(t, "majority", cfg.WriteConcern.W)
+		assert.True(t, *cfg.WriteConcern.Journal)
+	})
+
+	t.Run("DevelopmentConfig", func(t *testing.T) {
+		cfg := DevelopmentConfig()
+
+		require.NoError(t, cfg.validate())
+		assert.Equal(t, uint64(10), cfg.MaxPoolSize)
+		assert.Equal(t, 30*time.Second, cfg.Timeout)
+		assert.Equal(t, 2*time.Second, cfg.ServerSelectionTimeout)
+		assert.Empty(t, cfg.Compressors)
+	})
+
+	t.Run("LowLatencyConfig", func(t *testing.T) {
+		cfg := LowLatencyConfig()
+
+		require.NoError(t, cfg.validate())
+		assert.Equal(t, uint64(200), cfg.MaxPoolSize)
+		assert.Equal(t, uint64(50), cfg.MinPoolSize)
+		assert.Equal(t, 2*time.Second, cfg.Timeout)
+		assert.Equal(t, []string{"snappy"}, cfg.Compressors)
+		assert.Equal(t, readpref.NearestMode, cfg.ReadPreference.Mode())
+	})
+
+	t.Run("presets can be customized with options", func(t *testing.T) {
+		cfg := withOptions(ProductionConfig(), WithMaxPoolSize(300), WithCompressors("zlib"))
+
+		assert.Equal(t, uint64(300), cfg.MaxPoolSize)
+		assert.Equal(t, []string{"zlib"}, cfg.Compressors)
+	})
 }
 
 func TestConfigOptions(t *testing.T) {
@@ -74,6 +126,192 @@ func TestConfigOptions(t *testing.T) {
 				require.NotNil(t, cfg.ClientOptions)
 			},
 		},
+		{
+			name:   "WithWarmPool sets warm pool size",
+			option: WithWarmPool(20),
+			validate: func(t *testing.T, cfg Config) {
+				assert.Equal(t, uint64(20), cfg.WarmPool)
+			},
+		},
+		{
+			name:   "WithMinPoolSize sets min pool size",
+			option: WithMinPoolSize(10),
+			validate: func(t *testing.T, cfg Config) {
+				assert.Equal(t, uint64(10), cfg.MinPoolSize)
+			},
+		},
+		{
+			name:   "WithMaxConnIdleTime sets idle time",
+			option: WithMaxConnIdleTime(time.Minute),
+			validate: func(t *testing.T, cfg Config) {
+				assert.Equal(t, time.Minute, cfg.MaxConnIdleTime)
+			},
+		},
+		{
+			name:   "WithConnectTimeout sets connect timeout",
+			option: WithConnectTimeout(5 * time.Second),
+			validate: func(t *testing.T, cfg Config) {
+				assert.Equal(t, 5*time.Second, cfg.ConnectTimeout)
+			},
+		},
+		{
+			name:   "WithServerSelectionTimeout sets server selection timeout",
+			option: WithServerSelectionTimeout(3 * time.Second),
+			validate: func(t *testing.T, cfg Config) {
+				assert.Equal(t, 3*time.Second, cfg.ServerSelectionTimeout)
+			},
+		},
+		{
+			name:   "WithRetryWrites disables retryable writes",
+			option: WithRetryWrites(false),
+			validate: func(t *testing.T, cfg Config) {
+				assert.False(t, *cfg.RetryWrites)
+			},
+		},
+		{
+			name:   "WithRetryReads disables retryable reads",
+			option: WithRetryReads(false),
+			validate: func(t *testing.T, cfg Config) {
+				assert.False(t, *cfg.RetryReads)
+			},
+		},
+		{
+			name:   "WithReadPreference sets read preference",
+			option: WithReadPreference(readpref.SecondaryPreferred()),
+			validate: func(t *testing.T, cfg Config) {
+				require.NotNil(t, cfg.ReadPreference)
+				assert.Equal(t, readpref.SecondaryPreferredMode, cfg.ReadPreference.Mode())
+			},
+		},
+		{
+			name:   "WithReplicaSet sets replica set",
+			option: WithReplicaSet("rs0"),
+			validate: func(t *testing.T, cfg Config) {
+				assert.Equal(t, "rs0", cfg.ReplicaSet)
+			},
+		},
+		{
+			name:   "WithAppName sets app name",
+			option: WithAppName("billing"),
+			validate: func(t *testing.T, cfg Config) {
+				assert.Equal(t, "billing", cfg.AppName)
+			},
+		},
+		{
+			name:   "WithCompressors sets compressors",
+			option: WithCompressors("zstd", "zlib"),
+			validate: func(t *testing.T, cfg Config) {
+				assert.Equal(t, []string{"zstd", "zlib"}, cfg.Compressors)
+			},
+		},
+		{
+			name:   "WithLoadBalanced enables load-balanced mode",
+			option: WithLoadBalanced(true),
+			validate: func(t *testing.T, cfg Config) {
+				assert.True(t, cfg.LoadBalanced)
+			},
+		},
+		{
+			name:   "WithSRVMaxHosts sets SRV max hosts",
+			option: WithSRVMaxHosts(3),
+			validate: func(t *testing.T, cfg Config) {
+				assert.Equal(t, 3, cfg.SRVMaxHosts)
+			},
+		},
+		{
+			name:   "WithSRVServiceName sets SRV service name",
+			option: WithSRVServiceName("customname"),
+			validate: func(t *testing.T, cfg Config) {
+				assert.Equal(t, "customname", cfg.SRVServiceName)
+			},
+		},
+		{
+			name:   "WithWriteConcern sets write concern",
+			option: WithWriteConcern("majority", true, 5*time.Second),
+			validate: func(t *testing.T, cfg Config) {
+				require.NotNil(t, cfg.WriteConcern)
+				assert.Equal(t, "majority", cfg.WriteConcern.W)
+				require.NotNil(t, cfg.WriteConcern.Journal)
+				assert.True(t, *cfg.WriteConcern.Journal)
+				assert.Equal(t, 5*time.Second, cfg.WriteConcern.WTimeout)
+			},
+		},
+		{
+			name:   "WithReadConcern sets read concern",
+			option: WithReadConcern("majority"),
+			validate: func(t *testing.T, cfg Config) {
+				require.NotNil(t, cfg.ReadConcern)
+				assert.Equal(t, "majority", cfg.ReadConcern.Level)
+			},
+		},
+		{
+			name:   "WithServerAPI sets server API",
+			option: WithServerAPI("1", true, false),
+			validate: func(t *testing.T, cfg Config) {
+				require.NotNil(t, cfg.ServerAPI)
+				assert.Equal(t, options.ServerAPIVersion1, cfg.ServerAPI.ServerAPIVersion)
+				require.NotNil(t, cfg.ServerAPI.Strict)
+				assert.True(t, *cfg.ServerAPI.Strict)
+				require.NotNil(t, cfg.ServerAPI.DeprecationErrors)
+				assert.False(t, *cfg.ServerAPI.DeprecationErrors)
+			},
+		},
+		{
+			name:   "WithBSONRegistry sets registry",
+			option: WithBSONRegistry(bson.NewRegistry()),
+			validate: func(t *testing.T, cfg Config) {
+				assert.NotNil(t, cfg.BSONRegistry)
+			},
+		},
+		{
+			name: "WithCredentialProvider sets provider",
+			option: WithCredentialProvider(func(context.Context) (string, string, error) {
+				return "app", "secret", nil
+			}),
+			validate: func(t *testing.T, cfg Config) {
+				require.NotNil(t, cfg.CredentialProvider)
+				username, password, err := cfg.CredentialProvider(context.Background())
+				require.NoError(t, err)
+				assert.Equal(t, "app", username)
+				assert.Equal(t, "secret", password)
+			},
+		},
+		{
+			name:   "WithErrorQuerySummary enables summaries",
+			option: WithErrorQuerySummary(true),
+			validate: func(t *testing.T, cfg Config) {
+				assert.True(t, cfg.ErrorQuerySummary)
+			},
+		},
+		{
+			name:   "WithErrorHook sets hook",
+			option: WithErrorHook(func(context.Context, string, error) {}),
+			validate: func(t *testing.T, cfg Config) {
+				assert.NotNil(t, cfg.ErrorHook)
+			},
+		},
+		{
+			name:   "WithQueryCache sets cache",
+			option: WithQueryCache(NewMemoryQueryCache()),
+			validate: func(t *testing.T, cfg Config) {
+				assert.NotNil(t, cfg.QueryCache)
+			},
+		},
+		{
+			name:   "WithSafeWrites enables guard",
+			option: WithSafeWrites(),
+			validate: func(t *testing.T, cfg Config) {
+				assert.True(t, cfg.SafeWrites)
+			},
+		},
+		{
+			name:   "WithRequestIDFunc sets function",
+			option: WithRequestIDFunc(func(context.Context) string { return "trace" }),
+			validate: func(t *testing.T, cfg Config) {
+				require.NotNil(t, cfg.RequestID)
+				assert.Equal(t, "trace", cfg.RequestID(context.Background()))
+			},
+		},
 		{
 			name:   "WithClientOptions nil",
 			option: WithClientOptions(nil),
@@ -188,6 +426,121 @@ func TestConfigValidate(t *testing.T) {
 			errorField:  "Timeout",
 			errorMsg:    "must be greater than 0",
 		},
+		{
+			name:        "min pool size greater than max pool size",
+			config:      withOptions(DefaultConfig(), WithMaxPoolSize(10), WithMinPoolSize(20)),
+			expectError: true,
+			errorField:  "MinPoolSize",
+			errorMsg:    "cannot be greater than MaxPoolSize",
+		},
+		{
+			name:        "warm pool greater than max pool size",
+			config:      withOptions(DefaultConfig(), WithMaxPoolSize(10), WithWarmPool(20)),
+			expectError: true,
+			errorField:  "WarmPool",
+			errorMsg:    "cannot be greater than MaxPoolSize",
+		},
+		{
+			name:        "negative max conn idle time",
+			config:      withOptions(DefaultConfig(), WithMaxConnIdleTime(-time.Second)),
+			expectError: true,
+			errorField:  "MaxConnIdleTime",
+			errorMsg:    "cannot be negative",
+		},
+		{
+			name:        "negative connect timeout",
+			config:      withOptions(DefaultConfig(), WithConnectTimeout(-time.Second)),
+			expectError: true,
+			errorField:  "ConnectTimeout",
+			errorMsg:    "cannot be negative",
+		},
+		{
+			name:        "negative server selection timeout",
+			config:      withOptions(DefaultConfig(), WithServerSelectionTimeout(-time.Second)),
+			expectError: true,
+			errorField:  "ServerSelectionTimeout",
+			errorMsg:    "cannot be negative",
+		},
+		{
+			name:        "load balanced with replica set",
+			config:      withOptions(DefaultConfig(), WithLoadBalanced(true), WithReplicaSet("rs0")),
+			expectError: true,
+			errorField:  "LoadBalanced",
+			errorMsg:    "cannot be used with a replica set name",
+		},
+		{
+			name:        "negative SRV max hosts",
+			config:      withOptions(DefaultConfig(), WithSRVMaxHosts(-1)),
+			expectError: true,
+			errorField:  "SRVMaxHosts",
+			errorMsg:    "cannot be negative",
+		},
+		{
+			name:        "SRV max hosts with replica set",
+			config:      withOptions(DefaultConfig(), WithSRVMaxHosts(2), WithReplicaSet("rs0")),
+			expectError: true,
+			errorField:  "SRVMaxHosts",
+			errorMsg:    "cannot be used with a replica set name",
+		},
+		{
+			name:        "unsupported compressor",
+			config:      withOptions(DefaultConfig(), WithCompressors("lz4")),
+			expectError: true,
+			errorField:  "Compressors",
+			errorMsg:    "unsupported compressor",
+		},
+		{
+			name:        "valid write and read concern",
+			config:      withOptions(DefaultConfig(), WithWriteConcern(2, false, time.Second), WithReadConcern("local")),
+			expectError: false,
+		},
+		{
+			name:        "negative write concern w",
+			config:      withOptions(DefaultConfig(), WithWriteConcern(-1, false, 0)),
+			expectError: true,
+			errorField:  "WriteConcern",
+			errorMsg:    "w cannot be negative",
+		},
+		{
+			name:        "empty write concern w",
+			config:      withOptions(DefaultConfig(), WithWriteConcern("", false, 0)),
+			expectError: true,
+			errorField:  "WriteConcern",
+			errorMsg:    "w cannot be empty",
+		},
+		{
+			name:        "unsupported write concern w type",
+			config:      withOptions(DefaultConfig(), WithWriteConcern(1.5, false, 0)),
+			expectError: true,
+			errorField:  "WriteConcern",
+			errorMsg:    "w must be an int or a string",
+		},
+		{
+			name:        "negative write concern wtimeout",
+			config:      withOptions(DefaultConfig(), WithWriteConcern(1, false, -time.Second)),
+			expectError: true,
+			errorField:  "WriteConcern",
+			errorMsg:    "wtimeout cannot be negative",
+		},
+		{
+			name:        "unsupported read concern level",
+			config:      withOptions(DefaultConfig(), WithReadConcern("strong")),
+			expectError: true,
+			errorField:  "ReadConcern",
+			errorMsg:    "unsupported level",
+		},
+		{
+			name:        "valid server API",
+			config:      withOptions(DefaultConfig(), WithServerAPI("1", true, true)),
+			expectError: false,
+		},
+		{
+			name:        "unsupported server API version",
+			config:      withOptions(DefaultConfig(), WithServerAPI("2", false, false)),
+			expectError: true,
+			errorField:  "ServerAPI",
+			errorMsg:    "not supported",
+		},
 	}
 
 	for _, tt := range tests {
@@ -206,3 +559,112 @@ func TestConfigValidate(t *testing.T) {
 		})
 	}
 }
+
+// withOptions applies opts to cfg and returns it.
+func withOptions(cfg Config, opts ...Option) Config {
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+func TestNew_ValidatesOptions(t *testing.T) {
+	_, err := New(DefaultConfig(), WithReadConcern("strong"))
+
+	var configErr *ConfigError
+	require.ErrorAs(t, err, &configErr)
+	assert.Equal(t, "ReadConcern", configErr.Field)
+}
+
+func TestConfig_ClientOptions(t *testing.T) {
+	t.Run("applies config fields", func(t *testing.T) {
+		cfg := withOptions(DefaultConfig(),
+			WithURI("mongodb://db1:27017"),
+			WithMaxPoolSize(50),
+			WithMinPoolSize(5),
+			WithMaxConnIdleTime(time.Minute),
+			WithConnectTimeout(2*time.Second),
+			WithServerSelectionTimeout(3*time.Second),
+			WithRetryWrites(false),
+			WithReadPreference(readpref.Nearest()),
+			WithReplicaSet("rs0"),
+			WithAppName("billing"),
+			WithCompressors("snappy"),
+		)
+
+		opts := cfg.clientOptions()
+
+		assert.Equal(t, []string{"db1:27017"}, opts.Hosts)
+		assert.Equal(t, uint64(50), *opts.MaxPoolSize)
+		assert.Equal(t, uint64(5), *opts.MinPoolSize)
+		assert.Equal(t, time.Minute, *opts.MaxConnIdleTime)
+		assert.Equal(t, 2*time.Second, *opts.ConnectTimeout)
+		assert.Equal(t, 3*time.Second, *opts.ServerSelectionTimeout)
+		assert.False(t, *opts.RetryWrites)
+		assert.Nil(t, opts.RetryReads)
+		assert.Equal(t, readpref.NearestMode, opts.ReadPreference.Mode())
+		assert.Equal(t, "rs0", *opts.ReplicaSet)
+		assert.Equal(t, "billing", *opts.AppName)
+		assert.Equal(t, []string{"snappy"}, opts.Compressors)
+	})
+
+	t.Run("leaves unset fields to the driver", func(t *testing.T) {
+		cfg := DefaultConfig()
+		opts := cfg.clientOptions()
+
+		assert.Nil(t, opts.MinPoolSize)
+		assert.Nil(t, opts.ConnectTimeout)
+		assert.Nil(t, opts.RetryWrites)
+		assert.Nil(t, opts.RetryReads)
+		assert.Nil(t, opts.ReplicaSet)
+		assert.Nil(t, opts.AppName)
+		assert.Nil(t, opts.LoadBalanced)
+		assert.Nil(t, opts.SRVMaxHosts)
+		assert.Nil(t, opts.SRVServiceName)
+	})
+
+	t.Run("literal config keeps the driver retry defaults", func(t *testing.T) {
+		cfg := Config{URI: "mongodb://localhost:27017/?retryReads=false", Database: "app"}
+		opts := cfg.clientOptions()
+
+		assert.Nil(t, opts.RetryWrites, "unset, so the driver retries writes")
+		require.NotNil(t, opts.RetryReads)
+		assert.False(t, *opts.RetryReads, "the URI option is not overridden")
+	})
+
+	t.Run("applies load-balanced and SRV settings", func(t *testing.T) {
+		cfg := withOptions(DefaultConfig(),
+			WithLoadBalanced(true),
+			WithSRVMaxHosts(3),
+			WithSRVServiceName("customname"),
+		)
+
+		opts := cfg.clientOptions()
+
+		assert.True(t, *opts.LoadBalanced)
+		assert.Equal(t, 3, *opts.SRVMaxHosts)
+		assert.Equal(t, "customname", *opts.SRVServiceName)
+	})
+
+	t.Run("config fields take precedence over ClientOptions", func(t *testing.T) {
+		cfg := withOptions(DefaultConfig(),
+			WithClientOptions(options.Client().SetMinPoolSize(1).SetCompressors([]string{"zstd"})),
+			WithMinPoolSize(10),
+		)
+
+		opts := cfg.clientOptions()
+
+		assert.Equal(t, uint64(10), *opts.MinPoolSize)
+		assert.Equal(t, []string{"zstd"}, opts.Compressors)
+	})
+
+	t.Run("does not modify ClientOptions", func(t *testing.T) {
+		base := options.Client().SetMinPoolSize(1)
+		cfg := withOptions(DefaultConfig(), WithClientOptions(base), WithMinPoolSize(10))
+
+		_ = cfg.clientOptions()
+
+		assert.Equal(t, uint64(1), *base.MinPoolSize)
+		assert.Nil(t, base.MaxPoolSize)
+	})
+}
@@ -0,0 +1,128 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Current Operations
+//
+// This file provides typed wrappers of $currentOp and killOp, so operational tooling
+// can find and terminate runaway queries.
+//
+// See docs/operations.md for detailed usage guide and examples.
+
+// CurrentOp is an operation in progress on the server, as reported by $currentOp.
+// Fields not reported for an operation are zero; Raw holds the complete document.
+type CurrentOp struct {
+	OpID             any      `bson:"opid"`              // Operation ID to pass to KillOp: an int32 on mongod, a "shard:opid" string on mongos
+	Type             string   `bson:"type"`              // "op" for client operations, "idleSession", "idleCursor", ...
+	Host             string   `bson:"host"`              // Server running the operation
+	Desc             string   `bson:"desc"`              // Description of the connection or thread, e.g. "conn42"
+	Active           bool     `bson:"active"`            // Whether the operation has started
+	Op               string   `bson:"op"`                // Operation type, e.g. "query", "update", "command"
+	Namespace        string   `bson:"ns"`                // Namespace the operation runs on, "database.collection"
+	Command          bson.Raw `bson:"command"`           // Command document as sent by the client
+	PlanSummary      string   `bson:"planSummary"`       // Query plan, e.g. "COLLSCAN"
+	SecsRunning      int64    `bson:"secs_running"`      // Running time in seconds
+	MicrosecsRunning int64    `bson:"microsecs_running"` // Running time in microseconds
+	Client           string   `bson:"client"`            // Address of the client that started the operation
+	AppName          string   `bson:"appName"`           // Application name of the client
+	WaitingForLock   bool     `bson:"waitingForLock"`    // Whether the operation is waiting for a lock
+	KillPending      bool     `bson:"killPending"`       // Whether the operation was killed and is terminating
+	Raw              bson.Raw `bson:"-"`                 // Complete $currentOp document
+}
+
+// Running returns the time the operation has been running.
+func (o CurrentOp) Running() time.Duration {
+	return time.Duration(o.MicrosecsRunning) * time.Microsecond
+}
+
+// Comment returns the comment of the command, such as the request ID sent for
+// contexts created WithRequestID, or "" if it has none.
+func (o CurrentOp) Comment() string {
+	return commandComment(o.Command)
+}
+
+// CurrentOps returns the operations in progress on the server, of all users, that
+// match filter, applied as a $match stage to the $currentOp documents. Use nil for
+// all operations. Idle connections are not reported.
+//
+// Example:
+//
+//	// Queries on orders running for more than 30 seconds
+//	ops, err := client.CurrentOps(ctx, bson.M{
+//	    "ns":           "shop.orders",
+//	    "secs_running": bson.M{"$gte": 30},
+//	})
+func (c *Client) CurrentOps(ctx context.Context, filter any) ([]CurrentOp, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	pipeline := mongo.Pipeline{{{Key: "$currentOp", Value: bson.D{{Key: "allUsers", Value: true}}}}}
+	if filter != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: filter}})
+	}
+
+	cursor, err := c.client.Database("admin").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, c.operationError(ctx, start, "current ops", err)
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	var ops []CurrentOp
+	for cursor.Next(ctx) {
+		var op CurrentOp
+		if err := cursor.Decode(&op); err != nil {
+			return nil, c.operationError(ctx, start, "current ops decode", err)
+		}
+		op.Raw = append(bson.Raw(nil), cursor.Current...)
+		ops = append(ops, op)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, c.operationError(ctx, start, "current ops", err)
+	}
+
+	return ops, nil
+}
+
+// KillOp terminates the operation with the given ID, as reported by CurrentOps.
+// The operation stops at its next interruption point, so it may still be reported
+// with KillPending for a short time.
+//
+// Example:
+//
+//	for _, op := range ops {
+//	    if op.Running() > time.Minute {
+//	        _ = client.KillOp(ctx, op.OpID)
+//	    }
+//	}
+func (c *Client) KillOp(ctx context.Context, opID any) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if opID == nil {
+		return newOperationError("kill op", errors.New("operation ID cannot be nil"))
+	}
+
+	cmd := bson.D{{Key: "killOp", Value: 1}, {Key: "op", Value: opID}}
+	if err := c.client.Database("admin").RunCommand(ctx, cmd).Err(); err != nil {
+		return c.operationError(ctx, start, "kill op", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestClient_CurrentOps_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := NewRepository[bson.M](client, "orders")
+	_, err = repo.Create(ctx, bson.M{"status": "new"})
+	require.NoError(t, err)
+
+	// A query sleeping on the server until it is killed
+	done := make(chan error, 1)
+	go func() {
+		_, err := repo.Find(WithRequestID(ctx, "req-runaway"), bson.M{"$where": "sleep(30000) || true"})
+		done <- err
+	}()
+
+	var runaway CurrentOp
+	require.Eventually(t, func() bool {
+		ops, err := client.CurrentOps(ctx, bson.M{"command.comment": "req-runaway"})
+		if err != nil || len(ops) == 0 {
+			return false
+		}
+		runaway = ops[0]
+		return true
+	}, 10*time.Second, 50*time.Millisecond)
+
+	assert.Equal(t, database+".orders", runaway.Namespace)
+	assert.Equal(t, "req-runaway", runaway.Comment())
+	require.NoError(t, client.KillOp(ctx, runaway.OpID))
+
+	select {
+	case err := <-done:
+		assert.Error(t, err, "the killed query fails")
+	case <-time.After(20 * time.Second):
+		t.Fatal("the query was not killed")
+	}
+}
@@ -0,0 +1,79 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestClient_CurrentOps_ClosedClient(t *testing.T) {
+	client := &Client{closed: true}
+	ctx := context.Background()
+
+	_, err := client.CurrentOps(ctx, nil)
+	assert.ErrorIs(t, err, ErrClientClosed)
+
+	assert.ErrorIs(t, client.KillOp(ctx, int32(1)), ErrClientClosed)
+}
+
+func TestClient_CurrentOps(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	mock.AddResponses(testhelpers.CursorResponse("admin.$cmd.aggregate", bson.D{
+		{Key: "type", Value: "op"},
+		{Key: "opid", Value: int32(4242)},
+		{Key: "active", Value: true},
+		{Key: "op", Value: "query"},
+		{Key: "ns", Value: "testdb.orders"},
+		{Key: "command", Value: bson.D{{Key: "find", Value: "orders"}, {Key: "comment", Value: "req-1"}}},
+		{Key: "secs_running", Value: int64(42)},
+		{Key: "microsecs_running", Value: int64(42_500_000)},
+		{Key: "planSummary", Value: "COLLSCAN"},
+	}))
+
+	ops, err := client.CurrentOps(context.Background(), bson.M{"ns": "testdb.orders"})
+	require.NoError(t, err)
+	require.Len(t, ops, 1)
+
+	op := ops[0]
+	assert.Equal(t, int32(4242), op.OpID)
+	assert.True(t, op.Active)
+	assert.Equal(t, "testdb.orders", op.Namespace)
+	assert.Equal(t, int64(42), op.SecsRunning)
+	assert.Equal(t, 42500*time.Millisecond, op.Running())
+	assert.Equal(t, "req-1", op.Comment())
+	assert.Equal(t, "COLLSCAN", op.Raw.Lookup("planSummary").StringValue())
+}
+
+func TestClient_KillOp(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("kills the operation", func(t *testing.T) {
+		mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "info", Value: "attempting to kill op"}))
+		assert.NoError(t, client.KillOp(ctx, int32(4242)))
+	})
+
+	t.Run("rejects a nil ID", func(t *testing.T) {
+		var opErr *OperationError
+		require.ErrorAs(t, client.KillOp(ctx, nil), &opErr)
+		assert.Equal(t, "kill op", opErr.Op)
+	})
+
+	t.Run("returns server errors", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CommandErrorResponse(13, "Unauthorized", "not authorized"))
+		var opErr *OperationError
+		require.ErrorAs(t, client.KillOp(ctx, int32(4242)), &opErr)
+		assert.Equal(t, "kill op", opErr.Op)
+	})
+}
@@ -0,0 +1,231 @@
+package mongo_kit
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Field Encryption
+//
+// This file provides application-level encryption of struct fields tagged with
+// `encrypt:"aes"`, for deployments without MongoDB client-side field level encryption.
+// Values are encrypted with AES-GCM and stored as base64 strings.
+//
+// Because every encryption uses a random nonce, encrypted fields cannot be used
+// in query filters, indexes or sorts.
+//
+// See docs/repository.md for detailed usage guide and examples.
+
+// encryptTag is the struct tag that marks a field for encryption.
+const encryptTag = "encrypt"
+
+// KeyProvider supplies the AES key used to encrypt and decrypt fields.
+// The key must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+type KeyProvider interface {
+	Key(ctx context.Context) ([]byte, error)
+}
+
+// StaticKeyProvider is a KeyProvider that always returns the same key.
+type StaticKeyProvider []byte
+
+// Key returns the static key.
+func (k StaticKeyProvider) Key(context.Context) ([]byte, error) {
+	return k, nil
+}
+
+// FieldEncryptor encrypts and decrypts the string fields of a struct tagged with
+// `encrypt:"aes"`, including fields of nested structs, struct pointers and slices of structs.
+//
+// Example:
+//
+//	type Patient struct {
+//	    ID   primitive.ObjectID `bson:"_id,omitempty"`
+//	    Name string             `bson:"name"`
+//	    SSN  string             `bson:"ssn" encrypt:"aes"`
+//	}
+//
+//	enc := mongo_kit.NewFieldEncryptor(mongo_kit.StaticKeyProvider(key))
+//	err := enc.Encrypt(ctx, &patient)
+type FieldEncryptor struct {
+	keys KeyProvider
+}
+
+// NewFieldEncryptor creates a FieldEncryptor that uses keys to obtain the AES key.
+func NewFieldEncryptor(keys KeyProvider) *FieldEncryptor {
+	return &FieldEncryptor{keys: keys}
+}
+
+// Encrypt encrypts the tagged fields of the struct doc points to, in place.
+// Nested struct pointers and slices are replaced with encrypted copies, so values
+// shared with the caller are not modified. Empty strings are left unchanged.
+func (e *FieldEncryptor) Encrypt(ctx context.Context, doc any) error {
+	aead, err := e.aead(ctx)
+	if err != nil {
+		return newOperationError("encrypt", err)
+	}
+
+	err = transformFields(doc, func(plaintext string) (string, error) {
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+		return base64.StdEncoding.EncodeToString(sealed), nil
+	})
+	if err != nil {
+		return newOperationError("encrypt", err)
+	}
+	return nil
+}
+
+// Decrypt decrypts the tagged fields of the struct doc points to, in place.
+// Empty strings are left unchanged.
+func (e *FieldEncryptor) Decrypt(ctx context.Context, doc any) error {
+	aead, err := e.aead(ctx)
+	if err != nil {
+		return newOperationError("decrypt", err)
+	}
+
+	err = transformFields(doc, func(ciphertext string) (string, error) {
+		sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+		if err != nil {
+			return "", err
+		}
+		if len(sealed) < aead.NonceSize() {
+			return "", errors.New("ciphertext too short")
+		}
+		nonce, sealed := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		plaintext, err := aead.Open(nil, nonce, sealed, nil)
+		if err != nil {
+			return "", err
+		}
+		return string(plaintext), nil
+	})
+	if err != nil {
+		return newOperationError("decrypt", err)
+	}
+	return nil
+}
+
+// aead builds the AES-GCM cipher for the key returned by the key provider.
+func (e *FieldEncryptor) aead(ctx context.Context) (cipher.AEAD, error) {
+	key, err := e.keys.Key(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("key provider: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// transformFields applies fn to every non-empty tagged string field of the struct doc
+// points to. When doc points to a struct pointer, the pointer is replaced with one to
+// a transformed copy. doc that does not point to a struct (e.g. bson.M) is left
+// unchanged.
+func transformFields(doc any, fn func(string) (string, error)) error {
+	v := reflect.ValueOf(doc)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return errors.New("document must be a non-nil pointer")
+	}
+
+	v = v.Elem()
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		// doc points to a struct pointer, e.g. for a Repository[*T]: transform a copy,
+		// so the struct of the caller is not modified.
+		clone := reflect.New(v.Elem().Type())
+		clone.Elem().Set(v.Elem())
+		v.Set(clone)
+		v = clone.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	return transformStruct(v, fn)
+}
+
+// transformStruct applies fn to the tagged fields of the addressable struct v,
+// descending into nested structs, struct pointers and slices of structs.
+func transformStruct(v reflect.Value, fn func(string) (string, error)) error {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+
+		sf := t.Field(i)
+		if tag, ok := sf.Tag.Lookup(encryptTag); ok {
+			if tag != "aes" {
+				return fmt.Errorf("field %s: unsupported encryption %q", sf.Name, tag)
+			}
+			if field.Kind() != reflect.String {
+				return fmt.Errorf("field %s: only string fields can be encrypted", sf.Name)
+			}
+			if field.String() == "" {
+				continue
+			}
+
+			value, err := fn(field.String())
+			if err != nil {
+				return fmt.Errorf("field %s: %w", sf.Name, err)
+			}
+			field.SetString(value)
+			continue
+		}
+
+		if err := transformNested(field, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transformNested applies fn to the tagged fields inside a nested struct value,
+// struct pointer or slice of structs. Pointers and slices are replaced with copies.
+func transformNested(field reflect.Value, fn func(string) (string, error)) error {
+	switch field.Kind() {
+	case reflect.Struct:
+		return transformStruct(field, fn)
+
+	case reflect.Pointer:
+		if field.IsNil() || field.Elem().Kind() != reflect.Struct {
+			return nil
+		}
+		clone := reflect.New(field.Elem().Type())
+		clone.Elem().Set(field.Elem())
+		if err := transformStruct(clone.Elem(), fn); err != nil {
+			return err
+		}
+		field.Set(clone)
+
+	case reflect.Slice:
+		if field.IsNil() || field.Type().Elem().Kind() != reflect.Struct {
+			return nil
+		}
+		clone := reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+		reflect.Copy(clone, field)
+		for i := range clone.Len() {
+			if err := transformStruct(clone.Index(i), fn); err != nil {
+				return err
+			}
+		}
+		field.Set(clone)
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestRepository_FieldEncryption(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	type Patient struct {
+		ID   primitive.ObjectID `bson:"_id,omitempty"`
+		Name string             `bson:"name"`
+		SSN  string             `bson:"ssn" encrypt:"aes"`
+	}
+
+	ctx := context.Background()
+	repo := NewRepository[Patient](client, "patients", WithFieldEncryption(testKey()))
+	raw := NewRepository[bson.M](client, "patients")
+
+	id, err := repo.Create(ctx, Patient{Name: "Alice", SSN: "123-45-6789"})
+	require.NoError(t, err)
+	_, err = repo.CreateMany(ctx, []Patient{{Name: "Bob", SSN: "987-65-4321"}})
+	require.NoError(t, err)
+
+	t.Run("stores ciphertext", func(t *testing.T) {
+		doc, err := raw.FindByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", (*doc)["name"])
+		assert.NotEqual(t, "123-45-6789", (*doc)["ssn"])
+	})
+
+	t.Run("FindByID decrypts", func(t *testing.T) {
+		patient, err := repo.FindByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "123-45-6789", patient.SSN)
+	})
+
+	t.Run("FindOne decrypts", func(t *testing.T) {
+		patient, err := repo.FindOne(ctx, bson.M{"name": "Bob"})
+		require.NoError(t, err)
+		assert.Equal(t, "987-65-4321", patient.SSN)
+	})
+
+	t.Run("Find decrypts", func(t *testing.T) {
+		patients, err := repo.FindAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, patients, 2)
+		for _, p := range patients {
+			assert.Contains(t, []string{"123-45-6789", "987-65-4321"}, p.SSN)
+		}
+	})
+}
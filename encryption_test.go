@@ -0,0 +1,232 @@
+package mongo_kit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type encryptedAddress struct {
+	Street string `bson:"street" encrypt:"aes"`
+	City   string `bson:"city"`
+}
+
+type encryptedPatient struct {
+	Name     string             `bson:"name"`
+	SSN      string             `bson:"ssn" encrypt:"aes"`
+	Notes    string             `bson:"notes" encrypt:"aes"`
+	Address  encryptedAddress   `bson:"address"`
+	Billing  *encryptedAddress  `bson:"billing"`
+	Previous []encryptedAddress `bson:"previous"`
+}
+
+type failingKeyProvider struct{ err error }
+
+func (p failingKeyProvider) Key(context.Context) ([]byte, error) {
+	return nil, p.err
+}
+
+func testKey() StaticKeyProvider {
+	return StaticKeyProvider(bytes.Repeat([]byte{7}, 32))
+}
+
+func TestFieldEncryptor_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	enc := NewFieldEncryptor(testKey())
+
+	billing := &encryptedAddress{Street: "1 Billing Rd", City: "Lima"}
+	previous := []encryptedAddress{{Street: "2 Old St", City: "Quito"}}
+	patient := encryptedPatient{
+		Name:     "Alice",
+		SSN:      "123-45-6789",
+		Address:  encryptedAddress{Street: "3 Main St", City: "Bogota"},
+		Billing:  billing,
+		Previous: previous,
+	}
+
+	require.NoError(t, enc.Encrypt(ctx, &patient))
+
+	t.Run("encrypts tagged fields only", func(t *testing.T) {
+		assert.Equal(t, "Alice", patient.Name)
+		assert.NotEqual(t, "123-45-6789", patient.SSN)
+		assert.Empty(t, patient.Notes)
+		assert.NotEqual(t, "3 Main St", patient.Address.Street)
+		assert.Equal(t, "Bogota", patient.Address.City)
+		assert.NotEqual(t, "1 Billing Rd", patient.Billing.Street)
+		assert.NotEqual(t, "2 Old St", patient.Previous[0].Street)
+	})
+
+	t.Run("does not modify shared pointers and slices", func(t *testing.T) {
+		assert.Equal(t, "1 Billing Rd", billing.Street)
+		assert.Equal(t, "2 Old St", previous[0].Street)
+	})
+
+	t.Run("decrypts back to plaintext", func(t *testing.T) {
+		require.NoError(t, enc.Decrypt(ctx, &patient))
+		assert.Equal(t, "123-45-6789", patient.SSN)
+		assert.Equal(t, "3 Main St", patient.Address.Street)
+		assert.Equal(t, "1 Billing Rd", patient.Billing.Street)
+		assert.Equal(t, "2 Old St", patient.Previous[0].Street)
+	})
+}
+
+func TestFieldEncryptor_UsesRandomNonce(t *testing.T) {
+	ctx := context.Background()
+	enc := NewFieldEncryptor(testKey())
+
+	a := encryptedPatient{SSN: "123-45-6789"}
+	b := encryptedPatient{SSN: "123-45-6789"}
+	require.NoError(t, enc.Encrypt(ctx, &a))
+	require.NoError(t, enc.Encrypt(ctx, &b))
+
+	assert.NotEqual(t, a.SSN, b.SSN)
+}
+
+func TestFieldEncryptor_NonStructDocument(t *testing.T) {
+	doc := bson.M{"ssn": "123-45-6789"}
+
+	require.NoError(t, NewFieldEncryptor(testKey()).Encrypt(context.Background(), &doc))
+	assert.Equal(t, "123-45-6789", doc["ssn"])
+}
+
+func TestFieldEncryptor_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("key provider error", func(t *testing.T) {
+		providerErr := errors.New("kms unavailable")
+		enc := NewFieldEncryptor(failingKeyProvider{err: providerErr})
+
+		err := enc.Encrypt(ctx, &encryptedPatient{SSN: "x"})
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "encrypt", opErr.Op)
+		assert.ErrorIs(t, err, providerErr)
+	})
+
+	t.Run("invalid key length", func(t *testing.T) {
+		enc := NewFieldEncryptor(StaticKeyProvider([]byte("short")))
+		assert.Error(t, enc.Encrypt(ctx, &encryptedPatient{SSN: "x"}))
+	})
+
+	t.Run("non-pointer document", func(t *testing.T) {
+		enc := NewFieldEncryptor(testKey())
+		assert.Error(t, enc.Encrypt(ctx, encryptedPatient{SSN: "x"}))
+	})
+
+	t.Run("unsupported algorithm", func(t *testing.T) {
+		doc := struct {
+			SSN string `encrypt:"rsa"`
+		}{SSN: "x"}
+		err := NewFieldEncryptor(testKey()).Encrypt(ctx, &doc)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unsupported encryption "rsa"`)
+	})
+
+	t.Run("non-string field", func(t *testing.T) {
+		doc := struct {
+			Age int `encrypt:"aes"`
+		}{Age: 30}
+		err := NewFieldEncryptor(testKey()).Encrypt(ctx, &doc)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only string fields can be encrypted")
+	})
+
+	t.Run("decrypt with wrong key", func(t *testing.T) {
+		doc := encryptedPatient{SSN: "123-45-6789"}
+		require.NoError(t, NewFieldEncryptor(testKey()).Encrypt(ctx, &doc))
+
+		wrongKey := StaticKeyProvider(bytes.Repeat([]byte{9}, 32))
+		err := NewFieldEncryptor(wrongKey).Decrypt(ctx, &doc)
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "decrypt", opErr.Op)
+	})
+
+	t.Run("decrypt plaintext value", func(t *testing.T) {
+		doc := encryptedPatient{SSN: "not-encrypted"}
+		assert.Error(t, NewFieldEncryptor(testKey()).Decrypt(ctx, &doc))
+	})
+}
+
+func TestWithFieldEncryption(t *testing.T) {
+	repo := NewRepository[encryptedPatient](&Client{}, "patients", WithFieldEncryption(testKey()))
+	require.NotNil(t, repo.opts.encryptor)
+
+	plain := NewRepository[encryptedPatient](&Client{}, "patients")
+	assert.Nil(t, plain.opts.encryptor)
+}
+
+func TestWithFieldEncryption_PointerType(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	repo := NewRepository[*encryptedPatient](client, "patients", WithFieldEncryption(testKey()))
+	ctx := context.Background()
+
+	t.Run("encrypts a copy", func(t *testing.T) {
+		patient := &encryptedPatient{Name: "Ada", SSN: "123"}
+		doc := patient
+		require.NoError(t, repo.encrypt(ctx, &doc))
+		assert.NotSame(t, patient, doc)
+		assert.NotEqual(t, "123", doc.SSN)
+		assert.Equal(t, "123", patient.SSN, "the caller's struct is not modified")
+
+		plain := doc
+		require.NoError(t, repo.decrypt(ctx, &plain))
+		assert.Equal(t, "123", plain.SSN)
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		patient := &encryptedPatient{Name: "Ada", SSN: "123"}
+		mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}))
+		_, err := repo.Create(ctx, patient)
+		require.NoError(t, err)
+		assert.Equal(t, "123", patient.SSN)
+	})
+
+	t.Run("AggregateIter decrypts", func(t *testing.T) {
+		stored := encryptedPatient{Name: "Ada", SSN: "123"}
+		require.NoError(t, NewFieldEncryptor(testKey()).Encrypt(ctx, &stored))
+		mock.AddResponses(testhelpers.CursorResponse("testdb.patients", stored, encryptedPatient{Name: "Bob", SSN: "plaintext"}))
+
+		it, err := repo.AggregateIter(ctx, mongo.Pipeline{})
+		require.NoError(t, err)
+		defer func() { _ = it.Close(ctx) }()
+
+		require.True(t, it.Next(ctx))
+		assert.Equal(t, "123", it.Current().SSN)
+		assert.False(t, it.Next(ctx), "values that cannot be decrypted stop the iteration")
+		assert.Error(t, it.Err())
+	})
+
+	t.Run("Aggregate decrypts", func(t *testing.T) {
+		stored := encryptedPatient{Name: "Ada", SSN: "123"}
+		require.NoError(t, NewFieldEncryptor(testKey()).Encrypt(ctx, &stored))
+		mock.AddResponses(testhelpers.CursorResponse("testdb.patients", stored))
+
+		patients, err := repo.AggregateWithBuilder(ctx, NewAggregationBuilder())
+		require.NoError(t, err)
+		require.Len(t, patients, 1)
+		assert.Equal(t, "123", patients[0].SSN)
+	})
+
+	t.Run("Find decrypts", func(t *testing.T) {
+		stored := encryptedPatient{Name: "Ada", SSN: "123"}
+		require.NoError(t, NewFieldEncryptor(testKey()).Encrypt(ctx, &stored))
+		mock.AddResponses(testhelpers.CursorResponse("testdb.patients", stored))
+
+		patients, err := repo.Find(ctx, bson.M{})
+		require.NoError(t, err)
+		require.Len(t, patients, 1)
+		assert.Equal(t, "123", patients[0].SSN)
+		assert.Equal(t, "Ada", patients[0].Name)
+	})
+}
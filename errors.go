@@ -1,8 +1,16 @@
 package mongo_kit
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // Public Error Types
@@ -39,22 +47,107 @@ func (e *ConnectionError) Unwrap() error {
 
 // OperationError represents an error that occurred during a database operation.
 // The Op field identifies which operation failed, and Cause contains the underlying error.
+// Collection operations also identify the Database and Collection, and carry redacted
+// Filter and Update summaries when Config.ErrorQuerySummary is enabled.
 type OperationError struct {
-	Op    string // The name of the operation that failed (e.g., "find", "insert", "update")
-	Cause error  // The underlying error from MongoDB driver
+	Op         string // The name of the operation that failed (e.g., "find", "insert", "update")
+	Cause      error  // The underlying error from MongoDB driver
+	Database   string // The database the operation targeted (optional)
+	Collection string // The collection the operation targeted (optional)
+	Filter     string // Redacted filter summary with values replaced by "?" (optional)
+	Update     string // Redacted update summary with values replaced by "?" (optional)
 }
 
 func (e *OperationError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "mongo: operation '%s'", e.Op)
+
+	if e.Collection != "" {
+		namespace := e.Collection
+		if e.Database != "" {
+			namespace = e.Database + "." + e.Collection
+		}
+		fmt.Fprintf(&sb, " on '%s'", namespace)
+	}
+
+	var details []string
+	if e.Filter != "" {
+		details = append(details, "filter "+e.Filter)
+	}
+	if e.Update != "" {
+		details = append(details, "update "+e.Update)
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(&sb, " (%s)", strings.Join(details, ", "))
+	}
+
+	sb.WriteString(" failed")
 	if e.Cause != nil {
-		return fmt.Sprintf("mongo: operation '%s' failed: %v", e.Op, e.Cause)
+		fmt.Fprintf(&sb, ": %v", e.Cause)
 	}
-	return fmt.Sprintf("mongo: operation '%s' failed", e.Op)
+	return sb.String()
 }
 
 func (e *OperationError) Unwrap() error {
 	return e.Cause
 }
 
+// DuplicateKeyError represents a unique index violation (E11000).
+// It is available through errors.As on the error returned by any write operation,
+// so handlers can report the offending field without parsing server messages.
+type DuplicateKeyError struct {
+	Collection string // The collection the write targeted, e.g. "users" (empty if unknown)
+	Index      string // The name of the violated unique index, e.g. "email_1" (empty if unknown)
+	KeyValue   bson.M // The duplicated key fields and values, e.g. {"email": "a@b.com"} (nil if unknown)
+	Cause      error  // The underlying error from the MongoDB driver
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("mongo: duplicate key in collection '%s' index '%s': %v", e.Collection, e.Index, e.KeyValue)
+}
+
+func (e *DuplicateKeyError) Unwrap() error {
+	return e.Cause
+}
+
+// ValidationError represents a write rejected by the collection's document validator
+// (e.g. a $jsonSchema rule). It is available through errors.As on the error returned by
+// any write operation and carries the explanation the server reports.
+type ValidationError struct {
+	DocumentID any    // The _id of the document that failed validation (nil if unknown)
+	Details    bson.M // Server explanation, e.g. {"operatorName": "$jsonSchema", "schemaRulesNotSatisfied": [...]} (nil if unavailable)
+	Cause      error  // The underlying error from the MongoDB driver
+}
+
+func (e *ValidationError) Error() string {
+	if e.Details != nil {
+		return fmt.Sprintf("mongo: document failed validation: %v", e.Details)
+	}
+	return "mongo: document failed validation"
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Cause
+}
+
+// TimeoutError represents an operation that exceeded its context deadline or the
+// server-side time limit (maxTimeMS or the client Timeout). It is available through
+// errors.As on the error returned by any operation, so timeouts can be mapped to
+// 504 responses and alerts separately from other failures.
+type TimeoutError struct {
+	Op      string        // The operation that timed out (e.g., "find", "update one")
+	Elapsed time.Duration // How long the operation ran before it timed out
+	Cause   error         // The underlying error from the MongoDB driver or context
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("mongo: timed out after %s: %v", e.Elapsed.Round(time.Millisecond), e.Cause)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Cause
+}
+
 // Sentinel Errors
 // These are sentinel errors that can be checked using errors.Is().
 
@@ -62,8 +155,112 @@ var (
 	// ErrClientClosed is returned when an operation is attempted on a closed client.
 	// Use errors.Is(err, mongo.ErrClientClosed) to check for this error.
 	ErrClientClosed = errors.New("mongo: client is closed")
+
+	// ErrNotFound is returned when a single-document lookup matches no document.
+	// It is the same value as mongo.ErrNoDocuments, so errors.Is works with either.
+	// Use errors.Is(err, mongo_kit.ErrNotFound) to check for this error.
+	ErrNotFound = mongo.ErrNoDocuments
+
+	// ErrLockHeld is returned by Locker.Acquire when another holder owns the lock.
+	ErrLockHeld = errors.New("mongo: lock is held")
+
+	// ErrLockLost is returned by Locker.Renew and Locker.Release when the lock expired
+	// or was taken by another holder.
+	ErrLockLost = errors.New("mongo: lock is no longer held")
+
+	// ErrFullCollectionWrite is returned by clients created WithSafeWrites for updates
+	// and deletes of many documents with an empty filter, see AllowFullCollection.
+	ErrFullCollectionWrite = errors.New("mongo: empty filter would modify every document, use AllowFullCollection")
 )
 
+// Error Classification
+// These helpers classify errors so callers can implement retry policies.
+
+// transientErrorCodes are server error codes for conditions that resolve on their own,
+// such as elections, shutdowns and network failures between cluster members.
+var transientErrorCodes = map[int32]bool{
+	6:     true, // HostUnreachable
+	7:     true, // HostNotFound
+	89:    true, // NetworkTimeout
+	91:    true, // ShutdownInProgress
+	189:   true, // PrimarySteppedDown
+	262:   true, // ExceededTimeLimit
+	9001:  true, // SocketException
+	10107: true, // NotWritablePrimary
+	11600: true, // InterruptedAtShutdown
+	11602: true, // InterruptedDueToReplStateChange
+	13435: true, // NotPrimaryNoSecondaryOk
+	13436: true, // NotPrimaryOrSecondary
+}
+
+// IsTransient reports whether err is caused by a temporary condition that is
+// expected to resolve on its own: network errors, connection pool clears, replica
+// set elections and shutdowns, and errors labeled TransientTransactionError.
+// Context cancellation and deadline errors are not transient.
+//
+// Example:
+//
+//	if mongo_kit.IsTransient(err) {
+//	    metrics.Inc("mongo_transient_errors")
+//	}
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if mongo.IsNetworkError(err) || hasErrorLabel(err, "TransientTransactionError") {
+		return true
+	}
+
+	var poolErr interface{ Retryable() bool }
+	if errors.As(err, &poolErr) && poolErr.Retryable() {
+		return true
+	}
+
+	var ce mongo.CommandError
+	if errors.As(err, &ce) && transientErrorCodes[ce.Code] {
+		return true
+	}
+
+	var we mongo.WriteException
+	if errors.As(err, &we) && we.WriteConcernError != nil && transientErrorCodes[int32(we.WriteConcernError.Code)] {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// IsRetryable reports whether the operation that returned err can safely be retried:
+// the error is transient or the server labeled it RetryableWriteError or
+// UnknownTransactionCommitResult. Context cancellation and deadline errors, duplicate
+// keys and validation failures are not retryable.
+//
+// Example:
+//
+//	for attempt := 0; attempt < 3; attempt++ {
+//	    _, err = repo.Create(ctx, doc)
+//	    if !mongo_kit.IsRetryable(err) {
+//	        break
+//	    }
+//	    time.Sleep(backoff(attempt))
+//	}
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	return IsTransient(err) ||
+		hasErrorLabel(err, "RetryableWriteError") ||
+		hasErrorLabel(err, "UnknownTransactionCommitResult")
+}
+
+// hasErrorLabel reports whether any server error in the chain of err carries label.
+func hasErrorLabel(err error, label string) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorLabel(label)
+}
+
 // Internal constructor functions
 
 // newConfigFieldError creates a configuration error with a specific field.
@@ -78,5 +275,210 @@ func newConnectionError(cause error) error {
 
 // newOperationError creates an operation error for a specific operation and cause.
 func newOperationError(operation string, cause error) error {
-	return &OperationError{Op: operation, Cause: cause}
+	return &OperationError{Op: operation, Cause: classifyCause(cause)}
+}
+
+// classifyCause wraps driver errors that have a typed representation.
+// Duplicate key errors are wrapped in a DuplicateKeyError and document validation
+// failures in a ValidationError. A BulkError already classifies its individual writes.
+func classifyCause(cause error) error {
+	var bulkErr *BulkError
+	if errors.As(cause, &bulkErr) {
+		return cause
+	}
+	if dupErr := newDuplicateKeyError(cause); dupErr != nil {
+		return dupErr
+	}
+	if validationErr := newValidationError(cause); validationErr != nil {
+		return validationErr
+	}
+	return cause
+}
+
+// documentValidationFailure is the server error code for writes rejected by a validator.
+const documentValidationFailure = 121
+
+// newValidationError returns a ValidationError describing cause,
+// or nil if cause is not a document validation failure.
+func newValidationError(cause error) *ValidationError {
+	if cause == nil {
+		return nil
+	}
+
+	var existing *ValidationError
+	if errors.As(cause, &existing) {
+		return nil
+	}
+
+	errInfo, found := validationErrInfo(cause)
+	if !found {
+		return nil
+	}
+
+	validationErr := &ValidationError{Cause: cause}
+	if id, err := errInfo.LookupErr("failingDocumentId"); err == nil {
+		var documentID any
+		if err := id.Unmarshal(&documentID); err == nil {
+			validationErr.DocumentID = documentID
+		}
+	}
+	if details, ok := errInfo.Lookup("details").DocumentOK(); ok {
+		var value bson.M
+		if err := bson.Unmarshal(details, &value); err == nil {
+			validationErr.Details = value
+		}
+	}
+
+	return validationErr
+}
+
+// validationErrInfo returns the errInfo document of the first document validation
+// failure contained in err, and whether such a failure was found.
+func validationErrInfo(err error) (bson.Raw, bool) {
+	var we mongo.WriteException
+	if errors.As(err, &we) {
+		for _, writeErr := range we.WriteErrors {
+			if writeErr.Code == documentValidationFailure {
+				return writeErr.Details, true
+			}
+		}
+	}
+
+	var bwe mongo.BulkWriteException
+	if errors.As(err, &bwe) {
+		for _, writeErr := range bwe.WriteErrors {
+			if writeErr.Code == documentValidationFailure {
+				return writeErr.Details, true
+			}
+		}
+	}
+
+	var ce mongo.CommandError
+	if errors.As(err, &ce) && ce.Code == documentValidationFailure {
+		errInfo, _ := ce.Raw.Lookup("errInfo").DocumentOK()
+		return errInfo, true
+	}
+
+	return nil, false
+}
+
+// redactQuery summarizes a filter or update document with every value replaced by "?",
+// keeping field names and operators, e.g. {"age": {"$gt": ?}}.
+// Returns "" for nil and "<unavailable>" if the query cannot be marshaled.
+func redactQuery(query any) string {
+	if query == nil {
+		return ""
+	}
+
+	t, data, err := bson.MarshalValue(query)
+	if err != nil {
+		return "<unavailable>"
+	}
+	return redactValue(bson.RawValue{Type: t, Value: data})
+}
+
+// redactValue renders documents and arrays structurally and every other value as "?".
+func redactValue(v bson.RawValue) string {
+	switch v.Type {
+	case bson.TypeEmbeddedDocument:
+		elems, err := v.Document().Elements()
+		if err != nil {
+			return "?"
+		}
+		parts := make([]string, len(elems))
+		for i, elem := range elems {
+			parts[i] = fmt.Sprintf("%q: %s", elem.Key(), redactValue(elem.Value()))
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+
+	case bson.TypeArray:
+		values, err := v.Array().Values()
+		if err != nil {
+			return "?"
+		}
+		parts := make([]string, len(values))
+		for i, value := range values {
+			parts[i] = redactValue(value)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+
+	default:
+		return "?"
+	}
+}
+
+// duplicateKeyMessage matches the collection and index in an E11000 error message, e.g.
+// "E11000 duplicate key error collection: testdb.users index: email_1 dup key: { ... }".
+var duplicateKeyMessage = regexp.MustCompile(`collection: (\S+) index: (\S+)`)
+
+// newDuplicateKeyError returns a DuplicateKeyError describing cause,
+// or nil if cause is not a duplicate key error.
+func newDuplicateKeyError(cause error) *DuplicateKeyError {
+	if cause == nil || !mongo.IsDuplicateKeyError(cause) {
+		return nil
+	}
+
+	var existing *DuplicateKeyError
+	if errors.As(cause, &existing) {
+		return nil
+	}
+
+	message, raw := duplicateKeyDetails(cause)
+	dupErr := &DuplicateKeyError{Cause: cause}
+
+	if m := duplicateKeyMessage.FindStringSubmatch(message); m != nil {
+		dupErr.Collection = m[1]
+		if _, coll, ok := strings.Cut(m[1], "."); ok {
+			dupErr.Collection = coll
+		}
+		dupErr.Index = m[2]
+	}
+
+	if keyValue, ok := raw.Lookup("keyValue").DocumentOK(); ok {
+		var value bson.M
+		if err := bson.Unmarshal(keyValue, &value); err == nil {
+			dupErr.KeyValue = value
+		}
+	}
+
+	return dupErr
+}
+
+// duplicateKeyDetails returns the message and raw server document of the first
+// duplicate key error contained in err.
+func duplicateKeyDetails(err error) (string, bson.Raw) {
+	var we mongo.WriteException
+	if errors.As(err, &we) {
+		for _, writeErr := range we.WriteErrors {
+			if isDuplicateKeyCode(writeErr.Code) {
+				return writeErr.Message, writeErr.Raw
+			}
+		}
+	}
+
+	var bwe mongo.BulkWriteException
+	if errors.As(err, &bwe) {
+		for _, writeErr := range bwe.WriteErrors {
+			if isDuplicateKeyCode(writeErr.Code) {
+				return writeErr.Message, writeErr.Raw
+			}
+		}
+	}
+
+	var ce mongo.CommandError
+	if errors.As(err, &ce) && isDuplicateKeyCode(int(ce.Code)) {
+		return ce.Message, ce.Raw
+	}
+
+	return err.Error(), nil
+}
+
+// isDuplicateKeyCode reports whether code is a duplicate key error code.
+func isDuplicateKeyCode(code int) bool {
+	return code == 11000 || code == 11001 || code == 12582
+}
+
+// isTimeout reports whether err is a context deadline, client Timeout or maxTimeMS expiration.
+func isTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || mongo.IsTimeout(err)
 }
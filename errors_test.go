@@ -1,12 +1,16 @@
 package mongo_kit
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func TestConfigError(t *testing.T) {
@@ -88,6 +92,73 @@ func TestOperationError(t *testing.T) {
 	}
 }
 
+func TestOperationError_Context(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *OperationError
+		expected string
+	}{
+		{
+			name:     "with namespace",
+			err:      &OperationError{Op: "find", Database: "testdb", Collection: "users", Cause: errors.New("timeout")},
+			expected: "mongo: operation 'find' on 'testdb.users' failed: timeout",
+		},
+		{
+			name:     "with collection only",
+			err:      &OperationError{Op: "find", Collection: "users", Cause: errors.New("timeout")},
+			expected: "mongo: operation 'find' on 'users' failed: timeout",
+		},
+		{
+			name: "with filter and update summaries",
+			err: &OperationError{
+				Op: "update one", Database: "testdb", Collection: "users",
+				Filter: `{"_id": ?}`, Update: `{"$set": {"name": ?}}`,
+				Cause: errors.New("write conflict"),
+			},
+			expected: `mongo: operation 'update one' on 'testdb.users' (filter {"_id": ?}, update {"$set": {"name": ?}}) failed: write conflict`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.err.Error())
+		})
+	}
+}
+
+func TestRedactQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    any
+		expected string
+	}{
+		{name: "nil", query: nil, expected: ""},
+		{name: "simple", query: bson.M{"email": "a@b.com"}, expected: `{"email": ?}`},
+		{
+			name:     "operators",
+			query:    bson.D{{Key: "age", Value: bson.M{"$gt": 18}}, {Key: "status", Value: bson.M{"$in": bson.A{"a", "b"}}}},
+			expected: `{"age": {"$gt": ?}, "status": {"$in": [?, ?]}}`,
+		},
+		{
+			name:     "update",
+			query:    bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: "Bob"}, {Key: "age", Value: 30}}}},
+			expected: `{"$set": {"name": ?, "age": ?}}`,
+		},
+		{
+			name:     "pipeline",
+			query:    bson.A{bson.M{"$match": bson.M{"ssn": "123"}}},
+			expected: `[{"$match": {"ssn": ?}}]`,
+		},
+		{name: "unmarshalable", query: make(chan int), expected: "<unavailable>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, redactQuery(tt.query))
+		})
+	}
+}
+
 func TestErrClientClosed(t *testing.T) {
 	assert.Equal(t, "mongo: client is closed", ErrClientClosed.Error())
 	assert.True(t, errors.Is(ErrClientClosed, ErrClientClosed))
@@ -149,3 +220,258 @@ func TestErrorsAs(t *testing.T) {
 		})
 	}
 }
+
+func duplicateKeyWriteError(t *testing.T) mongo.WriteError {
+	t.Helper()
+	msg := `E11000 duplicate key error collection: testdb.users index: email_1 dup key: { email: "a@b.com" }`
+	raw, err := bson.Marshal(bson.D{
+		{Key: "index", Value: 0},
+		{Key: "code", Value: 11000},
+		{Key: "errmsg", Value: msg},
+		{Key: "keyPattern", Value: bson.D{{Key: "email", Value: 1}}},
+		{Key: "keyValue", Value: bson.D{{Key: "email", Value: "a@b.com"}}},
+	})
+	require.NoError(t, err)
+	return mongo.WriteError{Code: 11000, Message: msg, Raw: raw}
+}
+
+func TestDuplicateKeyError(t *testing.T) {
+	tests := []struct {
+		name  string
+		cause error
+	}{
+		{
+			name:  "write exception",
+			cause: mongo.WriteException{WriteErrors: mongo.WriteErrors{duplicateKeyWriteError(t)}},
+		},
+		{
+			name: "bulk write exception",
+			cause: mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+				{WriteError: mongo.WriteError{Code: 121, Message: "validation failed"}},
+				{WriteError: duplicateKeyWriteError(t)},
+			}},
+		},
+		{
+			name: "command error",
+			cause: mongo.CommandError{
+				Code:    11000,
+				Message: duplicateKeyWriteError(t).Message,
+				Raw:     duplicateKeyWriteError(t).Raw,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := newOperationError("insert one", tt.cause)
+
+			var dupErr *DuplicateKeyError
+			require.ErrorAs(t, err, &dupErr)
+			assert.Equal(t, "users", dupErr.Collection)
+			assert.Equal(t, "email_1", dupErr.Index)
+			assert.Equal(t, bson.M{"email": "a@b.com"}, dupErr.KeyValue)
+			assert.True(t, mongo.IsDuplicateKeyError(err))
+
+			var opErr *OperationError
+			require.ErrorAs(t, err, &opErr)
+			assert.Equal(t, "insert one", opErr.Op)
+		})
+	}
+
+	t.Run("message without details", func(t *testing.T) {
+		cause := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "E11000 duplicate key error"}}}
+
+		var dupErr *DuplicateKeyError
+		require.ErrorAs(t, newOperationError("insert one", cause), &dupErr)
+		assert.Empty(t, dupErr.Collection)
+		assert.Empty(t, dupErr.Index)
+		assert.Nil(t, dupErr.KeyValue)
+	})
+
+	t.Run("other errors are not wrapped", func(t *testing.T) {
+		err := newOperationError("insert one", errors.New("network error"))
+
+		var dupErr *DuplicateKeyError
+		assert.False(t, errors.As(err, &dupErr))
+	})
+
+	t.Run("formatting and unwrap", func(t *testing.T) {
+		cause := errors.New("E11000")
+		err := &DuplicateKeyError{Collection: "users", Index: "email_1", KeyValue: bson.M{"email": "a@b.com"}, Cause: cause}
+
+		assert.Equal(t, "mongo: duplicate key in collection 'users' index 'email_1': map[email:a@b.com]", err.Error())
+		assert.Equal(t, cause, errors.Unwrap(err))
+	})
+}
+
+func TestTimeoutError(t *testing.T) {
+	err := &TimeoutError{Op: "find", Elapsed: 1500 * time.Millisecond, Cause: context.DeadlineExceeded}
+
+	assert.Equal(t, "mongo: timed out after 1.5s: context deadline exceeded", err.Error())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, IsRetryable(err))
+
+	assert.True(t, isTimeout(context.DeadlineExceeded))
+	assert.True(t, isTimeout(mongo.CommandError{Code: 50, Name: "MaxTimeMSExpired"}))
+	assert.False(t, isTimeout(context.Canceled))
+	assert.False(t, isTimeout(errors.New("boom")))
+}
+
+func TestErrNotFound(t *testing.T) {
+	assert.ErrorIs(t, ErrNotFound, mongo.ErrNoDocuments)
+	assert.ErrorIs(t, fmt.Errorf("lookup: %w", mongo.ErrNoDocuments), ErrNotFound)
+}
+
+// retryablePoolError mimics the driver's pool cleared error.
+type retryablePoolError struct{}
+
+func (retryablePoolError) Error() string   { return "connection pool was cleared" }
+func (retryablePoolError) Retryable() bool { return true }
+
+type timeoutNetError struct{}
+
+func (timeoutNetError) Error() string   { return "i/o timeout" }
+func (timeoutNetError) Timeout() bool   { return true }
+func (timeoutNetError) Temporary() bool { return true }
+
+func TestIsTransientAndIsRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+		retryable bool
+	}{
+		{name: "nil", err: nil},
+		{name: "plain error", err: errors.New("boom")},
+		{name: "context canceled", err: newOperationError("find", context.Canceled)},
+		{name: "context deadline", err: newOperationError("find", context.DeadlineExceeded)},
+		{
+			name:      "network error label",
+			err:       newOperationError("find", mongo.CommandError{Labels: []string{"NetworkError"}}),
+			transient: true,
+			retryable: true,
+		},
+		{
+			name:      "transient transaction label",
+			err:       mongo.CommandError{Code: 251, Labels: []string{"TransientTransactionError"}},
+			transient: true,
+			retryable: true,
+		},
+		{
+			name:      "retryable write label",
+			err:       mongo.WriteException{Labels: []string{"RetryableWriteError"}},
+			retryable: true,
+		},
+		{
+			name:      "unknown commit result label",
+			err:       mongo.CommandError{Labels: []string{"UnknownTransactionCommitResult"}},
+			retryable: true,
+		},
+		{
+			name:      "primary stepped down",
+			err:       newOperationError("update one", mongo.CommandError{Code: 189, Name: "PrimarySteppedDown"}),
+			transient: true,
+			retryable: true,
+		},
+		{
+			name: "write concern error not writable primary",
+			err: mongo.WriteException{
+				WriteConcernError: &mongo.WriteConcernError{Code: 10107, Message: "not primary"},
+			},
+			transient: true,
+			retryable: true,
+		},
+		{
+			name:      "pool cleared",
+			err:       fmt.Errorf("checkout: %w", retryablePoolError{}),
+			transient: true,
+			retryable: true,
+		},
+		{
+			name:      "net error",
+			err:       newOperationError("find", timeoutNetError{}),
+			transient: true,
+			retryable: true,
+		},
+		{
+			name: "duplicate key",
+			err:  newOperationError("insert one", mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "E11000"}}}),
+		},
+		{
+			name: "unauthorized",
+			err:  mongo.CommandError{Code: 13, Name: "Unauthorized"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.transient, IsTransient(tt.err), "IsTransient")
+			assert.Equal(t, tt.retryable, IsRetryable(tt.err), "IsRetryable")
+		})
+	}
+}
+
+func validationWriteError(t *testing.T, id any) mongo.WriteError {
+	t.Helper()
+	details, err := bson.Marshal(bson.D{
+		{Key: "failingDocumentId", Value: id},
+		{Key: "details", Value: bson.D{
+			{Key: "operatorName", Value: "$jsonSchema"},
+			{Key: "schemaRulesNotSatisfied", Value: bson.A{bson.D{{Key: "operatorName", Value: "required"}}}},
+		}},
+	})
+	require.NoError(t, err)
+	return mongo.WriteError{Code: 121, Message: "Document failed validation", Details: details}
+}
+
+func TestValidationError(t *testing.T) {
+	t.Run("write exception", func(t *testing.T) {
+		cause := mongo.WriteException{WriteErrors: mongo.WriteErrors{validationWriteError(t, "doc-1")}}
+		err := newOperationError("insert one", cause)
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "doc-1", validationErr.DocumentID)
+		assert.Equal(t, "$jsonSchema", validationErr.Details["operatorName"])
+		assert.Contains(t, validationErr.Details, "schemaRulesNotSatisfied")
+		assert.Equal(t, cause, validationErr.Cause)
+		assert.False(t, IsRetryable(err))
+	})
+
+	t.Run("bulk write exception", func(t *testing.T) {
+		cause := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{{WriteError: validationWriteError(t, 42)}}}
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, newOperationError("bulk write", cause), &validationErr)
+		assert.EqualValues(t, 42, validationErr.DocumentID)
+	})
+
+	t.Run("command error", func(t *testing.T) {
+		raw, err := bson.Marshal(bson.D{
+			{Key: "ok", Value: 0},
+			{Key: "code", Value: 121},
+			{Key: "errInfo", Value: bson.D{{Key: "details", Value: bson.D{{Key: "operatorName", Value: "$gte"}}}}},
+		})
+		require.NoError(t, err)
+		cause := mongo.CommandError{Code: 121, Message: "Document failed validation", Raw: raw}
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, newOperationError("find one and update", cause), &validationErr)
+		assert.Nil(t, validationErr.DocumentID)
+		assert.Equal(t, bson.M{"operatorName": "$gte"}, validationErr.Details)
+	})
+
+	t.Run("without details", func(t *testing.T) {
+		cause := mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 121, Message: "Document failed validation"}}}
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, newOperationError("insert one", cause), &validationErr)
+		assert.Nil(t, validationErr.Details)
+		assert.Equal(t, "mongo: document failed validation", validationErr.Error())
+	})
+
+	t.Run("other errors are not wrapped", func(t *testing.T) {
+		var validationErr *ValidationError
+		assert.False(t, errors.As(newOperationError("insert one", errors.New("boom")), &validationErr))
+	})
+}
@@ -0,0 +1,284 @@
+// Package eventstore is an append-only event store for event-sourced aggregates, with
+// optimistic concurrency per stream and snapshots to shorten replays.
+//
+// See docs/eventstore.md for detailed usage guide and examples.
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// AnyVersion disables the version check of Append.
+const AnyVersion int64 = -1
+
+// ErrConcurrency is returned by Append when the stream is not at the expected version,
+// because another writer appended to it first. Reload the stream and retry.
+var ErrConcurrency = errors.New("eventstore: stream version conflict")
+
+// Event is a single event of a stream.
+type Event struct {
+	StreamID   string    `bson:"stream_id"`          // Stream the event belongs to (set by Append)
+	Version    int64     `bson:"version"`            // Position in the stream, starting at 1 (set by Append)
+	Type       string    `bson:"type"`               // Event type, e.g. "OrderPlaced"
+	Data       any       `bson:"data"`               // Event payload; a bson.Raw document when loaded
+	Metadata   bson.M    `bson:"metadata,omitempty"` // Optional metadata, e.g. correlation IDs
+	RecordedAt time.Time `bson:"recorded_at"`        // Time of the append (set by Append)
+}
+
+// Decode decodes the payload of the event into out.
+//
+// Example:
+//
+//	var placed OrderPlaced
+//	err := event.Decode(&placed)
+func (e Event) Decode(out any) error {
+	raw, ok := e.Data.(bson.Raw)
+	if !ok {
+		data, err := bson.Marshal(e.Data)
+		if err != nil {
+			return fmt.Errorf("eventstore: encode %s event: %w", e.Type, err)
+		}
+		raw = data
+	}
+	if err := bson.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("eventstore: decode %s event: %w", e.Type, err)
+	}
+	return nil
+}
+
+// storedEvent is the document of an event, with the payload left undecoded.
+type storedEvent struct {
+	StreamID   string    `bson:"stream_id"`
+	Version    int64     `bson:"version"`
+	Type       string    `bson:"type"`
+	Data       bson.Raw  `bson:"data"`
+	Metadata   bson.M    `bson:"metadata,omitempty"`
+	RecordedAt time.Time `bson:"recorded_at"`
+}
+
+// snapshot is the document of the latest snapshot of a stream.
+type snapshot struct {
+	StreamID  string    `bson:"_id"`
+	Version   int64     `bson:"version"`
+	State     bson.Raw  `bson:"state"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// Store appends and loads the events of streams kept in one collection, with a
+// unique index on (stream_id, version).
+type Store struct {
+	client    *mongokit.Client
+	events    *mongokit.Repository[storedEvent]
+	snapshots *mongokit.Repository[snapshot]
+
+	mu      sync.Mutex
+	indexed bool
+}
+
+// New returns a Store keeping events in collection and snapshots in
+// collection + "_snapshots".
+//
+// Example:
+//
+//	store := eventstore.New(client, "order_events")
+func New(client *mongokit.Client, collection string) *Store {
+	return &Store{
+		client:    client,
+		events:    mongokit.NewRepository[storedEvent](client, collection),
+		snapshots: mongokit.NewRepository[snapshot](client, collection+"_snapshots"),
+	}
+}
+
+// Append appends events to the stream streamID, numbering them after expectedVersion,
+// the version the caller last read (0 for a new stream). It returns the new version
+// of the stream, or ErrConcurrency if the stream is at another version or another
+// writer appends concurrently. Pass AnyVersion to append at the end without a check.
+//
+// The unique (stream_id, version) index lets exactly one of two concurrent writers
+// append the same version. Events of one call are inserted in order; wrap the call in
+// a transaction when a failure must not leave some of them appended.
+//
+// Example:
+//
+//	version, err := store.Append(ctx, orderID, loadedVersion,
+//	    eventstore.Event{Type: "ItemAdded", Data: ItemAdded{SKU: "A-1", Qty: 2}},
+//	)
+//	if errors.Is(err, eventstore.ErrConcurrency) {
+//	    // reload the order and retry the command
+//	}
+func (s *Store) Append(ctx context.Context, streamID string, expectedVersion int64, events ...Event) (int64, error) {
+	if streamID == "" {
+		return 0, errors.New("eventstore: stream ID is required")
+	}
+	if expectedVersion < AnyVersion {
+		return 0, fmt.Errorf("eventstore: invalid expected version %d", expectedVersion)
+	}
+	if err := s.ensureIndex(ctx); err != nil {
+		return 0, err
+	}
+
+	current, err := s.Version(ctx, streamID)
+	if err != nil {
+		return 0, err
+	}
+	if expectedVersion != AnyVersion && expectedVersion != current {
+		return 0, fmt.Errorf("%w: %s is at version %d, expected %d", ErrConcurrency, streamID, current, expectedVersion)
+	}
+	if len(events) == 0 {
+		return current, nil
+	}
+
+	now := s.client.Now()
+	docs := make([]storedEvent, len(events))
+	for i, event := range events {
+		if event.Type == "" {
+			return 0, fmt.Errorf("eventstore: event %d has no type", i)
+		}
+		payload := event.Data
+		if payload == nil {
+			payload = bson.D{}
+		}
+		data, err := bson.Marshal(payload)
+		if err != nil {
+			return 0, fmt.Errorf("eventstore: encode %s event: %w", event.Type, err)
+		}
+		docs[i] = storedEvent{
+			StreamID:   streamID,
+			Version:    current + int64(i) + 1,
+			Type:       event.Type,
+			Data:       data,
+			Metadata:   event.Metadata,
+			RecordedAt: now,
+		}
+	}
+
+	if _, err := s.events.CreateMany(ctx, docs); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return 0, fmt.Errorf("%w: %s was appended concurrently", ErrConcurrency, streamID)
+		}
+		return 0, err
+	}
+	return current + int64(len(events)), nil
+}
+
+// Load returns the events of streamID from fromVersion on, in version order.
+// Pass 1, or 0, to load the whole stream.
+//
+// Example:
+//
+//	events, err := store.Load(ctx, orderID, 1)
+//	for _, event := range events {
+//	    order.Apply(event)
+//	}
+func (s *Store) Load(ctx context.Context, streamID string, fromVersion int64) ([]Event, error) {
+	filter := bson.D{
+		{Key: "stream_id", Value: streamID},
+		{Key: "version", Value: bson.D{{Key: "$gte", Value: fromVersion}}},
+	}
+	docs, err := s.events.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "version", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, len(docs))
+	for i, doc := range docs {
+		events[i] = Event{
+			StreamID:   doc.StreamID,
+			Version:    doc.Version,
+			Type:       doc.Type,
+			Data:       doc.Data,
+			Metadata:   doc.Metadata,
+			RecordedAt: doc.RecordedAt,
+		}
+	}
+	return events, nil
+}
+
+// Version returns the version of the last event of streamID, or 0 if the stream has
+// no events.
+func (s *Store) Version(ctx context.Context, streamID string) (int64, error) {
+	last, err := s.events.FindOne(ctx, bson.D{{Key: "stream_id", Value: streamID}},
+		options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}).SetProjection(bson.D{{Key: "version", Value: 1}}))
+	if errors.Is(err, mongokit.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return last.Version, nil
+}
+
+// SaveSnapshot stores state as the state of streamID after the event at version,
+// replacing the previous snapshot of the stream.
+//
+// Example:
+//
+//	if order.Version%100 == 0 {
+//	    err = store.SaveSnapshot(ctx, orderID, order.Version, order)
+//	}
+func (s *Store) SaveSnapshot(ctx context.Context, streamID string, version int64, state any) error {
+	data, err := bson.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("eventstore: encode snapshot: %w", err)
+	}
+
+	_, err = s.snapshots.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: streamID}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "version", Value: version},
+			{Key: "state", Value: bson.Raw(data)},
+			{Key: "created_at", Value: s.client.Now()},
+		}}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// LoadSnapshot decodes the latest snapshot of streamID into out and returns its
+// version, or false if the stream has no snapshot. Replay the events after the
+// returned version to rebuild the current state.
+//
+// Example:
+//
+//	var order Order
+//	version, found, err := store.LoadSnapshot(ctx, orderID, &order)
+//	events, err := store.Load(ctx, orderID, version+1)
+func (s *Store) LoadSnapshot(ctx context.Context, streamID string, out any) (int64, bool, error) {
+	snap, err := s.snapshots.FindOne(ctx, bson.D{{Key: "_id", Value: streamID}})
+	if errors.Is(err, mongokit.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if err := bson.Unmarshal(snap.State, out); err != nil {
+		return 0, false, fmt.Errorf("eventstore: decode snapshot: %w", err)
+	}
+	return snap.Version, true, nil
+}
+
+// ensureIndex creates the unique (stream_id, version) index, once per Store.
+func (s *Store) ensureIndex(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.indexed {
+		return nil
+	}
+	index := mongokit.NewIndexBuilder().Key("stream_id", 1).Key("version", 1).Unique().Build()
+	if _, err := s.client.CreateIndexes(ctx, s.events.Collection(), []mongo.IndexModel{index}); err != nil {
+		return err
+	}
+	s.indexed = true
+	return nil
+}
@@ -0,0 +1,99 @@
+package eventstore
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type cart struct {
+	Items int `bson:"items"`
+}
+
+func TestStore_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := mongokit.New(mongokit.DefaultConfig(), mongokit.WithURI(container.URI), mongokit.WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	store := New(client, "cart_events")
+	added := func(sku string) Event {
+		return Event{Type: "ItemAdded", Data: itemAdded{SKU: sku, Qty: 1}}
+	}
+
+	t.Run("append and load", func(t *testing.T) {
+		version, err := store.Append(ctx, "cart-1", 0, added("A"), added("B"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), version)
+
+		version, err = store.Append(ctx, "cart-1", 2, added("C"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), version)
+
+		events, err := store.Load(ctx, "cart-1", 2)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, int64(2), events[0].Version)
+
+		var item itemAdded
+		require.NoError(t, events[1].Decode(&item))
+		assert.Equal(t, "C", item.SKU)
+
+		_, err = store.Append(ctx, "cart-1", 2, added("D"))
+		assert.ErrorIs(t, err, ErrConcurrency)
+	})
+
+	t.Run("one of concurrent writers wins", func(t *testing.T) {
+		var wins atomic.Int64
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := store.Append(ctx, "cart-race", 0, added("X")); err == nil {
+					wins.Add(1)
+				} else {
+					assert.ErrorIs(t, err, ErrConcurrency)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int64(1), wins.Load())
+
+		version, err := store.Version(ctx, "cart-race")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), version)
+	})
+
+	t.Run("snapshots", func(t *testing.T) {
+		var state cart
+		_, found, err := store.LoadSnapshot(ctx, "cart-1", &state)
+		require.NoError(t, err)
+		assert.False(t, found)
+
+		require.NoError(t, store.SaveSnapshot(ctx, "cart-1", 3, cart{Items: 3}))
+		version, found, err := store.LoadSnapshot(ctx, "cart-1", &state)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, int64(3), version)
+		assert.Equal(t, 3, state.Items)
+
+		events, err := store.Load(ctx, "cart-1", version+1)
+		require.NoError(t, err)
+		assert.Empty(t, events)
+	})
+}
@@ -0,0 +1,117 @@
+package eventstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type itemAdded struct {
+	SKU string `bson:"sku"`
+	Qty int    `bson:"qty"`
+}
+
+func TestEvent_Decode(t *testing.T) {
+	raw, err := bson.Marshal(itemAdded{SKU: "A-1", Qty: 2})
+	require.NoError(t, err)
+
+	var fromRaw itemAdded
+	require.NoError(t, Event{Type: "ItemAdded", Data: bson.Raw(raw)}.Decode(&fromRaw))
+	assert.Equal(t, itemAdded{SKU: "A-1", Qty: 2}, fromRaw)
+
+	var fromValue itemAdded
+	require.NoError(t, Event{Type: "ItemAdded", Data: itemAdded{SKU: "B-2", Qty: 1}}.Decode(&fromValue))
+	assert.Equal(t, "B-2", fromValue.SKU)
+
+	assert.Error(t, Event{Type: "Bad", Data: "not a document"}.Decode(&fromValue))
+}
+
+func TestStore_Append(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := mongokit.NewFromClient(mock.Client, mongokit.DefaultConfig(), mongokit.WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	store := New(client, "events")
+	event := Event{Type: "ItemAdded", Data: itemAdded{SKU: "A-1", Qty: 2}}
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		_, err := store.Append(ctx, "", 0, event)
+		assert.ErrorContains(t, err, "stream ID is required")
+
+		_, err = store.Append(ctx, "order-1", -2, event)
+		assert.ErrorContains(t, err, "invalid expected version")
+	})
+
+	t.Run("appends after the expected version", func(t *testing.T) {
+		mock.AddResponses(
+			testhelpers.SuccessResponse(), // createIndexes
+			testhelpers.CursorResponse("testdb.events", bson.D{{Key: "version", Value: int64(2)}}),
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 2}),
+		)
+		version, err := store.Append(ctx, "order-1", 2, event, event)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), version)
+	})
+
+	t.Run("rejects a stale expected version", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CursorResponse("testdb.events", bson.D{{Key: "version", Value: int64(3)}}))
+		_, err := store.Append(ctx, "order-1", 2, event)
+		assert.ErrorIs(t, err, ErrConcurrency)
+	})
+
+	t.Run("reports a concurrent append", func(t *testing.T) {
+		mock.AddResponses(
+			testhelpers.CursorResponse("testdb.events"),
+			testhelpers.WriteErrorResponse(0, 11000, "E11000 duplicate key error"),
+		)
+		_, err := store.Append(ctx, "order-2", 0, event)
+		assert.ErrorIs(t, err, ErrConcurrency)
+	})
+
+	t.Run("rejects events without a type", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CursorResponse("testdb.events"))
+		_, err := store.Append(ctx, "order-3", AnyVersion, Event{})
+		assert.ErrorContains(t, err, "has no type")
+	})
+}
+
+func TestStore_LoadSnapshot(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := mongokit.NewFromClient(mock.Client, mongokit.DefaultConfig(), mongokit.WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	store := New(client, "events")
+
+	t.Run("loads by the stream ID", func(t *testing.T) {
+		state, err := bson.Marshal(itemAdded{SKU: "A-1", Qty: 3})
+		require.NoError(t, err)
+		mock.AddResponses(testhelpers.CursorResponse("testdb.events_snapshots", bson.D{
+			{Key: "_id", Value: "cart-1"},
+			{Key: "version", Value: int64(7)},
+			{Key: "state", Value: bson.Raw(state)},
+		}))
+
+		var out itemAdded
+		version, found, err := store.LoadSnapshot(ctx, "cart-1", &out)
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, int64(7), version)
+		assert.Equal(t, itemAdded{SKU: "A-1", Qty: 3}, out)
+	})
+
+	t.Run("missing snapshot", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CursorResponse("testdb.events_snapshots"))
+		var out itemAdded
+		_, found, err := store.LoadSnapshot(ctx, "cart-2", &out)
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+}
@@ -0,0 +1,13 @@
+package eventstore
+
+import (
+	"os"
+	"testing"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+// TestMain runs the integration tests of the package on one shared MongoDB container.
+func TestMain(m *testing.M) {
+	os.Exit(testhelpers.SharedContainer(m))
+}
@@ -0,0 +1,276 @@
+// Package export writes the documents of a collection to NDJSON, Extended JSON or CSV,
+// for support and operations tasks that would otherwise need the mongoexport binary.
+//
+// See docs/export.md for detailed usage guide and examples.
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// defaultBatchSize is the number of documents read per batch when Options.BatchSize
+// is not set.
+const defaultBatchSize = 1000
+
+// Format is the output format of an export.
+type Format int
+
+const (
+	NDJSON  Format = iota // One relaxed Extended JSON document per line, readable by most JSON tools
+	ExtJSON               // One canonical Extended JSON document per line, preserving every BSON type
+	CSV                   // A header row and one row per document
+)
+
+// String returns the name of the format.
+func (f Format) String() string {
+	switch f {
+	case NDJSON:
+		return "ndjson"
+	case ExtJSON:
+		return "extjson"
+	case CSV:
+		return "csv"
+	default:
+		return "Format(" + strconv.Itoa(int(f)) + ")"
+	}
+}
+
+// Options configures an export.
+type Options struct {
+	Format     Format               // Output format (default: NDJSON)
+	Projection any                  // Fields to export (default: all fields)
+	Sort       any                  // Order of the documents (default: natural order)
+	Fields     []string             // CSV columns, as dotted paths (default: top-level fields of the first document)
+	BatchSize  int32                // Documents read per batch (default: 1000)
+	OnProgress func(exported int64) // Called after each batch of documents and at the end (default: nil)
+	// Transform rewrites each document before it is written, e.g. to mask personal data
+	// with anonymize.Rules.Apply; returning nil skips the document (default: nil).
+	Transform func(doc bson.Raw) (bson.Raw, error)
+}
+
+// validate checks that the options can be used.
+func (o *Options) validate() error {
+	if o.Format < NDJSON || o.Format > CSV {
+		return fmt.Errorf("export: unknown format %s", o.Format)
+	}
+	if o.BatchSize < 0 {
+		return errors.New("export: BatchSize cannot be negative")
+	}
+	return nil
+}
+
+// Collection writes the documents of collection matching filter to w and returns the
+// number of documents written. A nil filter exports every document.
+//
+// Documents are streamed from a cursor, so collections larger than memory can be
+// exported. Output is buffered and flushed before Collection returns.
+//
+// Example:
+//
+//	f, err := os.Create("orders.csv")
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//
+//	n, err := export.Collection(ctx, client, "orders", bson.M{"status": "paid"}, f, export.Options{
+//	    Format:     export.CSV,
+//	    Fields:     []string{"_id", "customer.email", "total", "created_at"},
+//	    OnProgress: func(n int64) { log.Printf("exported %d orders", n) },
+//	})
+func Collection(ctx context.Context, client *mongokit.Client, collection string, filter any, w io.Writer, opts Options) (int64, error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+	batchSize := opts.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultBatchSize
+	}
+	if filter == nil {
+		filter = bson.D{}
+	}
+
+	pipeline := []bson.D{{{Key: "$match", Value: filter}}}
+	if opts.Sort != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: opts.Sort}})
+	}
+	if opts.Projection != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$project", Value: opts.Projection}})
+	}
+
+	repo := mongokit.NewRepository[bson.Raw](client, collection)
+	it, err := repo.AggregateIter(ctx, pipeline, options.Aggregate().SetBatchSize(batchSize).SetAllowDiskUse(true))
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = it.Close(ctx) }()
+
+	buf := bufio.NewWriter(w)
+	enc := newEncoder(buf, opts)
+
+	var exported int64
+	for it.Next(ctx) {
+		doc := it.Current()
+		if opts.Transform != nil {
+			if doc, err = opts.Transform(doc); err != nil {
+				return exported, fmt.Errorf("export: transform document: %w", err)
+			}
+			if doc == nil {
+				continue
+			}
+		}
+		if err := enc.encode(doc); err != nil {
+			return exported, err
+		}
+		exported++
+		if opts.OnProgress != nil && exported%int64(batchSize) == 0 {
+			opts.OnProgress(exported)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return exported, err
+	}
+
+	if err := enc.flush(); err != nil {
+		return exported, fmt.Errorf("export: write: %w", err)
+	}
+	if opts.OnProgress != nil && exported%int64(batchSize) != 0 {
+		opts.OnProgress(exported)
+	}
+	return exported, nil
+}
+
+// encoder writes documents in the format of an export.
+type encoder struct {
+	format Format
+	w      *bufio.Writer
+	csv    *csv.Writer
+	fields []string
+	header bool // Whether the CSV header row was written
+}
+
+// newEncoder returns an encoder writing to w in the format of opts.
+func newEncoder(w *bufio.Writer, opts Options) *encoder {
+	enc := &encoder{format: opts.Format, w: w, fields: opts.Fields}
+	if opts.Format == CSV {
+		enc.csv = csv.NewWriter(w)
+	}
+	return enc
+}
+
+// encode writes doc.
+func (e *encoder) encode(doc bson.Raw) error {
+	if e.format == CSV {
+		return e.encodeCSV(doc)
+	}
+
+	data, err := bson.MarshalExtJSON(doc, e.format == ExtJSON, false)
+	if err != nil {
+		return fmt.Errorf("export: encode document: %w", err)
+	}
+	if _, err := e.w.Write(data); err != nil {
+		return fmt.Errorf("export: write: %w", err)
+	}
+	if err := e.w.WriteByte('\n'); err != nil {
+		return fmt.Errorf("export: write: %w", err)
+	}
+	return nil
+}
+
+// encodeCSV writes doc as a CSV row, preceded by the header row for the first document.
+func (e *encoder) encodeCSV(doc bson.Raw) error {
+	if e.fields == nil {
+		elems, err := doc.Elements()
+		if err != nil {
+			return fmt.Errorf("export: read document: %w", err)
+		}
+		for _, elem := range elems {
+			e.fields = append(e.fields, elem.Key())
+		}
+	}
+	if !e.header {
+		if err := e.csv.Write(e.fields); err != nil {
+			return fmt.Errorf("export: write: %w", err)
+		}
+		e.header = true
+	}
+
+	row := make([]string, len(e.fields))
+	for i, field := range e.fields {
+		value, err := doc.LookupErr(strings.Split(field, ".")...)
+		if err != nil {
+			continue // missing fields are empty cells
+		}
+		if row[i], err = CSVValue(value); err != nil {
+			return fmt.Errorf("export: encode field %s: %w", field, err)
+		}
+	}
+	if err := e.csv.Write(row); err != nil {
+		return fmt.Errorf("export: write: %w", err)
+	}
+	return nil
+}
+
+// flush writes buffered output.
+func (e *encoder) flush() error {
+	if e.csv != nil {
+		if e.fields != nil && !e.header {
+			// No document was exported: still write the configured header.
+			if err := e.csv.Write(e.fields); err != nil {
+				return err
+			}
+		}
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	return e.w.Flush()
+}
+
+// CSVValue returns the CSV cell of a value: strings as is, ObjectIDs as hex, dates as
+// RFC 3339 in UTC, numbers and booleans in Go syntax, null as an empty cell and other
+// values, such as documents and arrays, as relaxed Extended JSON.
+func CSVValue(value bson.RawValue) (string, error) {
+	switch value.Type {
+	case bsontype.String:
+		return value.StringValue(), nil
+	case bsontype.ObjectID:
+		return value.ObjectID().Hex(), nil
+	case bsontype.DateTime:
+		return value.Time().UTC().Format(time.RFC3339Nano), nil
+	case bsontype.Int32:
+		return strconv.FormatInt(int64(value.Int32()), 10), nil
+	case bsontype.Int64:
+		return strconv.FormatInt(value.Int64(), 10), nil
+	case bsontype.Double:
+		return strconv.FormatFloat(value.Double(), 'f', -1, 64), nil
+	case bsontype.Boolean:
+		return strconv.FormatBool(value.Boolean()), nil
+	case bsontype.Decimal128:
+		return value.Decimal128().String(), nil
+	case bsontype.Null, bsontype.Undefined:
+		return "", nil
+	}
+
+	data, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: value}}, false, false)
+	if err != nil {
+		return "", err
+	}
+	// Strip the {"v": ...} wrapper.
+	return string(data[len(`{"v":`) : len(data)-1]), nil
+}
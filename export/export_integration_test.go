@@ -0,0 +1,82 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestCollection_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := mongokit.New(mongokit.DefaultConfig(), mongokit.WithURI(container.URI), mongokit.WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := mongokit.NewRepository[bson.M](client, "orders")
+	for i := range 5 {
+		_, err := repo.Create(ctx, bson.M{"_id": i, "status": []string{"new", "paid"}[i%2], "total": i * 10})
+		require.NoError(t, err)
+	}
+
+	t.Run("NDJSON with filter and projection", func(t *testing.T) {
+		var out bytes.Buffer
+		n, err := Collection(ctx, client, "orders", bson.M{"status": "paid"}, &out, Options{
+			Projection: bson.M{"total": 1},
+			Sort:       bson.M{"_id": 1},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+		assert.Equal(t, "{\"_id\":1,\"total\":10}\n{\"_id\":3,\"total\":30}\n", out.String())
+	})
+
+	t.Run("CSV in batches", func(t *testing.T) {
+		var out bytes.Buffer
+		var calls int
+		n, err := Collection(ctx, client, "orders", nil, &out, Options{
+			Format:     CSV,
+			Fields:     []string{"_id", "status"},
+			Sort:       bson.M{"_id": 1},
+			BatchSize:  2,
+			OnProgress: func(int64) { calls++ },
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), n)
+		assert.Equal(t, 3, calls)
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 6)
+		assert.Equal(t, "_id,status", lines[0])
+		assert.Equal(t, "4,new", lines[5])
+	})
+
+	t.Run("Transform rewrites and skips documents", func(t *testing.T) {
+		var out bytes.Buffer
+		n, err := Collection(ctx, client, "orders", nil, &out, Options{
+			Projection: bson.M{"total": 1},
+			Sort:       bson.M{"_id": 1},
+			Transform: func(doc bson.Raw) (bson.Raw, error) {
+				if doc.Lookup("_id").Int32() > 1 {
+					return nil, nil
+				}
+				return bson.Marshal(bson.D{{Key: "_id", Value: doc.Lookup("_id")}, {Key: "total", Value: "hidden"}})
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+		assert.Equal(t, "{\"_id\":0,\"total\":\"hidden\"}\n{\"_id\":1,\"total\":\"hidden\"}\n", out.String())
+	})
+}
@@ -0,0 +1,131 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func rawDoc(t *testing.T, doc any) bson.Raw {
+	t.Helper()
+	data, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	return data
+}
+
+func encodeAll(t *testing.T, opts Options, docs ...bson.Raw) string {
+	t.Helper()
+	var out bytes.Buffer
+	enc := newEncoder(bufio.NewWriter(&out), opts)
+	for _, doc := range docs {
+		require.NoError(t, enc.encode(doc))
+	}
+	require.NoError(t, enc.flush())
+	return out.String()
+}
+
+func TestFormat_String(t *testing.T) {
+	assert.Equal(t, "ndjson", NDJSON.String())
+	assert.Equal(t, "extjson", ExtJSON.String())
+	assert.Equal(t, "csv", CSV.String())
+	assert.Equal(t, "Format(7)", Format(7).String())
+}
+
+func TestOptions_Validate(t *testing.T) {
+	assert.NoError(t, (&Options{}).validate())
+	assert.ErrorContains(t, (&Options{Format: Format(7)}).validate(), "unknown format")
+	assert.ErrorContains(t, (&Options{BatchSize: -1}).validate(), "cannot be negative")
+}
+
+func TestEncoder(t *testing.T) {
+	doc := rawDoc(t, bson.D{{Key: "_id", Value: 1}, {Key: "name", Value: "Ada"}, {Key: "score", Value: int64(42)}})
+
+	t.Run("NDJSON", func(t *testing.T) {
+		out := encodeAll(t, Options{Format: NDJSON}, doc, doc)
+		assert.Equal(t, `{"_id":1,"name":"Ada","score":42}`+"\n"+`{"_id":1,"name":"Ada","score":42}`+"\n", out)
+	})
+
+	t.Run("ExtJSON", func(t *testing.T) {
+		out := encodeAll(t, Options{Format: ExtJSON}, doc)
+		assert.Equal(t, `{"_id":{"$numberInt":"1"},"name":"Ada","score":{"$numberLong":"42"}}`+"\n", out)
+	})
+
+	t.Run("CSV with fields of the first document", func(t *testing.T) {
+		other := rawDoc(t, bson.D{{Key: "_id", Value: 2}, {Key: "name", Value: "Grace, Hopper"}})
+		out := encodeAll(t, Options{Format: CSV}, doc, other)
+		assert.Equal(t, "_id,name,score\n1,Ada,42\n2,\"Grace, Hopper\",\n", out)
+	})
+
+	t.Run("CSV with dotted fields", func(t *testing.T) {
+		nested := rawDoc(t, bson.D{{Key: "_id", Value: 1}, {Key: "customer", Value: bson.D{{Key: "email", Value: "a@b.c"}}}})
+		out := encodeAll(t, Options{Format: CSV, Fields: []string{"customer.email", "missing"}}, nested)
+		assert.Equal(t, "customer.email,missing\na@b.c,\n", out)
+	})
+
+	t.Run("CSV header without documents", func(t *testing.T) {
+		assert.Equal(t, "_id,name\n", encodeAll(t, Options{Format: CSV, Fields: []string{"_id", "name"}}))
+		assert.Empty(t, encodeAll(t, Options{Format: CSV}))
+	})
+}
+
+func TestCSVValue(t *testing.T) {
+	oid := primitive.NewObjectID()
+	when := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	doc := rawDoc(t, bson.D{
+		{Key: "oid", Value: oid},
+		{Key: "date", Value: when},
+		{Key: "double", Value: 1.5},
+		{Key: "bool", Value: true},
+		{Key: "null", Value: nil},
+		{Key: "array", Value: bson.A{1, "x"}},
+		{Key: "doc", Value: bson.D{{Key: "a", Value: 1}}},
+	})
+
+	tests := map[string]string{
+		"oid":    oid.Hex(),
+		"date":   "2024-03-01T12:30:00Z",
+		"double": "1.5",
+		"bool":   "true",
+		"null":   "",
+		"array":  `[1,"x"]`,
+		"doc":    `{"a":1}`,
+	}
+	for field, want := range tests {
+		got, err := CSVValue(doc.Lookup(field))
+		require.NoError(t, err)
+		assert.Equal(t, want, got, field)
+	}
+}
+
+func TestCollection(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := mongokit.NewFromClient(mock.Client, mongokit.DefaultConfig(), mongokit.WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.users",
+		bson.D{{Key: "_id", Value: 1}, {Key: "name", Value: "Ada"}},
+		bson.D{{Key: "_id", Value: 2}, {Key: "name", Value: "Grace"}},
+		bson.D{{Key: "_id", Value: 3}, {Key: "name", Value: "Linus"}},
+	))
+
+	var out bytes.Buffer
+	var progress []int64
+	n, err := Collection(context.Background(), client, "users", nil, &out, Options{
+		BatchSize:  2,
+		OnProgress: func(exported int64) { progress = append(progress, exported) },
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.Equal(t, []int64{2, 3}, progress)
+	assert.Equal(t, "{\"_id\":1,\"name\":\"Ada\"}\n{\"_id\":2,\"name\":\"Grace\"}\n{\"_id\":3,\"name\":\"Linus\"}\n", out.String())
+}
@@ -0,0 +1,204 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Geospatial Queries
+//
+// This file provides a repository for documents with GeoJSON locations: proximity and
+// polygon queries on 2dsphere indexes, and $geoNear aggregations with distances.
+//
+// See docs/repository.md for detailed usage guide and examples.
+
+// distanceField is the field $geoNear stores the distance of each result in.
+const distanceField = "_distance"
+
+// GeoPoint is a GeoJSON point, for location fields of documents.
+// Coordinates are [longitude, latitude].
+type GeoPoint struct {
+	Type        string     `bson:"type" json:"type"`
+	Coordinates [2]float64 `bson:"coordinates" json:"coordinates"`
+}
+
+// NewGeoPoint returns the GeoJSON point at lng, lat.
+//
+// Example:
+//
+//	store.Location = mongo_kit.NewGeoPoint(-73.9857, 40.7484)
+func NewGeoPoint(lng, lat float64) GeoPoint {
+	return GeoPoint{Type: "Point", Coordinates: [2]float64{lng, lat}}
+}
+
+// GeoResult is a document returned by GeoNear with its distance from the query point.
+type GeoResult[T any] struct {
+	Document T       // The matching document
+	Distance float64 // Distance from the query point, in meters
+}
+
+// GeoNearOptions configures GeoNear.
+type GeoNearOptions struct {
+	MinMeters float64 // Minimum distance from the point (default: none)
+	MaxMeters float64 // Maximum distance from the point (default: none)
+	Filter    any     // Additional query filter (default: none)
+	Limit     int64   // Maximum number of results (default: all)
+}
+
+// GeoRepository is a Repository with geospatial queries on GeoJSON fields. The
+// 2dsphere index of a field is created on its first query.
+type GeoRepository[T any] struct {
+	*Repository[T]
+
+	mu      sync.Mutex
+	indexed map[string]bool
+}
+
+// NewGeoRepository creates a GeoRepository for the specified collection.
+//
+// Example:
+//
+//	stores := mongo_kit.NewGeoRepository[Store](client, "stores")
+func NewGeoRepository[T any](client *Client, collection string, opts ...RepositoryOption) *GeoRepository[T] {
+	return &GeoRepository[T]{
+		Repository: NewRepository[T](client, collection, opts...),
+		indexed:    make(map[string]bool),
+	}
+}
+
+// EnsureGeoIndex creates a 2dsphere index on field unless this repository already did.
+// Queries call it, so calling it directly is only needed to create the index up front.
+func (g *GeoRepository[T]) EnsureGeoIndex(ctx context.Context, field string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.indexed[field] {
+		return nil
+	}
+	index := NewIndexBuilder().Key(field, "2dsphere").Build()
+	if _, err := g.client.CreateIndexes(ctx, g.collection, []mongo.IndexModel{index}); err != nil {
+		return err
+	}
+	g.indexed[field] = true
+	return nil
+}
+
+// FindNear finds documents whose location in field is within maxMeters of lng, lat,
+// nearest first. maxMeters of 0 means no limit. qb adds filters, a limit, a skip and a
+// projection, and may be nil.
+//
+// Example:
+//
+//	stores, err := repo.FindNear(ctx, "location", -73.9857, 40.7484, 2000,
+//	    mongo_kit.NewQueryBuilder().Equals("open", true).Limit(10))
+func (g *GeoRepository[T]) FindNear(ctx context.Context, field string, lng, lat, maxMeters float64, qb *QueryBuilder) ([]T, error) {
+	if maxMeters < 0 {
+		return nil, newOperationError("find near", errors.New("maxMeters cannot be negative"))
+	}
+	near := bson.D{{Key: "$geometry", Value: NewGeoPoint(lng, lat)}}
+	if maxMeters > 0 {
+		near = append(near, bson.E{Key: "$maxDistance", Value: maxMeters})
+	}
+	return g.findGeo(ctx, field, bson.D{{Key: "$near", Value: near}}, qb)
+}
+
+// FindWithinPolygon finds documents whose location in field is inside the polygon with
+// the given [longitude, latitude] vertices. The ring is closed automatically when the
+// last vertex differs from the first. qb may be nil.
+//
+// Example:
+//
+//	stores, err := repo.FindWithinPolygon(ctx, "location", [][2]float64{
+//	    {-74.02, 40.70}, {-73.93, 40.70}, {-73.93, 40.80}, {-74.02, 40.80},
+//	}, nil)
+func (g *GeoRepository[T]) FindWithinPolygon(ctx context.Context, field string, vertices [][2]float64, qb *QueryBuilder) ([]T, error) {
+	if len(vertices) < 3 {
+		return nil, newOperationError("find within polygon", errors.New("a polygon requires at least 3 vertices"))
+	}
+	ring := append([][2]float64(nil), vertices...)
+	if ring[0] != ring[len(ring)-1] {
+		ring = append(ring, ring[0])
+	}
+	polygon := bson.D{
+		{Key: "type", Value: "Polygon"},
+		{Key: "coordinates", Value: [][][2]float64{ring}},
+	}
+	return g.findGeo(ctx, field, bson.D{{Key: "$geoWithin", Value: bson.D{{Key: "$geometry", Value: polygon}}}}, qb)
+}
+
+// findGeo runs a find with the geospatial condition on field and the query of qb.
+func (g *GeoRepository[T]) findGeo(ctx context.Context, field string, condition bson.D, qb *QueryBuilder) ([]T, error) {
+	if err := g.EnsureGeoIndex(ctx, field); err != nil {
+		return nil, err
+	}
+	if qb == nil {
+		qb = NewQueryBuilder()
+	}
+	filter, opts := qb.Build()
+	filter = append(bson.D{{Key: field, Value: condition}}, filter...)
+	return g.Find(ctx, filter, opts)
+}
+
+// GeoNear runs a $geoNear aggregation on field and returns the documents nearest to
+// lng, lat first, each with its distance in meters.
+//
+// Example:
+//
+//	results, err := repo.GeoNear(ctx, "location", -73.9857, 40.7484, mongo_kit.GeoNearOptions{
+//	    MaxMeters: 5000,
+//	    Filter:    bson.M{"open": true},
+//	    Limit:     20,
+//	})
+//	for _, r := range results {
+//	    fmt.Printf("%s is %.0fm away\n", r.Document.Name, r.Distance)
+//	}
+func (g *GeoRepository[T]) GeoNear(ctx context.Context, field string, lng, lat float64, opts GeoNearOptions) ([]GeoResult[T], error) {
+	ctx, cancel := g.withTimeout(ctx)
+	defer cancel()
+
+	if opts.MinMeters < 0 || opts.MaxMeters < 0 || opts.Limit < 0 {
+		return nil, newOperationError("geo near", errors.New("MinMeters, MaxMeters and Limit cannot be negative"))
+	}
+	if err := g.EnsureGeoIndex(ctx, field); err != nil {
+		return nil, err
+	}
+
+	stage := bson.D{
+		{Key: "near", Value: NewGeoPoint(lng, lat)},
+		{Key: "key", Value: field},
+		{Key: "distanceField", Value: distanceField},
+		{Key: "spherical", Value: true},
+	}
+	if opts.MinMeters > 0 {
+		stage = append(stage, bson.E{Key: "minDistance", Value: opts.MinMeters})
+	}
+	if opts.MaxMeters > 0 {
+		stage = append(stage, bson.E{Key: "maxDistance", Value: opts.MaxMeters})
+	}
+	if opts.Filter != nil {
+		stage = append(stage, bson.E{Key: "query", Value: opts.Filter})
+	}
+	pipeline := []bson.D{{{Key: "$geoNear", Value: stage}}}
+	if opts.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: opts.Limit}})
+	}
+
+	var docs []bson.Raw
+	if err := g.client.aggregate(ctx, g.collection, pipeline, &docs); err != nil {
+		return nil, err
+	}
+
+	documents, scores, err := g.decodeScored(ctx, "geo near", docs, distanceField)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]GeoResult[T], len(docs))
+	for i := range docs {
+		results[i] = GeoResult[T]{Document: documents[i], Distance: scores[i]}
+	}
+	return results, nil
+}
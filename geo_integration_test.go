@@ -0,0 +1,66 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestGeoRepository_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := NewGeoRepository[geoStore](client, "stores")
+	_, err = repo.CreateMany(ctx, []geoStore{
+		{Name: "Empire State", Location: NewGeoPoint(-73.9857, 40.7484)},
+		{Name: "Times Square", Location: NewGeoPoint(-73.9855, 40.7580)},
+		{Name: "Brooklyn Bridge", Location: NewGeoPoint(-73.9969, 40.7061)},
+	})
+	require.NoError(t, err)
+
+	t.Run("FindNear", func(t *testing.T) {
+		stores, err := repo.FindNear(ctx, "location", -73.9857, 40.7484, 2000, nil)
+		require.NoError(t, err)
+		require.Len(t, stores, 2)
+		assert.Equal(t, "Empire State", stores[0].Name)
+		assert.Equal(t, "Times Square", stores[1].Name)
+
+		stores, err = repo.FindNear(ctx, "location", -73.9857, 40.7484, 0, NewQueryBuilder().NotEquals("name", "Empire State").Limit(1))
+		require.NoError(t, err)
+		require.Len(t, stores, 1)
+		assert.Equal(t, "Times Square", stores[0].Name)
+	})
+
+	t.Run("FindWithinPolygon", func(t *testing.T) {
+		midtown := [][2]float64{{-74.00, 40.74}, {-73.97, 40.74}, {-73.97, 40.77}, {-74.00, 40.77}}
+		stores, err := repo.FindWithinPolygon(ctx, "location", midtown, nil)
+		require.NoError(t, err)
+		assert.Len(t, stores, 2)
+	})
+
+	t.Run("GeoNear", func(t *testing.T) {
+		results, err := repo.GeoNear(ctx, "location", -73.9857, 40.7484, GeoNearOptions{
+			Filter: bson.M{"name": bson.M{"$ne": "Times Square"}},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "Empire State", results[0].Document.Name)
+		assert.InDelta(t, 0, results[0].Distance, 1)
+		assert.Equal(t, "Brooklyn Bridge", results[1].Document.Name)
+		assert.InDelta(t, 4800, results[1].Distance, 300)
+	})
+}
@@ -0,0 +1,73 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type geoStore struct {
+	Name     string   `bson:"name"`
+	Location GeoPoint `bson:"location"`
+}
+
+func TestNewGeoPoint(t *testing.T) {
+	point := NewGeoPoint(-73.9857, 40.7484)
+	assert.Equal(t, "Point", point.Type)
+	assert.Equal(t, [2]float64{-73.9857, 40.7484}, point.Coordinates)
+
+	data, err := bson.Marshal(point)
+	require.NoError(t, err)
+	assert.Equal(t, "Point", bson.Raw(data).Lookup("type").StringValue())
+}
+
+func TestGeoRepository(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	repo := NewGeoRepository[geoStore](client, "stores")
+	store := bson.D{{Key: "name", Value: "Midtown"}, {Key: "location", Value: NewGeoPoint(-73.98, 40.75)}}
+
+	mock.AddResponses(
+		testhelpers.SuccessResponse(), // createIndexes
+		testhelpers.CursorResponse("testdb.stores", store),
+	)
+	stores, err := repo.FindNear(ctx, "location", -73.98, 40.75, 1000, NewQueryBuilder().Limit(5))
+	require.NoError(t, err)
+	require.Len(t, stores, 1)
+	assert.Equal(t, "Midtown", stores[0].Name)
+
+	// The index is created once per field.
+	mock.AddResponses(testhelpers.CursorResponse("testdb.stores", store))
+	stores, err = repo.FindWithinPolygon(ctx, "location", [][2]float64{{-74, 40.7}, {-73.9, 40.7}, {-73.9, 40.8}}, nil)
+	require.NoError(t, err)
+	assert.Len(t, stores, 1)
+
+	withDistance := append(bson.D{}, store...)
+	withDistance = append(withDistance, bson.E{Key: distanceField, Value: 120.5})
+	mock.AddResponses(testhelpers.CursorResponse("testdb.stores", withDistance))
+	results, err := repo.GeoNear(ctx, "location", -73.98, 40.75, GeoNearOptions{MaxMeters: 5000, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Midtown", results[0].Document.Name)
+	assert.Equal(t, 120.5, results[0].Distance)
+}
+
+func TestGeoRepository_InvalidArguments(t *testing.T) {
+	repo := NewGeoRepository[geoStore](&Client{}, "stores")
+	ctx := context.Background()
+
+	_, err := repo.FindNear(ctx, "location", 0, 0, -1, nil)
+	assert.Error(t, err)
+	_, err = repo.FindWithinPolygon(ctx, "location", [][2]float64{{0, 0}, {1, 1}}, nil)
+	assert.Error(t, err)
+	_, err = repo.GeoNear(ctx, "location", 0, 0, GeoNearOptions{Limit: -1})
+	assert.Error(t, err)
+}
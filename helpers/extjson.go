@@ -0,0 +1,92 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ToExtJSON encodes a document, or a slice of documents, as MongoDB Extended JSON.
+// Canonical mode keeps every BSON type, e.g. {"$numberInt": "1"}, and suits exports
+// that must be imported without loss; relaxed mode prints numbers and dates in a more
+// readable form and suits logs.
+//
+// Example:
+//
+//	data, err := helpers.ToExtJSON(user, false)
+//	// {"_id":{"$oid":"65a1f0c2e4b0a1b2c3d4e5f6"},"name":"Alice","created_at":{"$date":"2024-01-15T00:00:00Z"}}
+func ToExtJSON(doc any, canonical bool) ([]byte, error) {
+	if !isDocumentList(doc) {
+		data, err := bson.MarshalExtJSON(doc, canonical, false)
+		if err != nil {
+			return nil, fmt.Errorf("helpers: encode extended JSON: %w", err)
+		}
+		return data, nil
+	}
+
+	rv := reflect.ValueOf(doc)
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := range rv.Len() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		data, err := bson.MarshalExtJSON(rv.Index(i).Interface(), canonical, false)
+		if err != nil {
+			return nil, fmt.Errorf("helpers: encode extended JSON document %d: %w", i, err)
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes(), nil
+}
+
+// FromExtJSON decodes Extended JSON, in canonical or relaxed mode, into out: a pointer
+// to a document such as a struct or bson.M, or a pointer to a slice of documents when
+// data is an array. Values such as {"$oid": ...} and {"$date": ...} are decoded to
+// their BSON types.
+//
+// Example:
+//
+//	var users []User
+//	err := helpers.FromExtJSON(data, &users)
+func FromExtJSON(data []byte, out any) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		if err := bson.UnmarshalExtJSON(data, false, out); err != nil {
+			return fmt.Errorf("helpers: decode extended JSON: %w", err)
+		}
+		return nil
+	}
+
+	// Extended JSON documents must be objects, so the array is decoded as a field.
+	wrapped := make([]byte, 0, len(trimmed)+8)
+	wrapped = append(wrapped, `{"d":`...)
+	wrapped = append(wrapped, trimmed...)
+	wrapped = append(wrapped, '}')
+
+	var doc bson.Raw
+	if err := bson.UnmarshalExtJSON(wrapped, false, &doc); err != nil {
+		return fmt.Errorf("helpers: decode extended JSON: %w", err)
+	}
+	if err := doc.Lookup("d").Unmarshal(out); err != nil {
+		return fmt.Errorf("helpers: decode extended JSON: %w", err)
+	}
+	return nil
+}
+
+// isDocumentList reports whether v is a slice or array of documents rather than a
+// document; bson.D and byte slices such as bson.Raw are documents.
+func isDocumentList(v any) bool {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return false
+	}
+	t := rv.Type()
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return false
+	}
+	return t != reflect.TypeFor[bson.D]() && t.Elem().Kind() != reflect.Uint8 && t.Elem() != reflect.TypeFor[bson.E]()
+}
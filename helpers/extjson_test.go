@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type extUser struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Name      string             `bson:"name"`
+	Age       int64              `bson:"age"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+func TestExtJSON(t *testing.T) {
+	id, _ := primitive.ObjectIDFromHex("65a1f0c2e4b0a1b2c3d4e5f6")
+	created := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	user := extUser{ID: id, Name: "Alice", Age: 30, CreatedAt: created}
+
+	t.Run("relaxed", func(t *testing.T) {
+		data, err := ToExtJSON(user, false)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"_id":{"$oid":"65a1f0c2e4b0a1b2c3d4e5f6"},"name":"Alice","age":30,"created_at":{"$date":"2024-01-15T00:00:00Z"}}`, string(data))
+	})
+
+	t.Run("canonical", func(t *testing.T) {
+		data, err := ToExtJSON(user, true)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"age":{"$numberLong":"30"}`)
+
+		var out extUser
+		require.NoError(t, FromExtJSON(data, &out))
+		assert.Equal(t, user, out)
+	})
+
+	t.Run("slices of documents", func(t *testing.T) {
+		data, err := ToExtJSON([]extUser{user, user}, true)
+		require.NoError(t, err)
+		assert.Equal(t, byte('['), data[0])
+
+		var out []extUser
+		require.NoError(t, FromExtJSON(data, &out))
+		assert.Equal(t, []extUser{user, user}, out)
+	})
+
+	t.Run("bson.D is a document", func(t *testing.T) {
+		data, err := ToExtJSON(bson.D{{Key: "a", Value: int32(1)}}, false)
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":1}`, string(data))
+	})
+
+	t.Run("decodes into maps", func(t *testing.T) {
+		var doc bson.M
+		require.NoError(t, FromExtJSON([]byte(`{"_id": {"$oid": "65a1f0c2e4b0a1b2c3d4e5f6"}}`), &doc))
+		assert.Equal(t, id, doc["_id"])
+	})
+
+	t.Run("reports invalid input", func(t *testing.T) {
+		var doc bson.M
+		assert.Error(t, FromExtJSON([]byte(`{"a":`), &doc))
+		assert.Error(t, FromExtJSON([]byte(`[1,`), &doc))
+
+		_, err := ToExtJSON(42, false)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,123 @@
+package helpers
+
+import (
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	timeType           = reflect.TypeFor[time.Time]()
+	primitivePkgPath   = reflect.TypeFor[primitive.ObjectID]().PkgPath()
+	marshalerType      = reflect.TypeFor[bson.Marshaler]()
+	valueMarshalerType = reflect.TypeFor[bsoncodec.ValueMarshaler]()
+)
+
+// FlattenForSet converts a nested struct or map into dotted paths suitable for $set,
+// e.g. {"address.city": "X", "address.zip": "Y"}, so updating an embedded document
+// only sets the given fields instead of replacing the whole document. Keys are
+// prefixed with prefix and a dot when prefix is not empty.
+//
+// Structs follow the rules of StructToBSON, so omitempty fields are left out.
+// Nested structs, string-keyed maps and bson.D are flattened; other values, including
+// slices, time.Time, ObjectIDs and types with custom BSON marshaling, are set as a whole.
+// Nested documents without fields produce no entries.
+//
+// Example:
+//
+//	set := helpers.FlattenForSet(Address{City: "Paris"}, "address")
+//	// bson.M{"address.city": "Paris"} — other fields of address are kept
+//	_, err := userRepo.UpdateByID(ctx, id, bson.M{"$set": set})
+func FlattenForSet(v any, prefix string) bson.M {
+	out := bson.M{}
+	flatten(out, prefix, reflect.ValueOf(v))
+	return out
+}
+
+// flatten adds the entries of v to out. Values that are not documents are set at key.
+func flatten(out bson.M, key string, v reflect.Value) {
+	fields, ok := documentFields(v)
+	if !ok {
+		if key != "" {
+			out[key] = interfaceOf(v)
+		}
+		return
+	}
+
+	for _, field := range fields {
+		path := field.key
+		if key != "" {
+			path = key + "." + field.key
+		}
+		flatten(out, path, field.value)
+	}
+}
+
+type documentField struct {
+	key   string
+	value reflect.Value
+}
+
+// documentFields returns the fields of v if it is a document that can be flattened.
+func documentFields(v reflect.Value) ([]documentField, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() || isLeafType(v.Type()) {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() || isLeafType(v.Type()) {
+		return nil, false
+	}
+
+	switch {
+	case v.Type() == reflect.TypeFor[bson.D]():
+		d := v.Interface().(bson.D)
+		fields := make([]documentField, len(d))
+		for i, e := range d {
+			fields[i] = documentField{e.Key, reflect.ValueOf(e.Value)}
+		}
+		return fields, true
+
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+		fields := make([]documentField, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			fields = append(fields, documentField{iter.Key().String(), iter.Value()})
+		}
+		return fields, true
+
+	case v.Kind() == reflect.Struct:
+		doc := bson.M{}
+		appendStruct(doc, v)
+		fields := make([]documentField, 0, len(doc))
+		for k, value := range doc {
+			fields = append(fields, documentField{k, reflect.ValueOf(value)})
+		}
+		return fields, true
+	}
+
+	return nil, false
+}
+
+// isLeafType reports whether values of t are set as a whole rather than flattened.
+// Of the primitive types, only the documents bson.M and bson.D are flattened.
+func isLeafType(t reflect.Type) bool {
+	if t.PkgPath() == primitivePkgPath {
+		return t.Kind() != reflect.Map && t != reflect.TypeFor[bson.D]()
+	}
+	return t == timeType ||
+		t.Implements(marshalerType) ||
+		t.Implements(valueMarshalerType)
+}
+
+// interfaceOf returns the value held by v, or nil for an invalid value.
+func interfaceOf(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
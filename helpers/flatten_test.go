@@ -0,0 +1,64 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type profile struct {
+	Bio     string    `bson:"bio,omitempty"`
+	Address address   `bson:"address"`
+	Tags    []string  `bson:"tags"`
+	Since   time.Time `bson:"since,omitempty"`
+	Manager *profile  `bson:"manager,omitempty"`
+}
+
+func TestFlattenForSet(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("flattens nested structs", func(t *testing.T) {
+		p := profile{
+			Address: address{City: "Paris", Zip: "75001"},
+			Tags:    []string{"a"},
+			Since:   since,
+		}
+		assert.Equal(t, bson.M{
+			"profile.address.city": "Paris",
+			"profile.address.zip":  "75001",
+			"profile.tags":         []string{"a"},
+			"profile.since":        since,
+		}, FlattenForSet(&p, "profile"))
+	})
+
+	t.Run("flattens maps and bson.D without prefix", func(t *testing.T) {
+		doc := bson.M{
+			"address": bson.D{{Key: "city", Value: "Rome"}},
+			"meta":    map[string]any{"source": "api", "nested": bson.M{"x": 1}},
+			"empty":   bson.M{},
+		}
+		assert.Equal(t, bson.M{
+			"address.city":  "Rome",
+			"meta.source":   "api",
+			"meta.nested.x": 1,
+		}, FlattenForSet(doc, ""))
+	})
+
+	t.Run("keeps leaf values whole", func(t *testing.T) {
+		id := primitive.NewObjectID()
+		dec, _ := primitive.ParseDecimal128("1.5")
+		assert.Equal(t, bson.M{
+			"ref.id":    id,
+			"ref.price": dec,
+			"ref.none":  nil,
+		}, FlattenForSet(bson.M{"id": id, "price": dec, "none": nil}, "ref"))
+	})
+
+	t.Run("sets a scalar at the prefix", func(t *testing.T) {
+		assert.Equal(t, bson.M{"count": 3}, FlattenForSet(3, "count"))
+		assert.Equal(t, bson.M{}, FlattenForSet(3, ""))
+	})
+}
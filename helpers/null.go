@@ -0,0 +1,145 @@
+package helpers
+
+import (
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Ptr returns a pointer to v, for optional fields and driver options that take pointers.
+//
+// Example:
+//
+//	patch := UserPatch{Nickname: helpers.Ptr("al")}
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// NullIfZero returns nil if v is the zero value of T, and a pointer to v otherwise.
+// A nil pointer is encoded as BSON null, so zero values are stored as null instead of
+// "", 0 or false.
+//
+// Example:
+//
+//	update := bson.M{"$set": bson.M{"nickname": helpers.NullIfZero(form.Nickname)}}
+func NullIfZero[T comparable](v T) *T {
+	var zero T
+	if v == zero {
+		return nil
+	}
+	return &v
+}
+
+// Nullable is a document field that tells apart a missing field, a field set to null
+// and a field with a value, which a plain T or *T field cannot: both a missing field
+// and null decode to a nil pointer.
+//
+// A Nullable that was not set is omitted when encoded with omitempty, and encoded as
+// null otherwise. Values are encoded and decoded with the default registry, so codecs
+// registered on the client, such as WithUUIDCodec, do not apply to T.
+//
+// Example:
+//
+//	type User struct {
+//	    ID       primitive.ObjectID        `bson:"_id"`
+//	    Nickname helpers.Nullable[string] `bson:"nickname,omitempty"`
+//	}
+//
+//	switch {
+//	case !user.Nickname.Present:
+//	    // field missing: never set
+//	case user.Nickname.Null:
+//	    // field explicitly cleared
+//	default:
+//	    log.Print(user.Nickname.Value)
+//	}
+type Nullable[T any] struct {
+	Value   T    // Field value, the zero value when absent or null
+	Present bool // The field exists in the document
+	Null    bool // The field exists and is null
+}
+
+// Some returns a Nullable holding v.
+func Some[T any](v T) Nullable[T] {
+	return Nullable[T]{Value: v, Present: true}
+}
+
+// Null returns a Nullable that is encoded as null.
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{Present: true, Null: true}
+}
+
+// Get returns the value and true if the field holds a value, and false if it is
+// absent or null.
+func (n Nullable[T]) Get() (T, bool) {
+	return n.Value, n.Present && !n.Null
+}
+
+// IsZero reports whether the field is absent, so omitempty leaves it out of the document.
+func (n Nullable[T]) IsZero() bool {
+	return !n.Present
+}
+
+// MarshalBSONValue implements bson.ValueMarshaler.
+func (n Nullable[T]) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if !n.Present || n.Null {
+		return bson.TypeNull, nil, nil
+	}
+	return bson.MarshalValue(n.Value)
+}
+
+// UnmarshalBSONValue implements bson.ValueUnmarshaler.
+func (n *Nullable[T]) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	var zero T
+	*n = Nullable[T]{Value: zero, Present: true}
+	if t == bson.TypeNull || t == bson.TypeUndefined {
+		n.Null = true
+		return nil
+	}
+	return bson.UnmarshalValue(t, data, &n.Value)
+}
+
+// FieldState describes whether a document field is missing, null or set.
+type FieldState int
+
+const (
+	FieldAbsent FieldState = iota // The field does not exist
+	FieldNull                     // The field exists and is null
+	FieldSet                      // The field exists and holds a value
+)
+
+// String returns "absent", "null" or "set".
+func (s FieldState) String() string {
+	switch s {
+	case FieldNull:
+		return "null"
+	case FieldSet:
+		return "set"
+	default:
+		return "absent"
+	}
+}
+
+// FieldStateOf returns the state of the field at path in doc. path may use dot
+// notation to reach embedded documents, e.g. "address.city"; a path through a missing
+// or non-document field is absent.
+//
+// Example:
+//
+//	docs, err := rawRepo.Find(ctx, bson.M{})
+//	for _, doc := range docs {
+//	    if helpers.FieldStateOf(doc, "deleted_at") == helpers.FieldAbsent {
+//	        // document predates soft deletes
+//	    }
+//	}
+func FieldStateOf(doc bson.Raw, path string) FieldState {
+	value, err := doc.LookupErr(strings.Split(path, ".")...)
+	if err != nil {
+		return FieldAbsent
+	}
+	if value.Type == bson.TypeNull || value.Type == bson.TypeUndefined {
+		return FieldNull
+	}
+	return FieldSet
+}
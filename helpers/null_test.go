@@ -0,0 +1,118 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+func TestPtr(t *testing.T) {
+	p := Ptr(42)
+	require.NotNil(t, p)
+	assert.Equal(t, 42, *p)
+}
+
+func TestNullIfZero(t *testing.T) {
+	assert.Nil(t, NullIfZero(""))
+	assert.Nil(t, NullIfZero(0))
+	assert.Equal(t, "al", *NullIfZero("al"))
+
+	data, err := bson.Marshal(bson.M{"nickname": NullIfZero("")})
+	require.NoError(t, err)
+	assert.Equal(t, bson.TypeNull, bson.Raw(data).Lookup("nickname").Type)
+}
+
+type nullableDoc struct {
+	Name     string           `bson:"name"`
+	Nickname Nullable[string] `bson:"nickname,omitempty"`
+	Age      Nullable[int]    `bson:"age"`
+}
+
+func TestNullable_Decode(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     bson.M
+		present bool
+		null    bool
+		value   string
+	}{
+		{name: "absent", doc: bson.M{"name": "Alice"}},
+		{name: "null", doc: bson.M{"name": "Alice", "nickname": nil}, present: true, null: true},
+		{name: "set", doc: bson.M{"name": "Alice", "nickname": "al"}, present: true, value: "al"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := bson.Marshal(tt.doc)
+			require.NoError(t, err)
+
+			var out nullableDoc
+			require.NoError(t, bson.Unmarshal(data, &out))
+
+			assert.Equal(t, tt.present, out.Nickname.Present)
+			assert.Equal(t, tt.null, out.Nickname.Null)
+			value, ok := out.Nickname.Get()
+			assert.Equal(t, tt.value, value)
+			assert.Equal(t, tt.present && !tt.null, ok)
+		})
+	}
+}
+
+func TestNullable_Encode(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      nullableDoc
+		nickname bsontype.Type
+	}{
+		{name: "absent omitted", doc: nullableDoc{}, nickname: 0},
+		{name: "null", doc: nullableDoc{Nickname: Null[string]()}, nickname: bson.TypeNull},
+		{name: "set", doc: nullableDoc{Nickname: Some("al")}, nickname: bson.TypeString},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := bson.Marshal(tt.doc)
+			require.NoError(t, err)
+			raw := bson.Raw(data)
+
+			value, err := raw.LookupErr("nickname")
+			if tt.nickname == 0 {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.nickname, value.Type)
+			}
+			// Without omitempty, an unset Nullable is written as null.
+			assert.Equal(t, bson.TypeNull, raw.Lookup("age").Type)
+		})
+	}
+
+	data, err := bson.Marshal(nullableDoc{Nickname: Some("al"), Age: Some(30)})
+	require.NoError(t, err)
+	var out nullableDoc
+	require.NoError(t, bson.Unmarshal(data, &out))
+	assert.Equal(t, Some("al"), out.Nickname)
+	assert.Equal(t, Some(30), out.Age)
+}
+
+func TestFieldStateOf(t *testing.T) {
+	data, err := bson.Marshal(bson.M{
+		"name":       "Alice",
+		"deleted_at": nil,
+		"address":    bson.M{"city": "Lima", "zip": nil},
+	})
+	require.NoError(t, err)
+	doc := bson.Raw(data)
+
+	assert.Equal(t, FieldSet, FieldStateOf(doc, "name"))
+	assert.Equal(t, FieldNull, FieldStateOf(doc, "deleted_at"))
+	assert.Equal(t, FieldAbsent, FieldStateOf(doc, "email"))
+	assert.Equal(t, FieldSet, FieldStateOf(doc, "address.city"))
+	assert.Equal(t, FieldNull, FieldStateOf(doc, "address.zip"))
+	assert.Equal(t, FieldAbsent, FieldStateOf(doc, "address.street"))
+	assert.Equal(t, FieldAbsent, FieldStateOf(doc, "name.first"))
+	assert.Equal(t, "null", FieldNull.String())
+}
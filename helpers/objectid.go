@@ -0,0 +1,26 @@
+package helpers
+
+import (
+	"encoding/binary"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ObjectIDTime returns the creation time embedded in id, with second precision.
+func ObjectIDTime(id primitive.ObjectID) time.Time {
+	return id.Timestamp()
+}
+
+// ObjectIDFromTime returns the smallest ObjectID created at t, truncated to the second.
+// Use it as a bound on _id to select documents by creation time without a separate
+// indexed timestamp field.
+//
+// Example:
+//
+//	filter := bson.M{"_id": bson.M{"$gte": helpers.ObjectIDFromTime(since)}}
+func ObjectIDFromTime(t time.Time) primitive.ObjectID {
+	var id primitive.ObjectID
+	binary.BigEndian.PutUint32(id[:4], uint32(t.Unix()))
+	return id
+}
@@ -0,0 +1,21 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestObjectIDTime(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+
+	bound := ObjectIDFromTime(created.Add(500 * time.Millisecond))
+	assert.True(t, ObjectIDTime(bound).Equal(created))
+	assert.Equal(t, "65e1ca750000000000000000", bound.Hex())
+
+	id := primitive.NewObjectID()
+	assert.WithinDuration(t, time.Now(), ObjectIDTime(id), 2*time.Second)
+	assert.LessOrEqual(t, ObjectIDFromTime(time.Now().Add(-time.Second)).Hex(), id.Hex())
+}
@@ -0,0 +1,173 @@
+// Package helpers provides conversions and small utilities for building MongoDB
+// documents and queries from Go values.
+//
+// See docs/helpers.md for detailed usage guide and examples.
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// bsonField describes how a struct field is encoded, following the rules of the
+// driver: the key is the bson tag name, or the lowercased field name without one.
+type bsonField struct {
+	Name      string
+	OmitEmpty bool
+	Inline    bool
+	Skip      bool
+}
+
+// parseField parses the bson tag of a struct field.
+func parseField(sf reflect.StructField) bsonField {
+	if !sf.IsExported() {
+		return bsonField{Skip: true}
+	}
+
+	tag := sf.Tag.Get("bson")
+	if tag == "-" {
+		return bsonField{Skip: true}
+	}
+
+	name, flags, _ := strings.Cut(tag, ",")
+	field := bsonField{Name: name}
+	if field.Name == "" {
+		field.Name = strings.ToLower(sf.Name)
+	}
+	for flag := range strings.SplitSeq(flags, ",") {
+		switch flag {
+		case "omitempty":
+			field.OmitEmpty = true
+		case "inline":
+			field.Inline = true
+		}
+	}
+	return field
+}
+
+// isEmpty reports whether v is empty for omitempty: its zero value, an empty slice
+// or map, or a value whose IsZero method returns true, such as time.Time.
+func isEmpty(v reflect.Value) bool {
+	if z, ok := v.Interface().(interface{ IsZero() bool }); ok {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return true
+		}
+		return z.IsZero()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// StructToBSON converts a struct, or a pointer to one, into a bson.M with the keys the
+// driver would encode, honoring bson tags, "-", omitempty and inline. Field values are
+// kept as Go values, so nested structs are encoded by the driver when the document is
+// sent. Maps with string keys are copied.
+//
+// Example:
+//
+//	update, err := helpers.StructToBSON(UserPatch{Name: "Alice"}) // omitempty fields dropped
+//	_, err = repo.UpdateByID(ctx, id, bson.M{"$set": update})
+func StructToBSON(v any) (bson.M, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, errors.New("helpers: cannot convert a nil value")
+		}
+		rv = rv.Elem()
+	}
+
+	doc := bson.M{}
+	switch rv.Kind() {
+	case reflect.Struct:
+		appendStruct(doc, rv)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("helpers: map keys must be strings, got %s", rv.Type().Key())
+		}
+		appendMap(doc, rv)
+	default:
+		return nil, fmt.Errorf("helpers: cannot convert %s to a document", rv.Type())
+	}
+	return doc, nil
+}
+
+// appendStruct adds the encoded fields of the struct rv to doc.
+func appendStruct(doc bson.M, rv reflect.Value) {
+	typ := rv.Type()
+	for i := range typ.NumField() {
+		field := parseField(typ.Field(i))
+		if field.Skip {
+			continue
+		}
+
+		value := rv.Field(i)
+		if field.OmitEmpty && isEmpty(value) {
+			continue
+		}
+
+		if field.Inline {
+			inner := value
+			if inner.Kind() == reflect.Pointer {
+				if inner.IsNil() {
+					continue
+				}
+				inner = inner.Elem()
+			}
+			switch inner.Kind() {
+			case reflect.Struct:
+				appendStruct(doc, inner)
+				continue
+			case reflect.Map:
+				appendMap(doc, inner)
+				continue
+			}
+		}
+
+		doc[field.Name] = value.Interface()
+	}
+}
+
+// appendMap adds the entries of the string-keyed map rv to doc.
+func appendMap(doc bson.M, rv reflect.Value) {
+	iter := rv.MapRange()
+	for iter.Next() {
+		doc[iter.Key().String()] = iter.Value().Interface()
+	}
+}
+
+// BSONToStruct decodes doc into out, which must be a pointer to a struct, honoring bson
+// tags. Values are converted the way the driver decodes documents, e.g. an int32 into
+// an int field. doc may be any document type, such as bson.M, bson.D or bson.Raw.
+//
+// Example:
+//
+//	var user User
+//	err := helpers.BSONToStruct(bson.M{"name": "Alice", "age": 30}, &user)
+func BSONToStruct(doc any, out any) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("helpers: out must be a non-nil pointer")
+	}
+
+	raw, ok := doc.(bson.Raw)
+	if !ok {
+		data, err := bson.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("helpers: encode document: %w", err)
+		}
+		raw = data
+	}
+
+	if err := bson.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("helpers: decode document: %w", err)
+	}
+	return nil
+}
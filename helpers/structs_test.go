@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type address struct {
+	City string `bson:"city"`
+	Zip  string `bson:"zip,omitempty"`
+}
+
+type audit struct {
+	CreatedBy string `bson:"created_by"`
+}
+
+type user struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Name      string             `bson:"name"`
+	Email     string             `bson:"email,omitempty"`
+	Age       int                `bson:"age,omitempty"`
+	Tags      []string           `bson:"tags,omitempty"`
+	Address   address            `bson:"address"`
+	UpdatedAt time.Time          `bson:"updated_at,omitempty"`
+	Password  string             `bson:"-"`
+	Nickname  string
+	Audit     audit          `bson:",inline"`
+	Extra     map[string]any `bson:",inline"`
+	internal  string
+}
+
+func TestStructToBSON(t *testing.T) {
+	u := user{
+		Name:     "Alice",
+		Age:      30,
+		Address:  address{City: "Paris"},
+		Password: "secret",
+		Nickname: "ali",
+		Audit:    audit{CreatedBy: "admin"},
+		Extra:    map[string]any{"plan": "pro"},
+		internal: "hidden",
+	}
+
+	doc, err := StructToBSON(&u)
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{
+		"name":       "Alice",
+		"age":        30,
+		"address":    address{City: "Paris"},
+		"nickname":   "ali",
+		"created_by": "admin",
+		"plan":       "pro",
+	}, doc)
+
+	t.Run("converts maps", func(t *testing.T) {
+		doc, err := StructToBSON(map[string]int{"a": 1})
+		require.NoError(t, err)
+		assert.Equal(t, bson.M{"a": 1}, doc)
+	})
+
+	t.Run("rejects other values", func(t *testing.T) {
+		_, err := StructToBSON(42)
+		assert.Error(t, err)
+
+		_, err = StructToBSON((*user)(nil))
+		assert.Error(t, err)
+
+		_, err = StructToBSON(map[int]string{1: "a"})
+		assert.Error(t, err)
+	})
+}
+
+func TestBSONToStruct(t *testing.T) {
+	var u user
+	err := BSONToStruct(bson.M{
+		"name":       "Alice",
+		"age":        int32(30),
+		"address":    bson.M{"city": "Paris"},
+		"created_by": "admin",
+	}, &u)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", u.Name)
+	assert.Equal(t, 30, u.Age)
+	assert.Equal(t, "Paris", u.Address.City)
+	assert.Equal(t, "admin", u.Audit.CreatedBy)
+
+	t.Run("decodes raw documents", func(t *testing.T) {
+		raw, err := bson.Marshal(bson.D{{Key: "name", Value: "Bob"}})
+		require.NoError(t, err)
+
+		var u user
+		require.NoError(t, BSONToStruct(bson.Raw(raw), &u))
+		assert.Equal(t, "Bob", u.Name)
+	})
+
+	t.Run("requires a pointer", func(t *testing.T) {
+		assert.Error(t, BSONToStruct(bson.M{}, user{}))
+	})
+
+	t.Run("round trips StructToBSON", func(t *testing.T) {
+		in := user{Name: "Carol", Email: "carol@test.com", Address: address{City: "Rome", Zip: "00100"}}
+		doc, err := StructToBSON(in)
+		require.NoError(t, err)
+
+		var out user
+		require.NoError(t, BSONToStruct(doc, &out))
+		assert.Equal(t, in.Name, out.Name)
+		assert.Equal(t, in.Email, out.Email)
+		assert.Equal(t, in.Address, out.Address)
+	})
+}
@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UUIDToBinary returns u as BSON binary subtype 4, the standard UUID representation,
+// for use in documents and filters without the UUID codec.
+//
+// Example:
+//
+//	order, err := orderRepo.FindOne(ctx, bson.M{"_id": helpers.UUIDToBinary(id)})
+func UUIDToBinary(u uuid.UUID) primitive.Binary {
+	return primitive.Binary{Subtype: bson.TypeBinaryUUID, Data: u[:]}
+}
+
+// UUIDFromBinary returns the UUID stored in b. It accepts binary subtype 4, the
+// legacy subtype 3 and generic 16-byte binaries.
+func UUIDFromBinary(b primitive.Binary) (uuid.UUID, error) {
+	if b.Subtype != bson.TypeBinaryUUID && b.Subtype != bson.TypeBinaryUUIDOld && b.Subtype != bson.TypeBinaryGeneric {
+		return uuid.Nil, fmt.Errorf("helpers: binary subtype %#x is not a UUID", b.Subtype)
+	}
+	u, err := uuid.FromBytes(b.Data)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("helpers: %w", err)
+	}
+	return u, nil
+}
@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUUIDBinary(t *testing.T) {
+	id := uuid.New()
+
+	b := UUIDToBinary(id)
+	assert.Equal(t, bson.TypeBinaryUUID, b.Subtype)
+
+	got, err := UUIDFromBinary(b)
+	require.NoError(t, err)
+	assert.Equal(t, id, got)
+
+	got, err = UUIDFromBinary(primitive.Binary{Subtype: bson.TypeBinaryUUIDOld, Data: id[:]})
+	require.NoError(t, err)
+	assert.Equal(t, id, got)
+
+	_, err = UUIDFromBinary(primitive.Binary{Subtype: bson.TypeBinaryMD5, Data: id[:]})
+	assert.Error(t, err)
+
+	_, err = UUIDFromBinary(primitive.Binary{Subtype: bson.TypeBinaryUUID, Data: []byte{1, 2}})
+	assert.Error(t, err)
+}
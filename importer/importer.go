@@ -0,0 +1,232 @@
+// Package importer loads NDJSON files into a collection: each line is decoded and
+// validated into the repository's document type, then inserted or upserted in bulk,
+// with a report of the lines that failed.
+//
+// See docs/importer.md for detailed usage guide and examples.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+const (
+	// defaultBatchSize is the number of documents written per bulk write when
+	// ImportOptions.BatchSize is not set.
+	defaultBatchSize = 500
+	// maxLineSize is the longest line read, the maximum size of a BSON document.
+	maxLineSize = 16 * 1024 * 1024
+)
+
+// Validator is implemented by document types that check their own fields. Documents
+// whose Validate method returns an error are reported and not written.
+type Validator interface {
+	Validate() error
+}
+
+// ImportOptions configures an import.
+type ImportOptions struct {
+	BatchSize int      // Documents written per bulk write (default: 500)
+	Upsert    bool     // Replace the documents matching KeyFields, or insert them (default: insert only)
+	KeyFields []string // Fields identifying a document when upserting, as dotted paths (default: "_id")
+	DryRun    bool     // Decode and validate every line without writing (default: false)
+}
+
+// LineError is the failure of a single line.
+type LineError struct {
+	Line int   // Line number, starting at 1
+	Err  error // Why the line was not imported
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e LineError) Unwrap() error {
+	return e.Err
+}
+
+// Report describes the result of an import.
+type Report struct {
+	Lines    int         // Non-empty lines read
+	Valid    int         // Lines decoded and validated
+	Inserted int64       // Documents inserted
+	Upserted int64       // Documents inserted by upserts
+	Modified int64       // Existing documents replaced by upserts
+	Errors   []LineError // Lines that failed, in line order
+}
+
+// Failed reports whether at least one line failed.
+func (r *Report) Failed() bool {
+	return len(r.Errors) > 0
+}
+
+// FromReader imports the NDJSON documents of r into repo. Each non-empty line is a
+// relaxed or canonical Extended JSON document, decoded into T and, when T implements
+// Validator, validated. Valid documents are written in unordered bulk writes of
+// BatchSize, so a failing document does not stop the others.
+//
+// Lines that cannot be decoded, validated or written are listed in the report; the
+// returned error is only set when the import itself fails, e.g. when r cannot be read
+// or the server is unreachable. The report is returned in both cases.
+//
+// Example:
+//
+//	f, err := os.Open("users.ndjson")
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//
+//	report, err := importer.FromReader(ctx, userRepo, f, importer.ImportOptions{
+//	    Upsert:    true,
+//	    KeyFields: []string{"email"},
+//	})
+//	for _, lineErr := range report.Errors {
+//	    log.Print(lineErr)
+//	}
+func FromReader[T any](ctx context.Context, repo *mongokit.Repository[T], r io.Reader, opts ImportOptions) (*Report, error) {
+	report := &Report{}
+	if opts.BatchSize < 0 {
+		return report, errors.New("importer: BatchSize cannot be negative")
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if len(opts.KeyFields) == 0 {
+		opts.KeyFields = []string{"_id"}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var batch writeBatch
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		report.Lines++
+
+		model, err := decodeLine(ctx, repo, text, opts)
+		if err != nil {
+			report.Errors = append(report.Errors, LineError{Line: line, Err: err})
+			continue
+		}
+		report.Valid++
+		if opts.DryRun {
+			continue
+		}
+
+		batch.add(line, model)
+		if len(batch.models) == opts.BatchSize {
+			if err := flush(ctx, repo, &batch, report); err != nil {
+				return report, err
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return report, fmt.Errorf("importer: read line %d: %w", line+1, err)
+	}
+
+	if err := flush(ctx, repo, &batch, report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// decodeLine decodes and validates a line and returns its write. The document is
+// decoded and encoded with the registry of repo, and audited and encrypted like
+// documents written by Create.
+func decodeLine[T any](ctx context.Context, repo *mongokit.Repository[T], text []byte, opts ImportOptions) (mongo.WriteModel, error) {
+	var doc T
+	if err := bson.UnmarshalExtJSONWithRegistry(repo.Registry(), text, false, &doc); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	if v, ok := any(&doc).(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("validate: %w", err)
+		}
+	}
+
+	encoded, err := repo.EncodeDocument(ctx, doc)
+	if err != nil {
+		return nil, fmt.Errorf("encode: %w", err)
+	}
+	if !opts.Upsert {
+		return mongo.NewInsertOneModel().SetDocument(encoded), nil
+	}
+
+	filter := bson.D{}
+	for _, field := range opts.KeyFields {
+		value, err := encoded.LookupErr(strings.Split(field, ".")...)
+		if err != nil {
+			return nil, fmt.Errorf("missing key field %s", field)
+		}
+		filter = append(filter, bson.E{Key: field, Value: value})
+	}
+	return mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(encoded).SetUpsert(true), nil
+}
+
+// writeBatch collects the writes of a bulk write with their line numbers.
+type writeBatch struct {
+	lines  []int
+	models []mongo.WriteModel
+}
+
+// add adds the write of line.
+func (b *writeBatch) add(line int, model mongo.WriteModel) {
+	b.lines = append(b.lines, line)
+	b.models = append(b.models, model)
+}
+
+// flush sends the writes of b, records the results in report and empties b. Failed
+// writes are reported as line errors; other failures are returned.
+func flush[T any](ctx context.Context, repo *mongokit.Repository[T], b *writeBatch, report *Report) error {
+	if len(b.models) == 0 {
+		return nil
+	}
+	defer func() {
+		b.lines = b.lines[:0]
+		b.models = b.models[:0]
+	}()
+
+	result, err := repo.BulkWrite(ctx, b.models, options.BulkWrite().SetOrdered(false))
+	if err == nil {
+		report.Inserted += result.InsertedCount
+		report.Upserted += result.UpsertedCount
+		report.Modified += result.ModifiedCount
+		return nil
+	}
+
+	var bulkErr *mongokit.BulkError
+	if !errors.As(err, &bulkErr) || len(bulkErr.Errors) == 0 {
+		return err
+	}
+	report.Inserted += bulkErr.InsertedCount
+	report.Upserted += bulkErr.UpsertedCount
+	report.Modified += bulkErr.ModifiedCount
+	for _, item := range bulkErr.Errors {
+		report.Errors = append(report.Errors, LineError{Line: b.lines[item.Index], Err: item.Cause})
+	}
+	// Write errors come after the decode errors of later lines of the batch.
+	slices.SortStableFunc(report.Errors, func(a, b LineError) int { return a.Line - b.Line })
+	return nil
+}
@@ -0,0 +1,58 @@
+package importer
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestFromReader_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := mongokit.New(mongokit.DefaultConfig(), mongokit.WithURI(container.URI), mongokit.WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := mongokit.NewRepository[user](client, "users")
+
+	report, err := FromReader(ctx, repo, strings.NewReader(strings.Join([]string{
+		`{"_id":1,"email":"a@example.com","age":30}`,
+		`{"_id":2,"email":"b@example.com","age":40}`,
+		`{"_id":1,"email":"duplicate@example.com"}`,
+		`{"_id":3}`,
+	}, "\n")), ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), report.Inserted)
+	require.Len(t, report.Errors, 2)
+	assert.Equal(t, 3, report.Errors[0].Line)
+	assert.Equal(t, 4, report.Errors[1].Line)
+
+	report, err = FromReader(ctx, repo, strings.NewReader(strings.Join([]string{
+		`{"_id":2,"email":"b@example.com","age":41}`,
+		`{"_id":5,"email":"e@example.com","age":50}`,
+	}, "\n")), ImportOptions{Upsert: true})
+	require.NoError(t, err)
+	assert.False(t, report.Failed())
+	assert.Equal(t, int64(1), report.Modified)
+	assert.Equal(t, int64(1), report.Upserted)
+
+	updated, err := repo.FindByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 41, updated.Age)
+
+	count, err := repo.CountAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
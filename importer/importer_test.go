@@ -0,0 +1,159 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type user struct {
+	ID    int    `bson:"_id"`
+	Email string `bson:"email"`
+	Age   int    `bson:"age"`
+}
+
+func (u *user) Validate() error {
+	if u.Email == "" {
+		return errors.New("email is required")
+	}
+	return nil
+}
+
+func newRepo(t *testing.T) (*testhelpers.MockClient, *mongokit.Repository[user]) {
+	t.Helper()
+	mock := testhelpers.NewMockClient(t)
+	client, err := mongokit.NewFromClient(mock.Client, mongokit.DefaultConfig(), mongokit.WithDatabase("testdb"))
+	require.NoError(t, err)
+	return mock, mongokit.NewRepository[user](client, "users")
+}
+
+func TestDecodeLine(t *testing.T) {
+	ctx := context.Background()
+	_, repo := newRepo(t)
+
+	t.Run("insert", func(t *testing.T) {
+		model, err := decodeLine(ctx, repo, []byte(`{"_id":1,"email":"a@b.c","age":{"$numberInt":"30"}}`), ImportOptions{})
+		require.NoError(t, err)
+		insert, ok := model.(*mongo.InsertOneModel)
+		require.True(t, ok)
+		var doc user
+		require.NoError(t, bson.Unmarshal(insert.Document.(bson.Raw), &doc))
+		assert.Equal(t, user{ID: 1, Email: "a@b.c", Age: 30}, doc)
+	})
+
+	t.Run("upsert by key fields", func(t *testing.T) {
+		model, err := decodeLine(ctx, repo, []byte(`{"_id":1,"email":"a@b.c"}`), ImportOptions{Upsert: true, KeyFields: []string{"email"}})
+		require.NoError(t, err)
+		replace, ok := model.(*mongo.ReplaceOneModel)
+		require.True(t, ok)
+		filter := replace.Filter.(bson.D)
+		require.Len(t, filter, 1)
+		assert.Equal(t, "email", filter[0].Key)
+		assert.True(t, *replace.Upsert)
+	})
+
+	t.Run("encrypts and audits like Create", func(t *testing.T) {
+		type patient struct {
+			ID        int       `bson:"_id"`
+			SSN       string    `bson:"ssn" encrypt:"aes"`
+			CreatedAt time.Time `bson:"created_at"`
+		}
+		mock := testhelpers.NewMockClient(t)
+		client, err := mongokit.NewFromClient(mock.Client, mongokit.DefaultConfig(), mongokit.WithDatabase("testdb"))
+		require.NoError(t, err)
+		patients := mongokit.NewRepository[patient](client, "patients",
+			mongokit.WithFieldEncryption(mongokit.StaticKeyProvider(bytes.Repeat([]byte("k"), 32))),
+			mongokit.WithAuditFields())
+
+		model, err := decodeLine(ctx, patients, []byte(`{"_id":1,"ssn":"123-45-6789"}`), ImportOptions{})
+		require.NoError(t, err)
+		doc := model.(*mongo.InsertOneModel).Document.(bson.Raw)
+		assert.NotEqual(t, "123-45-6789", doc.Lookup("ssn").StringValue())
+		assert.Equal(t, bson.TypeDateTime, doc.Lookup("created_at").Type)
+	})
+
+	t.Run("key filters use the client's registry", func(t *testing.T) {
+		type order struct {
+			ID uuid.UUID `bson:"_id"`
+		}
+		mock := testhelpers.NewMockClient(t)
+		client, err := mongokit.NewFromClient(mock.Client, mongokit.DefaultConfig(), mongokit.WithDatabase("testdb"), mongokit.WithUUIDCodec())
+		require.NoError(t, err)
+		orders := mongokit.NewRepository[order](client, "orders")
+
+		id := uuid.New()
+		model, err := decodeLine(ctx, orders, []byte(`{"_id":{"$uuid":"`+id.String()+`"}}`), ImportOptions{Upsert: true, KeyFields: []string{"_id"}})
+		require.NoError(t, err)
+		filter := model.(*mongo.ReplaceOneModel).Filter.(bson.D)
+		subtype, data := filter[0].Value.(bson.RawValue).Binary()
+		assert.Equal(t, bson.TypeBinaryUUID, subtype)
+		assert.Equal(t, id[:], data)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := decodeLine(ctx, repo, []byte(`{"_id":`), ImportOptions{})
+		assert.ErrorContains(t, err, "decode")
+		_, err = decodeLine(ctx, repo, []byte(`{"_id":1}`), ImportOptions{})
+		assert.ErrorContains(t, err, "email is required")
+		_, err = decodeLine(ctx, repo, []byte(`{"_id":1,"email":"a@b.c"}`), ImportOptions{Upsert: true, KeyFields: []string{"tenant.id"}})
+		assert.ErrorContains(t, err, "missing key field tenant.id")
+	})
+}
+
+func TestFromReader(t *testing.T) {
+	ctx := context.Background()
+	input := strings.Join([]string{
+		`{"_id":1,"email":"a@example.com"}`,
+		``,
+		`{"_id":2,"email":""}`,
+		`not json`,
+		`{"_id":3,"email":"c@example.com"}`,
+		`{"_id":4,"email":"d@example.com"}`,
+	}, "\n")
+
+	t.Run("dry run", func(t *testing.T) {
+		_, repo := newRepo(t)
+		report, err := FromReader(ctx, repo, strings.NewReader(input), ImportOptions{DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, 5, report.Lines)
+		assert.Equal(t, 3, report.Valid)
+		assert.True(t, report.Failed())
+		require.Len(t, report.Errors, 2)
+		assert.Equal(t, 3, report.Errors[0].Line)
+		assert.Equal(t, 4, report.Errors[1].Line)
+	})
+
+	t.Run("write errors are reported per line", func(t *testing.T) {
+		mock, repo := newRepo(t)
+		mock.AddResponses(
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 2}),
+			testhelpers.WriteErrorResponse(0, 11000, "E11000 duplicate key error"),
+		)
+		report, err := FromReader(ctx, repo, strings.NewReader(input), ImportOptions{BatchSize: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), report.Inserted)
+		require.Len(t, report.Errors, 3)
+		assert.Equal(t, 6, report.Errors[2].Line)
+		assert.ErrorContains(t, report.Errors[2], "line 6")
+		var dupErr *mongokit.DuplicateKeyError
+		assert.ErrorAs(t, report.Errors[2], &dupErr)
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		_, repo := newRepo(t)
+		_, err := FromReader(ctx, repo, strings.NewReader(input), ImportOptions{BatchSize: -1})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,666 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Index Management
+//
+// This file provides declarative index management on the Client.
+//
+// See docs/operations.md for detailed usage guide and examples.
+
+// IndexAction describes what SyncIndexes does with a single index.
+type IndexAction string
+
+const (
+	IndexActionCreate   IndexAction = "create"   // Declared index is missing and will be created
+	IndexActionRecreate IndexAction = "recreate" // Declared index exists with different keys or options and will be dropped and created again
+	IndexActionDrop     IndexAction = "drop"     // Existing index is not declared and will be dropped
+)
+
+// IndexChange is a single planned change produced by SyncIndexes.
+type IndexChange struct {
+	Collection string            // Collection the index belongs to
+	Name       string            // Index name
+	Action     IndexAction       // What is done with the index
+	Model      *mongo.IndexModel // Declared index model (nil for drops)
+}
+
+// IndexSyncPlan lists the changes needed to bring existing indexes in line with the declared ones.
+type IndexSyncPlan struct {
+	Changes []IndexChange
+}
+
+// HasChanges reports whether the plan contains at least one change.
+func (p *IndexSyncPlan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// SyncOptions configures SyncIndexes.
+type SyncOptions struct {
+	DropUnknown bool // Drop existing indexes that are not declared (the _id index is never dropped)
+	DryRun      bool // Only compute the plan, do not modify any index
+}
+
+// SyncIndexes compares the declared indexes of each collection in spec against the
+// existing ones, creates missing indexes, recreates indexes whose keys or options
+// changed and, when DropUnknown is set, drops indexes that are no longer declared.
+// The returned plan describes every change, and is returned even when DryRun is set.
+//
+// Indexes are matched by name; models without an explicit name use the default
+// name MongoDB generates from the keys (e.g. "email_1_created_at_-1").
+// Running SyncIndexes repeatedly with the same spec is safe.
+//
+// Recreating an index drops it before creating it again: MongoDB rejects a second index
+// with the same keys, so the replacement cannot be built first. Until it is built,
+// queries cannot use the index and a unique constraint is not enforced, so duplicates
+// written meanwhile make the creation fail. Review IndexActionRecreate changes with
+// DryRun and apply them when writes to the collection are paused.
+//
+// Example:
+//
+//	plan, err := client.SyncIndexes(ctx, map[string][]mongo.IndexModel{
+//	    "users": {
+//	        {Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+//	    },
+//	}, mongo_kit.SyncOptions{DropUnknown: true})
+func (c *Client) SyncIndexes(ctx context.Context, spec map[string][]mongo.IndexModel, opts SyncOptions) (*IndexSyncPlan, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	plan, err := c.planIndexes(ctx, spec, opts.DropUnknown)
+	if err != nil {
+		return nil, c.reportError(ctx, start, err)
+	}
+
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	if err := c.applyIndexPlan(ctx, plan); err != nil {
+		return plan, c.reportError(ctx, start, err)
+	}
+
+	return plan, nil
+}
+
+// ApplyIndexPlan executes the changes of a plan computed by SyncIndexes with DryRun,
+// e.g. once the plan has been reviewed. Changes are applied in order and the first
+// failure stops the remaining ones.
+//
+// Example:
+//
+//	plan, err := client.SyncIndexes(ctx, spec, mongo_kit.SyncOptions{DryRun: true})
+//	// review plan.Changes
+//	err = client.ApplyIndexPlan(ctx, plan)
+func (c *Client) ApplyIndexPlan(ctx context.Context, plan *IndexSyncPlan) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if err := c.applyIndexPlan(ctx, plan); err != nil {
+		return c.reportError(ctx, start, err)
+	}
+
+	return nil
+}
+
+// planIndexes computes the changes needed for every collection in spec.
+// The caller MUST hold c.mu.RLock().
+func (c *Client) planIndexes(ctx context.Context, spec map[string][]mongo.IndexModel, dropUnknown bool) (*IndexSyncPlan, error) {
+	plan := &IndexSyncPlan{}
+
+	collections := make([]string, 0, len(spec))
+	for name := range spec {
+		collections = append(collections, name)
+	}
+	slices.Sort(collections)
+
+	for _, collection := range collections {
+		// The index documents are compared as listed: IndexSpecification lacks options
+		// such as partialFilterExpression and collation.
+		cursor, err := c.getCollection(collection).Indexes().List(ctx)
+		if err != nil {
+			return nil, newOperationError("sync indexes", err)
+		}
+		var existing []bson.Raw
+		if err := cursor.All(ctx, &existing); err != nil {
+			return nil, newOperationError("sync indexes", err)
+		}
+
+		existingByName := make(map[string]bson.Raw, len(existing))
+		for _, index := range existing {
+			existingByName[index.Lookup("name").StringValue()] = index
+		}
+
+		declared := make(map[string]bool, len(spec[collection]))
+		for i := range spec[collection] {
+			model := &spec[collection][i]
+			name, err := indexModelName(model)
+			if err != nil {
+				return nil, newOperationError("sync indexes", fmt.Errorf("collection %s: %w", collection, err))
+			}
+			if declared[name] {
+				return nil, newOperationError("sync indexes", fmt.Errorf("collection %s: duplicate index name %q", collection, name))
+			}
+			declared[name] = true
+
+			current, ok := existingByName[name]
+			switch {
+			case !ok:
+				plan.Changes = append(plan.Changes, IndexChange{Collection: collection, Name: name, Action: IndexActionCreate, Model: model})
+			case !indexMatches(model, current):
+				plan.Changes = append(plan.Changes, IndexChange{Collection: collection, Name: name, Action: IndexActionRecreate, Model: model})
+			}
+		}
+
+		if !dropUnknown {
+			continue
+		}
+		for _, index := range existing {
+			name := index.Lookup("name").StringValue()
+			if name != "_id_" && !declared[name] {
+				plan.Changes = append(plan.Changes, IndexChange{Collection: collection, Name: name, Action: IndexActionDrop})
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// applyIndexPlan executes the changes of plan in order.
+// The caller MUST hold c.mu.RLock().
+func (c *Client) applyIndexPlan(ctx context.Context, plan *IndexSyncPlan) error {
+	for _, change := range plan.Changes {
+		indexes := c.getCollection(change.Collection).Indexes()
+
+		// A recreated index is dropped first, see SyncIndexes.
+		if change.Action == IndexActionDrop || change.Action == IndexActionRecreate {
+			if _, err := indexes.DropOne(ctx, change.Name); err != nil {
+				return newOperationError("sync indexes", fmt.Errorf("drop %s.%s: %w", change.Collection, change.Name, err))
+			}
+		}
+
+		if change.Action == IndexActionCreate || change.Action == IndexActionRecreate {
+			model := *change.Model
+			model.Options = indexOptionsWithName(model.Options, change.Name)
+			if _, err := indexes.CreateOne(ctx, model); err != nil {
+				return newOperationError("sync indexes", fmt.Errorf("create %s.%s: %w", change.Collection, change.Name, err))
+			}
+		}
+	}
+
+	return nil
+}
+
+// indexModelName returns the explicit name of model, or the name MongoDB
+// generates from its keys when no name is set.
+func indexModelName(model *mongo.IndexModel) (string, error) {
+	if model.Options != nil && model.Options.Name != nil && *model.Options.Name != "" {
+		return *model.Options.Name, nil
+	}
+
+	keys, err := bson.Marshal(model.Keys)
+	if err != nil {
+		return "", fmt.Errorf("invalid index keys: %w", err)
+	}
+
+	elems, err := bson.Raw(keys).Elements()
+	if err != nil {
+		return "", fmt.Errorf("invalid index keys: %w", err)
+	}
+	if len(elems) == 0 {
+		return "", errors.New("index keys cannot be empty")
+	}
+
+	parts := make([]string, 0, len(elems)*2)
+	for _, elem := range elems {
+		parts = append(parts, elem.Key(), rawValueString(elem.Value()))
+	}
+
+	return strings.Join(parts, "_"), nil
+}
+
+// rawValueString formats an index key value the way MongoDB does in generated index names.
+func rawValueString(v bson.RawValue) string {
+	if s, ok := v.StringValueOK(); ok {
+		return s
+	}
+	if f, ok := v.DoubleOK(); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	if i, ok := v.AsInt64OK(); ok {
+		return strconv.FormatInt(i, 10)
+	}
+	return v.String()
+}
+
+// indexMatches reports whether the existing index document, as listed by listIndexes,
+// has the same keys and options as the declared model: unique, sparse, hidden, TTL,
+// partial filter, collation, wildcard projection and text weights. Options the server
+// fills in with defaults, such as the collation strength, only need to match when declared.
+func indexMatches(model *mongo.IndexModel, existing bson.Raw) bool {
+	keys, err := bson.Marshal(model.Keys)
+	if err != nil {
+		return false
+	}
+	textKeys, keys := textIndexKeys(keys)
+	existingKeys, _ := existing.Lookup("key").DocumentOK()
+	if !keysEqual(keys, existingKeys) {
+		return false
+	}
+
+	opts := model.Options
+	if opts == nil {
+		opts = options.Index()
+	}
+
+	if derefBool(opts.Unique) != rawBool(existing.Lookup("unique")) ||
+		derefBool(opts.Sparse) != rawBool(existing.Lookup("sparse")) ||
+		derefBool(opts.Hidden) != rawBool(existing.Lookup("hidden")) {
+		return false
+	}
+
+	ttl, ok := existing.Lookup("expireAfterSeconds").AsInt64OK()
+	if (opts.ExpireAfterSeconds == nil) != !ok {
+		return false
+	}
+	if opts.ExpireAfterSeconds != nil && int64(*opts.ExpireAfterSeconds) != ttl {
+		return false
+	}
+
+	if !optionEqual(opts.PartialFilterExpression, existing.Lookup("partialFilterExpression"), false) ||
+		!optionEqual(opts.WildcardProjection, existing.Lookup("wildcardProjection"), false) {
+		return false
+	}
+
+	var collation any
+	if opts.Collation != nil && opts.Collation.Locale != "simple" { // the server lists no collation for "simple"
+		collation = opts.Collation.ToDocument()
+	}
+	if !optionEqual(collation, existing.Lookup("collation"), true) {
+		return false
+	}
+
+	if len(textKeys) > 0 {
+		weights := bson.D{}
+		for _, key := range textKeys {
+			weights = append(weights, bson.E{Key: key, Value: 1})
+		}
+		if opts.Weights != nil {
+			declared, err := bson.Marshal(opts.Weights)
+			if err != nil {
+				return false
+			}
+			elems, _ := bson.Raw(declared).Elements()
+			for _, elem := range elems {
+				weights = slices.DeleteFunc(weights, func(e bson.E) bool { return e.Key == elem.Key() })
+				weights = append(weights, bson.E{Key: elem.Key(), Value: elem.Value()})
+			}
+		}
+		return optionEqual(weights, existing.Lookup("weights"), false)
+	}
+	return optionEqual(opts.Weights, existing.Lookup("weights"), false)
+}
+
+// textIndexKeys returns the text fields of an index key document, and the key document
+// as the server lists it, where the text fields are replaced with _fts and _ftsx.
+func textIndexKeys(keys bson.Raw) ([]string, bson.Raw) {
+	elems, err := keys.Elements()
+	if err != nil {
+		return nil, keys
+	}
+
+	var textKeys []string
+	listed := bson.D{}
+	for _, elem := range elems {
+		if s, ok := elem.Value().StringValueOK(); !ok || s != "text" {
+			listed = append(listed, bson.E{Key: elem.Key(), Value: elem.Value()})
+			continue
+		}
+		if textKeys == nil {
+			listed = append(listed, bson.E{Key: "_fts", Value: "text"}, bson.E{Key: "_ftsx", Value: 1})
+		}
+		textKeys = append(textKeys, elem.Key())
+	}
+	if textKeys == nil {
+		return nil, keys
+	}
+
+	data, err := bson.Marshal(listed)
+	if err != nil {
+		return nil, keys
+	}
+	return textKeys, data
+}
+
+// optionEqual reports whether a declared document option equals the listed one, ignoring
+// the order of fields. A nil option must be absent; when subset is set, the listed
+// document may have fields that are not declared.
+func optionEqual(declared any, listed bson.RawValue, subset bool) bool {
+	listedDoc, listedOK := listed.DocumentOK()
+	if declared == nil {
+		return !listedOK
+	}
+	data, err := bson.Marshal(declared)
+	if err != nil || !listedOK {
+		return false
+	}
+	return documentsEqual(data, listedDoc, subset)
+}
+
+// documentsEqual compares two documents regardless of the order of their fields,
+// treating numerically equal values as equal. When subset is set, b may have fields
+// that are not in a.
+func documentsEqual(a, b bson.Raw, subset bool) bool {
+	aElems, err := a.Elements()
+	if err != nil {
+		return false
+	}
+	bElems, err := b.Elements()
+	if err != nil || len(aElems) > len(bElems) || (!subset && len(aElems) != len(bElems)) {
+		return false
+	}
+
+	for _, elem := range aElems {
+		value, err := b.LookupErr(elem.Key())
+		if err != nil || !valuesEqual(elem.Value(), value) {
+			return false
+		}
+	}
+	return true
+}
+
+// valuesEqual compares two values, treating numerically equal values as equal and
+// comparing embedded documents with documentsEqual.
+func valuesEqual(a, b bson.RawValue) bool {
+	if x, ok := rawNumber(a); ok {
+		y, ok := rawNumber(b)
+		return ok && x == y
+	}
+
+	switch a.Type {
+	case bson.TypeEmbeddedDocument:
+		return b.Type == bson.TypeEmbeddedDocument && documentsEqual(a.Document(), b.Document(), false)
+	case bson.TypeArray:
+		if b.Type != bson.TypeArray {
+			return false
+		}
+		aValues, aErr := a.Array().Values()
+		bValues, bErr := b.Array().Values()
+		if aErr != nil || bErr != nil || len(aValues) != len(bValues) {
+			return false
+		}
+		for i := range aValues {
+			if !valuesEqual(aValues[i], bValues[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a.Equal(b)
+	}
+}
+
+// keysEqual compares two index key documents, treating numerically equal
+// directions (e.g. int32 1 and double 1.0) as equal.
+func keysEqual(a, b bson.Raw) bool {
+	aElems, err := a.Elements()
+	if err != nil {
+		return false
+	}
+	bElems, err := b.Elements()
+	if err != nil || len(aElems) != len(bElems) {
+		return false
+	}
+
+	for i := range aElems {
+		if aElems[i].Key() != bElems[i].Key() {
+			return false
+		}
+		if rawValueString(aElems[i].Value()) != rawValueString(bElems[i].Value()) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// indexOptionsWithName returns a copy of opts with the index name set.
+func indexOptionsWithName(opts *options.IndexOptions, name string) *options.IndexOptions {
+	named := options.Index()
+	if opts != nil {
+		copied := *opts
+		named = &copied
+	}
+	return named.SetName(name)
+}
+
+// derefBool returns the value of b, or false if b is nil.
+func derefBool(b *bool) bool {
+	return b != nil && *b
+}
+
+// rawBool returns the value of a listed boolean option, which the server may store as a
+// number, or false if it is absent.
+func rawBool(v bson.RawValue) bool {
+	if b, ok := v.BooleanOK(); ok {
+		return b
+	}
+	n, ok := rawNumber(v)
+	return ok && n != 0
+}
+
+// rawNumber returns the value of an int32, int64 or double.
+func rawNumber(v bson.RawValue) (float64, bool) {
+	switch v.Type {
+	case bson.TypeInt32:
+		return float64(v.Int32()), true
+	case bson.TypeInt64:
+		return float64(v.Int64()), true
+	case bson.TypeDouble:
+		return v.Double(), true
+	default:
+		return 0, false
+	}
+}
+
+// IndexUsage holds the access statistics of a single index as reported by $indexStats.
+type IndexUsage struct {
+	Name     string        `bson:"name"`     // Index name
+	Key      bson.D        `bson:"key"`      // Index key document
+	Host     string        `bson:"host"`     // Server that reported the statistics
+	Accesses IndexAccesses `bson:"accesses"` // Access counters
+}
+
+// IndexAccesses holds the access counters of an index.
+type IndexAccesses struct {
+	Ops   int64     `bson:"ops"`   // Number of operations that used the index
+	Since time.Time `bson:"since"` // Time from which the counters were collected (usually server start)
+}
+
+// IndexUsageStats returns the access statistics of every index of the collection
+// using the $indexStats aggregation stage. Counters are reset when the server restarts.
+//
+// Example:
+//
+//	stats, err := client.IndexUsageStats(ctx, "users")
+//	for _, s := range stats {
+//	    fmt.Printf("%s: %d ops since %s\n", s.Name, s.Accesses.Ops, s.Accesses.Since)
+//	}
+func (c *Client) IndexUsageStats(ctx context.Context, collection string) ([]IndexUsage, error) {
+	var stats []IndexUsage
+	pipeline := mongo.Pipeline{{{Key: "$indexStats", Value: bson.D{}}}}
+	if err := c.aggregate(ctx, collection, pipeline, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// UnusedIndexes returns the sorted names of indexes with no recorded accesses on any host.
+// The _id index is never reported since it cannot be dropped.
+//
+// Example:
+//
+//	stats, _ := client.IndexUsageStats(ctx, "users")
+//	for _, name := range mongo_kit.UnusedIndexes(stats) {
+//	    log.Printf("index %s has not been used since server start", name)
+//	}
+func UnusedIndexes(stats []IndexUsage) []string {
+	ops := make(map[string]int64, len(stats))
+	for _, s := range stats {
+		ops[s.Name] += s.Accesses.Ops
+	}
+
+	var unused []string
+	for name, count := range ops {
+		if count == 0 && name != "_id_" {
+			unused = append(unused, name)
+		}
+	}
+	slices.Sort(unused)
+
+	return unused
+}
+
+// lazyIndex creates an index the first time it is needed, and again after a failed
+// attempt, so types that rely on an index do not require a setup step.
+type lazyIndex struct {
+	mu      sync.Mutex
+	created bool
+}
+
+// ensure creates model on collection unless it was already created.
+func (l *lazyIndex) ensure(ctx context.Context, client *Client, collection string, model mongo.IndexModel) error {
+	return l.create(func() error {
+		_, err := client.CreateIndexes(ctx, collection, []mongo.IndexModel{model})
+		return err
+	})
+}
+
+// create calls fn to create the index unless it was already created.
+func (l *lazyIndex) create(fn func() error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.created {
+		return nil
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	l.created = true
+	return nil
+}
+
+// expiryIndex returns a TTL index on a date field: documents expire at the date
+// stored in the field.
+func expiryIndex(field string) mongo.IndexModel {
+	return NewIndexBuilder().Key(field, 1).TTL(0).Build()
+}
+
+// IndexBuilder provides a fluent interface for building index models.
+type IndexBuilder struct {
+	keys    bson.D
+	options *options.IndexOptions
+}
+
+// NewIndexBuilder creates a new IndexBuilder instance.
+func NewIndexBuilder() *IndexBuilder {
+	return &IndexBuilder{
+		keys:    bson.D{},
+		options: options.Index(),
+	}
+}
+
+// Key adds a field to the index keys. Order is 1 (ascending), -1 (descending)
+// or an index type such as "2dsphere" or "hashed".
+func (ib *IndexBuilder) Key(field string, order any) *IndexBuilder {
+	ib.keys = append(ib.keys, bson.E{Key: field, Value: order})
+	return ib
+}
+
+// Text adds a text index key.
+func (ib *IndexBuilder) Text(field string) *IndexBuilder {
+	return ib.Key(field, "text")
+}
+
+// Wildcard adds a wildcard index key on all fields under path,
+// or on every field of the document when path is empty.
+func (ib *IndexBuilder) Wildcard(path string) *IndexBuilder {
+	if path == "" {
+		return ib.Key("$**", 1)
+	}
+	return ib.Key(path+".$**", 1)
+}
+
+// WildcardProjection sets the fields included or excluded by a wildcard index on all fields.
+func (ib *IndexBuilder) WildcardProjection(projection any) *IndexBuilder {
+	ib.options.SetWildcardProjection(projection)
+	return ib
+}
+
+// Name sets the index name.
+func (ib *IndexBuilder) Name(name string) *IndexBuilder {
+	ib.options.SetName(name)
+	return ib
+}
+
+// Unique makes the index reject duplicate values.
+func (ib *IndexBuilder) Unique() *IndexBuilder {
+	ib.options.SetUnique(true)
+	return ib
+}
+
+// Sparse makes the index skip documents that do not contain the indexed fields.
+func (ib *IndexBuilder) Sparse() *IndexBuilder {
+	ib.options.SetSparse(true)
+	return ib
+}
+
+// Hidden hides the index from the query planner while it is still maintained.
+func (ib *IndexBuilder) Hidden() *IndexBuilder {
+	ib.options.SetHidden(true)
+	return ib
+}
+
+// PartialFilter only indexes documents matching filter.
+func (ib *IndexBuilder) PartialFilter(filter any) *IndexBuilder {
+	ib.options.SetPartialFilterExpression(filter)
+	return ib
+}
+
+// TTL makes documents expire ttl after the date stored in the indexed field.
+func (ib *IndexBuilder) TTL(ttl time.Duration) *IndexBuilder {
+	ib.options.SetExpireAfterSeconds(int32(ttl / time.Second))
+	return ib
+}
+
+// Build returns the index model.
+func (ib *IndexBuilder) Build() mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    ib.keys,
+		Options: ib.options,
+	}
+}
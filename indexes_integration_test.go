@@ -0,0 +1,162 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestClient_Indexes_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	cfg := DefaultConfig()
+	WithURI(container.URI)(&cfg)
+	WithDatabase(database)(&cfg)
+
+	client, err := New(cfg)
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+
+	indexNames := func(t *testing.T, collection string) []string {
+		specs, err := client.getCollection(collection).Indexes().ListSpecifications(ctx)
+		require.NoError(t, err)
+		names := make([]string, 0, len(specs))
+		for _, s := range specs {
+			names = append(names, s.Name)
+		}
+		return names
+	}
+
+	t.Run("SyncIndexes creates missing indexes", func(t *testing.T) {
+		spec := map[string][]mongo.IndexModel{
+			"sync_users": {
+				{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+				{Keys: bson.D{{Key: "age", Value: 1}}},
+			},
+		}
+
+		plan, err := client.SyncIndexes(ctx, spec, SyncOptions{})
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 2)
+		assert.Equal(t, IndexActionCreate, plan.Changes[0].Action)
+		assert.ElementsMatch(t, []string{"_id_", "email_1", "age_1"}, indexNames(t, "sync_users"))
+
+		// A second run has nothing to do
+		plan, err = client.SyncIndexes(ctx, spec, SyncOptions{})
+		require.NoError(t, err)
+		assert.False(t, plan.HasChanges())
+	})
+
+	t.Run("SyncIndexes recreates changed and drops unknown indexes", func(t *testing.T) {
+		spec := map[string][]mongo.IndexModel{
+			"sync_users": {
+				{Keys: bson.D{{Key: "email", Value: 1}}},
+			},
+		}
+
+		plan, err := client.SyncIndexes(ctx, spec, SyncOptions{DropUnknown: true})
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 2)
+		assert.Equal(t, IndexChange{Collection: "sync_users", Name: "email_1", Action: IndexActionRecreate, Model: &spec["sync_users"][0]}, plan.Changes[0])
+		assert.Equal(t, IndexActionDrop, plan.Changes[1].Action)
+		assert.Equal(t, "age_1", plan.Changes[1].Name)
+		assert.ElementsMatch(t, []string{"_id_", "email_1"}, indexNames(t, "sync_users"))
+	})
+
+	t.Run("SyncIndexes recreates indexes whose options changed", func(t *testing.T) {
+		spec := map[string][]mongo.IndexModel{
+			"sync_options": {
+				NewIndexBuilder().Key("email", 1).PartialFilter(bson.M{"active": true}).Build(),
+				NewIndexBuilder().Key("age", 1).Build(),
+				NewIndexBuilder().Key("name", 1).Build(),
+				NewIndexBuilder().Text("title").Text("body").Build(),
+			},
+		}
+		spec["sync_options"][2].Options = options.Index().SetCollation(&options.Collation{Locale: "en", Strength: 2})
+
+		_, err := client.SyncIndexes(ctx, spec, SyncOptions{})
+		require.NoError(t, err)
+		plan, err := client.SyncIndexes(ctx, spec, SyncOptions{})
+		require.NoError(t, err)
+		assert.False(t, plan.HasChanges(), "options listed by the server match the declared ones")
+
+		spec["sync_options"][0] = NewIndexBuilder().Key("email", 1).PartialFilter(bson.M{"active": false}).Build()
+		spec["sync_options"][1] = NewIndexBuilder().Key("age", 1).Hidden().Build()
+		plan, err = client.SyncIndexes(ctx, spec, SyncOptions{})
+		require.NoError(t, err)
+		require.Len(t, plan.Changes, 2)
+		assert.Equal(t, IndexActionRecreate, plan.Changes[0].Action)
+		assert.Equal(t, IndexActionRecreate, plan.Changes[1].Action)
+	})
+
+	t.Run("SyncIndexes dry run does not modify indexes", func(t *testing.T) {
+		spec := map[string][]mongo.IndexModel{
+			"sync_users": {
+				{Keys: bson.D{{Key: "name", Value: 1}}},
+			},
+		}
+
+		plan, err := client.SyncIndexes(ctx, spec, SyncOptions{DropUnknown: true, DryRun: true})
+		require.NoError(t, err)
+		assert.Len(t, plan.Changes, 2)
+		assert.ElementsMatch(t, []string{"_id_", "email_1"}, indexNames(t, "sync_users"))
+	})
+
+	t.Run("IndexUsageStats reports index accesses", func(t *testing.T) {
+		repo := NewRepository[User](client, "usage_users")
+		_ = repo.Drop(ctx)
+		_, err := client.CreateIndexes(ctx, "usage_users", []mongo.IndexModel{
+			{Keys: bson.D{{Key: "email", Value: 1}}},
+			{Keys: bson.D{{Key: "age", Value: 1}}},
+		})
+		require.NoError(t, err)
+
+		_, err = repo.Find(ctx, bson.M{"email": "used@test.com"}, options.Find().SetHint("email_1"))
+		require.NoError(t, err)
+
+		stats, err := client.IndexUsageStats(ctx, "usage_users")
+		require.NoError(t, err)
+		assert.Len(t, stats, 3)
+
+		for _, s := range stats {
+			if s.Name == "email_1" {
+				assert.Positive(t, s.Accesses.Ops)
+				assert.False(t, s.Accesses.Since.IsZero())
+			}
+		}
+		assert.Equal(t, []string{"age_1"}, UnusedIndexes(stats))
+	})
+
+	t.Run("IndexBuilder models are accepted by the server", func(t *testing.T) {
+		_, err := client.CreateIndexes(ctx, "builder_users", []mongo.IndexModel{
+			NewIndexBuilder().Key("email", 1).Unique().PartialFilter(bson.M{"active": true}).Build(),
+			NewIndexBuilder().Key("age", 1).Hidden().Build(),
+			NewIndexBuilder().Key("expires_at", 1).TTL(time.Hour).Build(),
+			NewIndexBuilder().Wildcard("attributes").Build(),
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"_id_", "email_1", "age_1", "expires_at_1", "attributes.$**_1"}, indexNames(t, "builder_users"))
+
+		// Partial unique index only applies to active documents
+		repo := NewRepository[User](client, "builder_users")
+		_, err = repo.Create(ctx, User{Name: "A", Email: "dup@test.com", Active: false})
+		require.NoError(t, err)
+		_, err = repo.Create(ctx, User{Name: "B", Email: "dup@test.com", Active: false})
+		require.NoError(t, err)
+	})
+}
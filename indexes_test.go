@@ -0,0 +1,325 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestIndexModelName(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    mongo.IndexModel
+		expected string
+		errorMsg string
+	}{
+		{
+			name:     "explicit name",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetName("email_unique")},
+			expected: "email_unique",
+		},
+		{
+			name:     "generated single key",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "email", Value: 1}}},
+			expected: "email_1",
+		},
+		{
+			name:     "generated compound key",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}},
+			expected: "status_1_created_at_-1",
+		},
+		{
+			name:     "generated text key",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "title", Value: "text"}}},
+			expected: "title_text",
+		},
+		{
+			name:     "empty keys",
+			model:    mongo.IndexModel{Keys: bson.D{}},
+			errorMsg: "index keys cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, err := indexModelName(&tt.model)
+
+			if tt.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, name)
+		})
+	}
+}
+
+func TestIndexMatches(t *testing.T) {
+	mustMarshal := func(v any) bson.Raw {
+		data, err := bson.Marshal(v)
+		require.NoError(t, err)
+		return data
+	}
+
+	existing := mustMarshal(bson.D{
+		{Key: "v", Value: 2},
+		{Key: "key", Value: bson.D{{Key: "expires_at", Value: 1.0}}},
+		{Key: "name", Value: "expires_at_1"},
+		{Key: "unique", Value: true},
+		{Key: "expireAfterSeconds", Value: 60},
+	})
+
+	tests := []struct {
+		name     string
+		model    mongo.IndexModel
+		expected bool
+	}{
+		{
+			name:     "same keys and options",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetUnique(true).SetExpireAfterSeconds(60)},
+			expected: true,
+		},
+		{
+			name:     "different direction",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: -1}}, Options: options.Index().SetUnique(true).SetExpireAfterSeconds(60)},
+			expected: false,
+		},
+		{
+			name:     "different unique",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(60)},
+			expected: false,
+		},
+		{
+			name:     "different TTL",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetUnique(true).SetExpireAfterSeconds(120)},
+			expected: false,
+		},
+		{
+			name:     "missing TTL",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetUnique(true)},
+			expected: false,
+		},
+		{
+			name:     "added hidden",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetUnique(true).SetExpireAfterSeconds(60).SetHidden(true)},
+			expected: false,
+		},
+		{
+			name:     "added partial filter",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetUnique(true).SetExpireAfterSeconds(60).SetPartialFilterExpression(bson.M{"active": true})},
+			expected: false,
+		},
+		{
+			name:     "added collation",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetUnique(true).SetExpireAfterSeconds(60).SetCollation(&options.Collation{Locale: "en"})},
+			expected: false,
+		},
+		{
+			name:     "simple collation",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetUnique(true).SetExpireAfterSeconds(60).SetCollation(&options.Collation{Locale: "simple"})},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, indexMatches(&tt.model, existing))
+		})
+	}
+}
+
+func TestIndexMatches_Options(t *testing.T) {
+	mustMarshal := func(v any) bson.Raw {
+		data, err := bson.Marshal(v)
+		require.NoError(t, err)
+		return data
+	}
+
+	tests := []struct {
+		name     string
+		model    mongo.IndexModel
+		existing bson.D
+		expected bool
+	}{
+		{
+			name:  "same partial filter in another field order",
+			model: NewIndexBuilder().Key("email", 1).PartialFilter(bson.D{{Key: "active", Value: true}, {Key: "age", Value: bson.M{"$gte": 18}}}).Build(),
+			existing: bson.D{{Key: "key", Value: bson.D{{Key: "email", Value: 1}}}, {Key: "partialFilterExpression", Value: bson.D{
+				{Key: "age", Value: bson.D{{Key: "$gte", Value: int64(18)}}}, {Key: "active", Value: true},
+			}}},
+			expected: true,
+		},
+		{
+			name:     "different partial filter",
+			model:    NewIndexBuilder().Key("email", 1).PartialFilter(bson.M{"active": true}).Build(),
+			existing: bson.D{{Key: "key", Value: bson.D{{Key: "email", Value: 1}}}, {Key: "partialFilterExpression", Value: bson.D{{Key: "active", Value: false}}}},
+			expected: false,
+		},
+		{
+			name:     "removed partial filter",
+			model:    NewIndexBuilder().Key("email", 1).Build(),
+			existing: bson.D{{Key: "key", Value: bson.D{{Key: "email", Value: 1}}}, {Key: "partialFilterExpression", Value: bson.D{{Key: "active", Value: true}}}},
+			expected: false,
+		},
+		{
+			name:     "same hidden",
+			model:    NewIndexBuilder().Key("age", 1).Hidden().Build(),
+			existing: bson.D{{Key: "key", Value: bson.D{{Key: "age", Value: 1}}}, {Key: "hidden", Value: true}},
+			expected: true,
+		},
+		{
+			name:     "removed hidden",
+			model:    NewIndexBuilder().Key("age", 1).Build(),
+			existing: bson.D{{Key: "key", Value: bson.D{{Key: "age", Value: 1}}}, {Key: "hidden", Value: true}},
+			expected: false,
+		},
+		{
+			name:  "collation with server defaults",
+			model: mongo.IndexModel{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetCollation(&options.Collation{Locale: "en", Strength: 2})},
+			existing: bson.D{{Key: "key", Value: bson.D{{Key: "name", Value: 1}}}, {Key: "collation", Value: bson.D{
+				{Key: "locale", Value: "en"}, {Key: "caseLevel", Value: false}, {Key: "strength", Value: 2}, {Key: "version", Value: "57.1"},
+			}}},
+			expected: true,
+		},
+		{
+			name:     "different collation",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetCollation(&options.Collation{Locale: "fr"})},
+			existing: bson.D{{Key: "key", Value: bson.D{{Key: "name", Value: 1}}}, {Key: "collation", Value: bson.D{{Key: "locale", Value: "en"}, {Key: "strength", Value: 3}}}},
+			expected: false,
+		},
+		{
+			name:     "removed collation",
+			model:    mongo.IndexModel{Keys: bson.D{{Key: "name", Value: 1}}},
+			existing: bson.D{{Key: "key", Value: bson.D{{Key: "name", Value: 1}}}, {Key: "collation", Value: bson.D{{Key: "locale", Value: "en"}}}},
+			expected: false,
+		},
+		{
+			name:     "same wildcard projection",
+			model:    NewIndexBuilder().Wildcard("").WildcardProjection(bson.M{"secret": 0}).Build(),
+			existing: bson.D{{Key: "key", Value: bson.D{{Key: "$**", Value: 1}}}, {Key: "wildcardProjection", Value: bson.D{{Key: "secret", Value: 0}}}},
+			expected: true,
+		},
+		{
+			name:     "different wildcard projection",
+			model:    NewIndexBuilder().Wildcard("").WildcardProjection(bson.M{"secret": 0}).Build(),
+			existing: bson.D{{Key: "key", Value: bson.D{{Key: "$**", Value: 1}}}, {Key: "wildcardProjection", Value: bson.D{{Key: "token", Value: 0}}}},
+			expected: false,
+		},
+		{
+			name:  "text index with default weights",
+			model: NewIndexBuilder().Key("category", 1).Text("title").Text("body").Build(),
+			existing: bson.D{
+				{Key: "key", Value: bson.D{{Key: "category", Value: 1}, {Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: 1}}},
+				{Key: "weights", Value: bson.D{{Key: "body", Value: 1}, {Key: "title", Value: 1}}},
+			},
+			expected: true,
+		},
+		{
+			name:  "text index with declared weights",
+			model: mongo.IndexModel{Keys: bson.D{{Key: "title", Value: "text"}, {Key: "body", Value: "text"}}, Options: options.Index().SetWeights(bson.M{"title": 10})},
+			existing: bson.D{
+				{Key: "key", Value: bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: 1}}},
+				{Key: "weights", Value: bson.D{{Key: "body", Value: 1}, {Key: "title", Value: 10}}},
+			},
+			expected: true,
+		},
+		{
+			name:  "text index with changed weights",
+			model: NewIndexBuilder().Text("title").Text("body").Build(),
+			existing: bson.D{
+				{Key: "key", Value: bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: 1}}},
+				{Key: "weights", Value: bson.D{{Key: "body", Value: 1}, {Key: "title", Value: 10}}},
+			},
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, indexMatches(&tt.model, mustMarshal(tt.existing)))
+		})
+	}
+}
+
+func TestIndexSyncPlan_HasChanges(t *testing.T) {
+	assert.False(t, (&IndexSyncPlan{}).HasChanges())
+	assert.True(t, (&IndexSyncPlan{Changes: []IndexChange{{Name: "email_1", Action: IndexActionCreate}}}).HasChanges())
+}
+
+func TestClient_SyncIndexes_ClosedClient(t *testing.T) {
+	client := &Client{closed: true}
+
+	_, err := client.SyncIndexes(context.Background(), map[string][]mongo.IndexModel{}, SyncOptions{})
+	assert.ErrorIs(t, err, ErrClientClosed)
+}
+
+func TestUnusedIndexes(t *testing.T) {
+	stats := []IndexUsage{
+		{Name: "_id_", Host: "a", Accesses: IndexAccesses{Ops: 0}},
+		{Name: "email_1", Host: "a", Accesses: IndexAccesses{Ops: 10}},
+		{Name: "status_1", Host: "a", Accesses: IndexAccesses{Ops: 0}},
+		{Name: "status_1", Host: "b", Accesses: IndexAccesses{Ops: 3}},
+		{Name: "name_1", Host: "a", Accesses: IndexAccesses{Ops: 0}},
+		{Name: "age_1", Host: "a", Accesses: IndexAccesses{Ops: 0}},
+	}
+
+	assert.Equal(t, []string{"age_1", "name_1"}, UnusedIndexes(stats))
+	assert.Empty(t, UnusedIndexes(nil))
+}
+
+func TestIndexBuilder(t *testing.T) {
+	t.Run("keys and options", func(t *testing.T) {
+		model := NewIndexBuilder().
+			Key("email", 1).
+			Key("created_at", -1).
+			Name("email_created").
+			Unique().
+			Sparse().
+			Hidden().
+			PartialFilter(bson.M{"active": true}).
+			Build()
+
+		assert.Equal(t, bson.D{{Key: "email", Value: 1}, {Key: "created_at", Value: -1}}, model.Keys)
+		require.NotNil(t, model.Options)
+		assert.Equal(t, "email_created", *model.Options.Name)
+		assert.True(t, *model.Options.Unique)
+		assert.True(t, *model.Options.Sparse)
+		assert.True(t, *model.Options.Hidden)
+		assert.Equal(t, bson.M{"active": true}, model.Options.PartialFilterExpression)
+	})
+
+	t.Run("TTL", func(t *testing.T) {
+		model := NewIndexBuilder().Key("expires_at", 1).TTL(2 * time.Hour).Build()
+		assert.Equal(t, int32(7200), *model.Options.ExpireAfterSeconds)
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		model := NewIndexBuilder().Text("title").Text("body").Build()
+		assert.Equal(t, bson.D{{Key: "title", Value: "text"}, {Key: "body", Value: "text"}}, model.Keys)
+	})
+
+	t.Run("Wildcard on path", func(t *testing.T) {
+		model := NewIndexBuilder().Wildcard("attributes").Build()
+		assert.Equal(t, bson.D{{Key: "attributes.$**", Value: 1}}, model.Keys)
+	})
+
+	t.Run("Wildcard on all fields with projection", func(t *testing.T) {
+		model := NewIndexBuilder().Wildcard("").WildcardProjection(bson.M{"secret": 0}).Build()
+		assert.Equal(t, bson.D{{Key: "$**", Value: 1}}, model.Keys)
+		assert.Equal(t, bson.M{"secret": 0}, model.Options.WildcardProjection)
+	})
+
+	t.Run("generated name", func(t *testing.T) {
+		model := NewIndexBuilder().Key("status", 1).Key("age", -1).Build()
+		name, err := indexModelName(&model)
+		require.NoError(t, err)
+		assert.Equal(t, "status_1_age_-1", name)
+	})
+}
@@ -0,0 +1,113 @@
+package mongo_kit
+
+import (
+	"context"
+	"iter"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Iterator provides typed, incremental access to the results of a cursor.
+// Results are decoded one at a time, so arbitrarily large result sets can be
+// consumed with constant memory. An Iterator is not safe for concurrent use and
+// must be closed when no longer needed.
+//
+// Example:
+//
+//	it, err := repo.AggregateIter(ctx, pipeline)
+//	if err != nil {
+//	    return err
+//	}
+//	defer it.Close(ctx)
+//
+//	for it.Next(ctx) {
+//	    process(it.Current())
+//	}
+//	if err := it.Err(); err != nil {
+//	    return err
+//	}
+type Iterator[T any] struct {
+	cursor  *mongo.Cursor
+	op      string
+	decrypt func(ctx context.Context, document *T) error // Decrypts each result; nil without field encryption
+	current T
+	err     error
+}
+
+// newIterator wraps cursor in a typed Iterator. The op name is used in decode errors.
+func newIterator[T any](cursor *mongo.Cursor, op string) *Iterator[T] {
+	return &Iterator[T]{cursor: cursor, op: op}
+}
+
+// Next advances the iterator to the next result and decodes it.
+// Returns false when the results are exhausted or an error occurred; check Err afterwards.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	if !it.cursor.Next(ctx) {
+		if err := it.cursor.Err(); err != nil {
+			it.err = newOperationError(it.op, err)
+		}
+		return false
+	}
+
+	var current T
+	if err := it.cursor.Decode(&current); err != nil {
+		it.err = newOperationError(it.op+" decode", err)
+		return false
+	}
+	if it.decrypt != nil {
+		if err := it.decrypt(ctx, &current); err != nil {
+			it.err = err
+			return false
+		}
+	}
+	it.current = current
+
+	return true
+}
+
+// Current returns the result decoded by the last successful call to Next.
+func (it *Iterator[T]) Current() T {
+	return it.current
+}
+
+// Err returns the first error encountered during iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Close closes the underlying cursor. Calling Close multiple times is safe.
+func (it *Iterator[T]) Close(ctx context.Context) error {
+	return it.cursor.Close(ctx)
+}
+
+// All returns a range-over-func sequence of the remaining results.
+// Iteration stops at the first error, which is yielded with a zero value.
+// The iterator is closed when the sequence ends or the loop exits early.
+//
+// Example:
+//
+//	for doc, err := range it.All(ctx) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    process(doc)
+//	}
+func (it *Iterator[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer func() { _ = it.Close(ctx) }()
+
+		for it.Next(ctx) {
+			if !yield(it.current, nil) {
+				return
+			}
+		}
+		if it.err != nil {
+			var zero T
+			yield(zero, it.err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type iteratorDoc struct {
+	Name string `bson:"name"`
+	Age  int    `bson:"age"`
+}
+
+func newTestIterator[T any](t *testing.T, docs ...any) *Iterator[T] {
+	t.Helper()
+	cursor, err := mongo.NewCursorFromDocuments(docs, nil, nil)
+	require.NoError(t, err)
+	return newIterator[T](cursor, "aggregate")
+}
+
+func TestIterator_Next(t *testing.T) {
+	ctx := context.Background()
+	it := newTestIterator[iteratorDoc](t, bson.M{"name": "A", "age": 1}, bson.M{"name": "B", "age": 2})
+	defer func() { _ = it.Close(ctx) }()
+
+	var names []string
+	for it.Next(ctx) {
+		names = append(names, it.Current().Name)
+	}
+
+	require.NoError(t, it.Err())
+	assert.Equal(t, []string{"A", "B"}, names)
+	assert.False(t, it.Next(ctx))
+}
+
+func TestIterator_DecodeError(t *testing.T) {
+	ctx := context.Background()
+	it := newTestIterator[iteratorDoc](t, bson.M{"name": 123})
+	defer func() { _ = it.Close(ctx) }()
+
+	assert.False(t, it.Next(ctx))
+
+	var opErr *OperationError
+	require.ErrorAs(t, it.Err(), &opErr)
+	assert.Equal(t, "aggregate decode", opErr.Op)
+	assert.False(t, it.Next(ctx))
+}
+
+func TestIterator_CursorError(t *testing.T) {
+	ctx := context.Background()
+	cursorErr := errors.New("cursor failed")
+	cursor, err := mongo.NewCursorFromDocuments(nil, cursorErr, nil)
+	require.NoError(t, err)
+	it := newIterator[iteratorDoc](cursor, "aggregate")
+
+	assert.False(t, it.Next(ctx))
+	assert.ErrorIs(t, it.Err(), cursorErr)
+}
+
+func TestIterator_All(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("yields all results", func(t *testing.T) {
+		it := newTestIterator[iteratorDoc](t, bson.M{"name": "A"}, bson.M{"name": "B"}, bson.M{"name": "C"})
+
+		var names []string
+		for doc, err := range it.All(ctx) {
+			require.NoError(t, err)
+			names = append(names, doc.Name)
+		}
+		assert.Equal(t, []string{"A", "B", "C"}, names)
+	})
+
+	t.Run("stops early", func(t *testing.T) {
+		it := newTestIterator[iteratorDoc](t, bson.M{"name": "A"}, bson.M{"name": "B"})
+
+		count := 0
+		for range it.All(ctx) {
+			count++
+			break
+		}
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("yields decode error", func(t *testing.T) {
+		it := newTestIterator[iteratorDoc](t, bson.M{"name": "A"}, bson.M{"age": "invalid"})
+
+		var errs []error
+		for _, err := range it.All(ctx) {
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+		require.Len(t, errs, 1)
+		var opErr *OperationError
+		assert.ErrorAs(t, errs[0], &opErr)
+	})
+}
+
+func TestClient_AggregateIter_ClosedClient(t *testing.T) {
+	client := &Client{closed: true}
+
+	_, err := client.AggregateIter(context.Background(), "users", []bson.M{})
+	assert.ErrorIs(t, err, ErrClientClosed)
+
+	repo := NewRepository[User](client, "users")
+	_, err = repo.AggregateIter(context.Background(), []bson.M{})
+	assert.ErrorIs(t, err, ErrClientClosed)
+}
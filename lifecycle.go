@@ -0,0 +1,63 @@
+package mongo_kit
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Lifecycle
+//
+// This file provides helpers that tie the client lifetime to the process lifetime.
+
+// CloseOnSignal closes client gracefully when the process receives one of signals
+// (default: SIGINT and SIGTERM). In-flight operations finish before the client closes,
+// and drainTimeout bounds how long Close waits for connections to be returned.
+//
+// The returned channel receives the result of Close and is then closed. Call stop to
+// stop listening without closing the client; the channel is then closed without a value.
+//
+// Example:
+//
+//	done, stop := mongo_kit.CloseOnSignal(client, 10*time.Second)
+//	defer stop()
+//	go server.ListenAndServe()
+//	if err := <-done; err != nil {
+//	    log.Printf("closing mongo: %v", err)
+//	}
+func CloseOnSignal(client *Client, drainTimeout time.Duration, signals ...os.Signal) (done <-chan error, stop func()) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, signals...)
+
+	result := make(chan error, 1)
+	stopCh := make(chan struct{})
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			signal.Stop(sigCh)
+			close(stopCh)
+		})
+	}
+
+	go func() {
+		defer close(result)
+
+		select {
+		case <-sigCh:
+			signal.Stop(sigCh)
+			ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+			defer cancel()
+			result <- client.Close(ctx)
+		case <-stopCh:
+		}
+	}()
+
+	return result, stop
+}
@@ -0,0 +1,44 @@
+//go:build unix
+
+package mongo_kit
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloseOnSignal(t *testing.T) {
+	t.Run("closes client on signal", func(t *testing.T) {
+		client := &Client{closed: true}
+		done, stop := CloseOnSignal(client, time.Second, syscall.SIGUSR1)
+		defer stop()
+
+		require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+
+		select {
+		case err, ok := <-done:
+			assert.True(t, ok)
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("client was not closed")
+		}
+	})
+
+	t.Run("stop closes channel without closing client", func(t *testing.T) {
+		client := &Client{closed: true}
+		done, stop := CloseOnSignal(client, time.Second, syscall.SIGUSR2)
+		stop()
+		stop()
+
+		select {
+		case _, ok := <-done:
+			assert.False(t, ok)
+		case <-time.After(5 * time.Second):
+			t.Fatal("channel was not closed")
+		}
+	})
+}
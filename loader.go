@@ -0,0 +1,227 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Batch Loader
+//
+// This file provides a dataloader-style Loader that collects the lookups by key made
+// within a short window, such as those of GraphQL resolvers, into a single $in query,
+// solving N+1 query patterns.
+//
+// See docs/repository.md for detailed usage guide and examples.
+
+// Default Loader settings used when LoaderOptions leaves them unset.
+const (
+	DefaultLoaderWait     = time.Millisecond
+	DefaultLoaderMaxBatch = 100
+)
+
+// LoaderOptions configures a Loader.
+type LoaderOptions struct {
+	Field    string        // Unique field the keys are matched against (default: "_id")
+	Wait     time.Duration // Time a batch collects keys before its query runs (default: 1ms)
+	MaxBatch int           // Maximum number of keys per query; a full batch runs at once (default: 100)
+}
+
+// Loader batches and de-duplicates lookups by key of a repository. Keys requested
+// within the Wait window are loaded by one query matching Field with $in, and every
+// document is cached for the lifetime of the Loader, so create one Loader per request.
+// Loaders are safe for concurrent use.
+type Loader[K comparable, T any] struct {
+	repo *Repository[T]
+	opts LoaderOptions
+
+	mu      sync.Mutex
+	cache   map[K]*loaderResult[T]
+	pending *loaderBatch[K, T]
+}
+
+// loaderResult is the outcome of loading one key, available once done is closed.
+type loaderResult[T any] struct {
+	done     chan struct{}
+	document *T
+	err      error
+}
+
+// loaderBatch is a set of keys loaded by one query.
+type loaderBatch[K comparable, T any] struct {
+	ctx     context.Context
+	keys    []K
+	results map[K]*loaderResult[T]
+	timer   *time.Timer
+}
+
+// NewLoader returns a Loader of the documents of repo by key. The key type must match
+// the stored values of the field, e.g. primitive.ObjectID for generated _ids.
+//
+// Example:
+//
+//	// In a request-scoped GraphQL context
+//	authors := mongo_kit.NewLoader[primitive.ObjectID](authorRepo, mongo_kit.LoaderOptions{})
+//
+//	// In the Post.author resolver, called once per post
+//	author, err := authors.Load(ctx, post.AuthorID)
+func NewLoader[K comparable, T any](repo *Repository[T], opts LoaderOptions) *Loader[K, T] {
+	if opts.Field == "" {
+		opts.Field = "_id"
+	}
+	if opts.Wait <= 0 {
+		opts.Wait = DefaultLoaderWait
+	}
+	if opts.MaxBatch <= 0 {
+		opts.MaxBatch = DefaultLoaderMaxBatch
+	}
+	return &Loader[K, T]{
+		repo:  repo,
+		opts:  opts,
+		cache: make(map[K]*loaderResult[T]),
+	}
+}
+
+// Load returns the document with key, waiting for the batch that loads it. Returns
+// ErrNotFound if no document has the key. The query of a batch runs with the context
+// of its first Load; failed lookups are not cached, so they can be retried.
+func (l *Loader[K, T]) Load(ctx context.Context, key K) (*T, error) {
+	result := l.enqueue(ctx, key)
+	select {
+	case <-result.done:
+		return result.document, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// LoadMany returns the documents with keys, in the order of keys, loaded with the
+// same batches as Load. Keys without a document have a nil entry; other errors are
+// returned.
+func (l *Loader[K, T]) LoadMany(ctx context.Context, keys []K) ([]*T, error) {
+	results := make([]*loaderResult[T], len(keys))
+	for i, key := range keys {
+		results[i] = l.enqueue(ctx, key)
+	}
+
+	documents := make([]*T, len(keys))
+	for i, result := range results {
+		select {
+		case <-result.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		switch {
+		case result.err == nil:
+			documents[i] = result.document
+		case !errors.Is(result.err, ErrNotFound):
+			return nil, result.err
+		}
+	}
+	return documents, nil
+}
+
+// Clear removes key from the cache, so the next Load queries it again, e.g. after
+// the document was updated.
+func (l *Loader[K, T]) Clear(key K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.cache, key)
+}
+
+// enqueue returns the cached or pending result of key, adding key to the pending
+// batch when it was never requested.
+func (l *Loader[K, T]) enqueue(ctx context.Context, key K) *loaderResult[T] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if result, ok := l.cache[key]; ok {
+		return result
+	}
+
+	result := &loaderResult[T]{done: make(chan struct{})}
+	l.cache[key] = result
+
+	batch := l.pending
+	if batch == nil {
+		batch = &loaderBatch[K, T]{ctx: ctx, results: make(map[K]*loaderResult[T])}
+		batch.timer = time.AfterFunc(l.opts.Wait, func() { l.dispatch(batch) })
+		l.pending = batch
+	}
+	batch.keys = append(batch.keys, key)
+	batch.results[key] = result
+
+	if len(batch.keys) >= l.opts.MaxBatch {
+		l.pending = nil
+		if batch.timer.Stop() {
+			go l.dispatch(batch)
+		}
+	}
+	return result
+}
+
+// dispatch runs the query of batch and completes its results.
+func (l *Loader[K, T]) dispatch(batch *loaderBatch[K, T]) {
+	l.mu.Lock()
+	if l.pending == batch {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+
+	documents, err := l.query(batch.ctx, batch.keys)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, result := range batch.results {
+		switch document, ok := documents[key]; {
+		case err != nil:
+			result.err = err
+			if l.cache[key] == result {
+				delete(l.cache, key)
+			}
+		case ok:
+			result.document = document
+		default:
+			result.err = ErrNotFound
+		}
+		close(result.done)
+	}
+}
+
+// query loads the documents with keys by key.
+func (l *Loader[K, T]) query(ctx context.Context, keys []K) (map[K]*T, error) {
+	r := l.repo
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var raws []bson.Raw
+	filter := bson.D{{Key: l.opts.Field, Value: bson.D{{Key: "$in", Value: keys}}}}
+	if err := r.client.find(ctx, r.collection, filter, &raws); err != nil {
+		return nil, err
+	}
+
+	registry := r.client.bsonRegistry()
+	documents := make(map[K]*T, len(raws))
+	for _, raw := range raws {
+		var key K
+		if err := raw.Lookup(strings.Split(l.opts.Field, ".")...).UnmarshalWithRegistry(registry, &key); err != nil {
+			return nil, newOperationError("load decode", fmt.Errorf("%s: %w", l.opts.Field, err))
+		}
+		var document T
+		if err := bson.UnmarshalWithRegistry(registry, raw, &document); err != nil {
+			return nil, newOperationError("load decode", err)
+		}
+		if err := r.decrypt(ctx, &document); err != nil {
+			return nil, err
+		}
+		documents[key] = &document
+	}
+	return documents, nil
+}
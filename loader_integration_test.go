@@ -0,0 +1,69 @@
+package mongo_kit
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestLoader_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	var mu sync.Mutex
+	var finds int
+	monitor := &event.CommandMonitor{Started: func(_ context.Context, e *event.CommandStartedEvent) {
+		if e.CommandName == "find" {
+			mu.Lock()
+			finds++
+			mu.Unlock()
+		}
+	}}
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database),
+		WithClientOptions(options.Client().SetMonitor(monitor)))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := NewRepository[User](client, "users")
+	ids, err := repo.CreateMany(ctx, []User{{Name: "Ann"}, {Name: "Bob"}, {Name: "Cid"}})
+	require.NoError(t, err)
+
+	loader := NewLoader[primitive.ObjectID](repo, LoaderOptions{})
+	keys := []primitive.ObjectID{ids[0].(primitive.ObjectID), ids[1].(primitive.ObjectID), ids[0].(primitive.ObjectID), primitive.NewObjectID()}
+	users := make([]*User, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			users[i], errs[i] = loader.Load(ctx, key)
+		}()
+	}
+	wg.Wait()
+
+	require.NoError(t, errs[0])
+	assert.Equal(t, "Ann", users[0].Name)
+	assert.Equal(t, "Bob", users[1].Name)
+	assert.ErrorIs(t, errs[3], ErrNotFound)
+	assert.Equal(t, 1, finds, "keys are loaded with one query")
+
+	byName := NewLoader[string](repo, LoaderOptions{Field: "name"})
+	found, err := byName.LoadMany(ctx, []string{"Cid", "Dee"})
+	require.NoError(t, err)
+	assert.Equal(t, ids[2], found[0].ID)
+	assert.Nil(t, found[1])
+}
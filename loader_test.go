@@ -0,0 +1,126 @@
+package mongo_kit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type loaderUser struct {
+	ID   string `bson:"_id"`
+	Name string `bson:"name"`
+}
+
+func newLoaderRepository(t *testing.T) (*Repository[loaderUser], *testhelpers.MockClient) {
+	t.Helper()
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	return NewRepository[loaderUser](client, "users"), mock
+}
+
+func TestNewLoader_Defaults(t *testing.T) {
+	repo, _ := newLoaderRepository(t)
+	loader := NewLoader[string](repo, LoaderOptions{})
+	assert.Equal(t, LoaderOptions{Field: "_id", Wait: DefaultLoaderWait, MaxBatch: DefaultLoaderMaxBatch}, loader.opts)
+}
+
+func TestLoader_BatchesAndDeduplicates(t *testing.T) {
+	repo, mock := newLoaderRepository(t)
+	loader := NewLoader[string](repo, LoaderOptions{Wait: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	// A single reply: a second query would fail with no responses remaining
+	mock.AddResponses(testhelpers.CursorResponse("testdb.users",
+		bson.D{{Key: "_id", Value: "a"}, {Key: "name", Value: "Ann"}},
+		bson.D{{Key: "_id", Value: "b"}, {Key: "name", Value: "Bob"}},
+	))
+
+	keys := []string{"a", "b", "a", "missing"}
+	users := make([]*loaderUser, len(keys))
+	errs := make([]error, len(keys))
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			users[i], errs[i] = loader.Load(ctx, key)
+		}()
+	}
+	wg.Wait()
+
+	require.NoError(t, errs[0])
+	assert.Equal(t, "Ann", users[0].Name)
+	assert.Equal(t, "Bob", users[1].Name)
+	assert.Same(t, users[0], users[2])
+	assert.ErrorIs(t, errs[3], ErrNotFound)
+
+	// Cached for the lifetime of the loader
+	cached, err := loader.Load(ctx, "b")
+	require.NoError(t, err)
+	assert.Same(t, users[1], cached)
+
+	many, err := loader.LoadMany(ctx, []string{"missing", "a"})
+	require.NoError(t, err)
+	assert.Nil(t, many[0])
+	assert.Same(t, users[0], many[1])
+}
+
+func TestLoader_MaxBatch(t *testing.T) {
+	repo, mock := newLoaderRepository(t)
+	loader := NewLoader[string](repo, LoaderOptions{Wait: time.Hour, MaxBatch: 2})
+
+	// The two batches run concurrently, so both get the same reply
+	all := testhelpers.CursorResponse("testdb.users",
+		bson.D{{Key: "_id", Value: "a"}}, bson.D{{Key: "_id", Value: "b"}},
+		bson.D{{Key: "_id", Value: "c"}}, bson.D{{Key: "_id", Value: "d"}},
+	)
+	mock.AddResponses(all, all)
+	users, err := loader.LoadMany(context.Background(), []string{"a", "b", "c", "d"})
+	require.NoError(t, err)
+	require.Len(t, users, 4)
+	for i, id := range []string{"a", "b", "c", "d"} {
+		assert.Equal(t, id, users[i].ID)
+	}
+}
+
+func TestLoader_ErrorsAreNotCached(t *testing.T) {
+	repo, mock := newLoaderRepository(t)
+	loader := NewLoader[string](repo, LoaderOptions{})
+	ctx := context.Background()
+
+	mock.AddResponses(testhelpers.CommandErrorResponse(2, "BadValue", "boom"))
+	_, err := loader.Load(ctx, "a")
+	require.Error(t, err)
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.users", bson.D{{Key: "_id", Value: "a"}, {Key: "name", Value: "Ann"}}))
+	user, err := loader.Load(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "Ann", user.Name)
+
+	loader.Clear("a")
+	mock.AddResponses(testhelpers.CursorResponse("testdb.users", bson.D{{Key: "_id", Value: "a"}, {Key: "name", Value: "Anna"}}))
+	user, err = loader.Load(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "Anna", user.Name)
+
+	_, err = loader.LoadMany(ctx, []string{"b"})
+	assert.Error(t, err, "no response left for the query")
+}
+
+func TestLoader_ContextCanceled(t *testing.T) {
+	repo, _ := newLoaderRepository(t)
+	loader := NewLoader[string](repo, LoaderOptions{Wait: time.Hour})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := loader.Load(ctx, "a")
+	assert.ErrorIs(t, err, context.Canceled)
+}
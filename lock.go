@@ -0,0 +1,166 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Distributed Locks
+//
+// This file provides leases stored in a collection, so cron jobs and singletons
+// running in several processes can coordinate through MongoDB.
+//
+// See docs/coordination.md for detailed usage guide and examples.
+
+// Locker acquires, renews and releases named locks stored in a collection, one
+// document per held lock. Locks expire after their TTL, so a crashed holder cannot
+// block others forever; a TTL index removes expired lock documents.
+type Locker struct {
+	client     *Client
+	collection string
+	index      lazyIndex
+}
+
+// Lock is a lock held by this process. Keep it to Renew or Release the lock.
+type Lock struct {
+	Key       string    // Name of the lock
+	Token     string    // Identifies this holder; other holders of Key have a different token
+	ExpiresAt time.Time // Time after which other processes can acquire the lock
+}
+
+// NewLocker returns a Locker storing locks in collection of the client's database.
+//
+// Example:
+//
+//	locker := mongo_kit.NewLocker(client, "locks")
+func NewLocker(client *Client, collection string) *Locker {
+	return &Locker{client: client, collection: collection}
+}
+
+// Acquire takes the lock named key for ttl. It returns ErrLockHeld if another holder
+// owns an unexpired lock; it does not wait for the lock to be released.
+//
+// Expiry uses the client's clock, so the clocks of the processes sharing a lock
+// should be synchronized; choose a ttl well above the expected clock skew.
+//
+// Example:
+//
+//	lock, err := locker.Acquire(ctx, "nightly-report", time.Minute)
+//	if errors.Is(err, mongo_kit.ErrLockHeld) {
+//	    return nil // another instance runs the job
+//	}
+//	if err != nil {
+//	    return err
+//	}
+//	defer locker.Release(context.Background(), lock)
+func (l *Locker) Acquire(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	if key == "" || ttl <= 0 {
+		return nil, newOperationError("acquire lock", errors.New("key and a positive ttl are required"))
+	}
+	if err := l.index.ensure(ctx, l.client, l.collection, expiryIndex("expires_at")); err != nil {
+		return nil, err
+	}
+
+	now := l.client.Now()
+	lock := &Lock{Key: key, Token: primitive.NewObjectID().Hex(), ExpiresAt: now.Add(ttl)}
+	if err := l.client.acquireLock(ctx, l.collection, lock, now); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Renew extends lock to ttl from now. It returns ErrLockLost if the lock expired or
+// was taken by another holder, in which case the caller should stop its work.
+//
+// Example:
+//
+//	ticker := time.NewTicker(20 * time.Second)
+//	for range ticker.C {
+//	    if err := locker.Renew(ctx, lock, time.Minute); err != nil {
+//	        cancel() // lost the lock
+//	        return
+//	    }
+//	}
+func (l *Locker) Renew(ctx context.Context, lock *Lock, ttl time.Duration) error {
+	if lock == nil || ttl <= 0 {
+		return newOperationError("renew lock", errors.New("lock and a positive ttl are required"))
+	}
+
+	now := l.client.Now()
+	expiresAt := now.Add(ttl)
+	filter := bson.D{
+		{Key: "_id", Value: lock.Key},
+		{Key: "token", Value: lock.Token},
+		{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: now}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "expires_at", Value: expiresAt}}}}
+	result, err := l.client.updateOne(ctx, l.collection, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%w: %s", ErrLockLost, lock.Key)
+	}
+
+	lock.ExpiresAt = expiresAt
+	return nil
+}
+
+// Release releases lock so other processes can acquire it immediately. It returns
+// ErrLockLost if the lock is no longer held: it expired, was taken by another holder
+// or was already released.
+func (l *Locker) Release(ctx context.Context, lock *Lock) error {
+	if lock == nil {
+		return newOperationError("release lock", errors.New("lock is required"))
+	}
+
+	filter := bson.D{{Key: "_id", Value: lock.Key}, {Key: "token", Value: lock.Token}}
+	result, err := l.client.deleteOne(ctx, l.collection, filter)
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("%w: %s", ErrLockLost, lock.Key)
+	}
+	return nil
+}
+
+// acquireLock stores lock as the holder of lock.Key if the key is free or its lock
+// expired at now. A held lock is not an operation failure, so ErrLockHeld is not
+// reported to the error hook.
+func (c *Client) acquireLock(ctx context.Context, collection string, lock *Lock, now time.Time) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	// An expired lock is taken over by the update. An unexpired one does not match the
+	// filter, so the upsert inserts a second document with the same _id and fails.
+	filter := bson.D{{Key: "_id", Value: lock.Key}, {Key: "expires_at", Value: bson.D{{Key: "$lte", Value: now}}}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "token", Value: lock.Token},
+		{Key: "expires_at", Value: lock.ExpiresAt},
+		{Key: "acquired_at", Value: now},
+	}}}
+
+	start := time.Now()
+	coll := c.getCollection(collection)
+	err := coll.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetUpsert(true)).Err()
+	switch {
+	case err == nil, errors.Is(err, mongo.ErrNoDocuments):
+		return nil
+	case mongo.IsDuplicateKeyError(err):
+		return fmt.Errorf("%w: %s", ErrLockHeld, lock.Key)
+	default:
+		return c.collectionError(ctx, start, "acquire lock", collection, err, filter, update)
+	}
+}
@@ -0,0 +1,79 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestLocker_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	clock := testhelpers.NewFakeClock(time.Now())
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database), WithClock(clock))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	locker := NewLocker(client, database)
+	other := NewLocker(client, database)
+
+	t.Run("only one holder at a time", func(t *testing.T) {
+		lock, err := locker.Acquire(ctx, "exclusive", time.Minute)
+		require.NoError(t, err)
+
+		_, err = other.Acquire(ctx, "exclusive", time.Minute)
+		assert.ErrorIs(t, err, ErrLockHeld)
+
+		require.NoError(t, locker.Release(ctx, lock))
+
+		lock, err = other.Acquire(ctx, "exclusive", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, other.Release(ctx, lock))
+	})
+
+	t.Run("expired lock is taken over", func(t *testing.T) {
+		stale, err := locker.Acquire(ctx, "expiring", time.Minute)
+		require.NoError(t, err)
+
+		clock.Advance(2 * time.Minute)
+		fresh, err := other.Acquire(ctx, "expiring", time.Minute)
+		require.NoError(t, err)
+		assert.NotEqual(t, stale.Token, fresh.Token)
+
+		assert.ErrorIs(t, locker.Renew(ctx, stale, time.Minute), ErrLockLost)
+		assert.ErrorIs(t, locker.Release(ctx, stale), ErrLockLost)
+		require.NoError(t, other.Renew(ctx, fresh, time.Minute))
+		require.NoError(t, other.Release(ctx, fresh))
+	})
+
+	t.Run("creates a TTL index", func(t *testing.T) {
+		cursor, err := client.Database().Collection(database).Indexes().List(ctx)
+		require.NoError(t, err)
+		var indexes []struct {
+			Name               string `bson:"name"`
+			ExpireAfterSeconds *int32 `bson:"expireAfterSeconds"`
+		}
+		require.NoError(t, cursor.All(ctx, &indexes))
+
+		found := false
+		for _, idx := range indexes {
+			if idx.Name == "expires_at_1" {
+				found = true
+				require.NotNil(t, idx.ExpireAfterSeconds)
+				assert.Equal(t, int32(0), *idx.ExpireAfterSeconds)
+			}
+		}
+		assert.True(t, found)
+	})
+}
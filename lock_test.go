@@ -0,0 +1,65 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestLocker(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	clock := testhelpers.NewFakeClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"), WithClock(clock))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	locker := NewLocker(client, "locks")
+
+	mock.AddResponses(
+		testhelpers.SuccessResponse(), // createIndexes
+		testhelpers.SuccessResponse(bson.E{Key: "value", Value: nil}),
+	)
+	lock, err := locker.Acquire(ctx, "report", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "report", lock.Key)
+	assert.NotEmpty(t, lock.Token)
+	assert.Equal(t, clock.Now().Add(time.Minute), lock.ExpiresAt)
+
+	mock.AddResponses(testhelpers.CommandErrorResponse(11000, "DuplicateKey", "E11000 duplicate key error"))
+	_, err = locker.Acquire(ctx, "report", time.Minute)
+	assert.ErrorIs(t, err, ErrLockHeld)
+
+	clock.Advance(30 * time.Second)
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+	require.NoError(t, locker.Renew(ctx, lock, time.Minute))
+	assert.Equal(t, clock.Now().Add(time.Minute), lock.ExpiresAt)
+
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+	assert.ErrorIs(t, locker.Renew(ctx, lock, time.Minute), ErrLockLost)
+
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}))
+	require.NoError(t, locker.Release(ctx, lock))
+
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 0}))
+	assert.ErrorIs(t, locker.Release(ctx, lock), ErrLockLost)
+}
+
+func TestLocker_InvalidArguments(t *testing.T) {
+	client := &Client{}
+	locker := NewLocker(client, "locks")
+	ctx := context.Background()
+
+	_, err := locker.Acquire(ctx, "", time.Minute)
+	assert.Error(t, err)
+	_, err = locker.Acquire(ctx, "job", 0)
+	assert.Error(t, err)
+	assert.Error(t, locker.Renew(ctx, nil, time.Minute))
+	assert.Error(t, locker.Renew(ctx, &Lock{Key: "job"}, 0))
+	assert.Error(t, locker.Release(ctx, nil))
+}
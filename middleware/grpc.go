@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// GRPCOption configures the gRPC server interceptors.
+type GRPCOption func(*grpcOptions)
+
+// grpcOptions holds the settings applied by GRPCOption.
+type grpcOptions struct {
+	session     bool
+	sessionOpts []*options.SessionOptions
+}
+
+// WithSession starts a session for every call and ends it when the handler returns.
+// Operations run with the call context use the session, giving handlers causally
+// consistent reads; retrieve it with SessionFromContext to start a transaction.
+//
+// Example:
+//
+//	middleware.UnaryServerInterceptor(client, middleware.WithSession(
+//	    options.Session().SetCausalConsistency(true),
+//	))
+func WithSession(opts ...*options.SessionOptions) GRPCOption {
+	return func(o *grpcOptions) {
+		o.session = true
+		o.sessionOpts = opts
+	}
+}
+
+// UnaryServerInterceptor returns a gRPC unary server interceptor that stores client
+// (and, with WithSession, a session) in the context of every call.
+// Retrieve them in handlers with FromContext and SessionFromContext.
+//
+// Example:
+//
+//	server := grpc.NewServer(
+//	    grpc.UnaryInterceptor(middleware.UnaryServerInterceptor(client)),
+//	    grpc.StreamInterceptor(middleware.StreamServerInterceptor(client)),
+//	)
+func UnaryServerInterceptor(client *mongokit.Client, opts ...GRPCOption) grpc.UnaryServerInterceptor {
+	o := newGRPCOptions(opts)
+
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, end, err := o.inject(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		defer end()
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a gRPC stream server interceptor that stores client
+// (and, with WithSession, a session) in the context of every stream.
+// See UnaryServerInterceptor.
+func StreamServerInterceptor(client *mongokit.Client, opts ...GRPCOption) grpc.StreamServerInterceptor {
+	o := newGRPCOptions(opts)
+
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, end, err := o.inject(ss.Context(), client)
+		if err != nil {
+			return err
+		}
+		defer end()
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// SessionFromContext returns the session stored in ctx by the gRPC interceptors
+// configured WithSession, or by mongokit.ContextWithSession.
+// The boolean is false if ctx carries no session.
+func SessionFromContext(ctx context.Context) (mongo.Session, bool) {
+	return mongokit.SessionFromContext(ctx)
+}
+
+// newGRPCOptions applies opts to the default interceptor settings.
+func newGRPCOptions(opts []GRPCOption) *grpcOptions {
+	o := &grpcOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// inject stores client, and a new session if configured, in ctx.
+// The returned function ends the session and must be called when the call completes.
+func (o *grpcOptions) inject(ctx context.Context, client *mongokit.Client) (context.Context, func(), error) {
+	ctx = WithClient(ctx, client)
+	if !o.session {
+		return ctx, func() {}, nil
+	}
+
+	sess, err := client.StartSession(o.sessionOpts...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return mongokit.ContextWithSession(ctx, sess), func() { sess.EndSession(context.Background()) }, nil
+}
+
+// serverStream is a grpc.ServerStream whose Context carries the injected values.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the stream context with the injected client and session.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
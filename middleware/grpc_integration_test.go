@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"google.golang.org/grpc"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestGRPCInterceptors_Session_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := mongokit.New(mongokit.DefaultConfig(),
+		mongokit.WithURI(container.URI),
+		mongokit.WithDatabase(database),
+	)
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	repo := mongokit.NewRepository[bson.M](client, "orders")
+
+	t.Run("unary handler runs a transaction on the injected session", func(t *testing.T) {
+		interceptor := UnaryServerInterceptor(client, WithSession())
+
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+			sess, ok := SessionFromContext(ctx)
+			require.True(t, ok)
+
+			return sess.WithTransaction(ctx, func(sc mongo.SessionContext) (any, error) {
+				return repo.Create(sc, bson.M{"item": "book"})
+			})
+		})
+		require.NoError(t, err)
+
+		count, err := repo.Count(context.Background(), bson.M{"item": "book"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("stream handler receives a session", func(t *testing.T) {
+		interceptor := StreamServerInterceptor(client, WithSession())
+
+		err := interceptor(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(_ any, ss grpc.ServerStream) error {
+			_, ok := SessionFromContext(ss.Context())
+			assert.True(t, ok)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("returns error when client is closed", func(t *testing.T) {
+		closed, err := mongokit.New(mongokit.DefaultConfig(), mongokit.WithURI(container.URI))
+		require.NoError(t, err)
+		require.NoError(t, closed.Close(context.Background()))
+
+		interceptor := UnaryServerInterceptor(closed, WithSession())
+		_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+			t.Fatal("handler must not be called")
+			return nil, nil
+		})
+		assert.ErrorIs(t, err, mongokit.ErrClientClosed)
+	})
+}
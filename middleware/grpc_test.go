@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// fakeServerStream is a grpc.ServerStream that only provides a context.
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	client := new(mongokit.Client)
+	interceptor := UnaryServerInterceptor(client)
+
+	resp, err := interceptor(context.Background(), "req", &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+		got, ok := FromContext(ctx)
+		require.True(t, ok)
+		assert.Same(t, client, got)
+
+		_, ok = SessionFromContext(ctx)
+		assert.False(t, ok)
+		return "resp", nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "resp", resp)
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	client := new(mongokit.Client)
+	interceptor := StreamServerInterceptor(client)
+
+	called := false
+	err := interceptor(nil, &fakeServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(_ any, ss grpc.ServerStream) error {
+		called = true
+		got, ok := FromContext(ss.Context())
+		require.True(t, ok)
+		assert.Same(t, client, got)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestSessionFromContext(t *testing.T) {
+	sess, ok := SessionFromContext(context.Background())
+	assert.False(t, ok)
+	assert.Nil(t, sess)
+}
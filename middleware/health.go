@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// healthCheckTimeout bounds the time HealthHandler waits for the server.
+const healthCheckTimeout = 2 * time.Second
+
+// HealthResponse is the JSON body written by HealthHandler.
+type HealthResponse struct {
+	Status        string  `json:"status"`                   // "up" or "down"
+	LatencyMS     float64 `json:"latency_ms"`               // Round-trip time of the ping in milliseconds
+	ServerVersion string  `json:"server_version,omitempty"` // MongoDB server version (empty when down)
+	Error         string  `json:"error,omitempty"`          // "unavailable" when down; the cause is attached to the Gin context
+}
+
+// HealthHandler returns a Gin handler that pings the server and responds with a
+// HealthResponse: 200 OK when the server is reachable and 503 Service Unavailable
+// otherwise. The check is bounded by a 2 second timeout, so it is suitable for
+// /healthz endpoints and load balancer checks.
+//
+// Health endpoints are often public, so a failure is reported as "unavailable" and
+// its cause is only attached to the request with c.Error, for logging middleware such
+// as gin.Logger. The server version is fetched once and reused by later checks.
+//
+// Example:
+//
+//	router.GET("/healthz", middleware.HealthHandler(client))
+func HealthHandler(client *mongokit.Client) gin.HandlerFunc {
+	var (
+		mu      sync.Mutex
+		version string
+	)
+	serverVersion := func(ctx context.Context) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if version != "" {
+			return version, nil
+		}
+		v, err := client.ServerVersion(ctx)
+		if err != nil {
+			return "", err
+		}
+		version = v
+		return version, nil
+	}
+
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+		defer cancel()
+
+		start := time.Now()
+		err := client.Ping(ctx)
+		latency := time.Since(start)
+
+		resp := HealthResponse{Status: "up", LatencyMS: float64(latency.Microseconds()) / 1000}
+		if err == nil {
+			resp.ServerVersion, err = serverVersion(ctx)
+		}
+		if err != nil {
+			_ = c.Error(err)
+			resp.Status = "down"
+			resp.ServerVersion = ""
+			resp.Error = "unavailable"
+			c.JSON(http.StatusServiceUnavailable, resp)
+			return
+		}
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
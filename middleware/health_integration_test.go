@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestHealthHandler_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+
+	client, err := mongokit.New(mongokit.DefaultConfig(), mongokit.WithURI(container.URI))
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/healthz", HealthHandler(client))
+
+	check := func() (int, HealthResponse) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		var resp HealthResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return rec.Code, resp
+	}
+
+	t.Run("reports up with server version", func(t *testing.T) {
+		code, resp := check()
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "up", resp.Status)
+		assert.Regexp(t, `^7\.`, resp.ServerVersion)
+		assert.Empty(t, resp.Error)
+	})
+
+	t.Run("reports down when client is closed", func(t *testing.T) {
+		require.NoError(t, client.Close(context.Background()))
+
+		code, resp := check()
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "down", resp.Status)
+		assert.Equal(t, "unavailable", resp.Error)
+	})
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestHealthHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mock := testhelpers.NewMockClient(t)
+	client, err := mongokit.NewFromClient(mock.Client, mongokit.DefaultConfig(), mongokit.WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	var causes []error
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Next()
+		for _, e := range c.Errors {
+			causes = append(causes, e.Err)
+		}
+	})
+	router.GET("/healthz", HealthHandler(client))
+
+	check := func() (int, HealthResponse) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		var resp HealthResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return rec.Code, resp
+	}
+
+	t.Run("reports up and caches the server version", func(t *testing.T) {
+		mock.AddResponses(
+			testhelpers.SuccessResponse(),
+			testhelpers.SuccessResponse(bson.E{Key: "version", Value: "7.0.12"}),
+			testhelpers.SuccessResponse(),
+		)
+
+		for range 2 {
+			code, resp := check()
+			assert.Equal(t, http.StatusOK, code)
+			assert.Equal(t, "up", resp.Status)
+			assert.Equal(t, "7.0.12", resp.ServerVersion)
+		}
+	})
+
+	t.Run("reports down without the cause", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CommandErrorResponse(13, "Unauthorized", "command ping requires authentication on host db-internal:27017"))
+
+		code, resp := check()
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, HealthResponse{Status: "down", LatencyMS: resp.LatencyMS, Error: "unavailable"}, resp)
+		require.Len(t, causes, 1)
+		assert.ErrorContains(t, causes[0], "db-internal")
+	})
+
+	t.Run("reports down when client is closed", func(t *testing.T) {
+		require.NoError(t, client.Close(context.Background()))
+
+		code, resp := check()
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, "unavailable", resp.Error)
+	})
+}
@@ -0,0 +1,55 @@
+// Package middleware provides net/http, Gin and gRPC middleware that makes a mongo_kit Client
+// available to request handlers through the request context.
+//
+// See docs/middleware.md for detailed usage guide and examples.
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// contextKey is the type of the context keys defined by this package,
+// so they cannot collide with keys defined elsewhere.
+type contextKey int
+
+const (
+	clientKey contextKey = iota
+	tenantKey
+	queryKey
+	pageKey
+)
+
+// HTTPClient returns net/http middleware that stores client in the context of every
+// request. It works with any router built on http.Handler, such as chi or gorilla/mux.
+//
+// Example:
+//
+//	r := chi.NewRouter()
+//	r.Use(middleware.HTTPClient(client))
+//	r.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+//	    client, _ := middleware.FromContext(r.Context())
+//	    users, err := mongokit.NewRepository[User](client, "users").FindAll(r.Context())
+//	    // ...
+//	})
+func HTTPClient(client *mongokit.Client) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(WithClient(r.Context(), client)))
+		})
+	}
+}
+
+// WithClient returns a copy of ctx that carries client.
+func WithClient(ctx context.Context, client *mongokit.Client) context.Context {
+	return context.WithValue(ctx, clientKey, client)
+}
+
+// FromContext returns the client stored in ctx by HTTPClient or WithClient.
+// The boolean is false if ctx carries no client.
+func FromContext(ctx context.Context) (*mongokit.Client, bool) {
+	client, ok := ctx.Value(clientKey).(*mongokit.Client)
+	return client, ok && client != nil
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+func TestHTTPClient(t *testing.T) {
+	client := new(mongokit.Client)
+
+	var got *mongokit.Client
+	var ok bool
+	handler := HTTPClient(client)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok = FromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.True(t, ok)
+	assert.Same(t, client, got)
+}
+
+func TestFromContext(t *testing.T) {
+	t.Run("returns false without client", func(t *testing.T) {
+		client, ok := FromContext(context.Background())
+		assert.False(t, ok)
+		assert.Nil(t, client)
+	})
+
+	t.Run("returns false for nil client", func(t *testing.T) {
+		_, ok := FromContext(WithClient(context.Background(), nil))
+		assert.False(t, ok)
+	})
+
+	t.Run("returns client from WithClient", func(t *testing.T) {
+		client := new(mongokit.Client)
+		got, ok := FromContext(WithClient(context.Background(), client))
+		assert.True(t, ok)
+		assert.Same(t, client, got)
+	})
+}
@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// Default page sizes used by Pagination when PaginationConfig leaves them unset.
+const (
+	defaultPageLimit = 20
+	defaultMaxLimit  = 100
+)
+
+// FilterFunc adds the condition for a query parameter value to qb.
+// Return an error to reject the request with 400 Bad Request.
+type FilterFunc func(qb *mongokit.QueryBuilder, value string) error
+
+// PaginationConfig configures the query parameters accepted by Pagination.
+type PaginationConfig struct {
+	DefaultLimit int64                 // Page size when "limit" is absent (default: 20)
+	MaxLimit     int64                 // Largest accepted "limit" (default: 100)
+	SortFields   []string              // Fields accepted in "sort"; sorting is rejected when empty
+	DefaultSort  string                // Sort applied when "sort" is absent, e.g. "-created_at"
+	Filters      map[string]FilterFunc // Accepted filter parameters by query parameter name
+}
+
+// Page is the pagination parsed by Pagination.
+type Page struct {
+	Number int64 // 1-based page number
+	Limit  int64 // Page size
+}
+
+// Skip returns the number of documents before the page.
+func (p Page) Skip() int64 {
+	return (p.Number - 1) * p.Limit
+}
+
+// Pagination returns Gin middleware that parses the page, limit, sort and whitelisted
+// filter query parameters of list endpoints into a QueryBuilder stored in the request
+// context. Retrieve it with QueryFromContext and the page with PageFromContext.
+//
+// Query parameters:
+//   - page: 1-based page number (default: 1)
+//   - limit: page size, at most MaxLimit
+//   - sort: comma-separated fields from SortFields; prefix "-" for descending, e.g. "-age,name"
+//   - any key of Filters
+//
+// Requests with invalid parameters are aborted with 400 Bad Request; parameters that are
+// not whitelisted are ignored.
+//
+// Example:
+//
+//	router.GET("/users", middleware.Pagination(middleware.PaginationConfig{
+//	    SortFields:  []string{"name", "created_at"},
+//	    DefaultSort: "-created_at",
+//	    Filters: map[string]middleware.FilterFunc{
+//	        "status": middleware.EqualsFilter("status"),
+//	        "active": middleware.BoolFilter("active"),
+//	    },
+//	}), func(c *gin.Context) {
+//	    qb, _ := middleware.QueryFromContext(c.Request.Context())
+//	    users, err := userRepo.FindWithBuilder(c.Request.Context(), qb)
+//	    // ...
+//	})
+func Pagination(cfg PaginationConfig) gin.HandlerFunc {
+	if cfg.DefaultLimit <= 0 {
+		cfg.DefaultLimit = defaultPageLimit
+	}
+	if cfg.MaxLimit <= 0 {
+		cfg.MaxLimit = defaultMaxLimit
+	}
+
+	return func(c *gin.Context) {
+		qb, page, err := parsePagination(c, cfg)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := context.WithValue(c.Request.Context(), queryKey, qb)
+		ctx = context.WithValue(ctx, pageKey, page)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// QueryFromContext returns the QueryBuilder stored in ctx by Pagination.
+// The boolean is false if ctx carries no QueryBuilder.
+func QueryFromContext(ctx context.Context) (*mongokit.QueryBuilder, bool) {
+	qb, ok := ctx.Value(queryKey).(*mongokit.QueryBuilder)
+	return qb, ok
+}
+
+// PageFromContext returns the page stored in ctx by Pagination.
+// The boolean is false if ctx carries no page.
+func PageFromContext(ctx context.Context) (Page, bool) {
+	page, ok := ctx.Value(pageKey).(Page)
+	return page, ok
+}
+
+// EqualsFilter returns a FilterFunc that matches field against the parameter value as a string.
+func EqualsFilter(field string) FilterFunc {
+	return func(qb *mongokit.QueryBuilder, value string) error {
+		qb.Equals(field, value)
+		return nil
+	}
+}
+
+// IntFilter returns a FilterFunc that matches field against the parameter value as an integer.
+func IntFilter(field string) FilterFunc {
+	return func(qb *mongokit.QueryBuilder, value string) error {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("must be an integer")
+		}
+		qb.Equals(field, n)
+		return nil
+	}
+}
+
+// BoolFilter returns a FilterFunc that matches field against the parameter value as a boolean.
+func BoolFilter(field string) FilterFunc {
+	return func(qb *mongokit.QueryBuilder, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("must be a boolean")
+		}
+		qb.Equals(field, b)
+		return nil
+	}
+}
+
+// ObjectIDFilter returns a FilterFunc that matches field against the parameter value as an ObjectID.
+func ObjectIDFilter(field string) FilterFunc {
+	return func(qb *mongokit.QueryBuilder, value string) error {
+		id, err := primitive.ObjectIDFromHex(value)
+		if err != nil {
+			return fmt.Errorf("must be an ObjectID")
+		}
+		qb.Equals(field, id)
+		return nil
+	}
+}
+
+// parsePagination builds the QueryBuilder and Page for the query parameters of c.
+func parsePagination(c *gin.Context, cfg PaginationConfig) (*mongokit.QueryBuilder, Page, error) {
+	page := Page{Number: 1, Limit: cfg.DefaultLimit}
+
+	if value := c.Query("page"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 1 {
+			return nil, Page{}, fmt.Errorf("invalid page %q: must be a positive integer", value)
+		}
+		page.Number = n
+	}
+
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 1 || n > cfg.MaxLimit {
+			return nil, Page{}, fmt.Errorf("invalid limit %q: must be between 1 and %d", value, cfg.MaxLimit)
+		}
+		page.Limit = n
+	}
+
+	// Larger pages would overflow the number of documents to skip.
+	if maxPage := math.MaxInt64/page.Limit + 1; page.Number > maxPage {
+		return nil, Page{}, fmt.Errorf("invalid page %d: must be at most %d", page.Number, maxPage)
+	}
+
+	qb := mongokit.NewQueryBuilder().Skip(page.Skip()).Limit(page.Limit)
+
+	// DefaultSort comes from the configuration, so only requested fields are checked.
+	sort, requested := c.GetQuery("sort")
+	if !requested {
+		sort = cfg.DefaultSort
+	}
+	for field := range strings.SplitSeq(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		name, descending := strings.CutPrefix(field, "-")
+		if requested && !slices.Contains(cfg.SortFields, name) {
+			return nil, Page{}, fmt.Errorf("invalid sort field %q", name)
+		}
+		qb.Sort(name, !descending)
+	}
+
+	names := make([]string, 0, len(cfg.Filters))
+	for name := range cfg.Filters {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		value, ok := c.GetQuery(name)
+		if !ok {
+			continue
+		}
+		if err := cfg.Filters[name](qb, value); err != nil {
+			return nil, Page{}, fmt.Errorf("invalid filter %s: %w", name, err)
+		}
+	}
+
+	return qb, page, nil
+}
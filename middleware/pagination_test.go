@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+func TestPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cfg := PaginationConfig{
+		MaxLimit:    50,
+		SortFields:  []string{"name", "age"},
+		DefaultSort: "-created_at",
+		Filters: map[string]FilterFunc{
+			"status": EqualsFilter("status"),
+			"age":    IntFilter("age"),
+			"active": BoolFilter("active"),
+			"owner":  ObjectIDFilter("owner_id"),
+			"min_age": func(qb *mongokit.QueryBuilder, value string) error {
+				if value == "" {
+					return errors.New("required")
+				}
+				qb.GreaterThanOrEqual("age", value)
+				return nil
+			},
+		},
+	}
+
+	var qb *mongokit.QueryBuilder
+	var page Page
+	router := gin.New()
+	router.GET("/users", Pagination(cfg), func(c *gin.Context) {
+		var ok bool
+		qb, ok = QueryFromContext(c.Request.Context())
+		require.True(t, ok)
+		page, ok = PageFromContext(c.Request.Context())
+		require.True(t, ok)
+		c.Status(http.StatusNoContent)
+	})
+
+	serve := func(query string) int {
+		qb, page = nil, Page{}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users"+query, nil))
+		return rec.Code
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, serve(""))
+		assert.Equal(t, Page{Number: 1, Limit: 20}, page)
+
+		filter, opts := qb.Build()
+		assert.Empty(t, filter)
+		assert.Equal(t, int64(0), *opts.Skip)
+		assert.Equal(t, int64(20), *opts.Limit)
+		assert.Equal(t, bson.D{{Key: "created_at", Value: -1}}, opts.Sort)
+	})
+
+	t.Run("page, limit and sort", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, serve("?page=3&limit=10&sort=-age,name"))
+		assert.Equal(t, Page{Number: 3, Limit: 10}, page)
+
+		_, opts := qb.Build()
+		assert.Equal(t, int64(20), *opts.Skip)
+		assert.Equal(t, int64(10), *opts.Limit)
+		assert.Equal(t, bson.D{{Key: "age", Value: -1}, {Key: "name", Value: 1}}, opts.Sort)
+	})
+
+	t.Run("largest page", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, serve("?page=922337203685477581&limit=10"))
+		_, opts := qb.Build()
+		assert.Equal(t, int64(9223372036854775800), *opts.Skip)
+	})
+
+	t.Run("whitelisted filters", func(t *testing.T) {
+		owner := primitive.NewObjectID()
+		require.Equal(t, http.StatusNoContent, serve("?status=new&age=30&active=true&owner="+owner.Hex()+"&role=admin"))
+
+		assert.ElementsMatch(t, bson.D{
+			{Key: "status", Value: "new"},
+			{Key: "age", Value: int64(30)},
+			{Key: "active", Value: true},
+			{Key: "owner_id", Value: owner},
+		}, qb.GetFilter())
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, query := range []string{
+			"?page=0",
+			"?page=abc",
+			"?page=9223372036854775807",
+			"?page=922337203685477582&limit=10",
+			"?page=9223372036854775808",
+			"?limit=0",
+			"?limit=51",
+			"?sort=password",
+			"?sort=-created_at",
+			"?age=old",
+			"?active=maybe",
+			"?owner=123",
+			"?min_age=",
+		} {
+			assert.Equal(t, http.StatusBadRequest, serve(query), query)
+		}
+	})
+}
+
+func TestPage_Skip(t *testing.T) {
+	assert.Equal(t, int64(0), Page{Number: 1, Limit: 25}.Skip())
+	assert.Equal(t, int64(50), Page{Number: 3, Limit: 25}.Skip())
+}
+
+func TestQueryFromContext_Missing(t *testing.T) {
+	_, ok := QueryFromContext(context.Background())
+	assert.False(t, ok)
+
+	_, ok = PageFromContext(context.Background())
+	assert.False(t, ok)
+}
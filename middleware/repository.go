@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/gin-gonic/gin"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// repositoriesKey is the Gin context key under which WithRepositories stores repositories.
+const repositoriesKey = "mongokit.repositories"
+
+// WithRepositories returns Gin middleware that makes repos available to handlers by name,
+// so repositories are built once at startup instead of on every request.
+// Values must be *mongokit.Repository[T]; retrieve them with Repo or LookupRepo.
+//
+// Example:
+//
+//	router.Use(middleware.WithRepositories(map[string]any{
+//	    "users":  mongokit.NewRepository[User](client, "users"),
+//	    "orders": mongokit.NewRepository[Order](client, "orders"),
+//	}))
+func WithRepositories(repos map[string]any) gin.HandlerFunc {
+	registered := maps.Clone(repos)
+
+	return func(c *gin.Context) {
+		c.Set(repositoriesKey, registered)
+		c.Next()
+	}
+}
+
+// Repo returns the repository registered under name by WithRepositories.
+// It panics if no repository with that name and document type is registered,
+// which is a programming error.
+//
+// Example:
+//
+//	func listUsers(c *gin.Context) {
+//	    users, err := middleware.Repo[User](c, "users").FindAll(c.Request.Context())
+//	    // ...
+//	}
+func Repo[T any](c *gin.Context, name string) *mongokit.Repository[T] {
+	repo, ok := LookupRepo[T](c, name)
+	if !ok {
+		panic(fmt.Sprintf("middleware: no repository %q of type *Repository[%T] registered", name, *new(T)))
+	}
+	return repo
+}
+
+// LookupRepo returns the repository registered under name by WithRepositories.
+// The boolean is false if no repository with that name and document type is registered.
+func LookupRepo[T any](c *gin.Context, name string) (*mongokit.Repository[T], bool) {
+	value, ok := c.Get(repositoriesKey)
+	if !ok {
+		return nil, false
+	}
+
+	repos, _ := value.(map[string]any)
+	repo, ok := repos[name].(*mongokit.Repository[T])
+	return repo, ok && repo != nil
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+type testUser struct {
+	Name string `bson:"name"`
+}
+
+type testOrder struct {
+	Total int `bson:"total"`
+}
+
+func TestWithRepositories(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	client := new(mongokit.Client)
+	users := mongokit.NewRepository[testUser](client, "users")
+	repos := map[string]any{"users": users}
+
+	router := gin.New()
+	router.Use(WithRepositories(repos))
+	repos["orders"] = mongokit.NewRepository[testOrder](client, "orders")
+
+	router.GET("/", func(c *gin.Context) {
+		assert.Same(t, users, Repo[testUser](c, "users"))
+
+		_, ok := LookupRepo[testOrder](c, "users")
+		assert.False(t, ok, "wrong document type")
+
+		_, ok = LookupRepo[testOrder](c, "orders")
+		assert.False(t, ok, "registered after the middleware was created")
+
+		assert.PanicsWithValue(t,
+			`middleware: no repository "missing" of type *Repository[middleware.testUser] registered`,
+			func() { Repo[testUser](c, "missing") })
+
+		c.Status(http.StatusNoContent)
+	})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestLookupRepo_WithoutMiddleware(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	repo, ok := LookupRepo[testUser](c, "users")
+	assert.False(t, ok)
+	assert.Nil(t, repo)
+}
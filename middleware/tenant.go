@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// ErrUnknownTenant should be returned by a TenantClientProvider for tenants that do
+// not exist. Tenant responds to it with 404 Not Found.
+var ErrUnknownTenant = errors.New("middleware: unknown tenant")
+
+// TenantResolver extracts the tenant identifier from a request, e.g. from a header
+// or from a JWT claim set by an authentication middleware.
+type TenantResolver func(c *gin.Context) (string, error)
+
+// TenantClientProvider returns the client scoped to a tenant's database.
+// A tenant manager that caches one client per tenant can be plugged in here.
+type TenantClientProvider func(ctx context.Context, tenant string) (*mongokit.Client, error)
+
+// HeaderTenant returns a TenantResolver that reads the tenant identifier from the
+// request header name. Requests without the header are rejected.
+func HeaderTenant(name string) TenantResolver {
+	return func(c *gin.Context) (string, error) {
+		tenant := c.GetHeader(name)
+		if tenant == "" {
+			return "", errors.New("middleware: missing tenant header " + name)
+		}
+		return tenant, nil
+	}
+}
+
+// Tenant returns Gin middleware that resolves the tenant of every request and stores
+// the tenant identifier and its client in the request context. Handlers retrieve them
+// with TenantFromContext and FromContext, so the same handler code serves every tenant.
+//
+// Requests whose tenant cannot be resolved or is empty are aborted with 400 Bad Request, unknown
+// tenants (ErrUnknownTenant) with 404 Not Found and other provider errors with
+// 500 Internal Server Error.
+//
+// Example:
+//
+//	router.Use(middleware.Tenant(middleware.HeaderTenant("X-Tenant-ID"),
+//	    func(ctx context.Context, tenant string) (*mongokit.Client, error) {
+//	        return tenants.Client(ctx, tenant)
+//	    }))
+//	router.GET("/users", func(c *gin.Context) {
+//	    client, _ := middleware.FromContext(c.Request.Context())
+//	    users, err := mongokit.NewRepository[User](client, "users").FindAll(c.Request.Context())
+//	    // ...
+//	})
+func Tenant(resolve TenantResolver, clients TenantClientProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant, err := resolve(c)
+		if err == nil && tenant == "" {
+			err = errors.New("middleware: tenant not resolved")
+		}
+		if err != nil {
+			_ = c.AbortWithError(http.StatusBadRequest, err)
+			return
+		}
+
+		ctx := c.Request.Context()
+		client, err := clients(ctx, tenant)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ErrUnknownTenant) {
+				status = http.StatusNotFound
+			}
+			_ = c.AbortWithError(status, err)
+			return
+		}
+
+		ctx = context.WithValue(WithClient(ctx, client), tenantKey, tenant)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// TenantFromContext returns the tenant identifier stored in ctx by Tenant.
+// The boolean is false if ctx carries no tenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey).(string)
+	return tenant, ok
+}
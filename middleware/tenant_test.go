@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+func TestTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	acme := new(mongokit.Client)
+	clients := func(_ context.Context, tenant string) (*mongokit.Client, error) {
+		switch tenant {
+		case "acme":
+			return acme, nil
+		case "broken":
+			return nil, errors.New("pool exhausted")
+		default:
+			return nil, ErrUnknownTenant
+		}
+	}
+
+	router := gin.New()
+	router.Use(Tenant(HeaderTenant("X-Tenant-ID"), clients))
+	router.GET("/", func(c *gin.Context) {
+		tenant, ok := TenantFromContext(c.Request.Context())
+		assert.True(t, ok)
+		assert.Equal(t, "acme", tenant)
+
+		client, ok := FromContext(c.Request.Context())
+		assert.True(t, ok)
+		assert.Same(t, acme, client)
+
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name   string
+		tenant string
+		status int
+	}{
+		{name: "known tenant", tenant: "acme", status: http.StatusNoContent},
+		{name: "missing header", tenant: "", status: http.StatusBadRequest},
+		{name: "unknown tenant", tenant: "globex", status: http.StatusNotFound},
+		{name: "provider error", tenant: "broken", status: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.tenant != "" {
+				req.Header.Set("X-Tenant-ID", tt.tenant)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func TestTenant_EmptyTenant(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(Tenant(
+		func(c *gin.Context) (string, error) { return c.GetString("tenant_claim"), nil },
+		func(context.Context, string) (*mongokit.Client, error) {
+			t.Fatal("provider must not be called")
+			return nil, nil
+		},
+	))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTenantFromContext_Missing(t *testing.T) {
+	_, ok := TenantFromContext(context.Background())
+	assert.False(t, ok)
+}
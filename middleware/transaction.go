@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// Transaction returns Gin middleware that runs every request in a transaction, so all
+// writes made by the handler are atomic. The request context carries the client and the
+// session, and repository operations called with c.Request.Context() join the transaction.
+//
+// Handlers may call client.WithTransaction with the request context; its callback joins
+// the request transaction (see mongokit.ContextWithTransaction).
+//
+// The response is buffered until the transaction completes. The transaction commits when
+// the handler responds with a 2xx status and adds no errors with c.Error; otherwise it is
+// aborted and the handler's response is sent unchanged. A commit failing with a retryable
+// error, such as UnknownTransactionCommitResult, is retried up to commitAttempts times; if
+// it still fails the response is replaced with 500 Internal Server Error. The handler
+// itself is never re-run. A panic aborts the transaction and is re-raised
+// for gin.Recovery. Streaming responses are not supported.
+//
+// Example:
+//
+//	orders := router.Group("/orders", middleware.Transaction(client))
+//	orders.POST("", func(c *gin.Context) {
+//	    ctx := c.Request.Context()
+//	    if _, err := orderRepo.Create(ctx, order); err != nil {
+//	        _ = c.AbortWithError(http.StatusInternalServerError, err)
+//	        return
+//	    }
+//	    _, _ = stockRepo.UpdateOne(ctx, bson.M{"sku": order.SKU}, bson.M{"$inc": bson.M{"qty": -1}})
+//	    c.JSON(http.StatusCreated, order)
+//	})
+func Transaction(client *mongokit.Client, opts ...*options.TransactionOptions) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sess, err := client.StartSession()
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		defer sess.EndSession(context.Background())
+
+		if err := sess.StartTransaction(opts...); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		ctx := c.Request.Context()
+		c.Request = c.Request.WithContext(mongokit.ContextWithTransaction(WithClient(ctx, client), sess))
+
+		original := c.Writer
+		buffer := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = buffer
+
+		committed := false
+		defer func() {
+			c.Writer = original
+			if !committed {
+				_ = sess.AbortTransaction(context.Background())
+			}
+		}()
+
+		c.Next()
+		c.Writer = original
+
+		if len(c.Errors) > 0 || buffer.status < 200 || buffer.status >= 300 {
+			buffer.flush()
+			return
+		}
+
+		if err := commit(ctx, sess); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		committed = true
+
+		buffer.flush()
+	}
+}
+
+// commitAttempts is the number of times Transaction tries to commit.
+const commitAttempts = 3
+
+// commit commits the transaction of sess, retrying retryable errors. The driver sends
+// retried commits with a majority write concern, so a commit that was applied is not
+// applied twice.
+func commit(ctx context.Context, sess mongo.Session) error {
+	var err error
+	for range commitAttempts {
+		if err = sess.CommitTransaction(ctx); !mongokit.IsRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// bufferedWriter holds the status and body written by a handler until the
+// transaction completes, so a failed commit can still change the response.
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+// flush sends the buffered status and body to the underlying writer.
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	_, _ = w.ResponseWriter.Write(w.body.Bytes())
+}
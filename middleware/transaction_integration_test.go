@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestTransaction_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := mongokit.New(mongokit.DefaultConfig(),
+		mongokit.WithURI(container.URI),
+		mongokit.WithDatabase(database),
+	)
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := mongokit.NewRepository[bson.M](client, "orders")
+	require.NoError(t, client.CreateCollection(ctx, "orders"))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(gin.Recovery(), Transaction(client))
+
+	insertTwo := func(c *gin.Context) {
+		reqCtx := c.Request.Context()
+		_, err := repo.Create(reqCtx, bson.M{"path": c.FullPath()})
+		require.NoError(t, err)
+		_, err = repo.Create(reqCtx, bson.M{"path": c.FullPath()})
+		require.NoError(t, err)
+	}
+	router.POST("/commit", func(c *gin.Context) {
+		insertTwo(c)
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	})
+	router.POST("/bad-request", func(c *gin.Context) {
+		insertTwo(c)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid"})
+	})
+	router.POST("/error", func(c *gin.Context) {
+		insertTwo(c)
+		_ = c.Error(errors.New("boom"))
+		c.Status(http.StatusOK)
+	})
+	router.POST("/panic", func(c *gin.Context) {
+		insertTwo(c)
+		panic("boom")
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		return rec
+	}
+	count := func(path string) int64 {
+		n, err := repo.Count(ctx, bson.M{"path": path})
+		require.NoError(t, err)
+		return n
+	}
+
+	t.Run("commits on 2xx response", func(t *testing.T) {
+		rec := serve("/commit")
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.JSONEq(t, `{"ok":true}`, rec.Body.String())
+		assert.Equal(t, int64(2), count("/commit"))
+	})
+
+	t.Run("aborts on non-2xx response", func(t *testing.T) {
+		rec := serve("/bad-request")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, int64(0), count("/bad-request"))
+	})
+
+	t.Run("aborts when handler adds errors", func(t *testing.T) {
+		serve("/error")
+		assert.Equal(t, int64(0), count("/error"))
+	})
+
+	t.Run("aborts on panic", func(t *testing.T) {
+		rec := serve("/panic")
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, int64(0), count("/panic"))
+	})
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestBufferedWriter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("holds response until flushed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		buffer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = buffer
+
+		c.JSON(http.StatusCreated, gin.H{"id": 1})
+
+		assert.Equal(t, http.StatusCreated, buffer.Status())
+		assert.True(t, buffer.Written())
+		assert.False(t, rec.Flushed)
+		assert.Empty(t, rec.Body.String())
+
+		buffer.flush()
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.JSONEq(t, `{"id":1}`, rec.Body.String())
+	})
+
+	t.Run("flushes status without body", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		buffer := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = buffer
+
+		c.Status(http.StatusNoContent)
+		assert.Equal(t, -1, buffer.Size())
+
+		buffer.flush()
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+
+	t.Run("ignores status after body is written", func(t *testing.T) {
+		buffer := &bufferedWriter{status: http.StatusOK}
+		_, _ = buffer.WriteString("ok")
+		buffer.WriteHeader(http.StatusTeapot)
+
+		assert.Equal(t, http.StatusOK, buffer.Status())
+		assert.Equal(t, 2, buffer.Size())
+	})
+}
+
+func TestTransaction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mock := testhelpers.NewMockClient(t)
+	client, err := mongokit.NewFromClient(mock.Client, mongokit.DefaultConfig(), mongokit.WithDatabase("testdb"))
+	require.NoError(t, err)
+	repo := mongokit.NewRepository[bson.M](client, "orders")
+
+	router := gin.New()
+	router.Use(Transaction(client))
+	router.POST("/orders", func(c *gin.Context) {
+		err := client.WithTransaction(c.Request.Context(), func(ctx context.Context) error {
+			_, err := repo.Create(ctx, bson.M{"sku": "A-1"})
+			return err
+		})
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+		c.Status(http.StatusCreated)
+	})
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", nil))
+		return rec
+	}
+	unknownCommit := bson.D{
+		{Key: "ok", Value: 0},
+		{Key: "code", Value: 91},
+		{Key: "codeName", Value: "ShutdownInProgress"},
+		{Key: "errmsg", Value: "shutting down"},
+		{Key: "errorLabels", Value: bson.A{"UnknownTransactionCommitResult"}},
+	}
+
+	t.Run("handlers join the request transaction with WithTransaction", func(t *testing.T) {
+		mock.AddResponses(
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}), // insert
+			testhelpers.SuccessResponse(),                           // commitTransaction
+		)
+		rec := serve()
+		assert.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	})
+
+	t.Run("retries unknown commit results", func(t *testing.T) {
+		mock.AddResponses(
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}),
+			unknownCommit,
+			testhelpers.SuccessResponse(),
+		)
+		rec := serve()
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("fails after the last commit attempt", func(t *testing.T) {
+		mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}))
+		for range commitAttempts {
+			mock.AddResponses(unknownCommit)
+		}
+		mock.AddResponses(testhelpers.SuccessResponse()) // abortTransaction
+		rec := serve()
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		mock.ClearResponses()
+	})
+}
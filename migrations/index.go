@@ -0,0 +1,156 @@
+// Package migrations plans and applies schema changes, such as index changes, so they
+// can be reviewed like code before they reach a database.
+//
+// See docs/migrations.md for detailed usage guide and examples.
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+// Plan lists the index changes that bring a database in line with a declared spec.
+// Print it for review, then run Apply.
+type Plan struct {
+	*mongokit.IndexSyncPlan
+
+	client *mongokit.Client
+}
+
+// IndexPlan compares the declared indexes of each collection in spec against the
+// existing ones and returns the changes needed, without modifying any index:
+// missing indexes are created, indexes whose keys or options changed are dropped and
+// created again, and existing indexes that are not declared are dropped.
+// Only collections present in spec are inspected; the _id index is never dropped.
+//
+// Example:
+//
+//	plan, err := migrations.IndexPlan(ctx, client, map[string][]mongo.IndexModel{
+//	    "users": {mongokit.NewIndexBuilder().Key("email", 1).Unique().Build()},
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Print(plan)
+//	// users:
+//	//   + create email_1 {email: 1} unique
+//	//   - drop   legacy_name_1
+func IndexPlan(ctx context.Context, client *mongokit.Client, spec map[string][]mongo.IndexModel) (*Plan, error) {
+	plan, err := client.SyncIndexes(ctx, spec, mongokit.SyncOptions{DropUnknown: true, DryRun: true})
+	if err != nil {
+		return nil, err
+	}
+	return &Plan{IndexSyncPlan: plan, client: client}, nil
+}
+
+// Apply executes the changes of the plan in order. It stops at the first failure,
+// e.g. when an index changed since the plan was computed.
+func (p *Plan) Apply(ctx context.Context) error {
+	if !p.HasChanges() {
+		return nil
+	}
+	return p.client.ApplyIndexPlan(ctx, p.IndexSyncPlan)
+}
+
+// String returns the plan in a human-readable form, one line per change grouped by
+// collection: "+" creates, "~" recreates and "-" drops an index.
+func (p *Plan) String() string {
+	if !p.HasChanges() {
+		return "No index changes.\n"
+	}
+
+	var b strings.Builder
+	collection := ""
+	for _, change := range p.Changes {
+		if change.Collection != collection {
+			collection = change.Collection
+			fmt.Fprintf(&b, "%s:\n", collection)
+		}
+		b.WriteString("  ")
+		b.WriteString(formatChange(change))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// formatChange formats a single change of a plan.
+func formatChange(change mongokit.IndexChange) string {
+	switch change.Action {
+	case mongokit.IndexActionCreate:
+		return fmt.Sprintf("+ create %s %s", change.Name, formatModel(change.Model))
+	case mongokit.IndexActionRecreate:
+		return fmt.Sprintf("~ modify %s %s", change.Name, formatModel(change.Model))
+	default:
+		return fmt.Sprintf("- drop   %s", change.Name)
+	}
+}
+
+// formatModel formats the keys and options of an index model, e.g.
+// "{email: 1, created_at: -1} unique sparse collation=en".
+func formatModel(model *mongo.IndexModel) string {
+	var b strings.Builder
+	b.WriteString(formatKeys(model.Keys))
+
+	opts := model.Options
+	if opts == nil {
+		return b.String()
+	}
+	if opts.Unique != nil && *opts.Unique {
+		b.WriteString(" unique")
+	}
+	if opts.Sparse != nil && *opts.Sparse {
+		b.WriteString(" sparse")
+	}
+	if opts.Hidden != nil && *opts.Hidden {
+		b.WriteString(" hidden")
+	}
+	if opts.ExpireAfterSeconds != nil {
+		fmt.Fprintf(&b, " ttl=%ds", *opts.ExpireAfterSeconds)
+	}
+	if opts.PartialFilterExpression != nil {
+		b.WriteString(" partial")
+	}
+	if opts.Collation != nil {
+		fmt.Fprintf(&b, " collation=%s", opts.Collation.Locale)
+	}
+	return b.String()
+}
+
+// formatKeys formats an index key document as {field: direction, ...}.
+func formatKeys(keys any) string {
+	raw, err := bson.Marshal(keys)
+	if err != nil {
+		return fmt.Sprintf("%v", keys)
+	}
+	elems, err := bson.Raw(raw).Elements()
+	if err != nil {
+		return fmt.Sprintf("%v", keys)
+	}
+
+	parts := make([]string, len(elems))
+	for i, elem := range elems {
+		parts[i] = elem.Key() + ": " + formatValue(elem.Value())
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// formatValue formats an index key direction or type.
+func formatValue(v bson.RawValue) string {
+	if s, ok := v.StringValueOK(); ok {
+		return strconv.Quote(s)
+	}
+	if f, ok := v.DoubleOK(); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	if i, ok := v.AsInt64OK(); ok {
+		return strconv.FormatInt(i, 10)
+	}
+	return v.String()
+}
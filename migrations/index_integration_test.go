@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestIndexPlan_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := mongokit.New(mongokit.DefaultConfig(), mongokit.WithURI(container.URI), mongokit.WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	users := client.Database().Collection("users")
+	_, err = users.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "legacy", Value: 1}}})
+	require.NoError(t, err)
+
+	spec := map[string][]mongo.IndexModel{
+		"users": {mongokit.NewIndexBuilder().Key("email", 1).Unique().Build()},
+	}
+
+	plan, err := IndexPlan(ctx, client, spec)
+	require.NoError(t, err)
+	assert.Equal(t, "users:\n  + create email_1 {email: 1} unique\n  - drop   legacy_1\n", plan.String())
+
+	specs, err := users.Indexes().ListSpecifications(ctx)
+	require.NoError(t, err)
+	assert.Len(t, specs, 2, "planning must not modify indexes")
+
+	require.NoError(t, plan.Apply(ctx))
+
+	plan, err = IndexPlan(ctx, client, spec)
+	require.NoError(t, err)
+	assert.False(t, plan.HasChanges())
+	assert.Equal(t, "No index changes.\n", plan.String())
+}
@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestPlan_String(t *testing.T) {
+	t.Run("no changes", func(t *testing.T) {
+		plan := &Plan{IndexSyncPlan: &mongokit.IndexSyncPlan{}}
+		assert.Equal(t, "No index changes.\n", plan.String())
+	})
+
+	t.Run("groups changes by collection", func(t *testing.T) {
+		email := mongokit.NewIndexBuilder().Key("email", 1).Unique().Build()
+		status := mongo.IndexModel{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetExpireAfterSeconds(3600),
+		}
+		text := mongokit.NewIndexBuilder().Text("body").Build()
+
+		plan := &Plan{IndexSyncPlan: &mongokit.IndexSyncPlan{Changes: []mongokit.IndexChange{
+			{Collection: "orders", Name: "status_1_created_at_-1", Action: mongokit.IndexActionRecreate, Model: &status},
+			{Collection: "orders", Name: "legacy_1", Action: mongokit.IndexActionDrop},
+			{Collection: "posts", Name: "body_text", Action: mongokit.IndexActionCreate, Model: &text},
+			{Collection: "users", Name: "email_1", Action: mongokit.IndexActionCreate, Model: &email},
+		}}}
+
+		assert.Equal(t, "orders:\n"+
+			"  ~ modify status_1_created_at_-1 {status: 1, created_at: -1} ttl=3600s\n"+
+			"  - drop   legacy_1\n"+
+			"posts:\n"+
+			"  + create body_text {body: \"text\"}\n"+
+			"users:\n"+
+			"  + create email_1 {email: 1} unique\n", plan.String())
+	})
+}
+
+func TestIndexPlan_OptionsChanged(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := mongokit.NewFromClient(mock.Client, mongokit.DefaultConfig(), mongokit.WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.users",
+		bson.D{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}, {Key: "name", Value: "_id_"}},
+		bson.D{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "email", Value: 1}}}, {Key: "name", Value: "email_1"},
+			{Key: "partialFilterExpression", Value: bson.D{{Key: "active", Value: true}}}},
+		bson.D{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "age", Value: 1}}}, {Key: "name", Value: "age_1"}},
+		bson.D{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "name", Value: 1}}}, {Key: "name", Value: "name_1"}},
+	))
+
+	plan, err := IndexPlan(context.Background(), client, map[string][]mongo.IndexModel{
+		"users": {
+			mongokit.NewIndexBuilder().Key("email", 1).PartialFilter(bson.M{"active": true, "verified": true}).Build(),
+			mongokit.NewIndexBuilder().Key("age", 1).Hidden().Build(),
+			{Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetCollation(&options.Collation{Locale: "en"})},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "users:\n"+
+		"  ~ modify email_1 {email: 1} partial\n"+
+		"  ~ modify age_1 {age: 1} hidden\n"+
+		"  ~ modify name_1 {name: 1} collation=en\n", plan.String())
+}
@@ -3,11 +3,14 @@ package mongo_kit
 import (
 	"context"
 	"errors"
+	"time"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
 // Database Operations
@@ -27,10 +30,14 @@ func (c *Client) insertOne(ctx context.Context, collection string, document any)
 		return nil, err
 	}
 
+	start := time.Now()
+	ctx, _, cancel := budgeted(ctx)
+	defer cancel()
 	coll := c.getCollection(collection)
 	result, err := coll.InsertOne(ctx, document)
+	c.invalidateQueries(collection)
 	if err != nil {
-		return nil, newOperationError("insert one", err)
+		return nil, c.collectionError(ctx, start, "insert one", collection, err, nil, nil)
 	}
 
 	return result, nil
@@ -38,7 +45,8 @@ func (c *Client) insertOne(ctx context.Context, collection string, document any)
 
 // insertMany inserts multiple documents into the specified collection in a single operation.
 // Returns *mongo.InsertManyResult with the InsertedIDs map.
-func (c *Client) insertMany(ctx context.Context, collection string, documents []any) (*mongo.InsertManyResult, error) {
+// When some documents fail the error wraps a BulkError with the IDs that were inserted.
+func (c *Client) insertMany(ctx context.Context, collection string, documents []any, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
@@ -46,17 +54,26 @@ func (c *Client) insertMany(ctx context.Context, collection string, documents []
 		return nil, err
 	}
 
+	start := time.Now()
+	ctx, _, cancel := budgeted(ctx)
+	defer cancel()
 	coll := c.getCollection(collection)
-	result, err := coll.InsertMany(ctx, documents)
+	result, err := coll.InsertMany(ctx, documents, opts...)
+	c.invalidateQueries(collection)
 	if err != nil {
-		return nil, newOperationError("insert many", err)
+		if result != nil {
+			if bulkErr := newInsertManyError(err, result.InsertedIDs, isOrderedInsert(opts)); bulkErr != nil {
+				err = bulkErr
+			}
+		}
+		return nil, c.collectionError(ctx, start, "insert many", collection, err, nil, nil)
 	}
 
 	return result, nil
 }
 
 // findOne finds a single document matching the filter and decodes it into result.
-// Returns mongo.ErrNoDocuments if no document matches.
+// Returns ErrNotFound if no document matches.
 func (c *Client) findOne(ctx context.Context, collection string, filter any, result any, opts ...*options.FindOneOptions) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -65,14 +82,49 @@ func (c *Client) findOne(ctx context.Context, collection string, filter any, res
 		return err
 	}
 
+	start := time.Now()
+	ctx, maxTime, cancel := budgeted(ctx)
+	defer cancel()
+	if maxTime > 0 {
+		opts = append([]*options.FindOneOptions{options.FindOne().SetMaxTime(maxTime)}, opts...)
+	}
+	if comment := c.comment(ctx); comment != "" {
+		opts = append([]*options.FindOneOptions{options.FindOne().SetComment(comment)}, opts...)
+	}
 	coll := c.getCollection(collection)
 	err := coll.FindOne(ctx, filter, opts...).Decode(result)
 	if err != nil {
-		// Return ErrNoDocuments directly for clearer error handling
-		if errors.Is(err, mongo.ErrNoDocuments) {
-			return err
+		// Return ErrNotFound directly for clearer error handling
+		if errors.Is(err, ErrNotFound) {
+			return ErrNotFound
 		}
-		return newOperationError("find one", err)
+		return c.collectionError(ctx, start, "find one", collection, err, filter, nil)
+	}
+
+	return nil
+}
+
+// findInserted reads the document with the given _id from the primary, so a document
+// inserted just before is found whatever the configured read preference.
+func (c *Client) findInserted(ctx context.Context, collection string, id any, result any) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	ctx, maxTime, cancel := budgeted(ctx)
+	defer cancel()
+	opts := options.FindOne()
+	if maxTime > 0 {
+		opts.SetMaxTime(maxTime)
+	}
+	filter := bson.D{{Key: "_id", Value: id}}
+	coll := c.defaultDB.Collection(collection, options.Collection().SetReadPreference(readpref.Primary()))
+	if err := coll.FindOne(ctx, filter, opts).Decode(result); err != nil {
+		return c.collectionError(ctx, start, "create and return", collection, err, filter, nil)
 	}
 
 	return nil
@@ -88,15 +140,24 @@ func (c *Client) find(ctx context.Context, collection string, filter any, result
 		return err
 	}
 
+	start := time.Now()
+	ctx, maxTime, cancel := budgeted(ctx)
+	defer cancel()
+	if maxTime > 0 {
+		opts = append([]*options.FindOptions{options.Find().SetMaxTime(maxTime)}, opts...)
+	}
+	if comment := c.comment(ctx); comment != "" {
+		opts = append([]*options.FindOptions{options.Find().SetComment(comment)}, opts...)
+	}
 	coll := c.getCollection(collection)
 	cursor, err := coll.Find(ctx, filter, opts...)
 	if err != nil {
-		return newOperationError("find", err)
+		return c.collectionError(ctx, start, "find", collection, err, filter, nil)
 	}
 	defer func() { _ = cursor.Close(ctx) }()
 
 	if err := cursor.All(ctx, results); err != nil {
-		return newOperationError("find decode", err)
+		return c.collectionError(ctx, start, "find decode", collection, err, filter, nil)
 	}
 
 	return nil
@@ -112,15 +173,52 @@ func (c *Client) updateOne(ctx context.Context, collection string, filter any, u
 		return nil, err
 	}
 
+	start := time.Now()
+	ctx, _, cancel := budgeted(ctx)
+	defer cancel()
+	if comment := c.comment(ctx); comment != "" {
+		opts = append([]*options.UpdateOptions{options.Update().SetComment(comment)}, opts...)
+	}
 	coll := c.getCollection(collection)
 	result, err := coll.UpdateOne(ctx, filter, update, opts...)
+	c.invalidateQueries(collection)
 	if err != nil {
-		return nil, newOperationError("update one", err)
+		return nil, c.collectionError(ctx, start, "update one", collection, err, filter, update)
 	}
 
 	return result, nil
 }
 
+// findOneAndUpdate updates a single document matching the filter and decodes the
+// document before or after the update, as set by opts, into result.
+// Returns ErrNotFound if no document matched and none was upserted.
+func (c *Client) findOneAndUpdate(ctx context.Context, collection string, filter any, update any, result any, opts ...*options.FindOneAndUpdateOptions) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	ctx, _, cancel := budgeted(ctx)
+	defer cancel()
+	if comment := c.comment(ctx); comment != "" {
+		opts = append([]*options.FindOneAndUpdateOptions{options.FindOneAndUpdate().SetComment(comment)}, opts...)
+	}
+	coll := c.getCollection(collection)
+	err := coll.FindOneAndUpdate(ctx, filter, update, opts...).Decode(result)
+	c.invalidateQueries(collection)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrNotFound
+		}
+		return c.collectionError(ctx, start, "find one and update", collection, err, filter, update)
+	}
+
+	return nil
+}
+
 // updateMany updates all documents matching the filter.
 // Update must use operators like $set, $inc, etc.
 func (c *Client) updateMany(ctx context.Context, collection string, filter any, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
@@ -130,11 +228,21 @@ func (c *Client) updateMany(ctx context.Context, collection string, filter any,
 	if err := c.checkState(); err != nil {
 		return nil, err
 	}
+	if err := c.checkFullCollection("update many", filter); err != nil {
+		return nil, err
+	}
 
+	start := time.Now()
+	ctx, _, cancel := budgeted(ctx)
+	defer cancel()
+	if comment := c.comment(ctx); comment != "" {
+		opts = append([]*options.UpdateOptions{options.Update().SetComment(comment)}, opts...)
+	}
 	coll := c.getCollection(collection)
 	result, err := coll.UpdateMany(ctx, filter, update, opts...)
+	c.invalidateQueries(collection)
 	if err != nil {
-		return nil, newOperationError("update many", err)
+		return nil, c.collectionError(ctx, start, "update many", collection, err, filter, update)
 	}
 
 	return result, nil
@@ -150,10 +258,14 @@ func (c *Client) deleteOne(ctx context.Context, collection string, filter any, o
 		return nil, err
 	}
 
+	start := time.Now()
+	ctx, _, cancel := budgeted(ctx)
+	defer cancel()
 	coll := c.getCollection(collection)
 	result, err := coll.DeleteOne(ctx, filter, opts...)
+	c.invalidateQueries(collection)
 	if err != nil {
-		return nil, newOperationError("delete one", err)
+		return nil, c.collectionError(ctx, start, "delete one", collection, err, filter, nil)
 	}
 
 	return result, nil
@@ -168,11 +280,18 @@ func (c *Client) deleteMany(ctx context.Context, collection string, filter any,
 	if err := c.checkState(); err != nil {
 		return nil, err
 	}
+	if err := c.checkFullCollection("delete many", filter); err != nil {
+		return nil, err
+	}
 
+	start := time.Now()
+	ctx, _, cancel := budgeted(ctx)
+	defer cancel()
 	coll := c.getCollection(collection)
 	result, err := coll.DeleteMany(ctx, filter, opts...)
+	c.invalidateQueries(collection)
 	if err != nil {
-		return nil, newOperationError("delete many", err)
+		return nil, c.collectionError(ctx, start, "delete many", collection, err, filter, nil)
 	}
 
 	return result, nil
@@ -188,15 +307,45 @@ func (c *Client) countDocuments(ctx context.Context, collection string, filter a
 		return 0, err
 	}
 
+	start := time.Now()
+	ctx, maxTime, cancel := budgeted(ctx)
+	defer cancel()
+	if maxTime > 0 {
+		opts = append([]*options.CountOptions{options.Count().SetMaxTime(maxTime)}, opts...)
+	}
 	coll := c.getCollection(collection)
 	count, err := coll.CountDocuments(ctx, filter, opts...)
 	if err != nil {
-		return 0, newOperationError("count documents", err)
+		return 0, c.collectionError(ctx, start, "count documents", collection, err, filter, nil)
 	}
 
 	return count, nil
 }
 
+// distinct returns the distinct values of field across the documents matching the filter.
+func (c *Client) distinct(ctx context.Context, collection string, field string, filter any, opts ...*options.DistinctOptions) ([]any, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	ctx, maxTime, cancel := budgeted(ctx)
+	defer cancel()
+	if maxTime > 0 {
+		opts = append([]*options.DistinctOptions{options.Distinct().SetMaxTime(maxTime)}, opts...)
+	}
+	coll := c.getCollection(collection)
+	values, err := coll.Distinct(ctx, field, filter, opts...)
+	if err != nil {
+		return nil, c.collectionError(ctx, start, "distinct", collection, err, filter, nil)
+	}
+
+	return values, nil
+}
+
 // aggregate runs an aggregation pipeline and decodes results.
 // Pipeline must be []bson.M, []bson.D, mongo.Pipeline, or bson.A.
 func (c *Client) aggregate(ctx context.Context, collection string, pipeline any, results any, opts ...*options.AggregateOptions) error {
@@ -207,83 +356,138 @@ func (c *Client) aggregate(ctx context.Context, collection string, pipeline any,
 		return err
 	}
 
-	// Validate pipeline type
-	switch pipeline.(type) {
-	case []bson.M, []bson.D, mongo.Pipeline, bson.A:
-		// Valid types - continue
-	case nil:
-		return newOperationError("aggregate", errors.New("pipeline cannot be nil"))
-	default:
-		return newOperationError("aggregate", errors.New("pipeline must be []bson.M, []bson.D, mongo.Pipeline, or bson.A"))
+	start := time.Now()
+	if err := validatePipeline("aggregate", pipeline); err != nil {
+		return err
+	}
+	ctx, maxTime, cancel := budgeted(ctx)
+	defer cancel()
+	if maxTime > 0 {
+		opts = append([]*options.AggregateOptions{options.Aggregate().SetMaxTime(maxTime)}, opts...)
+	}
+	if comment := c.comment(ctx); comment != "" {
+		opts = append([]*options.AggregateOptions{options.Aggregate().SetComment(comment)}, opts...)
 	}
 
 	coll := c.getCollection(collection)
 	cursor, err := coll.Aggregate(ctx, pipeline, opts...)
 	if err != nil {
-		return newOperationError("aggregate", err)
+		return c.collectionError(ctx, start, "aggregate", collection, err, nil, nil)
 	}
 	defer func() { _ = cursor.Close(ctx) }()
 
 	if err := cursor.All(ctx, results); err != nil {
-		return newOperationError("aggregate decode", err)
+		return c.collectionError(ctx, start, "aggregate decode", collection, err, nil, nil)
 	}
 
 	return nil
 }
 
-// convertToObjectID converts a string or ObjectID to primitive.ObjectID.
-// Returns an error if the conversion fails or the ObjectID is invalid.
-func convertToObjectID(id any, operation string) (primitive.ObjectID, error) {
+// aggregateCursor runs an aggregation pipeline and returns the open cursor.
+// The lock is only held while the cursor is created; the caller owns and must close the cursor.
+func (c *Client) aggregateCursor(ctx context.Context, collection string, pipeline any, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	if err := validatePipeline("aggregate", pipeline); err != nil {
+		return nil, err
+	}
+	ctx, maxTime, cancel := budgeted(ctx)
+	defer cancel()
+	if maxTime > 0 {
+		opts = append([]*options.AggregateOptions{options.Aggregate().SetMaxTime(maxTime)}, opts...)
+	}
+	if comment := c.comment(ctx); comment != "" {
+		opts = append([]*options.AggregateOptions{options.Aggregate().SetComment(comment)}, opts...)
+	}
+
+	coll := c.getCollection(collection)
+	cursor, err := coll.Aggregate(ctx, pipeline, opts...)
+	if err != nil {
+		return nil, c.collectionError(ctx, start, "aggregate", collection, err, nil, nil)
+	}
+
+	return cursor, nil
+}
+
+// validatePipeline checks that pipeline is one of the supported pipeline types.
+// Returns an OperationError for the given operation if it is nil or of an unsupported type.
+func validatePipeline(operation string, pipeline any) error {
+	switch pipeline.(type) {
+	case []bson.M, []bson.D, mongo.Pipeline, bson.A:
+		return nil
+	case nil:
+		return newOperationError(operation, errors.New("pipeline cannot be nil"))
+	default:
+		return newOperationError(operation, errors.New("pipeline must be []bson.M, []bson.D, mongo.Pipeline, or bson.A"))
+	}
+}
+
+// convertID converts the ID passed to the ByID operations to its _id filter value:
+// a hex string or ObjectID becomes a primitive.ObjectID, and a uuid.UUID becomes
+// binary subtype 4, as stored by WithUUIDCodec.
+// Returns an error if the conversion fails or the ID is invalid.
+func convertID(id any, operation string) (any, error) {
 	switch v := id.(type) {
 	case string:
 		objID, err := primitive.ObjectIDFromHex(v)
 		if err != nil {
-			return primitive.ObjectID{}, newOperationError(operation, err)
+			return nil, newOperationError(operation, err)
 		}
 		return objID, nil
 	case primitive.ObjectID:
 		if v.IsZero() {
-			return primitive.ObjectID{}, newOperationError(operation, errors.New("ObjectID cannot be zero"))
+			return nil, newOperationError(operation, errors.New("ObjectID cannot be zero"))
 		}
 		return v, nil
+	case uuid.UUID:
+		if v == uuid.Nil {
+			return nil, newOperationError(operation, errors.New("UUID cannot be nil"))
+		}
+		return uuidFilterValue(v), nil
 	default:
-		return primitive.ObjectID{}, newOperationError(operation, mongo.ErrInvalidIndexValue)
+		return nil, newOperationError(operation, mongo.ErrInvalidIndexValue)
 	}
 }
 
 // findByID finds a single document by its _id field.
-// ID can be string or primitive.ObjectID.
+// ID can be a hex string, primitive.ObjectID or uuid.UUID.
 func (c *Client) findByID(ctx context.Context, collection string, id any, result any) error {
-	objID, err := convertToObjectID(id, "find by id")
+	idValue, err := convertID(id, "find by id")
 	if err != nil {
 		return err
 	}
 
-	filter := bson.M{"_id": objID}
+	filter := bson.M{"_id": idValue}
 	return c.findOne(ctx, collection, filter, result)
 }
 
 // updateByID updates a single document by its _id field.
-// ID can be string or primitive.ObjectID.
+// ID can be a hex string, primitive.ObjectID or uuid.UUID.
 func (c *Client) updateByID(ctx context.Context, collection string, id any, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
-	objID, err := convertToObjectID(id, "update by id")
+	idValue, err := convertID(id, "update by id")
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{"_id": objID}
+	filter := bson.M{"_id": idValue}
 	return c.updateOne(ctx, collection, filter, update, opts...)
 }
 
 // deleteByID deletes a single document by its _id field.
-// ID can be string or primitive.ObjectID.
+// ID can be a hex string, primitive.ObjectID or uuid.UUID.
 func (c *Client) deleteByID(ctx context.Context, collection string, id any) (*mongo.DeleteResult, error) {
-	objID, err := convertToObjectID(id, "delete by id")
+	idValue, err := convertID(id, "delete by id")
 	if err != nil {
 		return nil, err
 	}
 
-	filter := bson.M{"_id": objID}
+	filter := bson.M{"_id": idValue}
 	return c.deleteOne(ctx, collection, filter)
 }
 
@@ -297,10 +501,16 @@ func (c *Client) estimatedDocumentCount(ctx context.Context, collection string,
 		return 0, err
 	}
 
+	start := time.Now()
+	ctx, maxTime, cancel := budgeted(ctx)
+	defer cancel()
+	if maxTime > 0 {
+		opts = append([]*options.EstimatedDocumentCountOptions{options.EstimatedDocumentCount().SetMaxTime(maxTime)}, opts...)
+	}
 	coll := c.getCollection(collection)
 	count, err := coll.EstimatedDocumentCount(ctx, opts...)
 	if err != nil {
-		return 0, newOperationError("estimated document count", err)
+		return 0, c.collectionError(ctx, start, "estimated document count", collection, err, nil, nil)
 	}
 
 	return count, nil
@@ -323,9 +533,12 @@ func (c *Client) dropCollection(ctx context.Context, collection string) error {
 		return err
 	}
 
+	start := time.Now()
 	coll := c.getCollection(collection)
-	if err := coll.Drop(ctx); err != nil {
-		return newOperationError("drop collection", err)
+	err := coll.Drop(ctx)
+	c.invalidateQueries(collection)
+	if err != nil {
+		return c.collectionError(ctx, start, "drop collection", collection, err, nil, nil)
 	}
 
 	return nil
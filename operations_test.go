@@ -0,0 +1,41 @@
+package mongo_kit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestValidatePipeline(t *testing.T) {
+	tests := []struct {
+		name        string
+		pipeline    any
+		expectError bool
+		errorMsg    string
+	}{
+		{name: "[]bson.M", pipeline: []bson.M{{"$match": bson.M{}}}},
+		{name: "[]bson.D", pipeline: []bson.D{{{Key: "$match", Value: bson.M{}}}}},
+		{name: "mongo.Pipeline", pipeline: mongo.Pipeline{}},
+		{name: "bson.A", pipeline: bson.A{}},
+		{name: "nil", pipeline: nil, expectError: true, errorMsg: "pipeline cannot be nil"},
+		{name: "unsupported type", pipeline: "invalid", expectError: true, errorMsg: "pipeline must be"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validatePipeline("aggregate", tt.pipeline)
+
+			if tt.expectError {
+				var opErr *OperationError
+				require.ErrorAs(t, err, &opErr)
+				assert.Equal(t, "aggregate", opErr.Op)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Parallel Queries
+//
+// This file provides helpers that run independent queries concurrently with bounded
+// parallelism, for handlers that fan out to many collections.
+//
+// See docs/operations.md for detailed usage guide and examples.
+
+// DefaultParallelism is the number of functions Parallel runs at a time.
+const DefaultParallelism = 8
+
+// Parallel runs fns concurrently, at most DefaultParallelism at a time, and waits for
+// all of them. Every function runs even if others fail; their errors are returned
+// joined, each wrapped with the index of its function. Functions usually store their
+// results in variables captured by their closure.
+//
+// When ctx carries a session, which cannot be used concurrently, the functions run one
+// after the other.
+//
+// Example:
+//
+//	var user *User
+//	var orders []Order
+//	var unread int64
+//	err := mongo_kit.Parallel(ctx,
+//	    func(ctx context.Context) (err error) { user, err = users.FindByID(ctx, id); return },
+//	    func(ctx context.Context) (err error) { orders, err = ordersRepo.Find(ctx, byUser); return },
+//	    func(ctx context.Context) (err error) { unread, err = messages.Count(ctx, unreadByUser); return },
+//	)
+func Parallel(ctx context.Context, fns ...func(ctx context.Context) error) error {
+	return ParallelLimit(ctx, DefaultParallelism, fns...)
+}
+
+// ParallelLimit is like Parallel with at most limit functions running at a time.
+// A limit below 1 runs the functions one after the other.
+func ParallelLimit(ctx context.Context, limit int, fns ...func(ctx context.Context) error) error {
+	if _, ok := SessionFromContext(ctx); ok {
+		limit = 1
+	}
+	limit = max(limit, 1)
+
+	errs := make([]error, len(fns))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, fn := range fns {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx); err != nil {
+				errs[i] = fmt.Errorf("parallel %d: %w", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Gather runs fns like Parallel and returns their results in the order of fns. If any
+// function fails, the joined errors are returned with the results of all functions,
+// zero for those that failed.
+//
+// Example:
+//
+//	counts, err := mongo_kit.Gather(ctx,
+//	    func(ctx context.Context) (int64, error) { return users.CountAll(ctx) },
+//	    func(ctx context.Context) (int64, error) { return orders.CountAll(ctx) },
+//	)
+func Gather[T any](ctx context.Context, fns ...func(ctx context.Context) (T, error)) ([]T, error) {
+	results := make([]T, len(fns))
+	tasks := make([]func(context.Context) error, len(fns))
+	for i, fn := range fns {
+		tasks[i] = func(ctx context.Context) (err error) {
+			results[i], err = fn(ctx)
+			return err
+		}
+	}
+	return results, Parallel(ctx, tasks...)
+}
@@ -0,0 +1,113 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestParallel(t *testing.T) {
+	var a, b int
+	err := Parallel(context.Background(),
+		func(context.Context) error { a = 1; return nil },
+		func(context.Context) error { b = 2; return nil },
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 1, a)
+	assert.Equal(t, 2, b)
+
+	require.NoError(t, Parallel(context.Background()))
+}
+
+func TestParallel_JoinsErrors(t *testing.T) {
+	first, second := errors.New("first"), errors.New("second")
+	var ran atomic.Int32
+	err := Parallel(context.Background(),
+		func(context.Context) error { ran.Add(1); return first },
+		func(context.Context) error { ran.Add(1); return nil },
+		func(context.Context) error { ran.Add(1); return second },
+	)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, first)
+	assert.ErrorIs(t, err, second)
+	assert.Contains(t, err.Error(), "parallel 0: first")
+	assert.Contains(t, err.Error(), "parallel 2: second")
+	assert.EqualValues(t, 3, ran.Load(), "every function runs")
+}
+
+func TestParallelLimit(t *testing.T) {
+	var running, peak atomic.Int32
+	fn := func(context.Context) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	}
+
+	fns := []func(context.Context) error{fn, fn, fn, fn, fn, fn}
+	require.NoError(t, ParallelLimit(context.Background(), 2, fns...))
+	assert.LessOrEqual(t, peak.Load(), int32(2))
+
+	peak.Store(0)
+	require.NoError(t, ParallelLimit(context.Background(), 0, fns...))
+	assert.Equal(t, int32(1), peak.Load())
+}
+
+func TestParallel_SessionRunsSequentially(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	sess, err := mock.Client.StartSession()
+	require.NoError(t, err)
+	defer sess.EndSession(context.Background())
+	ctx := ContextWithSession(context.Background(), sess)
+
+	var running, peak atomic.Int32
+	fn := func(context.Context) error {
+		peak.Store(max(peak.Load(), running.Add(1)))
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+		return nil
+	}
+	require.NoError(t, Parallel(ctx, fn, fn, fn))
+	assert.Equal(t, int32(1), peak.Load())
+}
+
+func TestGather(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	repo := NewRepository[bson.M](client, "users")
+
+	// Both counts get the same reply, whichever runs first
+	mock.AddResponses(
+		testhelpers.CursorResponse("testdb.users", bson.D{{Key: "n", Value: int32(4)}}),
+		testhelpers.CursorResponse("testdb.users", bson.D{{Key: "n", Value: int32(4)}}),
+	)
+	counts, err := Gather(context.Background(),
+		func(ctx context.Context) (int64, error) { return repo.CountAll(ctx) },
+		func(ctx context.Context) (int64, error) { return repo.Count(ctx, bson.M{"active": true}) },
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{4, 4}, counts)
+
+	boom := errors.New("boom")
+	results, err := Gather(context.Background(),
+		func(context.Context) (string, error) { return "a", nil },
+		func(context.Context) (string, error) { return "", boom },
+	)
+	assert.ErrorIs(t, err, boom)
+	assert.Equal(t, []string{"a", ""}, results)
+}
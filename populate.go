@@ -0,0 +1,132 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Relationship Population
+//
+// This file provides Populate, which loads the documents referenced by a slice of
+// documents with a single $in query and attaches them, as an application-side
+// alternative to $lookup.
+//
+// See docs/repository.md for detailed usage guide and examples.
+
+// Populate loads the documents of foreign whose foreignField matches the localField of
+// docs, with one $in query, and calls assign for every document of docs with the
+// documents it references, in the order of its references. Documents without a match
+// are assigned an empty slice.
+//
+// Fields are bson field names and may be dotted paths. Array fields hold several
+// references: a localField of IDs populates a to-many relationship, and a foreignField
+// array matches any of its values. References match foreign values of the same BSON
+// type, e.g. an ObjectID does not match its hex string.
+//
+// Example:
+//
+//	// Post.AuthorID references Author._id
+//	err := mongo_kit.Populate(ctx, posts, "author_id", authors, "_id", func(p *Post, a []Author) {
+//	    if len(a) > 0 {
+//	        p.Author = &a[0]
+//	    }
+//	})
+//
+//	// Comment.PostID references Post._id: one-to-many
+//	err = mongo_kit.Populate(ctx, posts, "_id", comments, "post_id", func(p *Post, c []Comment) {
+//	    p.Comments = c
+//	})
+func Populate[T, R any](ctx context.Context, docs []T, localField string, foreign *Repository[R], foreignField string, assign func(*T, []R)) error {
+	if localField == "" || foreignField == "" {
+		return newOperationError("populate", errors.New("local and foreign fields cannot be empty"))
+	}
+	if assign == nil {
+		return newOperationError("populate", errors.New("assign cannot be nil"))
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	registry := foreign.client.bsonRegistry()
+	references := make([][]bson.RawValue, len(docs))
+	seen := make(map[string]bool)
+	var values bson.A
+	for i := range docs {
+		raw, err := bson.MarshalWithRegistry(registry, docs[i])
+		if err != nil {
+			return newOperationError("populate", fmt.Errorf("encode document %d: %w", i, err))
+		}
+		references[i] = fieldValues(raw, localField)
+		for _, ref := range references[i] {
+			if key := valueKey(ref); !seen[key] {
+				seen[key] = true
+				values = append(values, ref)
+			}
+		}
+	}
+
+	byValue := make(map[string][]R)
+	if len(values) > 0 {
+		ctx, cancel := foreign.withTimeout(ctx)
+		defer cancel()
+
+		var raws []bson.Raw
+		filter := bson.D{{Key: foreignField, Value: bson.D{{Key: "$in", Value: values}}}}
+		if err := foreign.client.find(ctx, foreign.collection, filter, &raws); err != nil {
+			return err
+		}
+		for _, raw := range raws {
+			var related R
+			if err := bson.UnmarshalWithRegistry(registry, raw, &related); err != nil {
+				return newOperationError("populate decode", err)
+			}
+			if err := foreign.decrypt(ctx, &related); err != nil {
+				return err
+			}
+			for _, value := range fieldValues(raw, foreignField) {
+				key := valueKey(value)
+				byValue[key] = append(byValue[key], related)
+			}
+		}
+	}
+
+	for i := range docs {
+		related := []R{}
+		for _, ref := range references[i] {
+			related = append(related, byValue[valueKey(ref)]...)
+		}
+		assign(&docs[i], related)
+	}
+	return nil
+}
+
+// fieldValues returns the values of the field at the dotted path in doc, with arrays
+// expanded into their elements. Missing fields and nulls have no values.
+func fieldValues(doc bson.Raw, path string) []bson.RawValue {
+	value, err := doc.LookupErr(strings.Split(path, ".")...)
+	if err != nil {
+		return nil
+	}
+	switch value.Type {
+	case bsontype.Null, bsontype.Undefined:
+		return nil
+	case bsontype.Array:
+		elems, err := value.Array().Values()
+		if err != nil {
+			return nil
+		}
+		return elems
+	default:
+		return []bson.RawValue{value}
+	}
+}
+
+// valueKey returns a map key identifying a BSON value by its type and bytes.
+func valueKey(value bson.RawValue) string {
+	return string(rune(value.Type)) + string(value.Value)
+}
@@ -0,0 +1,63 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestPopulate_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	authorRepo := NewRepository[populateAuthor](client, "authors")
+	ann := populateAuthor{ID: primitive.NewObjectID(), Name: "Ann"}
+	bob := populateAuthor{ID: primitive.NewObjectID(), Name: "Bob"}
+	_, err = authorRepo.CreateMany(ctx, []populateAuthor{ann, bob})
+	require.NoError(t, err)
+
+	postRepo := NewRepository[populatePost](client, "posts")
+	_, err = postRepo.CreateMany(ctx, []populatePost{
+		{Title: "one", AuthorID: ann.ID, EditorIDs: []primitive.ObjectID{bob.ID}},
+		{Title: "two", AuthorID: bob.ID},
+	})
+	require.NoError(t, err)
+
+	posts, err := postRepo.Find(ctx, bson.M{})
+	require.NoError(t, err)
+	err = Populate(ctx, posts, "author_id", authorRepo, "_id", func(p *populatePost, a []populateAuthor) {
+		if len(a) > 0 {
+			p.Author = &a[0]
+		}
+	})
+	require.NoError(t, err)
+	byTitle := map[string]populatePost{}
+	for _, p := range posts {
+		byTitle[p.Title] = p
+	}
+	assert.Equal(t, ann, *byTitle["one"].Author)
+	assert.Equal(t, bob, *byTitle["two"].Author)
+
+	counts := map[primitive.ObjectID]int{}
+	authors := []populateAuthor{ann, bob}
+	err = Populate(ctx, authors, "_id", postRepo, "author_id", func(a *populateAuthor, p []populatePost) {
+		counts[a.ID] = len(p)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[primitive.ObjectID]int{ann.ID: 1, bob.ID: 1}, counts)
+}
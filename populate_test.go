@@ -0,0 +1,127 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type populateAuthor struct {
+	ID   primitive.ObjectID `bson:"_id"`
+	Name string             `bson:"name"`
+}
+
+type populatePost struct {
+	Title     string               `bson:"title"`
+	AuthorID  primitive.ObjectID   `bson:"author_id"`
+	EditorIDs []primitive.ObjectID `bson:"editor_ids"`
+	Author    *populateAuthor      `bson:"-"`
+	Editors   []populateAuthor     `bson:"-"`
+}
+
+func TestFieldValues(t *testing.T) {
+	doc, err := bson.Marshal(bson.D{
+		{Key: "a", Value: 1},
+		{Key: "tags", Value: bson.A{"x", "y"}},
+		{Key: "nested", Value: bson.D{{Key: "id", Value: "n"}}},
+		{Key: "none", Value: nil},
+	})
+	require.NoError(t, err)
+
+	assert.Len(t, fieldValues(doc, "a"), 1)
+	assert.Len(t, fieldValues(doc, "tags"), 2)
+	assert.Equal(t, "n", fieldValues(doc, "nested.id")[0].StringValue())
+	assert.Empty(t, fieldValues(doc, "none"))
+	assert.Empty(t, fieldValues(doc, "missing"))
+}
+
+func TestPopulate(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	authors := NewRepository[populateAuthor](client, "authors")
+	ctx := context.Background()
+
+	ann, bob, missing := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	posts := []populatePost{
+		{Title: "one", AuthorID: ann, EditorIDs: []primitive.ObjectID{bob, ann}},
+		{Title: "two", AuthorID: bob},
+		{Title: "three", AuthorID: missing},
+	}
+	reply := testhelpers.CursorResponse("testdb.authors",
+		bson.D{{Key: "_id", Value: ann}, {Key: "name", Value: "Ann"}},
+		bson.D{{Key: "_id", Value: bob}, {Key: "name", Value: "Bob"}},
+	)
+
+	mock.AddResponses(reply)
+	err = Populate(ctx, posts, "author_id", authors, "_id", func(p *populatePost, a []populateAuthor) {
+		if len(a) > 0 {
+			p.Author = &a[0]
+		}
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Ann", posts[0].Author.Name)
+	assert.Equal(t, "Bob", posts[1].Author.Name)
+	assert.Nil(t, posts[2].Author)
+
+	mock.AddResponses(reply)
+	err = Populate(ctx, posts, "editor_ids", authors, "_id", func(p *populatePost, a []populateAuthor) {
+		p.Editors = a
+	})
+	require.NoError(t, err)
+	require.Len(t, posts[0].Editors, 2)
+	assert.Equal(t, "Bob", posts[0].Editors[0].Name, "references keep their order")
+	assert.Equal(t, "Ann", posts[0].Editors[1].Name)
+	assert.Equal(t, []populateAuthor{}, posts[1].Editors)
+}
+
+func TestPopulate_OneToMany(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	comments := NewRepository[bson.M](client, "comments")
+
+	authors := []populateAuthor{{ID: primitive.NewObjectID()}, {ID: primitive.NewObjectID()}}
+	mock.AddResponses(testhelpers.CursorResponse("testdb.comments",
+		bson.D{{Key: "author_id", Value: authors[0].ID}, {Key: "text", Value: "a"}},
+		bson.D{{Key: "author_id", Value: authors[0].ID}, {Key: "text", Value: "b"}},
+	))
+	counts := map[primitive.ObjectID]int{}
+	err = Populate(context.Background(), authors, "_id", comments, "author_id", func(a *populateAuthor, c []bson.M) {
+		counts[a.ID] = len(c)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, counts[authors[0].ID])
+	assert.Equal(t, 0, counts[authors[1].ID])
+}
+
+func TestPopulate_Errors(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	authors := NewRepository[populateAuthor](client, "authors")
+	ctx := context.Background()
+	assign := func(*populatePost, []populateAuthor) {}
+
+	err = Populate(ctx, []populatePost{{}}, "", authors, "_id", assign)
+	var opErr *OperationError
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "populate", opErr.Op)
+
+	err = Populate[populatePost, populateAuthor](ctx, []populatePost{{}}, "author_id", authors, "_id", nil)
+	require.ErrorAs(t, err, &opErr)
+
+	// No documents or no references: no query is sent
+	require.NoError(t, Populate(ctx, nil, "author_id", authors, "_id", assign))
+	require.NoError(t, Populate(ctx, []bson.M{{"title": "x"}}, "author_id", authors, "_id", func(*bson.M, []populateAuthor) {}))
+
+	mock.AddResponses(testhelpers.CommandErrorResponse(2, "BadValue", "boom"))
+	err = Populate(ctx, []populatePost{{AuthorID: primitive.NewObjectID()}}, "author_id", authors, "_id", assign)
+	assert.Error(t, err)
+}
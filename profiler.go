@@ -0,0 +1,174 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Database Profiler
+//
+// This file provides helpers to configure the database profiler and read the
+// operations it records in system.profile, so performance investigations can be
+// scripted.
+//
+// See docs/operations.md for detailed usage guide and examples.
+
+// ProfilingLevel is the level of the database profiler.
+type ProfilingLevel int
+
+// Profiler levels.
+const (
+	ProfilingOff  ProfilingLevel = 0 // Record no operations
+	ProfilingSlow ProfilingLevel = 1 // Record operations slower than the slow threshold
+	ProfilingAll  ProfilingLevel = 2 // Record every operation
+)
+
+// ProfilingStatus holds the profiler settings of a database.
+type ProfilingStatus struct {
+	Level      ProfilingLevel // Profiler level
+	Slow       time.Duration  // Threshold above which operations are slow
+	SampleRate float64        // Fraction of slow operations that are recorded
+}
+
+// ProfilerEntry is an operation recorded by the database profiler. Fields not
+// reported for an operation are zero; Raw holds the complete entry.
+type ProfilerEntry struct {
+	Op             string    `bson:"op"`             // Operation type, e.g. "query", "update", "command"
+	Namespace      string    `bson:"ns"`             // Namespace the operation ran on, "database.collection"
+	Command        bson.Raw  `bson:"command"`        // Command document as sent by the client
+	Millis         int64     `bson:"millis"`         // Execution time in milliseconds
+	PlanSummary    string    `bson:"planSummary"`    // Query plan, e.g. "COLLSCAN" or "IXSCAN { email: 1 }"
+	KeysExamined   int64     `bson:"keysExamined"`   // Index keys scanned
+	DocsExamined   int64     `bson:"docsExamined"`   // Documents scanned
+	NReturned      int64     `bson:"nreturned"`      // Documents returned
+	NModified      int64     `bson:"nModified"`      // Documents modified by updates
+	NDeleted       int64     `bson:"ndeleted"`       // Documents deleted
+	ResponseLength int64     `bson:"responseLength"` // Size of the response in bytes
+	Client         string    `bson:"client"`         // Address of the client that ran the operation
+	AppName        string    `bson:"appName"`        // Application name of the client
+	User           string    `bson:"user"`           // Authenticated user
+	Timestamp      time.Time `bson:"ts"`             // Time the operation completed
+	Raw            bson.Raw  `bson:"-"`              // Complete profiler document
+}
+
+// Duration returns the execution time of the operation.
+func (e ProfilerEntry) Duration() time.Duration {
+	return time.Duration(e.Millis) * time.Millisecond
+}
+
+// Comment returns the comment of the command, such as the request ID sent for
+// contexts created WithRequestID, or "" if it has none.
+func (e ProfilerEntry) Comment() string {
+	return commandComment(e.Command)
+}
+
+// commandComment returns the string comment of a command document, or "" if it has
+// none.
+func commandComment(command bson.Raw) string {
+	comment, _ := command.Lookup("comment").StringValueOK()
+	return comment
+}
+
+// SetProfilingLevel sets the profiler level of database db and returns its previous
+// settings. Operations slower than slow are recorded at ProfilingSlow; a zero slow
+// keeps the current threshold. The threshold applies to every database of the server.
+// If db is empty the default database is used.
+//
+// Example:
+//
+//	previous, err := client.SetProfilingLevel(ctx, "", mongo_kit.ProfilingSlow, 50*time.Millisecond)
+//	defer client.SetProfilingLevel(ctx, "", previous.Level, previous.Slow)
+func (c *Client) SetProfilingLevel(ctx context.Context, db string, level ProfilingLevel, slow time.Duration) (ProfilingStatus, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return ProfilingStatus{}, err
+	}
+
+	start := time.Now()
+	if level < ProfilingOff || level > ProfilingAll {
+		return ProfilingStatus{}, newOperationError("set profiling level", errors.New("level must be ProfilingOff, ProfilingSlow or ProfilingAll"))
+	}
+	if slow < 0 {
+		return ProfilingStatus{}, newOperationError("set profiling level", errors.New("slow threshold cannot be negative"))
+	}
+	if db == "" {
+		db = c.defaultDB.Name()
+	}
+
+	cmd := bson.D{{Key: "profile", Value: int32(level)}}
+	if slow > 0 {
+		cmd = append(cmd, bson.E{Key: "slowms", Value: slow.Milliseconds()})
+	}
+
+	var result struct {
+		Was        int32   `bson:"was"`
+		SlowMS     int64   `bson:"slowms"`
+		SampleRate float64 `bson:"sampleRate"`
+	}
+	if err := c.client.Database(db).RunCommand(ctx, cmd).Decode(&result); err != nil {
+		return ProfilingStatus{}, c.operationError(ctx, start, "set profiling level", err)
+	}
+
+	return ProfilingStatus{
+		Level:      ProfilingLevel(result.Was),
+		Slow:       time.Duration(result.SlowMS) * time.Millisecond,
+		SampleRate: result.SampleRate,
+	}, nil
+}
+
+// GetProfilerEntries returns the operations recorded by the profiler of database db
+// that match filter, most recent first. Use nil for all entries and opts to limit
+// them. If db is empty the default database is used.
+//
+// Example:
+//
+//	entries, err := client.GetProfilerEntries(ctx, "",
+//	    bson.M{"ns": "shop.orders", "millis": bson.M{"$gte": 100}},
+//	    options.Find().SetLimit(20))
+//	for _, e := range entries {
+//	    fmt.Println(e.Duration(), e.PlanSummary, e.Comment())
+//	}
+func (c *Client) GetProfilerEntries(ctx context.Context, db string, filter any, opts ...*options.FindOptions) ([]ProfilerEntry, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	if db == "" {
+		db = c.defaultDB.Name()
+	}
+	if filter == nil {
+		filter = bson.D{}
+	}
+
+	opts = append([]*options.FindOptions{options.Find().SetSort(bson.D{{Key: "ts", Value: -1}})}, opts...)
+	cursor, err := c.client.Database(db).Collection("system.profile").Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, c.operationError(ctx, start, "get profiler entries", err)
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	var entries []ProfilerEntry
+	for cursor.Next(ctx) {
+		var entry ProfilerEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, c.operationError(ctx, start, "get profiler entries decode", err)
+		}
+		entry.Raw = append(bson.Raw(nil), cursor.Current...)
+		entries = append(entries, entry)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, c.operationError(ctx, start, "get profiler entries", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,48 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestClient_Profiler_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	previous, err := client.SetProfilingLevel(ctx, "", ProfilingAll, 0)
+	require.NoError(t, err)
+	assert.Equal(t, ProfilingOff, previous.Level)
+
+	repo := NewRepository[bson.M](client, "orders")
+	_, err = repo.Create(ctx, bson.M{"status": "new"})
+	require.NoError(t, err)
+	_, err = repo.Find(WithRequestID(ctx, "req-profiled"), bson.M{"status": "new"})
+	require.NoError(t, err)
+
+	status, err := client.SetProfilingLevel(ctx, "", ProfilingOff, 0)
+	require.NoError(t, err)
+	assert.Equal(t, ProfilingAll, status.Level)
+
+	entries, err := client.GetProfilerEntries(ctx, "", bson.M{"ns": database + ".orders", "op": "query"})
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	assert.Equal(t, "req-profiled", entries[0].Comment())
+	assert.Equal(t, "COLLSCAN", entries[0].PlanSummary)
+	assert.WithinDuration(t, time.Now(), entries[0].Timestamp, time.Minute)
+}
@@ -0,0 +1,93 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestClient_Profiler_ClosedClient(t *testing.T) {
+	client := &Client{closed: true}
+	ctx := context.Background()
+
+	_, err := client.SetProfilingLevel(ctx, "", ProfilingAll, 0)
+	assert.ErrorIs(t, err, ErrClientClosed)
+
+	_, err = client.GetProfilerEntries(ctx, "", nil)
+	assert.ErrorIs(t, err, ErrClientClosed)
+}
+
+func TestClient_SetProfilingLevel(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("returns the previous settings", func(t *testing.T) {
+		mock.AddResponses(testhelpers.SuccessResponse(
+			bson.E{Key: "was", Value: int32(0)},
+			bson.E{Key: "slowms", Value: int32(100)},
+			bson.E{Key: "sampleRate", Value: 1.0},
+		))
+		previous, err := client.SetProfilingLevel(ctx, "", ProfilingSlow, 20*time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, ProfilingStatus{Level: ProfilingOff, Slow: 100 * time.Millisecond, SampleRate: 1}, previous)
+	})
+
+	t.Run("rejects invalid arguments", func(t *testing.T) {
+		var opErr *OperationError
+		_, err := client.SetProfilingLevel(ctx, "", ProfilingLevel(3), 0)
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "set profiling level", opErr.Op)
+
+		_, err = client.SetProfilingLevel(ctx, "", ProfilingSlow, -time.Second)
+		require.ErrorAs(t, err, &opErr)
+	})
+
+	t.Run("returns server errors", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CommandErrorResponse(13, "Unauthorized", "not authorized"))
+		_, err := client.SetProfilingLevel(ctx, "", ProfilingAll, 0)
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "set profiling level", opErr.Op)
+	})
+}
+
+func TestClient_GetProfilerEntries(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	ts := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.AddResponses(testhelpers.CursorResponse("testdb.system.profile", bson.D{
+		{Key: "op", Value: "query"},
+		{Key: "ns", Value: "testdb.orders"},
+		{Key: "command", Value: bson.D{{Key: "find", Value: "orders"}, {Key: "comment", Value: "req-1"}}},
+		{Key: "millis", Value: int32(120)},
+		{Key: "planSummary", Value: "COLLSCAN"},
+		{Key: "docsExamined", Value: int32(5000)},
+		{Key: "nreturned", Value: int32(3)},
+		{Key: "ts", Value: ts},
+	}))
+
+	entries, err := client.GetProfilerEntries(context.Background(), "", bson.M{"ns": "testdb.orders"})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	entry := entries[0]
+	assert.Equal(t, "query", entry.Op)
+	assert.Equal(t, "testdb.orders", entry.Namespace)
+	assert.Equal(t, 120*time.Millisecond, entry.Duration())
+	assert.Equal(t, "COLLSCAN", entry.PlanSummary)
+	assert.Equal(t, int64(5000), entry.DocsExamined)
+	assert.Equal(t, int64(3), entry.NReturned)
+	assert.Equal(t, ts, entry.Timestamp.UTC())
+	assert.Equal(t, "req-1", entry.Comment())
+	assert.Equal(t, "COLLSCAN", entry.Raw.Lookup("planSummary").StringValue())
+}
@@ -0,0 +1,549 @@
+// Package quality scans a collection against the schema of a Go struct and reports the
+// documents that drifted from it: required fields that are missing, fields stored
+// with a type the struct cannot hold and references to documents that do not exist.
+//
+// See docs/quality.md for detailed usage guide and examples.
+package quality
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	"github.com/edaniel30/mongo-kit-go/export"
+)
+
+const (
+	// defaultBatchSize is the number of documents read and checked per batch when
+	// Options.BatchSize is not set.
+	defaultBatchSize = 1000
+	// defaultMaxIssues is the number of issues listed in a report when
+	// Options.MaxIssues is not set.
+	defaultMaxIssues = 1000
+)
+
+// Kind is the kind of a data-quality issue.
+type Kind int
+
+const (
+	MissingField     Kind = iota // A required field is missing
+	WrongType                    // A field has a BSON type the struct field cannot hold
+	InvalidReference             // A reference points to a document that does not exist
+)
+
+// String returns the name of the kind.
+func (k Kind) String() string {
+	switch k {
+	case MissingField:
+		return "missing field"
+	case WrongType:
+		return "wrong type"
+	case InvalidReference:
+		return "invalid reference"
+	default:
+		return "Kind(" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// MarshalText encodes the kind by name, e.g. in JSON reports.
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Reference declares a field that references the documents of another collection.
+type Reference struct {
+	Field        string // Referencing field, as a dotted path; arrays hold several references
+	Collection   string // Referenced collection
+	ForeignField string // Referenced field, as a dotted path (default: "_id")
+}
+
+// Options configures a scan.
+type Options struct {
+	Filter     any                 // Documents to scan (default: every document)
+	References []Reference         // Reference fields to check (default: none)
+	Ignore     []string            // Fields not checked, as dotted paths, e.g. fields stored by custom codecs (default: none)
+	SampleSize int64               // Scan a random sample of this many documents (default: every document)
+	BatchSize  int32               // Documents read and checked per batch (default: 1000)
+	MaxIssues  int                 // Issues listed in the report; further issues are only counted (default: 1000)
+	OnProgress func(scanned int64) // Called after each batch of documents (default: nil)
+}
+
+// validate checks that the options can be used.
+func (o *Options) validate() error {
+	if o.BatchSize < 0 {
+		return errors.New("quality: BatchSize cannot be negative")
+	}
+	if o.SampleSize < 0 {
+		return errors.New("quality: SampleSize cannot be negative")
+	}
+	if o.MaxIssues < 0 {
+		return errors.New("quality: MaxIssues cannot be negative")
+	}
+	for _, ref := range o.References {
+		if ref.Field == "" || ref.Collection == "" {
+			return errors.New("quality: references need a Field and a Collection")
+		}
+	}
+	return nil
+}
+
+// Issue is a data-quality problem of a document.
+type Issue struct {
+	ID       any    // _id of the document
+	Field    string // Dotted path of the field; array elements share the path of the array
+	Kind     Kind   // Kind of problem
+	Expected string // Accepted BSON types, e.g. "int|long", or the referenced "collection.field"
+	Actual   string // BSON type found, or the reference that does not exist; empty for MissingField
+}
+
+// String describes the issue in one line.
+func (i Issue) String() string {
+	switch i.Kind {
+	case WrongType:
+		return fmt.Sprintf("_id %v: %s: wrong type: expected %s, found %s", i.ID, i.Field, i.Expected, i.Actual)
+	case InvalidReference:
+		return fmt.Sprintf("_id %v: %s: invalid reference: %s not found in %s", i.ID, i.Field, i.Actual, i.Expected)
+	default:
+		return fmt.Sprintf("_id %v: %s: %s", i.ID, i.Field, i.Kind)
+	}
+}
+
+// FieldCount is the number of documents with an issue of a kind in a field.
+type FieldCount struct {
+	Field     string
+	Kind      Kind
+	Documents int64
+}
+
+// Report describes the result of a scan.
+type Report struct {
+	Collection string       // Scanned collection
+	Sampled    bool         // Whether a random sample was scanned instead of every document
+	Scanned    int64        // Documents scanned
+	Invalid    int64        // Documents with at least one issue
+	Counts     []FieldCount // Documents per field and kind of issue, most frequent first
+	Issues     []Issue      // The first MaxIssues issues, in scan order
+	Truncated  bool         // Whether issues were left out of Issues
+}
+
+// Failed reports whether at least one document has an issue.
+func (r *Report) Failed() bool {
+	return r.Invalid > 0
+}
+
+// WriteTo writes the report as text: a summary line, the number of documents per
+// field and kind of issue, and the listed issues.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	scope := "documents"
+	if r.Sampled {
+		scope = "sampled documents"
+	}
+	fmt.Fprintf(&buf, "%s: %d %s scanned, %d with issues\n", r.Collection, r.Scanned, scope, r.Invalid)
+
+	if len(r.Counts) > 0 {
+		buf.WriteByte('\n')
+		tw := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "FIELD\tISSUE\tDOCUMENTS")
+		for _, c := range r.Counts {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", c.Field, c.Kind, c.Documents)
+		}
+		_ = tw.Flush()
+	}
+
+	if len(r.Issues) > 0 {
+		buf.WriteByte('\n')
+		for _, issue := range r.Issues {
+			buf.WriteString(issue.String())
+			buf.WriteByte('\n')
+		}
+		if r.Truncated {
+			buf.WriteString("more issues were found but not listed\n")
+		}
+	}
+	return buf.WriteTo(w)
+}
+
+// Scan checks the documents of collection against the schema of struct type T and
+// returns a report of the documents that do not match it:
+//
+//   - fields without omitempty, which the driver always writes, must be present;
+//   - fields must have a BSON type the struct field can hold, e.g. int or long for an
+//     int field, and null only for pointers, slices, maps and interfaces. Embedded
+//     structs and array elements are checked too; fields tagged encrypt must be strings;
+//   - the fields of References must match a document of the referenced collection.
+//
+// Documents are read with an aggregation cursor and checked in batches of BatchSize;
+// references are checked with one $in query per reference and batch. With SampleSize,
+// a random sample of the documents matching Filter is scanned instead.
+//
+// The returned error is only set when the scan itself fails, e.g. when the server is
+// unreachable; the report holds what was scanned so far.
+//
+// Example:
+//
+//	report, err := quality.Scan[Order](ctx, client, "orders", quality.Options{
+//	    SampleSize: 10000,
+//	    References: []quality.Reference{{Field: "customer_id", Collection: "customers"}},
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	if report.Failed() {
+//	    report.WriteTo(os.Stdout)
+//	}
+func Scan[T any](ctx context.Context, client *mongokit.Client, collection string, opts Options) (*Report, error) {
+	report := &Report{Collection: collection, Sampled: opts.SampleSize > 0}
+	if err := opts.validate(); err != nil {
+		return report, err
+	}
+	schema := schemaOf(reflect.TypeFor[T](), "", nil)
+	if schema == nil {
+		return report, fmt.Errorf("quality: %s is not a struct with exported fields", reflect.TypeFor[T]())
+	}
+	if opts.BatchSize == 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+	if opts.MaxIssues == 0 {
+		opts.MaxIssues = defaultMaxIssues
+	}
+	if opts.Filter == nil {
+		opts.Filter = bson.D{}
+	}
+
+	ab := mongokit.NewAggregationBuilder().Match(opts.Filter).BatchSize(opts.BatchSize).AllowDiskUse()
+	if opts.SampleSize > 0 {
+		ab.AddStage(bson.D{{Key: "$sample", Value: bson.D{{Key: "size", Value: opts.SampleSize}}}})
+	}
+	it, err := mongokit.NewRepository[bson.Raw](client, collection).AggregateIterWithBuilder(ctx, ab)
+	if err != nil {
+		return report, err
+	}
+	defer func() { _ = it.Close(ctx) }()
+
+	s := newScanner(client, schema, opts, report)
+	batch := make([]bson.Raw, 0, opts.BatchSize)
+	for it.Next(ctx) {
+		batch = append(batch, it.Current())
+		if len(batch) < int(opts.BatchSize) {
+			continue
+		}
+		if err := s.checkBatch(ctx, batch); err != nil {
+			return s.finish(), err
+		}
+		batch = batch[:0]
+	}
+	if err := it.Err(); err != nil {
+		return s.finish(), err
+	}
+	if len(batch) > 0 {
+		if err := s.checkBatch(ctx, batch); err != nil {
+			return s.finish(), err
+		}
+	}
+	return s.finish(), nil
+}
+
+// scanner checks batches of documents and records their issues in a report.
+type scanner struct {
+	client *mongokit.Client
+	schema []field
+	opts   Options
+	ignore map[string]bool
+	report *Report
+	counts map[FieldCount]int64 // Documents per field and kind, keyed with a zero count
+}
+
+// newScanner returns a scanner recording issues in report.
+func newScanner(client *mongokit.Client, schema []field, opts Options, report *Report) *scanner {
+	s := &scanner{
+		client: client,
+		schema: schema,
+		opts:   opts,
+		ignore: make(map[string]bool, len(opts.Ignore)),
+		report: report,
+		counts: make(map[FieldCount]int64),
+	}
+	for _, path := range opts.Ignore {
+		s.ignore[path] = true
+	}
+	return s
+}
+
+// checkBatch checks the fields and references of batch and records their issues.
+func (s *scanner) checkBatch(ctx context.Context, batch []bson.Raw) error {
+	issues := make([][]Issue, len(batch))
+	for i, doc := range batch {
+		issues[i] = s.checkFields(doc, s.schema, nil)
+	}
+	for _, ref := range s.opts.References {
+		if err := s.checkReference(ctx, batch, ref, issues); err != nil {
+			return err
+		}
+	}
+
+	for i, doc := range batch {
+		s.report.Scanned++
+		if len(issues[i]) == 0 {
+			continue
+		}
+		s.report.Invalid++
+
+		id := documentID(doc)
+		seen := make(map[FieldCount]bool)
+		for _, issue := range issues[i] {
+			key := FieldCount{Field: issue.Field, Kind: issue.Kind}
+			if seen[key] {
+				continue // one issue per field and kind, e.g. for array elements
+			}
+			seen[key] = true
+			s.counts[key]++
+
+			if len(s.report.Issues) == s.opts.MaxIssues {
+				s.report.Truncated = true
+				continue
+			}
+			issue.ID = id
+			s.report.Issues = append(s.report.Issues, issue)
+		}
+	}
+
+	if s.opts.OnProgress != nil {
+		s.opts.OnProgress(s.report.Scanned)
+	}
+	return nil
+}
+
+// checkFields appends the issues of the fields of doc to issues.
+func (s *scanner) checkFields(doc bson.Raw, fields []field, issues []Issue) []Issue {
+	for _, f := range fields {
+		if s.ignore[f.path] {
+			continue
+		}
+		value, err := doc.LookupErr(f.name)
+		if err != nil {
+			if f.required {
+				issues = append(issues, Issue{Field: f.path, Kind: MissingField})
+			}
+			continue
+		}
+		issues = s.checkValue(value, f, issues)
+	}
+	return issues
+}
+
+// checkValue appends the issues of value, the value of field f, to issues.
+func (s *scanner) checkValue(value bson.RawValue, f field, issues []Issue) []Issue {
+	if f.types == nil {
+		return issues
+	}
+	if value.Type == bsontype.Null || value.Type == bsontype.Undefined {
+		if !f.nullable {
+			issues = append(issues, wrongType(f, value.Type))
+		}
+		return issues
+	}
+	if !slices.Contains(f.types, value.Type) {
+		return append(issues, wrongType(f, value.Type))
+	}
+
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		if f.fields != nil {
+			issues = s.checkFields(value.Document(), f.fields, issues)
+		}
+	case bsontype.Array:
+		if f.elem != nil {
+			values, _ := value.Array().Values()
+			for _, elem := range values {
+				issues = s.checkValue(elem, *f.elem, issues)
+			}
+		}
+	}
+	return issues
+}
+
+// wrongType returns a WrongType issue for a value of type t in field f.
+func wrongType(f field, t bsontype.Type) Issue {
+	expected := make([]string, len(f.types))
+	for i, ft := range f.types {
+		expected[i] = typeAlias(ft)
+	}
+	return Issue{Field: f.path, Kind: WrongType, Expected: strings.Join(expected, "|"), Actual: typeAlias(t)}
+}
+
+// checkReference appends to issues[i] the references of field ref.Field of batch[i]
+// that match no document of the referenced collection.
+func (s *scanner) checkReference(ctx context.Context, batch []bson.Raw, ref Reference, issues [][]Issue) error {
+	foreignField := cmp.Or(ref.ForeignField, "_id")
+
+	references := make([][]bson.RawValue, len(batch))
+	seen := make(map[string]bool)
+	var values []any
+	for i, doc := range batch {
+		for _, value := range lookupValues(doc, strings.Split(ref.Field, ".")) {
+			if value.Type == bsontype.Null || value.Type == bsontype.Undefined {
+				continue
+			}
+			references[i] = append(references[i], value)
+			if key := valueKey(value); !seen[key] {
+				seen[key] = true
+				values = append(values, value)
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	qb := mongokit.NewQueryBuilder().In(foreignField, values...).Project(bson.D{{Key: foreignField, Value: 1}})
+	found, err := mongokit.NewRepository[bson.Raw](s.client, ref.Collection).FindWithBuilder(ctx, qb)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool, len(found))
+	for _, doc := range found {
+		for _, value := range lookupValues(doc, strings.Split(foreignField, ".")) {
+			existing[valueKey(value)] = true
+		}
+	}
+
+	target := ref.Collection + "." + foreignField
+	for i, refs := range references {
+		for _, value := range refs {
+			if existing[valueKey(value)] {
+				continue
+			}
+			actual, err := export.CSVValue(value)
+			if err != nil {
+				actual = value.String()
+			}
+			issues[i] = append(issues[i], Issue{Field: ref.Field, Kind: InvalidReference, Expected: target, Actual: actual})
+		}
+	}
+	return nil
+}
+
+// finish sorts the counts of the report, most frequent first, and returns the report.
+func (s *scanner) finish() *Report {
+	s.report.Counts = make([]FieldCount, 0, len(s.counts))
+	for key, n := range s.counts {
+		key.Documents = n
+		s.report.Counts = append(s.report.Counts, key)
+	}
+	slices.SortFunc(s.report.Counts, func(a, b FieldCount) int {
+		return cmp.Or(
+			cmp.Compare(b.Documents, a.Documents),
+			cmp.Compare(a.Field, b.Field),
+			cmp.Compare(a.Kind, b.Kind),
+		)
+	})
+	return s.report
+}
+
+// lookupValues returns the values at path in doc. Arrays along the path are traversed
+// and an array at the end of the path yields its elements, as in MongoDB queries.
+func lookupValues(doc bson.Raw, path []string) []bson.RawValue {
+	value, err := doc.LookupErr(path[0])
+	if err != nil {
+		return nil
+	}
+
+	switch {
+	case value.Type == bsontype.Array:
+		elems, _ := value.Array().Values()
+		if len(path) == 1 {
+			return elems
+		}
+		var values []bson.RawValue
+		for _, elem := range elems {
+			if sub, ok := elem.DocumentOK(); ok {
+				values = append(values, lookupValues(sub, path[1:])...)
+			}
+		}
+		return values
+	case len(path) == 1:
+		return []bson.RawValue{value}
+	case value.Type == bsontype.EmbeddedDocument:
+		return lookupValues(value.Document(), path[1:])
+	default:
+		return nil
+	}
+}
+
+// valueKey returns a key identifying value in maps. Numbers of different types that
+// are equal have the same key, as they match in queries.
+func valueKey(value bson.RawValue) string {
+	switch value.Type {
+	case bsontype.Int32:
+		return "number:" + strconv.FormatInt(int64(value.Int32()), 10)
+	case bsontype.Int64:
+		return "number:" + strconv.FormatInt(value.Int64(), 10)
+	case bsontype.Double:
+		return "number:" + strconv.FormatFloat(value.Double(), 'f', -1, 64)
+	}
+	return string(rune(value.Type)) + string(value.Value)
+}
+
+// documentID returns the _id of doc as a Go value, or nil if it has none.
+func documentID(doc bson.Raw) any {
+	var result struct {
+		ID any `bson:"_id"`
+	}
+	if err := bson.Unmarshal(doc, &result); err != nil {
+		return nil
+	}
+	return result.ID
+}
+
+// typeAlias returns the $type alias of t, e.g. "long" or "objectId".
+func typeAlias(t bsontype.Type) string {
+	switch t {
+	case bsontype.Double:
+		return "double"
+	case bsontype.String:
+		return "string"
+	case bsontype.EmbeddedDocument:
+		return "object"
+	case bsontype.Array:
+		return "array"
+	case bsontype.Binary:
+		return "binData"
+	case bsontype.Undefined:
+		return "undefined"
+	case bsontype.ObjectID:
+		return "objectId"
+	case bsontype.Boolean:
+		return "bool"
+	case bsontype.DateTime:
+		return "date"
+	case bsontype.Null:
+		return "null"
+	case bsontype.Regex:
+		return "regex"
+	case bsontype.JavaScript:
+		return "javascript"
+	case bsontype.Int32:
+		return "int"
+	case bsontype.Timestamp:
+		return "timestamp"
+	case bsontype.Int64:
+		return "long"
+	case bsontype.Decimal128:
+		return "decimal"
+	default:
+		return t.String()
+	}
+}
@@ -0,0 +1,85 @@
+package quality
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestScan_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := mongokit.New(mongokit.DefaultConfig(), mongokit.WithURI(container.URI), mongokit.WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	customer := primitive.NewObjectID()
+	_, err = mongokit.NewRepository[bson.M](client, "customers").Create(ctx, bson.M{"_id": customer})
+	require.NoError(t, err)
+
+	orders := mongokit.NewRepository[bson.M](client, "orders")
+	for i := range 10 {
+		_, err := orders.Create(ctx, bson.M{"_id": i, "customer_id": customer, "total": float64(i), "items": bson.A{}})
+		require.NoError(t, err)
+	}
+	_, err = orders.UpdateByID(ctx, 3, bson.M{"$set": bson.M{"total": "3.00"}})
+	require.NoError(t, err)
+	_, err = orders.UpdateByID(ctx, 5, bson.M{"$set": bson.M{"customer_id": primitive.NewObjectID()}})
+	require.NoError(t, err)
+	_, err = orders.UpdateByID(ctx, 8, bson.M{"$unset": bson.M{"items": ""}})
+	require.NoError(t, err)
+
+	opts := Options{
+		References: []Reference{{Field: "customer_id", Collection: "customers"}},
+		BatchSize:  4,
+	}
+
+	t.Run("scans every document in batches", func(t *testing.T) {
+		var batches int
+		opts := opts
+		opts.OnProgress = func(int64) { batches++ }
+		report, err := Scan[order](ctx, client, "orders", opts)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(10), report.Scanned)
+		assert.Equal(t, int64(3), report.Invalid)
+		assert.Equal(t, 3, batches)
+
+		byID := make(map[any]Kind)
+		for _, issue := range report.Issues {
+			byID[issue.ID] = issue.Kind
+		}
+		assert.Equal(t, map[any]Kind{int32(3): WrongType, int32(5): InvalidReference, int32(8): MissingField}, byID)
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		opts := opts
+		opts.Filter = bson.M{"_id": bson.M{"$lt": 5}}
+		report, err := Scan[order](ctx, client, "orders", opts)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), report.Scanned)
+		assert.Equal(t, int64(1), report.Invalid)
+	})
+
+	t.Run("sample", func(t *testing.T) {
+		opts := opts
+		opts.SampleSize = 4
+		report, err := Scan[order](ctx, client, "orders", opts)
+		require.NoError(t, err)
+		assert.True(t, report.Sampled)
+		assert.Equal(t, int64(4), report.Scanned)
+	})
+}
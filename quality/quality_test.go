@@ -0,0 +1,249 @@
+package quality
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type item struct {
+	SKU string `bson:"sku"`
+	Qty int    `bson:"qty"`
+}
+
+type order struct {
+	ID         int                `bson:"_id"`
+	CustomerID primitive.ObjectID `bson:"customer_id"`
+	Total      float64            `bson:"total"`
+	Items      []item             `bson:"items"`
+	Note       *string            `bson:"note,omitempty"`
+	PaidAt     time.Time          `bson:"paid_at,omitempty"`
+	Card       string             `bson:"card,omitempty" encrypt:"aes"`
+	Extra      map[string]any     `bson:"extra,omitempty"`
+	internal   string
+}
+
+func rawDoc(t *testing.T, doc any) bson.Raw {
+	t.Helper()
+	data, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	return data
+}
+
+func TestKind_String(t *testing.T) {
+	assert.Equal(t, "missing field", MissingField.String())
+	assert.Equal(t, "wrong type", WrongType.String())
+	assert.Equal(t, "invalid reference", InvalidReference.String())
+	assert.Equal(t, "Kind(7)", Kind(7).String())
+}
+
+func TestOptions_Validate(t *testing.T) {
+	assert.NoError(t, (&Options{}).validate())
+	assert.ErrorContains(t, (&Options{BatchSize: -1}).validate(), "cannot be negative")
+	assert.ErrorContains(t, (&Options{SampleSize: -1}).validate(), "cannot be negative")
+	assert.ErrorContains(t, (&Options{MaxIssues: -1}).validate(), "cannot be negative")
+	assert.ErrorContains(t, (&Options{References: []Reference{{Field: "customer_id"}}}).validate(), "need a Field and a Collection")
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema := schemaOf(reflect.TypeFor[order](), "", nil)
+	byName := make(map[string]field)
+	for _, f := range schema {
+		byName[f.name] = f
+	}
+	require.Len(t, byName, 8, "unexported fields are skipped")
+
+	assert.True(t, byName["total"].required)
+	assert.Equal(t, numberTypes, byName["total"].types)
+	assert.False(t, byName["note"].required)
+	assert.True(t, byName["note"].nullable)
+	assert.Equal(t, []bsontype.Type{bsontype.DateTime}, byName["paid_at"].types)
+	assert.Equal(t, encryptedTypes, byName["card"].types)
+
+	items := byName["items"]
+	assert.Equal(t, []bsontype.Type{bsontype.Array}, items.types)
+	require.NotNil(t, items.elem)
+	require.Len(t, items.elem.fields, 2)
+	assert.Equal(t, "items.qty", items.elem.fields[1].path)
+
+	assert.Nil(t, schemaOf(reflect.TypeFor[int](), "", nil))
+}
+
+type category struct {
+	Name     string     `bson:"name"`
+	Parent   *category  `bson:"parent,omitempty"`
+	Children []category `bson:"children,omitempty"`
+}
+
+func TestSchemaOf_SelfReferential(t *testing.T) {
+	schema := schemaOf(reflect.TypeFor[category](), "", nil)
+	require.Len(t, schema, 3)
+	assert.Nil(t, schema[1].types, "the recursive parent accepts any type")
+	assert.Nil(t, schema[2].elem)
+
+	s := newScanner(nil, schema, Options{}, &Report{})
+	doc := rawDoc(t, category{Name: "shoes", Parent: &category{Name: "clothing"}})
+	assert.Empty(t, s.checkFields(doc, s.schema, nil))
+	assert.Equal(t, []Issue{{Field: "name", Kind: MissingField}}, s.checkFields(rawDoc(t, bson.D{}), s.schema, nil))
+}
+
+func TestScanner_CheckFields(t *testing.T) {
+	s := newScanner(nil, schemaOf(reflect.TypeFor[order](), "", nil), Options{}, &Report{})
+
+	valid := rawDoc(t, order{ID: 1, CustomerID: primitive.NewObjectID(), Total: 10, Items: []item{{SKU: "a", Qty: 1}}, Card: "c2VhbGVk"})
+	assert.Empty(t, s.checkFields(valid, s.schema, nil))
+
+	invalid := rawDoc(t, bson.D{
+		{Key: "_id", Value: 2},
+		{Key: "total", Value: "10.50"},
+		{Key: "items", Value: bson.A{bson.D{{Key: "sku", Value: "a"}}, bson.D{{Key: "sku", Value: "b"}, {Key: "qty", Value: int64(2)}}}},
+		{Key: "card", Value: 4111},
+	})
+	issues := s.checkFields(invalid, s.schema, nil)
+	assert.Equal(t, []Issue{
+		{Field: "customer_id", Kind: MissingField},
+		{Field: "total", Kind: WrongType, Expected: "int|long|double", Actual: "string"},
+		{Field: "items.qty", Kind: MissingField},
+		{Field: "card", Kind: WrongType, Expected: "string", Actual: "int"},
+	}, issues)
+
+	t.Run("null only for nillable fields", func(t *testing.T) {
+		doc := rawDoc(t, bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "customer_id", Value: primitive.NewObjectID()},
+			{Key: "total", Value: 1},
+			{Key: "items", Value: nil},
+			{Key: "note", Value: nil},
+		})
+		issues := s.checkFields(doc, s.schema, nil)
+		assert.Equal(t, []Issue{{Field: "_id", Kind: WrongType, Expected: "int|long", Actual: "null"}}, issues)
+	})
+
+	t.Run("ignored fields", func(t *testing.T) {
+		s := newScanner(nil, s.schema, Options{Ignore: []string{"total", "items.qty", "customer_id", "card"}}, &Report{})
+		assert.Empty(t, s.checkFields(invalid, s.schema, nil))
+	})
+}
+
+func TestLookupValues(t *testing.T) {
+	doc := rawDoc(t, bson.D{
+		{Key: "author", Value: bson.D{{Key: "id", Value: 1}}},
+		{Key: "tags", Value: bson.A{"a", "b"}},
+		{Key: "items", Value: bson.A{bson.D{{Key: "sku", Value: "x"}}, bson.D{{Key: "sku", Value: "y"}}}},
+	})
+
+	assert.Len(t, lookupValues(doc, []string{"author", "id"}), 1)
+	assert.Len(t, lookupValues(doc, []string{"tags"}), 2)
+	assert.Len(t, lookupValues(doc, []string{"items", "sku"}), 2)
+	assert.Empty(t, lookupValues(doc, []string{"missing"}))
+	assert.Empty(t, lookupValues(doc, []string{"tags", "x"}))
+}
+
+func TestValueKey(t *testing.T) {
+	values := rawDoc(t, bson.D{{Key: "a", Value: int32(3)}, {Key: "b", Value: int64(3)}, {Key: "c", Value: 3.0}, {Key: "d", Value: "3"}})
+	assert.Equal(t, valueKey(values.Lookup("a")), valueKey(values.Lookup("b")))
+	assert.Equal(t, valueKey(values.Lookup("a")), valueKey(values.Lookup("c")))
+	assert.NotEqual(t, valueKey(values.Lookup("a")), valueKey(values.Lookup("d")))
+}
+
+func TestScan(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := mongokit.NewFromClient(mock.Client, mongokit.DefaultConfig(), mongokit.WithDatabase("testdb"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	known, unknown := primitive.NewObjectID(), primitive.NewObjectID()
+	mock.AddResponses(
+		testhelpers.CursorResponse("testdb.orders",
+			order{ID: 1, CustomerID: known, Total: 10, Items: []item{}},
+			order{ID: 2, CustomerID: unknown, Total: 5, Items: []item{}},
+			bson.D{{Key: "_id", Value: 3}, {Key: "customer_id", Value: known}, {Key: "total", Value: "7"}, {Key: "items", Value: bson.A{}}},
+		),
+		testhelpers.CursorResponse("testdb.customers", bson.D{{Key: "_id", Value: known}}),
+	)
+
+	var progress []int64
+	report, err := Scan[order](ctx, client, "orders", Options{
+		References: []Reference{{Field: "customer_id", Collection: "customers"}},
+		OnProgress: func(n int64) { progress = append(progress, n) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(3), report.Scanned)
+	assert.Equal(t, int64(2), report.Invalid)
+	assert.True(t, report.Failed())
+	assert.Equal(t, []int64{3}, progress)
+	assert.Equal(t, []Issue{
+		{ID: int32(2), Field: "customer_id", Kind: InvalidReference, Expected: "customers._id", Actual: unknown.Hex()},
+		{ID: int32(3), Field: "total", Kind: WrongType, Expected: "int|long|double", Actual: "string"},
+	}, report.Issues)
+	assert.Equal(t, []FieldCount{
+		{Field: "customer_id", Kind: InvalidReference, Documents: 1},
+		{Field: "total", Kind: WrongType, Documents: 1},
+	}, report.Counts)
+
+	t.Run("rejects non-struct types", func(t *testing.T) {
+		_, err := Scan[bson.M](ctx, client, "orders", Options{})
+		assert.ErrorContains(t, err, "not a struct")
+	})
+}
+
+func TestScan_MaxIssues(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := mongokit.NewFromClient(mock.Client, mongokit.DefaultConfig(), mongokit.WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.orders", bson.D{{Key: "_id", Value: 1}}, bson.D{{Key: "_id", Value: 2}}))
+	report, err := Scan[order](context.Background(), client, "orders", Options{MaxIssues: 2})
+	require.NoError(t, err)
+
+	assert.Len(t, report.Issues, 2)
+	assert.True(t, report.Truncated)
+	assert.Equal(t, int64(2), report.Invalid)
+	assert.Len(t, report.Counts, 3, "issues left out are still counted")
+	assert.Equal(t, int64(2), report.Counts[0].Documents)
+}
+
+func TestReport_WriteTo(t *testing.T) {
+	report := &Report{
+		Collection: "orders",
+		Sampled:    true,
+		Scanned:    100,
+		Invalid:    2,
+		Counts: []FieldCount{
+			{Field: "total", Kind: WrongType, Documents: 1},
+			{Field: "customer_id", Kind: MissingField, Documents: 1},
+		},
+		Issues: []Issue{
+			{ID: 7, Field: "total", Kind: WrongType, Expected: "int|long", Actual: "string"},
+			{ID: 9, Field: "customer_id", Kind: MissingField},
+		},
+		Truncated: true,
+	}
+
+	var out bytes.Buffer
+	n, err := report.WriteTo(&out)
+	require.NoError(t, err)
+	assert.Equal(t, int64(out.Len()), n)
+	assert.Equal(t, `orders: 100 sampled documents scanned, 2 with issues
+
+FIELD        ISSUE          DOCUMENTS
+total        wrong type     1
+customer_id  missing field  1
+
+_id 7: total: wrong type: expected int|long, found string
+_id 9: customer_id: missing field
+more issues were found but not listed
+`, out.String())
+}
@@ -0,0 +1,175 @@
+package quality
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	tTime          = reflect.TypeOf(time.Time{})
+	tObjectID      = reflect.TypeOf(primitive.ObjectID{})
+	tDateTime      = reflect.TypeOf(primitive.DateTime(0))
+	tTimestamp     = reflect.TypeOf(primitive.Timestamp{})
+	tDecimal       = reflect.TypeOf(primitive.Decimal128{})
+	tBinary        = reflect.TypeOf(primitive.Binary{})
+	tRegex         = reflect.TypeOf(primitive.Regex{})
+	tUUID          = reflect.TypeOf(uuid.UUID{})
+	tD             = reflect.TypeOf(bson.D{})
+	tRaw           = reflect.TypeOf(bson.Raw{})
+	tMarshaler     = reflect.TypeOf((*bson.Marshaler)(nil)).Elem()
+	tValueMarshal  = reflect.TypeOf((*bson.ValueMarshaler)(nil)).Elem()
+	numberTypes    = []bsontype.Type{bsontype.Int32, bsontype.Int64, bsontype.Double}
+	integerTypes   = []bsontype.Type{bsontype.Int32, bsontype.Int64}
+	encryptedTypes = []bsontype.Type{bsontype.String}
+)
+
+// field is the expected shape of a document field, derived from a struct field.
+type field struct {
+	name     string          // Key in the document
+	path     string          // Dotted path from the document root, used in reports
+	types    []bsontype.Type // Accepted BSON types; nil accepts any type
+	nullable bool            // Whether null is accepted
+	required bool            // Whether the field must be present
+	fields   []field         // Fields of embedded documents, including array elements
+	elem     *field          // Shape of array elements
+}
+
+// schemaOf returns the fields of the documents encoded from struct type t, following
+// the encoding rules of the driver: the key is the bson tag name, or the lowercased
+// field name without one. Fields without omitempty are always encoded, so they are
+// required.
+//
+// seen holds the struct types being described along the path, so self-referential
+// types such as trees terminate; it may be nil at the root.
+func schemaOf(t reflect.Type, prefix string, seen map[reflect.Type]bool) []field {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return nil
+	}
+	if seen == nil {
+		seen = make(map[reflect.Type]bool)
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	var fields []field
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("bson")
+		if tag == "-" {
+			continue
+		}
+
+		name, flags, _ := strings.Cut(tag, ",")
+		omitEmpty, inline := false, false
+		for flag := range strings.SplitSeq(flags, ",") {
+			switch flag {
+			case "omitempty":
+				omitEmpty = true
+			case "inline":
+				inline = true
+			}
+		}
+		if inline {
+			fields = append(fields, schemaOf(sf.Type, prefix, seen)...)
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+
+		f := shapeOf(sf.Type, prefix+name, seen)
+		f.name = name
+		f.required = !omitEmpty
+		if _, ok := sf.Tag.Lookup("encrypt"); ok {
+			// Stored as base64 ciphertext by repositories created WithFieldEncryption.
+			f = field{name: name, path: f.path, types: encryptedTypes, nullable: f.nullable, required: f.required}
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// shapeOf returns the expected shape of the values encoded from Go type t. Struct types
+// in seen, which would recurse, accept any type.
+func shapeOf(t reflect.Type, path string, seen map[reflect.Type]bool) field {
+	f := field{path: path}
+	for t.Kind() == reflect.Pointer {
+		f.nullable = true
+		t = t.Elem()
+	}
+	if t.Implements(tMarshaler) || t.Implements(tValueMarshal) || reflect.PointerTo(t).Implements(tValueMarshal) {
+		return f // encoded by the type itself
+	}
+
+	switch t {
+	case tTime, tDateTime:
+		f.types = []bsontype.Type{bsontype.DateTime}
+		return f
+	case tObjectID:
+		f.types = []bsontype.Type{bsontype.ObjectID}
+		return f
+	case tTimestamp:
+		f.types = []bsontype.Type{bsontype.Timestamp}
+		return f
+	case tDecimal:
+		f.types = []bsontype.Type{bsontype.Decimal128}
+		return f
+	case tBinary, tUUID:
+		f.types = []bsontype.Type{bsontype.Binary}
+		return f
+	case tRegex:
+		f.types = []bsontype.Type{bsontype.Regex}
+		return f
+	case tD, tRaw:
+		f.types = []bsontype.Type{bsontype.EmbeddedDocument}
+		f.nullable = true
+		return f
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		f.types = []bsontype.Type{bsontype.String}
+	case reflect.Bool:
+		f.types = []bsontype.Type{bsontype.Boolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f.types = integerTypes
+	case reflect.Float32, reflect.Float64:
+		f.types = numberTypes
+	case reflect.Struct:
+		if seen[t] {
+			return f // e.g. the parent of a tree node, checked as any type
+		}
+		f.types = []bsontype.Type{bsontype.EmbeddedDocument}
+		f.fields = schemaOf(t, path+".", seen)
+	case reflect.Map:
+		f.types = []bsontype.Type{bsontype.EmbeddedDocument}
+		f.nullable = true
+	case reflect.Slice, reflect.Array:
+		f.nullable = f.nullable || t.Kind() == reflect.Slice
+		if t.Elem().Kind() == reflect.Uint8 {
+			f.types = []bsontype.Type{bsontype.Binary}
+			return f
+		}
+		f.types = []bsontype.Type{bsontype.Array}
+		elem := shapeOf(t.Elem(), path, seen)
+		if elem.types != nil {
+			f.elem = &elem
+		}
+	case reflect.Interface:
+		f.nullable = true // any type
+	}
+	return f
+}
@@ -0,0 +1,448 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Named Queries
+//
+// This file provides a registry of named filters and pipelines, registered once and run
+// by name with parameters, so complex queries live in one place, can be checked against
+// the schema registry at startup and can be tested on their own.
+//
+// See docs/repository.md for detailed usage guide and examples.
+
+// Param is a placeholder in the filter or pipeline of a Query, replaced by the parameter
+// of the same name when the query runs.
+type Param string
+
+// Query is a named find filter or aggregation pipeline.
+type Query struct {
+	Name       string // Unique name of the query
+	Collection string // Collection the query is written for (default: any collection)
+	Filter     any    // Find filter, may contain Params
+	Sort       any    // Sort of the find, e.g. bson.D{{"created_at", -1}} (default: none)
+	Limit      int64  // Maximum number of documents found (default: no limit)
+	Pipeline   any    // Aggregation pipeline run instead of a find, may contain Params
+}
+
+// queries holds the registered queries in registration order.
+var queries struct {
+	mu     sync.RWMutex
+	byName map[string]*Query
+	order  []*Query
+}
+
+// RegisterQuery adds query to the registry of named queries. Call it from an init
+// function or at startup, before ValidateQueries. It panics if the name is empty or
+// already registered, or if the query sets both or neither of Filter and Pipeline.
+//
+// Example:
+//
+//	func init() {
+//	    mongo_kit.RegisterQuery(mongo_kit.Query{
+//	        Name:       "active_premium_users",
+//	        Collection: "users",
+//	        Filter: bson.M{
+//	            "status":     "active",
+//	            "plan":       "premium",
+//	            "last_login": bson.M{"$gte": mongo_kit.Param("since")},
+//	        },
+//	        Sort: bson.D{{Key: "last_login", Value: -1}},
+//	    })
+//	}
+func RegisterQuery(query Query) {
+	if query.Name == "" {
+		panic("mongo_kit: RegisterQuery requires a query name")
+	}
+	if (query.Filter == nil) == (query.Pipeline == nil) {
+		panic(fmt.Sprintf("mongo_kit: query %q must set exactly one of Filter and Pipeline", query.Name))
+	}
+
+	queries.mu.Lock()
+	defer queries.mu.Unlock()
+
+	if _, ok := queries.byName[query.Name]; ok {
+		panic(fmt.Sprintf("mongo_kit: query %q is already registered", query.Name))
+	}
+	if queries.byName == nil {
+		queries.byName = make(map[string]*Query)
+	}
+	queries.byName[query.Name] = &query
+	queries.order = append(queries.order, &query)
+}
+
+// QueryFor returns the query registered with name, and false if there is none.
+func QueryFor(name string) (Query, bool) {
+	queries.mu.RLock()
+	defer queries.mu.RUnlock()
+
+	query, ok := queries.byName[name]
+	if !ok {
+		return Query{}, false
+	}
+	return *query, true
+}
+
+// Queries returns the registered queries in registration order.
+func Queries() []Query {
+	queries.mu.RLock()
+	defer queries.mu.RUnlock()
+
+	result := make([]Query, len(queries.order))
+	for i, q := range queries.order {
+		result[i] = *q
+	}
+	return result
+}
+
+// Bind returns the query with every Param in its filter and pipeline replaced by the
+// parameter of the same name. It fails if a Param has no parameter or a parameter is
+// not used by the query, which catches misspelled names. Bind is what Named runs, so
+// tests can assert the filter a query produces without a database.
+//
+// Example:
+//
+//	query, _ := mongo_kit.QueryFor("active_premium_users")
+//	bound, err := query.Bind(map[string]any{"since": since})
+//	// bound.Filter["last_login"] == bson.M{"$gte": since}
+func (q Query) Bind(params map[string]any) (Query, error) {
+	used := make(map[string]bool, len(params))
+	var err error
+	q.Filter, err = bindParams(q.Filter, params, used)
+	if err != nil {
+		return Query{}, fmt.Errorf("query %q: %w", q.Name, err)
+	}
+	q.Pipeline, err = bindParams(q.Pipeline, params, used)
+	if err != nil {
+		return Query{}, fmt.Errorf("query %q: %w", q.Name, err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(params)) {
+		if !used[name] {
+			return Query{}, fmt.Errorf("query %q: unknown parameter %q", q.Name, name)
+		}
+	}
+	return q, nil
+}
+
+// bindParams returns a copy of v with its Params replaced by their values in params,
+// recording the parameters used. Values other than documents and arrays are kept.
+func bindParams(v any, params map[string]any, used map[string]bool) (any, error) {
+	switch v := v.(type) {
+	case Param:
+		value, ok := params[string(v)]
+		if !ok {
+			return nil, fmt.Errorf("missing parameter %q", string(v))
+		}
+		used[string(v)] = true
+		return value, nil
+	case bson.M:
+		return bindMap(v, params, used)
+	case map[string]any:
+		return bindMap(v, params, used)
+	case bson.D:
+		return bindDoc(v, params, used)
+	case bson.A:
+		return bindSlice(v, params, used)
+	case []any:
+		return bindSlice(v, params, used)
+	case []bson.M:
+		return bindSlice(v, params, used)
+	case []bson.D:
+		return bindSlice(v, params, used)
+	case mongo.Pipeline:
+		return bindSlice(v, params, used)
+	default:
+		return v, nil
+	}
+}
+
+// bindMap binds the values of a map document.
+func bindMap[M ~map[string]any](m M, params map[string]any, used map[string]bool) (M, error) {
+	out := make(M, len(m))
+	for k, v := range m {
+		bound, err := bindParams(v, params, used)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = bound
+	}
+	return out, nil
+}
+
+// bindDoc binds the values of an ordered document.
+func bindDoc(d bson.D, params map[string]any, used map[string]bool) (bson.D, error) {
+	out := make(bson.D, len(d))
+	for i, e := range d {
+		bound, err := bindParams(e.Value, params, used)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = bson.E{Key: e.Key, Value: bound}
+	}
+	return out, nil
+}
+
+// bindSlice binds the elements of an array or pipeline, keeping its type.
+func bindSlice[S ~[]E, E any](s S, params map[string]any, used map[string]bool) (S, error) {
+	out := make(S, len(s))
+	for i, v := range s {
+		bound, err := bindParams(v, params, used)
+		if err != nil {
+			return nil, err
+		}
+		elem, ok := bound.(E)
+		if !ok {
+			return nil, fmt.Errorf("parameter at index %d has type %T, want %T", i, bound, elem)
+		}
+		out[i] = elem
+	}
+	return out, nil
+}
+
+// ValidateQueries checks every registered query: its pipeline must be of a supported
+// type, and for queries with a Collection, the collection must be registered with
+// Register and the fields the filter, or the $match stages of the pipeline, refer to
+// must exist in the registered document type. All problems are returned together.
+// Call it at startup, after the Register and RegisterQuery calls.
+//
+// Example:
+//
+//	if err := mongo_kit.ValidateQueries(); err != nil {
+//	    log.Fatal(err)
+//	}
+func ValidateQueries() error {
+	collections := make(map[string]reflect.Type)
+	for _, schema := range Schemas() {
+		collections[schema.Collection] = schema.Type
+	}
+
+	var errs []error
+	for _, q := range Queries() {
+		if q.Pipeline != nil {
+			if err := validatePipeline("validate queries", q.Pipeline); err != nil {
+				errs = append(errs, fmt.Errorf("query %q: %w", q.Name, err))
+				continue
+			}
+		}
+		if q.Collection == "" {
+			continue
+		}
+		typ, ok := collections[q.Collection]
+		if !ok {
+			errs = append(errs, fmt.Errorf("query %q: collection %q is not registered", q.Name, q.Collection))
+			continue
+		}
+		for _, path := range queryFields(q) {
+			if !hasField(typ, strings.Split(path, ".")) {
+				errs = append(errs, fmt.Errorf("query %q: %s has no field %q", q.Name, typ, path))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// queryFields returns the field paths the filter of q, or the $match stages of its
+// pipeline, refer to.
+func queryFields(q Query) []string {
+	var fields []string
+	if q.Filter != nil {
+		fields = filterFields(q.Filter, fields)
+	}
+	for _, stage := range documentElements(q.Pipeline) {
+		for _, e := range documentFields(stage) {
+			if e.Key == "$match" {
+				fields = filterFields(e.Value, fields)
+			}
+		}
+	}
+	return fields
+}
+
+// filterFields appends the field paths of filter to fields. Operators are skipped,
+// except $and, $or and $nor, whose clauses are filters themselves.
+func filterFields(filter any, fields []string) []string {
+	for _, e := range documentFields(filter) {
+		switch {
+		case e.Key == "$and" || e.Key == "$or" || e.Key == "$nor":
+			for _, clause := range documentElements(e.Value) {
+				fields = filterFields(clause, fields)
+			}
+		case strings.HasPrefix(e.Key, "$"):
+		default:
+			fields = append(fields, e.Key)
+		}
+	}
+	return fields
+}
+
+// documentFields returns the elements of a bson.D, bson.M or map document, with the
+// keys of maps sorted.
+func documentFields(v any) bson.D {
+	switch v := v.(type) {
+	case bson.D:
+		return v
+	case bson.M:
+		return mapFields(v)
+	case map[string]any:
+		return mapFields(v)
+	default:
+		return nil
+	}
+}
+
+// mapFields returns the elements of a map document sorted by key.
+func mapFields[M ~map[string]any](m M) bson.D {
+	d := make(bson.D, 0, len(m))
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		d = append(d, bson.E{Key: k, Value: m[k]})
+	}
+	return d
+}
+
+// documentElements returns the elements of an array or pipeline.
+func documentElements(v any) []any {
+	switch v := v.(type) {
+	case bson.A:
+		return v
+	case []any:
+		return v
+	case []bson.M:
+		out := make([]any, len(v))
+		for i, d := range v {
+			out[i] = d
+		}
+		return out
+	case []bson.D:
+		out := make([]any, len(v))
+		for i, d := range v {
+			out[i] = d
+		}
+		return out
+	case mongo.Pipeline:
+		out := make([]any, len(v))
+		for i, d := range v {
+			out[i] = d
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+// hasField reports whether documents of typ can have the field at path, following the
+// encoding rules of the driver: the key is the bson tag name, or the lowercased field
+// name without one. Maps, interfaces and inline maps accept any field below them.
+func hasField(typ reflect.Type, path []string) bool {
+	for len(path) > 0 {
+		switch typ.Kind() {
+		case reflect.Pointer:
+			typ = typ.Elem()
+		case reflect.Slice, reflect.Array:
+			typ = typ.Elem()
+			if _, err := strconv.Atoi(path[0]); err == nil {
+				path = path[1:]
+			}
+		case reflect.Struct:
+			field, ok := structField(typ, path[0])
+			if !ok {
+				return false
+			}
+			if field == nil {
+				return true // inline map
+			}
+			typ = field
+			path = path[1:]
+		case reflect.Map, reflect.Interface:
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// structField returns the type of the field of struct typ encoded under key, looking
+// into inline structs. A nil type with true means typ has an inline map, which accepts
+// any key.
+func structField(typ reflect.Type, key string) (reflect.Type, bool) {
+	inlineMap := false
+	for i := range typ.NumField() {
+		sf := typ.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("bson")
+		if tag == "-" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+		if slices.Contains(strings.Split(flags, ","), "inline") {
+			ft := sf.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Map {
+				inlineMap = true
+			} else if field, ok := structField(ft, key); ok {
+				return field, true
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		if name == key {
+			return sf.Type, true
+		}
+	}
+	return nil, inlineMap
+}
+
+// Named runs the query registered with name, with its Params replaced by params, and
+// returns the documents it finds or the results of its pipeline. It fails if the query
+// is not registered, if it was registered for another collection, or if params do not
+// match the Params of the query.
+//
+// Example:
+//
+//	users, err := repo.Named(ctx, "active_premium_users", map[string]any{
+//	    "since": time.Now().AddDate(0, -1, 0),
+//	})
+func (r *Repository[T]) Named(ctx context.Context, name string, params map[string]any) ([]T, error) {
+	query, ok := QueryFor(name)
+	if !ok {
+		return nil, newOperationError("named query", fmt.Errorf("query %q is not registered", name))
+	}
+	if query.Collection != "" && query.Collection != r.collection {
+		return nil, newOperationError("named query", fmt.Errorf("query %q is registered for collection %q, not %q", name, query.Collection, r.collection))
+	}
+	query, err := query.Bind(params)
+	if err != nil {
+		return nil, newOperationError("named query", err)
+	}
+
+	if query.Pipeline != nil {
+		return r.Aggregate(ctx, query.Pipeline)
+	}
+	opts := options.Find()
+	if query.Sort != nil {
+		opts.SetSort(query.Sort)
+	}
+	if query.Limit > 0 {
+		opts.SetLimit(query.Limit)
+	}
+	return r.Find(ctx, query.Filter, opts)
+}
@@ -0,0 +1,186 @@
+package mongo_kit
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+// resetQueries empties the query registry for the duration of the test.
+func resetQueries(t *testing.T) {
+	queries.mu.Lock()
+	byName, order := queries.byName, queries.order
+	queries.byName, queries.order = nil, nil
+	queries.mu.Unlock()
+
+	t.Cleanup(func() {
+		queries.mu.Lock()
+		queries.byName, queries.order = byName, order
+		queries.mu.Unlock()
+	})
+}
+
+type queryProfile struct {
+	Plan    string    `bson:"plan"`
+	Renewed time.Time `bson:"renewed"`
+}
+
+type queryUser struct {
+	Name    string         `bson:"name"`
+	Status  string         `bson:"status"`
+	Login   time.Time      `bson:"last_login"`
+	Profile queryProfile   `bson:"profile"`
+	Tags    []queryProfile `bson:"tags"`
+	Extra   map[string]any `bson:",inline"`
+	Hidden  string         `bson:"-"`
+}
+
+func TestRegisterQuery(t *testing.T) {
+	resetQueries(t)
+
+	RegisterQuery(Query{Name: "active", Filter: bson.M{"status": "active"}})
+	RegisterQuery(Query{Name: "by_plan", Pipeline: mongo.Pipeline{{{Key: "$match", Value: bson.M{"plan": Param("plan")}}}}})
+
+	query, ok := QueryFor("active")
+	require.True(t, ok)
+	assert.Equal(t, bson.M{"status": "active"}, query.Filter)
+	_, ok = QueryFor("missing")
+	assert.False(t, ok)
+
+	registered := Queries()
+	require.Len(t, registered, 2)
+	assert.Equal(t, "active", registered[0].Name)
+	assert.Equal(t, "by_plan", registered[1].Name)
+
+	assert.PanicsWithValue(t, `mongo_kit: query "active" is already registered`, func() {
+		RegisterQuery(Query{Name: "active", Filter: bson.M{}})
+	})
+	assert.Panics(t, func() { RegisterQuery(Query{Filter: bson.M{}}) })
+	assert.Panics(t, func() { RegisterQuery(Query{Name: "none"}) })
+	assert.Panics(t, func() { RegisterQuery(Query{Name: "both", Filter: bson.M{}, Pipeline: bson.A{}}) })
+}
+
+func TestQuery_Bind(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := Query{
+		Name: "active_premium_users",
+		Filter: bson.M{
+			"plan":       Param("plan"),
+			"last_login": bson.M{"$gte": Param("since")},
+			"$or":        bson.A{bson.D{{Key: "status", Value: "active"}}, bson.M{"tags": bson.M{"$in": []any{Param("plan")}}}},
+		},
+	}
+
+	bound, err := query.Bind(map[string]any{"plan": "premium", "since": since})
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{
+		"plan":       "premium",
+		"last_login": bson.M{"$gte": since},
+		"$or":        bson.A{bson.D{{Key: "status", Value: "active"}}, bson.M{"tags": bson.M{"$in": []any{"premium"}}}},
+	}, bound.Filter)
+	assert.Equal(t, Param("plan"), query.Filter.(bson.M)["plan"], "the registered query is not modified")
+
+	_, err = query.Bind(map[string]any{"plan": "premium"})
+	assert.EqualError(t, err, `query "active_premium_users": missing parameter "since"`)
+
+	_, err = query.Bind(map[string]any{"plan": "premium", "since": since, "snice": since})
+	assert.EqualError(t, err, `query "active_premium_users": unknown parameter "snice"`)
+
+	pipeline := Query{Name: "p", Pipeline: mongo.Pipeline{{{Key: "$match", Value: bson.M{"plan": Param("plan")}}}}}
+	bound, err = pipeline.Bind(map[string]any{"plan": "free"})
+	require.NoError(t, err)
+	assert.Equal(t, mongo.Pipeline{{{Key: "$match", Value: bson.M{"plan": "free"}}}}, bound.Pipeline)
+}
+
+func TestValidateQueries(t *testing.T) {
+	resetSchemas(t)
+	resetQueries(t)
+
+	Register[queryUser]("query_users")
+	Register[schemaOrder]("query_orders")
+
+	RegisterQuery(Query{Name: "valid", Collection: "query_users", Filter: bson.M{
+		"status":       "active",
+		"profile.plan": Param("plan"),
+		"tags.0.plan":  "free",
+		"nickname":     "inline maps accept any field",
+		"$or":          bson.A{bson.M{"last_login": bson.M{"$gte": Param("since")}}, bson.M{"name": "x"}},
+		"$expr":        bson.M{"$gt": bson.A{"$a", "$b"}},
+	}})
+	RegisterQuery(Query{Name: "any_collection", Filter: bson.M{"whatever": 1}})
+	RegisterQuery(Query{Name: "bad_field", Collection: "query_orders", Filter: bson.D{{Key: "totl", Value: 1}}})
+	RegisterQuery(Query{Name: "bad_match", Collection: "query_orders", Pipeline: []bson.M{
+		{"$match": bson.M{"$and": bson.A{bson.M{"total": 1}, bson.M{"status": "paid"}}}},
+	}})
+	RegisterQuery(Query{Name: "unregistered", Collection: "invoices", Filter: bson.M{}})
+	RegisterQuery(Query{Name: "bad_pipeline", Pipeline: "not a pipeline"})
+
+	err := ValidateQueries()
+	require.Error(t, err)
+	msg := err.Error()
+	assert.Contains(t, msg, `query "bad_field": mongo_kit.schemaOrder has no field "totl"`)
+	assert.Contains(t, msg, `query "bad_match": mongo_kit.schemaOrder has no field "status"`)
+	assert.Contains(t, msg, `query "unregistered": collection "invoices" is not registered`)
+	assert.Contains(t, msg, `query "bad_pipeline"`)
+	assert.NotContains(t, msg, `"valid"`)
+	assert.NotContains(t, msg, "any_collection")
+}
+
+func TestHasField(t *testing.T) {
+	typ := reflect.TypeFor[queryUser]()
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"name", true},
+		{"profile.renewed", true},
+		{"profile.missing", false},
+		{"tags.plan", true},
+		{"tags.1.renewed", true},
+		{"name.first", false},
+		{"anything", true}, // inline map
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, hasField(typ, strings.Split(tt.path, ".")), tt.path)
+	}
+
+	assert.False(t, hasField(reflect.TypeFor[schemaOrder](), []string{"Total"}), "tag names replace field names")
+}
+
+func TestRepository_Named(t *testing.T) {
+	resetQueries(t)
+	RegisterQuery(Query{Name: "by_status", Collection: "users", Filter: bson.M{"status": Param("status")}, Limit: 1})
+	RegisterQuery(Query{Name: "orders_only", Collection: "orders", Filter: bson.M{}})
+
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	repo := NewRepository[bson.M](client, "users")
+	ctx := context.Background()
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.users", bson.D{{Key: "status", Value: "active"}}))
+	users, err := repo.Named(ctx, "by_status", map[string]any{"status": "active"})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "active", users[0]["status"])
+
+	_, err = repo.Named(ctx, "missing", nil)
+	assert.ErrorContains(t, err, `query "missing" is not registered`)
+
+	_, err = repo.Named(ctx, "orders_only", nil)
+	assert.ErrorContains(t, err, `registered for collection "orders"`)
+
+	_, err = repo.Named(ctx, "by_status", nil)
+	var opErr *OperationError
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "named query", opErr.Op)
+}
@@ -2,8 +2,10 @@ package mongo_kit
 
 import (
 	"maps"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
@@ -30,8 +32,9 @@ func NewQueryBuilder() *QueryBuilder {
 }
 
 // Filter adds a filter condition to the query.
+// uuid.UUID values are matched as BSON binary subtype 4, see RegisterUUIDCodec.
 func (qb *QueryBuilder) Filter(key string, value any) *QueryBuilder {
-	qb.filter = append(qb.filter, bson.E{Key: key, Value: value})
+	qb.filter = append(qb.filter, bson.E{Key: key, Value: uuidFilterValue(value)})
 	return qb
 }
 
@@ -42,7 +45,7 @@ func (qb *QueryBuilder) Equals(key string, value any) *QueryBuilder {
 
 // NotEquals adds a not equals filter.
 func (qb *QueryBuilder) NotEquals(key string, value any) *QueryBuilder {
-	return qb.Filter(key, bson.M{"$ne": value})
+	return qb.Filter(key, bson.M{"$ne": uuidFilterValue(value)})
 }
 
 // GreaterThan adds a greater than filter.
@@ -67,12 +70,12 @@ func (qb *QueryBuilder) LessThanOrEqual(key string, value any) *QueryBuilder {
 
 // In adds an in filter.
 func (qb *QueryBuilder) In(key string, values ...any) *QueryBuilder {
-	return qb.Filter(key, bson.M{"$in": values})
+	return qb.Filter(key, bson.M{"$in": uuidFilterValues(values)})
 }
 
 // NotIn adds a not in filter.
 func (qb *QueryBuilder) NotIn(key string, values ...any) *QueryBuilder {
-	return qb.Filter(key, bson.M{"$nin": values})
+	return qb.Filter(key, bson.M{"$nin": uuidFilterValues(values)})
 }
 
 // Exists adds an exists filter.
@@ -80,6 +83,19 @@ func (qb *QueryBuilder) Exists(key string, exists bool) *QueryBuilder {
 	return qb.Filter(key, bson.M{"$exists": exists})
 }
 
+// Type adds a filter on the BSON type of a field. bsonType is a type alias such as
+// "string", "objectId" or "number", a type number, or a bson.A of them.
+func (qb *QueryBuilder) Type(key string, bsonType any) *QueryBuilder {
+	return qb.Filter(key, bson.M{"$type": bsonType})
+}
+
+// AnomalousType adds a filter matching documents where a field exists with a type
+// other than expectedType, e.g. numbers stored as strings. Missing fields do not
+// match; use Exists to find them.
+func (qb *QueryBuilder) AnomalousType(key string, expectedType any) *QueryBuilder {
+	return qb.Filter(key, bson.M{"$exists": true, "$not": bson.M{"$type": expectedType}})
+}
+
 // Regex adds a regex filter.
 func (qb *QueryBuilder) Regex(key string, pattern string, options string) *QueryBuilder {
 	return qb.Filter(key, bson.M{"$regex": pattern, "$options": options})
@@ -145,6 +161,25 @@ func (qb *QueryBuilder) Project(projection any) *QueryBuilder {
 	return qb
 }
 
+// BatchSize sets the number of documents returned by the server per cursor batch.
+func (qb *QueryBuilder) BatchSize(size int32) *QueryBuilder {
+	qb.options.SetBatchSize(size)
+	return qb
+}
+
+// NoCursorTimeout prevents the server from closing the cursor after its idle timeout.
+// Make sure the results are fully consumed or the cursor is closed.
+func (qb *QueryBuilder) NoCursorTimeout() *QueryBuilder {
+	qb.options.SetNoCursorTimeout(true)
+	return qb
+}
+
+// AllowDiskUse lets the server write temporary data to disk for large sorts.
+func (qb *QueryBuilder) AllowDiskUse() *QueryBuilder {
+	qb.options.SetAllowDiskUse(true)
+	return qb
+}
+
 // GetFilter returns the filter without options.
 func (qb *QueryBuilder) GetFilter() bson.D {
 	return qb.filter
@@ -192,6 +227,31 @@ func (qb *QueryBuilder) NorConditions(builders ...*QueryBuilder) *QueryBuilder {
 	return qb.combineConditions("$nor", builders...)
 }
 
+// CreatedBetween filters documents whose ObjectID _id was generated in [from, to),
+// using the timestamp embedded in ObjectIDs, so time ranges can be scanned on the _id
+// index without a separate timestamp field. Bounds have second precision; a zero time
+// leaves that side of the range open.
+func (qb *QueryBuilder) CreatedBetween(from, to time.Time) *QueryBuilder {
+	// The smallest ObjectID of the second: the driver fills in the random and counter bytes.
+	bound := func(t time.Time) primitive.ObjectID {
+		id := primitive.NewObjectIDFromTimestamp(t)
+		clear(id[4:])
+		return id
+	}
+
+	bounds := bson.M{}
+	if !from.IsZero() {
+		bounds["$gte"] = bound(from)
+	}
+	if !to.IsZero() {
+		bounds["$lt"] = bound(to)
+	}
+	if len(bounds) == 0 {
+		return qb
+	}
+	return qb.Filter("_id", bounds)
+}
+
 // Where adds a raw MongoDB expression to the filter.
 func (qb *QueryBuilder) Where(expression any) *QueryBuilder {
 	switch v := expression.(type) {
@@ -291,6 +351,13 @@ func (ub *UpdateBuilder) CurrentDate(key string) *UpdateBuilder {
 	return ub
 }
 
+// CurrentTimestamp sets field to the current time as a BSON timestamp, the type of
+// oplog entries, instead of a date.
+func (ub *UpdateBuilder) CurrentTimestamp(key string) *UpdateBuilder {
+	ub.addOperator("$currentDate", key, bson.M{"$type": "timestamp"})
+	return ub
+}
+
 // Rename renames a field.
 func (ub *UpdateBuilder) Rename(oldName string, newName string) *UpdateBuilder {
 	ub.addOperator("$rename", oldName, newName)
@@ -331,12 +398,14 @@ func (ub *UpdateBuilder) Build() bson.D {
 // AggregationBuilder provides a fluent interface for building aggregation pipelines.
 type AggregationBuilder struct {
 	pipeline []bson.D
+	options  *options.AggregateOptions
 }
 
 // NewAggregationBuilder creates a new AggregationBuilder instance.
 func NewAggregationBuilder() *AggregationBuilder {
 	return &AggregationBuilder{
 		pipeline: []bson.D{},
+		options:  options.Aggregate(),
 	}
 }
 
@@ -404,7 +473,24 @@ func (ab *AggregationBuilder) AddStage(stage bson.D) *AggregationBuilder {
 	return ab
 }
 
+// BatchSize sets the number of documents returned by the server per cursor batch.
+func (ab *AggregationBuilder) BatchSize(size int32) *AggregationBuilder {
+	ab.options.SetBatchSize(size)
+	return ab
+}
+
+// AllowDiskUse lets pipeline stages write temporary data to disk when they exceed the memory limit.
+func (ab *AggregationBuilder) AllowDiskUse() *AggregationBuilder {
+	ab.options.SetAllowDiskUse(true)
+	return ab
+}
+
 // Build returns the aggregation pipeline.
 func (ab *AggregationBuilder) Build() []bson.D {
 	return ab.pipeline
 }
+
+// Options returns the aggregate options configured on the builder.
+func (ab *AggregationBuilder) Options() *options.AggregateOptions {
+	return ab.options
+}
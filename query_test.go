@@ -2,10 +2,12 @@ package mongo_kit
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestNewQueryBuilder(t *testing.T) {
@@ -110,6 +112,25 @@ func TestQueryBuilder_FilterOperators(t *testing.T) {
 				assert.Equal(t, false, m["$exists"])
 			},
 		},
+		{
+			name:        "Type",
+			build:       func() *QueryBuilder { return NewQueryBuilder().Type("age", "number") },
+			expectedKey: "age",
+			validateVal: func(t *testing.T, val any) {
+				m := val.(bson.M)
+				assert.Equal(t, "number", m["$type"])
+			},
+		},
+		{
+			name:        "AnomalousType",
+			build:       func() *QueryBuilder { return NewQueryBuilder().AnomalousType("age", bson.A{"int", "long"}) },
+			expectedKey: "age",
+			validateVal: func(t *testing.T, val any) {
+				m := val.(bson.M)
+				assert.Equal(t, true, m["$exists"])
+				assert.Equal(t, bson.M{"$type": bson.A{"int", "long"}}, m["$not"])
+			},
+		},
 		{
 			name:        "Regex",
 			build:       func() *QueryBuilder { return NewQueryBuilder().Regex("email", ".*@test\\.com$", "i") },
@@ -286,6 +307,56 @@ func TestQueryBuilder_Options(t *testing.T) {
 
 		assert.Equal(t, projection, opts.Projection)
 	})
+
+	t.Run("Cursor options", func(t *testing.T) {
+		qb := NewQueryBuilder().BatchSize(500).NoCursorTimeout().AllowDiskUse()
+		_, opts := qb.Build()
+
+		require.NotNil(t, opts.BatchSize)
+		assert.Equal(t, int32(500), *opts.BatchSize)
+		require.NotNil(t, opts.NoCursorTimeout)
+		assert.True(t, *opts.NoCursorTimeout)
+		require.NotNil(t, opts.AllowDiskUse)
+		assert.True(t, *opts.AllowDiskUse)
+	})
+}
+
+func TestQueryBuilder_CreatedBetween(t *testing.T) {
+	from := time.Date(2024, 3, 1, 12, 30, 45, 500, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	t.Run("both bounds", func(t *testing.T) {
+		filter := NewQueryBuilder().CreatedBetween(from, to).GetFilter()
+
+		require.Len(t, filter, 1)
+		assert.Equal(t, "_id", filter[0].Key)
+		bounds := filter[0].Value.(bson.M)
+		assert.Equal(t, "65e1ca750000000000000000", bounds["$gte"].(primitive.ObjectID).Hex())
+		assert.Equal(t, to.Unix(), bounds["$lt"].(primitive.ObjectID).Timestamp().Unix())
+	})
+
+	t.Run("open upper bound", func(t *testing.T) {
+		filter := NewQueryBuilder().CreatedBetween(from, time.Time{}).GetFilter()
+
+		require.Len(t, filter, 1)
+		bounds := filter[0].Value.(bson.M)
+		assert.Contains(t, bounds, "$gte")
+		assert.NotContains(t, bounds, "$lt")
+	})
+
+	t.Run("open lower bound", func(t *testing.T) {
+		filter := NewQueryBuilder().CreatedBetween(time.Time{}, to).GetFilter()
+
+		require.Len(t, filter, 1)
+		bounds := filter[0].Value.(bson.M)
+		assert.NotContains(t, bounds, "$gte")
+		assert.Contains(t, bounds, "$lt")
+	})
+
+	t.Run("no bounds", func(t *testing.T) {
+		filter := NewQueryBuilder().CreatedBetween(time.Time{}, time.Time{}).GetFilter()
+		assert.Empty(t, filter)
+	})
 }
 
 func TestQueryBuilder_Where(t *testing.T) {
@@ -468,6 +539,14 @@ func TestUpdateBuilder_Operators(t *testing.T) {
 				assert.Equal(t, true, doc["updatedAt"])
 			},
 		},
+		{
+			name:       "CurrentTimestamp",
+			build:      func() *UpdateBuilder { return NewUpdateBuilder().CurrentTimestamp("syncedAt") },
+			expectedOp: "$currentDate",
+			validateDoc: func(t *testing.T, doc bson.M) {
+				assert.Equal(t, bson.M{"$type": "timestamp"}, doc["syncedAt"])
+			},
+		},
 		{
 			name:       "Rename",
 			build:      func() *UpdateBuilder { return NewUpdateBuilder().Rename("old", "new") },
@@ -522,6 +601,18 @@ func TestNewAggregationBuilder(t *testing.T) {
 	ab := NewAggregationBuilder()
 	require.NotNil(t, ab)
 	assert.Empty(t, ab.pipeline)
+	assert.NotNil(t, ab.Options())
+}
+
+func TestAggregationBuilder_Options(t *testing.T) {
+	ab := NewAggregationBuilder().Match(bson.M{}).BatchSize(100).AllowDiskUse()
+
+	opts := ab.Options()
+	require.NotNil(t, opts.BatchSize)
+	assert.Equal(t, int32(100), *opts.BatchSize)
+	require.NotNil(t, opts.AllowDiskUse)
+	assert.True(t, *opts.AllowDiskUse)
+	assert.Len(t, ab.Build(), 1)
 }
 
 func TestAggregationBuilder_Stages(t *testing.T) {
@@ -0,0 +1,230 @@
+package mongo_kit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Query Cache
+//
+// This file provides an optional cache of repository query results, invalidated by
+// collection whenever the client writes to it, for hot read-mostly lookups such as
+// configuration collections.
+//
+// See docs/repository.md for detailed usage guide and examples.
+
+// QueryCache stores encoded query results by collection namespace ("database.collection")
+// and key. NewMemoryQueryCache returns an in-process implementation; implement it over
+// Redis or memcached to share results between processes. Implementations must be safe
+// for concurrent use.
+type QueryCache interface {
+	Get(namespace, key string) ([]byte, bool)
+	Set(namespace, key string, value []byte, ttl time.Duration)
+	Invalidate(namespace string)
+}
+
+// WithQueryCache sets the cache storing the query results of repositories created with
+// WithQueryCaching. Writes made through the client invalidate the cached results of
+// their collection.
+//
+// Example:
+//
+//	client, err := mongo_kit.New(cfg, mongo_kit.WithQueryCache(mongo_kit.NewMemoryQueryCache()))
+//	settings := mongo_kit.NewRepository[Setting](client, "settings", mongo_kit.WithQueryCaching(time.Minute))
+func WithQueryCache(cache QueryCache) Option {
+	return func(c *Config) {
+		c.QueryCache = cache
+	}
+}
+
+// WithQueryCaching caches the results of FindByID, FindOne, Find, FindAll, Count and
+// CountAll for ttl in the QueryCache of the client. Results are cached per filter and
+// options, and dropped when the client writes to the collection; writes made by other
+// processes are only seen once ttl expires. Reads run in a session are not cached.
+// Without a QueryCache on the client, the option has no effect.
+func WithQueryCaching(ttl time.Duration) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.cacheTTL = ttl
+	}
+}
+
+// MemoryQueryCache is an in-process QueryCache. Expired entries are removed when read.
+type MemoryQueryCache struct {
+	mu      sync.Mutex
+	entries map[string]map[string]memoryQueryEntry
+	now     func() time.Time
+}
+
+// memoryQueryEntry is a cached result and its expiration.
+type memoryQueryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryQueryCache returns an empty in-process QueryCache.
+func NewMemoryQueryCache() *MemoryQueryCache {
+	return &MemoryQueryCache{entries: make(map[string]map[string]memoryQueryEntry), now: time.Now}
+}
+
+// Get returns the unexpired value stored for key in namespace.
+func (m *MemoryQueryCache) Get(namespace, key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[namespace][key]
+	if !ok {
+		return nil, false
+	}
+	if !m.now().Before(entry.expiresAt) {
+		delete(m.entries[namespace], key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set stores value for key in namespace for ttl.
+func (m *MemoryQueryCache) Set(namespace, key string, value []byte, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entries[namespace] == nil {
+		m.entries[namespace] = make(map[string]memoryQueryEntry)
+	}
+	m.entries[namespace][key] = memoryQueryEntry{value: value, expiresAt: m.now().Add(ttl)}
+}
+
+// Invalidate removes every value stored in namespace.
+func (m *MemoryQueryCache) Invalidate(namespace string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, namespace)
+}
+
+// queryCache returns the QueryCache of the client and the namespace of collection, or a
+// nil cache when none is configured.
+func (c *Client) queryCache(collection string) (QueryCache, string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.config.QueryCache == nil {
+		return nil, ""
+	}
+	return c.config.QueryCache, c.defaultDB.Name() + "." + collection
+}
+
+// invalidateQueries drops the cached query results of collection.
+// The caller MUST hold c.mu.RLock().
+func (c *Client) invalidateQueries(collection string) {
+	c.invalidateNamespace(c.defaultDB.Name() + "." + collection)
+}
+
+// invalidateNamespace drops the cached query results of a "db.collection" namespace,
+// for writes that may target another database than the default one.
+// The caller MUST hold c.mu.RLock().
+func (c *Client) invalidateNamespace(namespace string) {
+	if c.config.QueryCache != nil {
+		c.queryGeneration(namespace).Add(1)
+		c.config.QueryCache.Invalidate(namespace)
+	}
+}
+
+// queryGeneration returns the invalidation counter of namespace. Reads compare it before
+// and after loading a result, so a result loaded while a write invalidated the
+// namespace is not cached.
+func (c *Client) queryGeneration(namespace string) *atomic.Uint64 {
+	gen, _ := c.queryGenerations.LoadOrStore(namespace, new(atomic.Uint64))
+	return gen.(*atomic.Uint64)
+}
+
+// cached runs load, which stores its result in out, unless a result of the same query is
+// cached, in which case it is decoded into out instead. The query is identified by T,
+// op, filter and opts. Results are cached before decryption, so encrypted fields stay
+// encrypted in the cache. Results loaded while a write invalidated the collection are
+// not cached.
+func (r *Repository[T]) cached(ctx context.Context, op string, filter, opts any, out any, load func() error) error {
+	if r.opts.cacheTTL <= 0 {
+		return load()
+	}
+	if _, ok := SessionFromContext(ctx); ok {
+		return load()
+	}
+	cache, namespace := r.client.queryCache(r.collection)
+	if cache == nil {
+		return load()
+	}
+
+	key, err := queryCacheKey(reflect.TypeFor[T](), op, filter, opts)
+	if err != nil {
+		return load() // uncacheable queries still run
+	}
+	gen := r.client.queryGeneration(namespace)
+	start := gen.Load()
+	registry := r.client.bsonRegistry()
+	if data, ok := cache.Get(namespace, key); ok {
+		if err := bson.Raw(data).Lookup("v").UnmarshalWithRegistry(registry, out); err == nil {
+			return nil
+		}
+	}
+
+	if err := load(); err != nil {
+		return err
+	}
+	if gen.Load() != start {
+		return nil // a write may have changed the result while it was loaded
+	}
+	if data, err := bson.MarshalWithRegistry(registry, bson.D{{Key: "v", Value: out}}); err == nil {
+		cache.Set(namespace, key, data, r.opts.cacheTTL)
+		if gen.Load() != start {
+			// Invalidated between the check and Set: drop what may be a stale result.
+			cache.Invalidate(namespace)
+		}
+	}
+	return nil
+}
+
+// queryCacheKey returns the cache key of a query: a hash of the result type, the
+// operation, the filter as canonical Extended JSON and the options as JSON. The result
+// type keeps repositories of different types on the same collection apart, as they
+// decode the same documents differently.
+func queryCacheKey(typ reflect.Type, op string, filter, opts any) (string, error) {
+	if filter == nil {
+		filter = bson.D{}
+	}
+	filterJSON, err := bson.MarshalExtJSON(bson.D{{Key: "f", Value: filter}}, true, false)
+	if err != nil {
+		return "", err
+	}
+	optsJSON, err := json.Marshal(opts)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(typeKey(typ)))
+	h.Write([]byte{0})
+	h.Write([]byte(op))
+	h.Write([]byte{0})
+	h.Write(filterJSON)
+	h.Write([]byte{0})
+	h.Write(optsJSON)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// typeKey identifies typ across packages: its name qualified with the import path of
+// its element type, e.g. "example.com/app/model []*model.User".
+func typeKey(typ reflect.Type) string {
+	elem := typ
+	for elem.Kind() == reflect.Pointer || elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array || elem.Kind() == reflect.Map {
+		elem = elem.Elem()
+	}
+	return elem.PkgPath() + " " + typ.String()
+}
@@ -0,0 +1,51 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestRepository_QueryCaching_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database),
+		WithQueryCache(NewMemoryQueryCache()))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	settings := NewRepository[bson.M](client, "settings", WithQueryCaching(time.Minute))
+	writer := NewRepository[bson.M](client, "settings")
+	_, err = writer.Create(ctx, bson.M{"key": "theme", "value": "dark"})
+	require.NoError(t, err)
+
+	found, err := settings.FindOne(ctx, bson.M{"key": "theme"})
+	require.NoError(t, err)
+	assert.Equal(t, "dark", (*found)["value"])
+
+	// A write made outside the client is not seen until the ttl expires
+	_, err = client.Database().Collection("settings").UpdateOne(ctx, bson.M{"key": "theme"}, bson.M{"$set": bson.M{"value": "light"}})
+	require.NoError(t, err)
+	found, err = settings.FindOne(ctx, bson.M{"key": "theme"})
+	require.NoError(t, err)
+	assert.Equal(t, "dark", (*found)["value"])
+
+	// A write through any repository of the client invalidates the collection
+	_, err = writer.UpdateOne(ctx, bson.M{"key": "theme"}, bson.M{"$set": bson.M{"value": "blue"}})
+	require.NoError(t, err)
+	found, err = settings.FindOne(ctx, bson.M{"key": "theme"})
+	require.NoError(t, err)
+	assert.Equal(t, "blue", (*found)["value"])
+}
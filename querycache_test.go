@@ -0,0 +1,181 @@
+package mongo_kit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestMemoryQueryCache(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewMemoryQueryCache()
+	cache.now = func() time.Time { return now }
+
+	cache.Set("db.settings", "k", []byte("v"), time.Minute)
+	value, ok := cache.Get("db.settings", "k")
+	require.True(t, ok)
+	assert.Equal(t, []byte("v"), value)
+
+	_, ok = cache.Get("db.other", "k")
+	assert.False(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = cache.Get("db.settings", "k")
+	assert.False(t, ok, "entries expire after their ttl")
+
+	cache.Set("db.settings", "k", []byte("v"), time.Minute)
+	cache.Invalidate("db.settings")
+	_, ok = cache.Get("db.settings", "k")
+	assert.False(t, ok)
+}
+
+func TestQueryCacheKey(t *testing.T) {
+	typ := reflect.TypeFor[bson.M]()
+	a, err := queryCacheKey(typ, "find", bson.M{"name": "a"}, nil)
+	require.NoError(t, err)
+	same, err := queryCacheKey(typ, "find", bson.M{"name": "a"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, a, same)
+
+	others := []struct {
+		typ    reflect.Type
+		op     string
+		filter any
+		opts   any
+	}{
+		{typ, "find", bson.M{"name": "b"}, nil},
+		{typ, "find one", bson.M{"name": "a"}, nil},
+		{typ, "find", bson.M{"name": "a"}, []*options.FindOptions{options.Find().SetLimit(1)}},
+		{typ, "find", bson.M{"name": int32(1)}, nil},
+		{typ, "find", bson.M{"name": "1"}, nil},
+		{reflect.TypeFor[bson.D](), "find", bson.M{"name": "a"}, nil},
+		{reflect.TypeFor[struct{ Name string }](), "find", bson.M{"name": "a"}, nil},
+	}
+	seen := map[string]bool{a: true}
+	for _, o := range others {
+		key, err := queryCacheKey(o.typ, o.op, o.filter, o.opts)
+		require.NoError(t, err)
+		assert.False(t, seen[key], "%s %v %v", o.op, o.filter, o.opts)
+		seen[key] = true
+	}
+}
+
+func TestRepository_QueryCaching(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"), WithQueryCache(NewMemoryQueryCache()))
+	require.NoError(t, err)
+	repo := NewRepository[bson.M](client, "settings", WithQueryCaching(time.Minute))
+	ctx := context.Background()
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.settings", bson.D{{Key: "key", Value: "theme"}, {Key: "value", Value: "dark"}}))
+	first, err := repo.Find(ctx, bson.M{"key": "theme"})
+	require.NoError(t, err)
+
+	// Served from the cache: the mock has no response left
+	cached, err := repo.Find(ctx, bson.M{"key": "theme"})
+	require.NoError(t, err)
+	assert.Equal(t, first, cached)
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.settings", bson.D{{Key: "n", Value: 1}}))
+	count, err := repo.Count(ctx, bson.M{"key": "theme"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	count, err = repo.Count(ctx, bson.M{"key": "theme"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// A write invalidates the collection
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+	_, err = repo.UpdateOne(ctx, bson.M{"key": "theme"}, bson.M{"$set": bson.M{"value": "light"}})
+	require.NoError(t, err)
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.settings", bson.D{{Key: "key", Value: "theme"}, {Key: "value", Value: "light"}}))
+	updated, err := repo.Find(ctx, bson.M{"key": "theme"})
+	require.NoError(t, err)
+	require.Len(t, updated, 1)
+	assert.Equal(t, "light", updated[0]["value"])
+
+	t.Run("reads in a session are not cached", func(t *testing.T) {
+		sess, err := client.StartSession()
+		require.NoError(t, err)
+		defer sess.EndSession(ctx)
+
+		mock.AddResponses(testhelpers.CursorResponse("testdb.settings", bson.D{{Key: "key", Value: "theme"}, {Key: "value", Value: "blue"}}))
+		inSession, err := repo.Find(ContextWithSession(ctx, sess), bson.M{"key": "theme"})
+		require.NoError(t, err)
+		assert.Equal(t, "blue", inSession[0]["value"])
+	})
+
+	t.Run("repositories without caching always query", func(t *testing.T) {
+		plain := NewRepository[bson.M](client, "settings")
+		mock.AddResponses(testhelpers.CursorResponse("testdb.settings"))
+		results, err := plain.Find(ctx, bson.M{"key": "theme"})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestRepository_QueryCaching_PerType(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"), WithQueryCache(NewMemoryQueryCache()))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	type setting struct {
+		Key string `bson:"key"`
+	}
+	maps := NewRepository[bson.M](client, "settings", WithQueryCaching(time.Minute))
+	structs := NewRepository[setting](client, "settings", WithQueryCaching(time.Minute))
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.settings", bson.D{{Key: "key", Value: "theme"}, {Key: "value", Value: "dark"}}))
+	_, err = maps.Find(ctx, bson.M{})
+	require.NoError(t, err)
+
+	// Not served from the entry of the bson.M repository
+	mock.AddResponses(testhelpers.CursorResponse("testdb.settings", bson.D{{Key: "key", Value: "locale"}}))
+	results, err := structs.Find(ctx, bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, []setting{{Key: "locale"}}, results)
+}
+
+func TestRepository_QueryCaching_ConcurrentWrite(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"), WithQueryCache(NewMemoryQueryCache()))
+	require.NoError(t, err)
+	repo := NewRepository[bson.M](client, "settings", WithQueryCaching(time.Minute))
+	ctx := context.Background()
+
+	// The read loads its result, then a write commits and invalidates the collection
+	// before the read stores it.
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+	var stale []bson.M
+	err = repo.cached(ctx, "find", bson.M{"key": "theme"}, nil, &stale, func() error {
+		stale = []bson.M{{"key": "theme", "value": "dark"}}
+		written := make(chan error)
+		go func() {
+			_, err := repo.UpdateOne(ctx, bson.M{"key": "theme"}, bson.M{"$set": bson.M{"value": "light"}})
+			written <- err
+		}()
+		return <-written
+	})
+	require.NoError(t, err)
+
+	var fresh []bson.M
+	loaded := false
+	err = repo.cached(ctx, "find", bson.M{"key": "theme"}, nil, &fresh, func() error {
+		loaded = true
+		fresh = []bson.M{{"key": "theme", "value": "light"}}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, loaded, "the result loaded during the write is not cached")
+	assert.Equal(t, "light", fresh[0]["value"])
+}
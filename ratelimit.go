@@ -0,0 +1,150 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Rate Limiting
+//
+// This file provides a rate limiter shared by all processes using the same
+// collection, built on atomic counters that expire with a TTL index.
+//
+// See docs/coordination.md for detailed usage guide and examples.
+
+// RateLimitStrategy selects how a RateLimiter counts requests.
+type RateLimitStrategy int
+
+const (
+	// FixedWindow counts requests in consecutive windows aligned to the window size.
+	// It is exact within a window but allows up to twice the limit across a boundary.
+	FixedWindow RateLimitStrategy = iota
+	// SlidingWindow weights the count of the previous window by its overlap with the
+	// window ending now, smoothing bursts at window boundaries.
+	SlidingWindow
+)
+
+// RateLimiter decides whether a request is allowed under a limit per window, with
+// one counter document per key and window.
+type RateLimiter struct {
+	client     *Client
+	collection string
+	strategy   RateLimitStrategy
+	index      lazyIndex
+}
+
+// rateCounter is the document counting the requests of a key in a window.
+type rateCounter struct {
+	Count int64 `bson:"count"`
+}
+
+// NewRateLimiter returns a RateLimiter storing counters in collection of the client's
+// database.
+//
+// Example:
+//
+//	limiter := mongo_kit.NewRateLimiter(client, "rate_limits", mongo_kit.SlidingWindow)
+func NewRateLimiter(client *Client, collection string, strategy RateLimitStrategy) *RateLimiter {
+	return &RateLimiter{client: client, collection: collection, strategy: strategy}
+}
+
+// Allow counts a request for key and reports whether it is within limit requests
+// per window. Denied requests are counted too, so a client retrying in a loop stays
+// limited. Windows are aligned to the client's clock.
+//
+// Example:
+//
+//	ok, err := limiter.Allow(ctx, "login:"+ip, 5, time.Minute)
+//	if err != nil {
+//	    return err
+//	}
+//	if !ok {
+//	    http.Error(w, "too many requests", http.StatusTooManyRequests)
+//	    return
+//	}
+func (r *RateLimiter) Allow(ctx context.Context, key string, limit int64, window time.Duration) (bool, error) {
+	if key == "" || limit <= 0 || window <= 0 {
+		return false, newOperationError("rate limit", errors.New("key, a positive limit and a positive window are required"))
+	}
+	if err := r.index.ensure(ctx, r.client, r.collection, expiryIndex("expires_at")); err != nil {
+		return false, err
+	}
+
+	now := r.client.Now()
+	start := now.Truncate(window)
+	// Counters are kept for two windows, so the sliding strategy can read the previous one.
+	count, err := r.client.incrementCounter(ctx, r.collection, counterID(key, start), start.Add(2*window))
+	if err != nil {
+		return false, err
+	}
+
+	if r.strategy == SlidingWindow {
+		previous, err := r.count(ctx, counterID(key, start.Add(-window)))
+		if err != nil {
+			return false, err
+		}
+		count += slidingCount(previous, now.Sub(start), window)
+	}
+
+	return count <= limit, nil
+}
+
+// count returns the count of the counter id, or 0 if it does not exist.
+func (r *RateLimiter) count(ctx context.Context, id string) (int64, error) {
+	var counter rateCounter
+	err := r.client.findOne(ctx, r.collection, bson.D{{Key: "_id", Value: id}}, &counter)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	return counter.Count, err
+}
+
+// counterID returns the _id of the counter of key for the window starting at start.
+func counterID(key string, start time.Time) string {
+	return key + ":" + strconv.FormatInt(start.UnixMilli(), 10)
+}
+
+// slidingCount returns the share of the previous window's count that overlaps the
+// window ending elapsed into the current window.
+func slidingCount(previous int64, elapsed, window time.Duration) int64 {
+	weight := 1 - float64(elapsed)/float64(window)
+	return int64(float64(previous) * weight)
+}
+
+// incrementCounter atomically increments the counter id, creating it with the given
+// expiry, and returns the new count.
+func (c *Client) incrementCounter(ctx context.Context, collection, id string, expiresAt time.Time) (int64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return 0, err
+	}
+
+	filter := bson.D{{Key: "_id", Value: id}}
+	update := bson.D{
+		{Key: "$inc", Value: bson.D{{Key: "count", Value: 1}}},
+		{Key: "$setOnInsert", Value: bson.D{{Key: "expires_at", Value: expiresAt}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	start := time.Now()
+	coll := c.getCollection(collection)
+	var counter rateCounter
+	err := coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&counter)
+	if mongo.IsDuplicateKeyError(err) {
+		// A concurrent request created the counter first; it now exists, so the retry updates it.
+		err = coll.FindOneAndUpdate(ctx, filter, update, opts).Decode(&counter)
+	}
+	if err != nil {
+		return 0, c.collectionError(ctx, start, "rate limit", collection, err, filter, update)
+	}
+
+	return counter.Count, nil
+}
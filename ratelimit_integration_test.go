@@ -0,0 +1,73 @@
+package mongo_kit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestRateLimiter_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	clock := testhelpers.NewFakeClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database), WithClock(clock))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+
+	t.Run("concurrent requests share the limit", func(t *testing.T) {
+		limiter := NewRateLimiter(client, "limits", FixedWindow)
+
+		var allowed atomic.Int64
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ok, err := limiter.Allow(ctx, "concurrent", 5, time.Minute)
+				assert.NoError(t, err)
+				if ok {
+					allowed.Add(1)
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int64(5), allowed.Load())
+
+		clock.Advance(time.Minute)
+		ok, err := limiter.Allow(ctx, "concurrent", 5, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, ok, "a new window starts with a fresh count")
+	})
+
+	t.Run("sliding window counts the previous window", func(t *testing.T) {
+		limiter := NewRateLimiter(client, "sliding", SlidingWindow)
+
+		for range 4 {
+			ok, err := limiter.Allow(ctx, "user", 4, time.Minute)
+			require.NoError(t, err)
+			assert.True(t, ok)
+		}
+
+		clock.Advance(time.Minute + 15*time.Second)
+		ok, err := limiter.Allow(ctx, "user", 4, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = limiter.Allow(ctx, "user", 4, time.Minute)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,83 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func counterResponse(count int64) bson.D {
+	return testhelpers.SuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "count", Value: count}}})
+}
+
+func TestRateLimiter(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	clock := testhelpers.NewFakeClock(time.Date(2024, 3, 1, 12, 0, 15, 0, time.UTC))
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"), WithClock(clock))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("fixed window", func(t *testing.T) {
+		limiter := NewRateLimiter(client, "limits", FixedWindow)
+
+		mock.AddResponses(testhelpers.SuccessResponse(), counterResponse(3))
+		ok, err := limiter.Allow(ctx, "login", 3, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		mock.AddResponses(counterResponse(4))
+		ok, err = limiter.Allow(ctx, "login", 3, time.Minute)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("sliding window", func(t *testing.T) {
+		limiter := NewRateLimiter(client, "limits", SlidingWindow)
+
+		// 15s into the window: 75% of the previous window's 4 requests still count.
+		mock.AddResponses(
+			testhelpers.SuccessResponse(),
+			counterResponse(1),
+			testhelpers.CursorResponse("testdb.limits", bson.D{{Key: "count", Value: 4}}),
+		)
+		ok, err := limiter.Allow(ctx, "login", 4, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		mock.AddResponses(
+			counterResponse(2),
+			testhelpers.CursorResponse("testdb.limits", bson.D{{Key: "count", Value: 4}}),
+		)
+		ok, err = limiter.Allow(ctx, "login", 4, time.Minute)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		limiter := NewRateLimiter(client, "limits", FixedWindow)
+
+		_, err := limiter.Allow(ctx, "", 1, time.Minute)
+		assert.Error(t, err)
+		_, err = limiter.Allow(ctx, "login", 0, time.Minute)
+		assert.Error(t, err)
+		_, err = limiter.Allow(ctx, "login", 1, 0)
+		assert.Error(t, err)
+	})
+}
+
+func TestSlidingCount(t *testing.T) {
+	assert.Equal(t, int64(10), slidingCount(10, 0, time.Minute))
+	assert.Equal(t, int64(5), slidingCount(10, 30*time.Second, time.Minute))
+	assert.Equal(t, int64(0), slidingCount(10, time.Minute, time.Minute))
+}
+
+func TestCounterID(t *testing.T) {
+	start := time.UnixMilli(1709294400000)
+	assert.Equal(t, "login:1709294400000", counterID("login", start))
+}
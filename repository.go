@@ -3,8 +3,14 @@ package mongo_kit
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -19,69 +25,332 @@ import (
 type Repository[T any] struct {
 	client     *Client
 	collection string
+	opts       repositoryOptions
+	textIndex  lazyIndex
+}
+
+// repositoryOptions holds the optional behavior configured with RepositoryOption functions.
+type repositoryOptions struct {
+	encryptor *FieldEncryptor
+	cacheTTL  time.Duration
+	audit     bool
+	timeout   time.Duration
+}
+
+// RepositoryOption is a function that configures optional Repository behavior.
+type RepositoryOption func(*repositoryOptions)
+
+// WithFieldEncryption encrypts fields tagged `encrypt:"aes"` in Create and CreateMany
+// and decrypts them in FindByID, FindOne and Find (and the builder variants), using
+// the AES key returned by keys. Encrypted fields cannot be used in query filters.
+//
+// Example:
+//
+//	repo := mongo_kit.NewRepository[Patient](client, "patients",
+//	    mongo_kit.WithFieldEncryption(mongo_kit.StaticKeyProvider(key)))
+func WithFieldEncryption(keys KeyProvider) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.encryptor = NewFieldEncryptor(keys)
+	}
+}
+
+// WithOperationTimeout limits every operation of the repository to timeout, for
+// latency-critical collections that need tighter deadlines than the rest of the
+// service. A shorter deadline already set on the context is kept. Iterators returned by
+// AggregateIter are not limited, as they outlive the call that opens them.
+//
+// Example:
+//
+//	sessions := mongo_kit.NewRepository[Session](client, "sessions",
+//	    mongo_kit.WithOperationTimeout(200*time.Millisecond))
+func WithOperationTimeout(timeout time.Duration) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.timeout = timeout
+	}
 }
 
 // NewRepository creates a new type-safe repository for the specified collection.
-func NewRepository[T any](client *Client, collection string) *Repository[T] {
-	return &Repository[T]{
+func NewRepository[T any](client *Client, collection string, opts ...RepositoryOption) *Repository[T] {
+	r := &Repository[T]{
 		client:     client,
 		collection: collection,
 	}
+	for _, opt := range opts {
+		opt(&r.opts)
+	}
+	return r
+}
+
+// withTimeout returns ctx limited to the operation timeout of the repository, if any.
+func (r *Repository[T]) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.opts.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, r.opts.timeout)
+}
+
+// encrypt encrypts the tagged fields of document when field encryption is enabled.
+func (r *Repository[T]) encrypt(ctx context.Context, document *T) error {
+	if r.opts.encryptor == nil {
+		return nil
+	}
+	return r.opts.encryptor.Encrypt(ctx, document)
+}
+
+// decrypt decrypts the tagged fields of document when field encryption is enabled.
+func (r *Repository[T]) decrypt(ctx context.Context, document *T) error {
+	if r.opts.encryptor == nil {
+		return nil
+	}
+	return r.opts.encryptor.Decrypt(ctx, document)
+}
+
+// decodeScored decodes documents returned with a numeric score in field, such as a
+// distance or a relevance score, into T and the score of each document.
+func (r *Repository[T]) decodeScored(ctx context.Context, op string, docs []bson.Raw, field string) ([]T, []float64, error) {
+	registry := r.client.bsonRegistry()
+	results := make([]T, len(docs))
+	scores := make([]float64, len(docs))
+	for i, doc := range docs {
+		if err := bson.UnmarshalWithRegistry(registry, doc, &results[i]); err != nil {
+			return nil, nil, newOperationError(op+" decode", err)
+		}
+		if err := r.decrypt(ctx, &results[i]); err != nil {
+			return nil, nil, err
+		}
+		score, ok := doc.Lookup(field).DoubleOK()
+		if !ok {
+			return nil, nil, newOperationError(op+" decode", fmt.Errorf("result %d has no %s", i, field))
+		}
+		scores[i] = score
+	}
+	return results, scores, nil
 }
 
 // Create inserts a new document and returns its ID.
+// When T is a pointer type, the generated _id is also set on the document, see Insert.
 func (r *Repository[T]) Create(ctx context.Context, document T) (any, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	r.auditCreated(ctx, &document)
+	original := document // encrypt replaces a pointer T with an encrypted copy
+	if err := r.encrypt(ctx, &document); err != nil {
+		return nil, err
+	}
+
 	result, err := r.client.insertOne(ctx, r.collection, document)
 	if err != nil {
 		return nil, err
 	}
+	setInsertedID(original, result.InsertedID)
 	return result.InsertedID, nil
 }
 
+// CreateID inserts a new document like Create and returns its ID as an ObjectID, for
+// collections whose _id is generated by the driver or set as an ObjectID.
+// If the document has an _id of another type, it is inserted and an error is returned.
+//
+// Example:
+//
+//	id, err := repo.CreateID(ctx, user)
+//	fmt.Println(id.Hex())
+func (r *Repository[T]) CreateID(ctx context.Context, document T) (primitive.ObjectID, error) {
+	id, err := r.Create(ctx, document)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	oid, ok := id.(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID, newOperationError("create id", fmt.Errorf("inserted _id is %T, not an ObjectID", id))
+	}
+	return oid, nil
+}
+
+// Insert inserts a new document like Create and sets the generated _id on it: when the
+// field tagged `bson:"_id"` is zero, it receives the ID generated by the driver.
+//
+// Example:
+//
+//	user := User{Name: "Alice"}
+//	if _, err := repo.Insert(ctx, &user); err != nil {
+//	    return err
+//	}
+//	fmt.Println(user.ID.Hex())
+func (r *Repository[T]) Insert(ctx context.Context, document *T) (any, error) {
+	r.auditCreated(ctx, document)
+	id, err := r.Create(ctx, *document)
+	if err != nil {
+		return nil, err
+	}
+	setInsertedID(document, id)
+	return id, nil
+}
+
+// Registry returns the BSON registry the repository encodes and decodes documents
+// with: the BSONRegistry of the client configuration, or the default registry.
+func (r *Repository[T]) Registry() *bsoncodec.Registry {
+	return r.client.bsonRegistry()
+}
+
+// EncodeDocument returns document as Create writes it: with the audit fields stamped
+// and the tagged fields encrypted, encoded with the client's BSON registry. Use it to
+// build the insert and replace models of BulkWrite, which writes documents unchanged.
+//
+// Example:
+//
+//	doc, err := repo.EncodeDocument(ctx, user)
+//	if err != nil {
+//	    return err
+//	}
+//	models = append(models, mongo.NewInsertOneModel().SetDocument(doc))
+func (r *Repository[T]) EncodeDocument(ctx context.Context, document T) (bson.Raw, error) {
+	r.auditCreated(ctx, &document)
+	if err := r.encrypt(ctx, &document); err != nil {
+		return nil, err
+	}
+
+	data, err := bson.MarshalWithRegistry(r.client.bsonRegistry(), document)
+	if err != nil {
+		return nil, newOperationError("encode document", err)
+	}
+	return data, nil
+}
+
+// setInsertedID sets id on the _id field of the struct document points to, when the
+// field is zero and id is assignable to it. Other documents are left unchanged.
+func setInsertedID(document any, id any) {
+	v := reflect.ValueOf(document)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || !v.CanSet() || id == nil {
+		return
+	}
+
+	for i := range v.NumField() {
+		sf := v.Type().Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("bson"), ",")
+		if name != "_id" || !sf.IsExported() {
+			continue
+		}
+		field := v.Field(i)
+		if field.IsZero() && reflect.TypeOf(id).AssignableTo(field.Type()) {
+			field.Set(reflect.ValueOf(id))
+		}
+		return
+	}
+}
+
+// CreateAndReturn inserts a new document and returns it as stored, with its generated
+// _id and any values set by the server populated, without a separate FindByID call.
+// The document is read back from the primary, so it is found even when reads go to
+// secondaries.
+func (r *Repository[T]) CreateAndReturn(ctx context.Context, document T) (*T, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	id, err := r.Create(ctx, document)
+	if err != nil {
+		return nil, err
+	}
+
+	var result T
+	if err := r.client.findInserted(ctx, r.collection, id, &result); err != nil {
+		return nil, err
+	}
+	if err := r.decrypt(ctx, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // CreateMany inserts multiple documents and returns their IDs.
-func (r *Repository[T]) CreateMany(ctx context.Context, documents []T) ([]any, error) {
+// The generated IDs are also set on the documents of the slice, see Insert.
+// If some documents fail, the error wraps a BulkError with the IDs that were inserted;
+// use options.InsertMany().SetOrdered(false) to attempt every document.
+func (r *Repository[T]) CreateMany(ctx context.Context, documents []T, opts ...*options.InsertManyOptions) ([]any, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	// Convert []T to []any for InsertMany
 	docs := make([]any, len(documents))
 	for i, doc := range documents {
+		r.auditCreated(ctx, &doc)
+		if err := r.encrypt(ctx, &doc); err != nil {
+			return nil, err
+		}
 		docs[i] = doc
 	}
 
-	result, err := r.client.insertMany(ctx, r.collection, docs)
+	result, err := r.client.insertMany(ctx, r.collection, docs, opts...)
 	if err != nil {
 		return nil, err
 	}
+	for i, id := range result.InsertedIDs {
+		setInsertedID(&documents[i], id)
+	}
 	return result.InsertedIDs, nil
 }
 
 // FindByID finds a single document by its _id field.
-// Returns mongo.ErrNoDocuments if not found.
+// Returns ErrNotFound if not found.
 func (r *Repository[T]) FindByID(ctx context.Context, id any) (*T, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	var result T
-	err := r.client.findByID(ctx, r.collection, id, &result)
+	err := r.cached(ctx, "find by id", bson.D{{Key: "_id", Value: id}}, nil, &result, func() error {
+		return r.client.findByID(ctx, r.collection, id, &result)
+	})
 	if err != nil {
 		return nil, err
 	}
+	if err := r.decrypt(ctx, &result); err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
 // FindOne finds a single document matching the filter.
-// Returns mongo.ErrNoDocuments if not found.
+// Returns ErrNotFound if not found.
 func (r *Repository[T]) FindOne(ctx context.Context, filter any, opts ...*options.FindOneOptions) (*T, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	var result T
-	err := r.client.findOne(ctx, r.collection, filter, &result, opts...)
+	err := r.cached(ctx, "find one", filter, opts, &result, func() error {
+		return r.client.findOne(ctx, r.collection, filter, &result, opts...)
+	})
 	if err != nil {
 		return nil, err
 	}
+	if err := r.decrypt(ctx, &result); err != nil {
+		return nil, err
+	}
 	return &result, nil
 }
 
 // Find finds all documents matching the filter.
 func (r *Repository[T]) Find(ctx context.Context, filter any, opts ...*options.FindOptions) ([]T, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	var results []T
-	err := r.client.find(ctx, r.collection, filter, &results, opts...)
+	err := r.cached(ctx, "find", filter, opts, &results, func() error {
+		return r.client.find(ctx, r.collection, filter, &results, opts...)
+	})
 	if err != nil {
 		return nil, err
 	}
+	for i := range results {
+		if err := r.decrypt(ctx, &results[i]); err != nil {
+			return nil, err
+		}
+	}
 	return results, nil
 }
 
@@ -90,44 +359,119 @@ func (r *Repository[T]) FindAll(ctx context.Context, opts ...*options.FindOption
 	return r.Find(ctx, bson.M{}, opts...)
 }
 
+// BulkWrite executes a mix of insert, update, replace and delete models in one request.
+// If some writes fail, the error wraps a BulkError with per-index errors and the counts
+// of the writes that succeeded.
+func (r *Repository[T]) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...*options.BulkWriteOptions) (*mongo.BulkWriteResult, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.client.bulkWrite(ctx, r.collection, models, opts...)
+}
+
 // UpdateByID updates a single document by its _id field.
 func (r *Repository[T]) UpdateByID(ctx context.Context, id any, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
-	return r.client.updateByID(ctx, r.collection, id, update, opts...)
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.client.updateByID(ctx, r.collection, id, r.auditUpdate(ctx, update, updateUpserts(opts)), opts...)
 }
 
 // UpdateOne updates a single document matching the filter.
 func (r *Repository[T]) UpdateOne(ctx context.Context, filter any, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
-	return r.client.updateOne(ctx, r.collection, filter, update, opts...)
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.client.updateOne(ctx, r.collection, filter, r.auditUpdate(ctx, update, updateUpserts(opts)), opts...)
 }
 
 // UpdateMany updates all documents matching the filter.
+// Clients created WithSafeWrites reject empty filters, see AllowFullCollection.
 func (r *Repository[T]) UpdateMany(ctx context.Context, filter any, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
-	return r.client.updateMany(ctx, r.collection, filter, update, opts...)
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.client.updateMany(ctx, r.collection, filter, r.auditUpdate(ctx, update, updateUpserts(opts)), opts...)
 }
 
 // Upsert updates a document if it exists, or inserts it if it doesn't.
 func (r *Repository[T]) Upsert(ctx context.Context, filter any, update any) (*mongo.UpdateResult, error) {
-	return r.client.upsertOne(ctx, r.collection, filter, update)
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.client.upsertOne(ctx, r.collection, filter, r.auditUpdate(ctx, update, true))
+}
+
+// UpsertWithBuilders updates the first document matching the QueryBuilder, in its sort
+// order, with the UpdateBuilder, or inserts a document built from the equality
+// conditions of the filter and the update when none matches, and returns the document
+// as it is after the write. The projection of the QueryBuilder applies to the result.
+//
+// Example:
+//
+//	profile, err := repo.UpsertWithBuilders(ctx,
+//	    mongo_kit.NewQueryBuilder().Equals("user_id", userID),
+//	    mongo_kit.NewUpdateBuilder().Set("theme", "dark").CurrentDate("updated_at"),
+//	)
+func (r *Repository[T]) UpsertWithBuilders(ctx context.Context, qb *QueryBuilder, ub *UpdateBuilder) (*T, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	filter, findOpts := qb.Build()
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	if findOpts.Sort != nil {
+		opts.SetSort(findOpts.Sort)
+	}
+	if findOpts.Projection != nil {
+		opts.SetProjection(findOpts.Projection)
+	}
+
+	var result T
+	if err := r.client.findOneAndUpdate(ctx, r.collection, filter, r.auditUpdate(ctx, ub.Build(), true), &result, opts); err != nil {
+		return nil, err
+	}
+	if err := r.decrypt(ctx, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
 // DeleteByID deletes a single document by its _id field.
 func (r *Repository[T]) DeleteByID(ctx context.Context, id any) (*mongo.DeleteResult, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	return r.client.deleteByID(ctx, r.collection, id)
 }
 
 // DeleteOne deletes a single document matching the filter.
 func (r *Repository[T]) DeleteOne(ctx context.Context, filter any) (*mongo.DeleteResult, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	return r.client.deleteOne(ctx, r.collection, filter)
 }
 
 // DeleteMany deletes all documents matching the filter.
+// Clients created WithSafeWrites reject empty filters, see AllowFullCollection.
 func (r *Repository[T]) DeleteMany(ctx context.Context, filter any) (*mongo.DeleteResult, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	return r.client.deleteMany(ctx, r.collection, filter)
 }
 
 // Count returns the number of documents matching the filter.
 func (r *Repository[T]) Count(ctx context.Context, filter any, opts ...*options.CountOptions) (int64, error) {
-	return r.client.countDocuments(ctx, r.collection, filter, opts...)
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var count int64
+	err := r.cached(ctx, "count", filter, opts, &count, func() (err error) {
+		count, err = r.client.countDocuments(ctx, r.collection, filter, opts...)
+		return err
+	})
+	return count, err
 }
 
 // CountAll counts all documents in the collection.
@@ -138,11 +482,17 @@ func (r *Repository[T]) CountAll(ctx context.Context, opts ...*options.CountOpti
 // EstimatedCount returns an estimated count using collection metadata.
 // Faster than Count but may be less accurate.
 func (r *Repository[T]) EstimatedCount(ctx context.Context, opts ...*options.EstimatedDocumentCountOptions) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	return r.client.estimatedDocumentCount(ctx, r.collection, opts...)
 }
 
 // Exists checks if at least one document matching the filter exists.
 func (r *Repository[T]) Exists(ctx context.Context, filter any) (bool, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	count, err := r.client.countDocuments(ctx, r.collection, filter, options.Count().SetLimit(1))
 	if err != nil {
 		return false, err
@@ -153,7 +503,7 @@ func (r *Repository[T]) Exists(ctx context.Context, filter any) (bool, error) {
 // ExistsByID checks if a document with the given _id exists.
 func (r *Repository[T]) ExistsByID(ctx context.Context, id any) (bool, error) {
 	_, err := r.FindByID(ctx, id)
-	if errors.Is(err, mongo.ErrNoDocuments) {
+	if errors.Is(err, ErrNotFound) {
 		return false, nil
 	}
 	if err != nil {
@@ -164,17 +514,43 @@ func (r *Repository[T]) ExistsByID(ctx context.Context, id any) (bool, error) {
 
 // Aggregate executes an aggregation pipeline and returns typed results.
 func (r *Repository[T]) Aggregate(ctx context.Context, pipeline any, opts ...*options.AggregateOptions) ([]T, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	var results []T
 	err := r.client.aggregate(ctx, r.collection, pipeline, &results, opts...)
 	if err != nil {
 		return nil, err
 	}
+	for i := range results {
+		if err := r.decrypt(ctx, &results[i]); err != nil {
+			return nil, err
+		}
+	}
 	return results, nil
 }
 
+// AggregateIter executes an aggregation pipeline and returns an iterator over typed results,
+// so large outputs can be consumed incrementally. Results are decrypted as they are decoded
+// when field encryption is enabled. The caller must close the iterator.
+func (r *Repository[T]) AggregateIter(ctx context.Context, pipeline any, opts ...*options.AggregateOptions) (*Iterator[T], error) {
+	cursor, err := r.client.aggregateCursor(ctx, r.collection, pipeline, opts...)
+	if err != nil {
+		return nil, err
+	}
+	it := newIterator[T](cursor, "aggregate")
+	if r.opts.encryptor != nil {
+		it.decrypt = r.decrypt
+	}
+	return it, nil
+}
+
 // Drop deletes the entire collection.
 // WARNING: This permanently deletes all documents and indexes.
 func (r *Repository[T]) Drop(ctx context.Context) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
 	return r.client.dropCollection(ctx, r.collection)
 }
 
@@ -185,7 +561,7 @@ func (r *Repository[T]) FindWithBuilder(ctx context.Context, qb *QueryBuilder) (
 }
 
 // FindOneWithBuilder finds a single document using a QueryBuilder.
-// Returns mongo.ErrNoDocuments if not found.
+// Returns ErrNotFound if not found.
 func (r *Repository[T]) FindOneWithBuilder(ctx context.Context, qb *QueryBuilder) (*T, error) {
 	filter, opts := qb.Build()
 
@@ -204,6 +580,60 @@ func (r *Repository[T]) FindOneWithBuilder(ctx context.Context, qb *QueryBuilder
 	return r.FindOne(ctx, filter, findOneOpts)
 }
 
+// FindAndCount finds the documents of a QueryBuilder, with its sort, skip and limit, and
+// counts all the documents matching its filter, as list endpoints need for a page and
+// its total. The find and the count run concurrently, or one after the other when ctx
+// carries a session, which cannot be used concurrently.
+//
+// Example:
+//
+//	qb := mongo_kit.NewQueryBuilder().Equals("status", "active").Skip(40).Limit(20)
+//	users, total, err := repo.FindAndCount(ctx, qb)
+func (r *Repository[T]) FindAndCount(ctx context.Context, qb *QueryBuilder) ([]T, int64, error) {
+	filter, opts := qb.Build()
+
+	if _, ok := SessionFromContext(ctx); ok {
+		items, err := r.Find(ctx, filter, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+		total, err := r.Count(ctx, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		return items, total, nil
+	}
+
+	var total int64
+	var countErr error
+	counted := make(chan struct{})
+	go func() {
+		defer close(counted)
+		total, countErr = r.Count(ctx, filter)
+	}()
+
+	items, err := r.Find(ctx, filter, opts)
+	<-counted
+	if err != nil {
+		return nil, 0, err
+	}
+	if countErr != nil {
+		return nil, 0, countErr
+	}
+	return items, total, nil
+}
+
+// AggregateWithBuilder executes the pipeline and options of an AggregationBuilder.
+func (r *Repository[T]) AggregateWithBuilder(ctx context.Context, ab *AggregationBuilder) ([]T, error) {
+	return r.Aggregate(ctx, ab.Build(), ab.Options())
+}
+
+// AggregateIterWithBuilder executes the pipeline and options of an AggregationBuilder
+// and returns an iterator over the results. The caller must close the iterator.
+func (r *Repository[T]) AggregateIterWithBuilder(ctx context.Context, ab *AggregationBuilder) (*Iterator[T], error) {
+	return r.AggregateIter(ctx, ab.Build(), ab.Options())
+}
+
 // CountWithBuilder counts documents using a QueryBuilder filter.
 func (r *Repository[T]) CountWithBuilder(ctx context.Context, qb *QueryBuilder) (int64, error) {
 	filter := qb.GetFilter()
@@ -220,3 +650,37 @@ func (r *Repository[T]) ExistsWithBuilder(ctx context.Context, qb *QueryBuilder)
 func (r *Repository[T]) Collection() string {
 	return r.collection
 }
+
+// DistinctValues returns the distinct values of field across the documents matching
+// the filter in the given collection, decoded into V.
+//
+// Example:
+//
+//	statuses, err := mongo_kit.DistinctValues[string](ctx, client, "orders", "status", bson.M{})
+func DistinctValues[V any](ctx context.Context, client *Client, collection, field string, filter any, opts ...*options.DistinctOptions) ([]V, error) {
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	values, err := client.distinct(ctx, collection, field, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := client.bsonRegistry()
+	results := make([]V, 0, len(values))
+	for _, value := range values {
+		t, data, err := bson.MarshalValue(value)
+		if err != nil {
+			return nil, newOperationError("distinct decode", err)
+		}
+
+		var v V
+		if err := (bson.RawValue{Type: t, Value: data}).UnmarshalWithRegistry(registry, &v); err != nil {
+			return nil, newOperationError("distinct decode", err)
+		}
+		results = append(results, v)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,33 @@
+package mongo_kit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func BenchmarkRepository(b *testing.B) {
+	if testing.Short() {
+		b.Skip("skipping benchmark in short mode")
+	}
+
+	container := testhelpers.Shared(b)
+	database := testhelpers.IsolatedDatabase(b)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(b, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	testhelpers.BenchRepository(b, NewRepository[User](client, "users"), testhelpers.BenchProfile[User]{
+		Factory: func(i int) User {
+			return User{Name: fmt.Sprintf("user-%d", i), Email: fmt.Sprintf("user-%d@test.com", i), Age: i % 90, Active: i%2 == 0}
+		},
+		Filter: bson.M{"active": true, "age": bson.M{"$gte": 30}},
+		Update: bson.M{"$inc": bson.M{"age": 1}},
+	})
+}
@@ -2,14 +2,24 @@ package mongo_kit
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 
 	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
 )
@@ -27,12 +37,12 @@ func TestRepository_Integration(t *testing.T) {
 		t.Skip("skipping integration test in short mode")
 	}
 
-	container := testhelpers.SetupMongoContainer(t)
-	defer container.Teardown(t)
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
 
 	cfg := DefaultConfig()
 	WithURI(container.URI)(&cfg)
-	WithDatabase("testdb")(&cfg)
+	WithDatabase(database)(&cfg)
 
 	client, err := New(cfg)
 	require.NoError(t, err)
@@ -56,6 +66,32 @@ func TestRepository_Integration(t *testing.T) {
 		require.NotNil(t, id)
 	})
 
+	t.Run("Insert sets generated ID", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+
+		user := User{Name: "Ann", Email: "ann@test.com"}
+		id, err := repo.Insert(ctx, &user)
+		require.NoError(t, err)
+		assert.Equal(t, id, user.ID)
+
+		found, err := repo.FindByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, user, *found)
+	})
+
+	t.Run("CreateAndReturn returns stored document", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+
+		created, err := repo.CreateAndReturn(ctx, User{Name: "Jane", Email: "jane@test.com", Age: 31, Active: true})
+		require.NoError(t, err)
+		assert.False(t, created.ID.IsZero(), "generated _id is populated")
+		assert.Equal(t, "Jane", created.Name)
+
+		found, err := repo.FindByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created, found)
+	})
+
 	t.Run("CreateMany inserts multiple documents", func(t *testing.T) {
 		_ = repo.Drop(ctx)
 
@@ -86,6 +122,7 @@ func TestRepository_Integration(t *testing.T) {
 
 		_, err := repo.FindByID(ctx, primitive.NewObjectID())
 		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+		assert.ErrorIs(t, err, ErrNotFound)
 	})
 
 	t.Run("FindOne returns document", func(t *testing.T) {
@@ -104,6 +141,18 @@ func TestRepository_Integration(t *testing.T) {
 		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
 	})
 
+	t.Run("FindOne returns TimeoutError when deadline is exceeded", func(t *testing.T) {
+		timeoutCtx, cancel := context.WithTimeout(ctx, time.Nanosecond)
+		defer cancel()
+		time.Sleep(time.Millisecond)
+
+		_, err := repo.FindOne(timeoutCtx, bson.M{"name": "FindOne"})
+		var timeoutErr *TimeoutError
+		require.ErrorAs(t, err, &timeoutErr)
+		assert.Equal(t, "find one", timeoutErr.Op)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
 	t.Run("Find returns matching documents", func(t *testing.T) {
 		_ = repo.Drop(ctx)
 		_, _ = repo.CreateMany(ctx, []User{
@@ -187,6 +236,22 @@ func TestRepository_Integration(t *testing.T) {
 		assert.Equal(t, int64(1), result.ModifiedCount)
 	})
 
+	t.Run("UpsertWithBuilders returns resulting document", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+
+		qb := NewQueryBuilder().Equals("email", "builders@test.com")
+		inserted, err := repo.UpsertWithBuilders(ctx, qb, NewUpdateBuilder().Set("name", "Inserted").Set("age", 20))
+		require.NoError(t, err)
+		assert.False(t, inserted.ID.IsZero())
+		assert.Equal(t, "builders@test.com", inserted.Email)
+		assert.Equal(t, "Inserted", inserted.Name)
+
+		updated, err := repo.UpsertWithBuilders(ctx, qb, NewUpdateBuilder().Inc("age", 1))
+		require.NoError(t, err)
+		assert.Equal(t, inserted.ID, updated.ID)
+		assert.Equal(t, 21, updated.Age)
+	})
+
 	t.Run("DeleteByID deletes document", func(t *testing.T) {
 		_ = repo.Drop(ctx)
 		id, _ := repo.Create(ctx, User{Name: "ToDelete", Email: "delete@test.com", Age: 25, Active: true})
@@ -257,6 +322,35 @@ func TestRepository_Integration(t *testing.T) {
 		assert.GreaterOrEqual(t, count, int64(2))
 	})
 
+	t.Run("DistinctValues returns typed distinct values", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		_, _ = repo.CreateMany(ctx, []User{
+			{Name: "A", Email: "a@test.com", Age: 20, Active: true},
+			{Name: "B", Email: "b@test.com", Age: 30, Active: true},
+			{Name: "C", Email: "c@test.com", Age: 30, Active: false},
+		})
+
+		ages, err := DistinctValues[int](ctx, client, "users", "age", nil)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []int{20, 30}, ages)
+
+		names, err := DistinctValues[string](ctx, client, "users", "name", bson.M{"active": true})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"A", "B"}, names)
+	})
+
+	t.Run("Client EstimatedCount with max time", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		_, _ = repo.CreateMany(ctx, []User{
+			{Name: "A", Email: "a@test.com", Age: 20},
+			{Name: "B", Email: "b@test.com", Age: 30},
+		})
+
+		count, err := client.EstimatedCount(ctx, "users", time.Second)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
 	t.Run("Exists returns true when document exists", func(t *testing.T) {
 		_ = repo.Drop(ctx)
 		_, _ = repo.Create(ctx, User{Name: "Exists", Email: "exists@test.com", Age: 25, Active: true})
@@ -309,6 +403,67 @@ func TestRepository_Integration(t *testing.T) {
 		assert.Equal(t, "Agg1", results[0].Name)
 	})
 
+	t.Run("AggregateIter streams typed results", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		_, _ = repo.CreateMany(ctx, []User{
+			{Name: "A", Email: "a@test.com", Age: 20, Active: true},
+			{Name: "B", Email: "b@test.com", Age: 30, Active: true},
+			{Name: "C", Email: "c@test.com", Age: 40, Active: false},
+		})
+
+		pipeline := NewAggregationBuilder().
+			Match(bson.M{"active": true}).
+			Sort(bson.D{{Key: "age", Value: 1}}).
+			Build()
+
+		it, err := repo.AggregateIter(ctx, pipeline, options.Aggregate().SetBatchSize(1))
+		require.NoError(t, err)
+		defer func() { _ = it.Close(ctx) }()
+
+		var names []string
+		for it.Next(ctx) {
+			names = append(names, it.Current().Name)
+		}
+		require.NoError(t, it.Err())
+		assert.Equal(t, []string{"A", "B"}, names)
+	})
+
+	t.Run("AggregateWithBuilder applies builder options", func(t *testing.T) {
+		ab := NewAggregationBuilder().
+			Match(bson.M{"active": true}).
+			Sort(bson.D{{Key: "age", Value: -1}}).
+			BatchSize(1).
+			AllowDiskUse()
+
+		results, err := repo.AggregateWithBuilder(ctx, ab)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "B", results[0].Name)
+
+		it, err := repo.AggregateIterWithBuilder(ctx, ab)
+		require.NoError(t, err)
+		count := 0
+		for _, err := range it.All(ctx) {
+			require.NoError(t, err)
+			count++
+		}
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("Client AggregateIter streams bson.M results", func(t *testing.T) {
+		pipeline := NewAggregationBuilder().Group("$active", bson.M{"count": bson.M{"$sum": 1}}).Build()
+
+		it, err := client.AggregateIter(ctx, "users", pipeline)
+		require.NoError(t, err)
+
+		counts := map[bool]int32{}
+		for doc, err := range it.All(ctx) {
+			require.NoError(t, err)
+			counts[doc["_id"].(bool)] = doc["count"].(int32)
+		}
+		assert.Equal(t, map[bool]int32{true: 2, false: 1}, counts)
+	})
+
 	t.Run("Drop removes collection", func(t *testing.T) {
 		dropRepo := NewRepository[User](client, "to_drop_repo")
 		_, _ = dropRepo.Create(ctx, User{Name: "DropMe"})
@@ -340,6 +495,21 @@ func TestRepository_Integration(t *testing.T) {
 		assert.Equal(t, "Builder2", results[0].Name) // Sorted by age desc
 	})
 
+	t.Run("FindWithBuilder with cursor options", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		_, _ = repo.CreateMany(ctx, []User{
+			{Name: "A", Email: "a@test.com", Age: 20},
+			{Name: "B", Email: "b@test.com", Age: 30},
+			{Name: "C", Email: "c@test.com", Age: 40},
+		})
+
+		qb := NewQueryBuilder().Sort("age", true).BatchSize(1).NoCursorTimeout().AllowDiskUse()
+		found, err := repo.FindWithBuilder(ctx, qb)
+		require.NoError(t, err)
+		require.Len(t, found, 3)
+		assert.Equal(t, "A", found[0].Name)
+	})
+
 	t.Run("FindOneWithBuilder returns single document", func(t *testing.T) {
 		_ = repo.Drop(ctx)
 		_, _ = repo.CreateMany(ctx, []User{
@@ -365,6 +535,23 @@ func TestRepository_Integration(t *testing.T) {
 		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
 	})
 
+	t.Run("FindAndCount returns page and total", func(t *testing.T) {
+		_ = repo.Drop(ctx)
+		_, _ = repo.CreateMany(ctx, []User{
+			{Name: "Page1", Email: "p1@test.com", Age: 25, Active: true},
+			{Name: "Page2", Email: "p2@test.com", Age: 30, Active: true},
+			{Name: "Page3", Email: "p3@test.com", Age: 35, Active: true},
+			{Name: "Page4", Email: "p4@test.com", Age: 40, Active: false},
+		})
+
+		qb := NewQueryBuilder().Equals("active", true).Sort("age", true).Skip(1).Limit(1)
+		users, total, err := repo.FindAndCount(ctx, qb)
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, "Page2", users[0].Name)
+		assert.Equal(t, int64(3), total)
+	})
+
 	t.Run("CountWithBuilder counts matching documents", func(t *testing.T) {
 		_ = repo.Drop(ctx)
 		_, _ = repo.CreateMany(ctx, []User{
@@ -401,12 +588,12 @@ func TestClient_CreateCollection(t *testing.T) {
 		t.Skip("skipping integration test in short mode")
 	}
 
-	container := testhelpers.SetupMongoContainer(t)
-	defer container.Teardown(t)
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
 
 	cfg := DefaultConfig()
 	WithURI(container.URI)(&cfg)
-	WithDatabase("testdb")(&cfg)
+	WithDatabase(database)(&cfg)
 
 	client, err := New(cfg)
 	require.NoError(t, err)
@@ -414,6 +601,14 @@ func TestClient_CreateCollection(t *testing.T) {
 
 	ctx := context.Background()
 
+	t.Run("Ping and ServerVersion", func(t *testing.T) {
+		require.NoError(t, client.Ping(ctx))
+
+		version, err := client.ServerVersion(ctx)
+		require.NoError(t, err)
+		assert.Regexp(t, `^7\.`, version)
+	})
+
 	t.Run("CreateCollection creates new collection", func(t *testing.T) {
 		collName := "test_collection_new"
 		err := client.CreateCollection(ctx, collName)
@@ -457,12 +652,12 @@ func TestClient_CreateIndexes(t *testing.T) {
 		t.Skip("skipping integration test in short mode")
 	}
 
-	container := testhelpers.SetupMongoContainer(t)
-	defer container.Teardown(t)
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
 
 	cfg := DefaultConfig()
 	WithURI(container.URI)(&cfg)
-	WithDatabase("testdb")(&cfg)
+	WithDatabase(database)(&cfg)
 
 	client, err := New(cfg)
 	require.NoError(t, err)
@@ -508,6 +703,12 @@ func TestClient_CreateIndexes(t *testing.T) {
 		_, err = repo.Create(ctx, User{Name: "Jane", Email: "unique@test.com", Age: 25, Active: false})
 		assert.Error(t, err)
 		assert.True(t, mongo.IsDuplicateKeyError(err))
+
+		var dupErr *DuplicateKeyError
+		require.ErrorAs(t, err, &dupErr)
+		assert.Equal(t, collName, dupErr.Collection)
+		assert.Equal(t, "email_unique_idx", dupErr.Index)
+		assert.Equal(t, bson.M{"email": "unique@test.com"}, dupErr.KeyValue)
 	})
 
 	t.Run("CreateIndexes with empty array returns error", func(t *testing.T) {
@@ -537,3 +738,438 @@ func TestClient_CreateIndexes(t *testing.T) {
 		require.NoError(t, err)
 	})
 }
+
+func TestClient_ListDatabases(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	cfg := DefaultConfig()
+	WithURI(container.URI)(&cfg)
+	WithDatabase(database)(&cfg)
+
+	client, err := New(cfg)
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	require.NoError(t, client.CreateCollection(ctx, "users"))
+
+	t.Run("ListDatabases includes default database", func(t *testing.T) {
+		dbs, err := client.ListDatabases(ctx, nil)
+		require.NoError(t, err)
+
+		names := make([]string, 0, len(dbs))
+		for _, db := range dbs {
+			names = append(names, db.Name)
+		}
+		assert.Contains(t, names, database)
+	})
+
+	t.Run("ListDatabases with filter", func(t *testing.T) {
+		dbs, err := client.ListDatabases(ctx, bson.M{"name": database})
+		require.NoError(t, err)
+		require.Len(t, dbs, 1)
+		assert.Equal(t, database, dbs[0].Name)
+	})
+
+	t.Run("DatabaseExists", func(t *testing.T) {
+		exists, err := client.DatabaseExists(ctx, database)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = client.DatabaseExists(ctx, "missing_db")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("CollectionExists", func(t *testing.T) {
+		exists, err := client.CollectionExists(ctx, "users")
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		exists, err = client.CollectionExists(ctx, "missing_collection")
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+}
+
+func TestClient_ReadWriteConcern(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(),
+		WithURI(container.URI),
+		WithDatabase(database),
+		WithWriteConcern("majority", true, 5*time.Second),
+		WithReadConcern("majority"),
+	)
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := NewRepository[User](client, "concern_users")
+
+	id, err := repo.Create(ctx, User{Name: "Alice", Email: "alice@example.com", Age: 30})
+	require.NoError(t, err)
+
+	user, err := repo.FindByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", user.Name)
+}
+
+func TestClient_ServerAPI(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(),
+		WithURI(container.URI),
+		WithDatabase(database),
+		WithServerAPI("1", true, true),
+	)
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := NewRepository[User](client, "server_api_users")
+
+	_, err = repo.Create(ctx, User{Name: "Alice", Email: "alice@example.com", Age: 30})
+	require.NoError(t, err)
+
+	count, err := repo.CountAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+// status is a custom enum stored as its string name through a custom codec.
+type status int
+
+const (
+	statusActive status = iota + 1
+	statusBanned
+)
+
+var statusNames = map[status]string{statusActive: "active", statusBanned: "banned"}
+
+func statusRegistry() *bsoncodec.Registry {
+	statusType := reflect.TypeOf(status(0))
+	registry := bson.NewRegistry()
+
+	registry.RegisterTypeEncoder(statusType, bsoncodec.ValueEncoderFunc(
+		func(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+			return vw.WriteString(statusNames[status(val.Int())])
+		}))
+	registry.RegisterTypeDecoder(statusType, bsoncodec.ValueDecoderFunc(
+		func(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+			name, err := vr.ReadString()
+			if err != nil {
+				return err
+			}
+			for s, n := range statusNames {
+				if n == name {
+					val.SetInt(int64(s))
+					return nil
+				}
+			}
+			return fmt.Errorf("unknown status %q", name)
+		}))
+
+	return registry
+}
+
+func TestClient_BSONRegistry_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(),
+		WithURI(container.URI),
+		WithDatabase(database),
+		WithBSONRegistry(statusRegistry()),
+	)
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	type Account struct {
+		ID     primitive.ObjectID `bson:"_id,omitempty"`
+		Status status             `bson:"status"`
+	}
+
+	ctx := context.Background()
+	repo := NewRepository[Account](client, "accounts")
+
+	id, err := repo.Create(ctx, Account{Status: statusBanned})
+	require.NoError(t, err)
+
+	t.Run("encodes with custom codec", func(t *testing.T) {
+		raw, err := NewRepository[bson.M](client, "accounts").FindByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "banned", (*raw)["status"])
+	})
+
+	t.Run("decodes with custom codec", func(t *testing.T) {
+		account, err := repo.FindByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, statusBanned, account.Status)
+	})
+
+	t.Run("DistinctValues decodes with custom codec", func(t *testing.T) {
+		values, err := DistinctValues[status](ctx, client, "accounts", "status", nil)
+		require.NoError(t, err)
+		assert.Equal(t, []status{statusBanned}, values)
+	})
+}
+
+func TestClient_ApplyConfig(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := NewRepository[User](client, "reload_users")
+
+	id, err := repo.Create(ctx, User{Name: "Alice", Email: "alice@example.com", Age: 30})
+	require.NoError(t, err)
+
+	t.Run("SetMaxPoolSize rebuilds the client", func(t *testing.T) {
+		require.NoError(t, client.SetMaxPoolSize(ctx, 20))
+		assert.Equal(t, uint64(20), client.Config().MaxPoolSize)
+
+		user, err := repo.FindByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", user.Name)
+	})
+
+	t.Run("ApplyConfig switches default database", func(t *testing.T) {
+		cfg := client.Config()
+		cfg.Database = testhelpers.IsolatedDatabase(t)
+		require.NoError(t, client.ApplyConfig(ctx, cfg))
+
+		_, err := repo.FindByID(ctx, id)
+		assert.ErrorIs(t, err, mongo.ErrNoDocuments)
+	})
+
+	t.Run("ApplyConfig keeps current client on connection failure", func(t *testing.T) {
+		cfg := client.Config()
+		cfg.URI = "mongodb://127.0.0.1:1"
+		cfg.Timeout = 500 * time.Millisecond
+		cfg.ServerSelectionTimeout = 500 * time.Millisecond
+
+		err := client.ApplyConfig(ctx, cfg)
+		var connErr *ConnectionError
+		require.ErrorAs(t, err, &connErr)
+
+		assert.Equal(t, container.URI, client.Config().URI)
+		_, err = repo.CountAll(ctx)
+		assert.NoError(t, err)
+	})
+}
+
+func TestClient_CredentialProvider(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	ctx := context.Background()
+
+	admin, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = admin.Close(context.Background()) }()
+
+	err = admin.client.Database("admin").RunCommand(ctx, bson.D{
+		{Key: "createUser", Value: "app"},
+		{Key: "pwd", Value: "secret"},
+		{Key: "roles", Value: bson.A{bson.M{"role": "readWrite", "db": database}}},
+	}).Err()
+	require.NoError(t, err)
+
+	calls := 0
+	provider := func(context.Context) (string, string, error) {
+		calls++
+		return "app", "secret", nil
+	}
+
+	client, err := New(DefaultConfig(),
+		WithURI(container.URI),
+		WithDatabase(database),
+		WithCredentialProvider(provider),
+	)
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	t.Run("credentials are fetched on connect", func(t *testing.T) {
+		assert.Equal(t, 1, calls)
+
+		_, err := NewRepository[User](client, "users").Create(ctx, User{Name: "Alice"})
+		require.NoError(t, err)
+	})
+
+	t.Run("Reconnect fetches credentials again", func(t *testing.T) {
+		require.NoError(t, client.Reconnect(ctx))
+		assert.Equal(t, 2, calls)
+
+		count, err := NewRepository[User](client, "users").CountAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("wrong credentials fail to connect", func(t *testing.T) {
+		_, err := New(DefaultConfig(),
+			WithURI(container.URI),
+			WithDatabase(database),
+			WithCredentialProvider(func(context.Context) (string, string, error) {
+				return "app", "wrong", nil
+			}),
+		)
+
+		var connErr *ConnectionError
+		assert.ErrorAs(t, err, &connErr)
+	})
+}
+
+func TestClient_WithTransaction_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := NewRepository[User](client, "txn_users")
+	require.NoError(t, client.CreateCollection(ctx, "txn_users"))
+
+	txnOpts := options.Transaction().
+		SetWriteConcern(writeconcern.Majority()).
+		SetReadConcern(readconcern.Snapshot())
+
+	t.Run("commits", func(t *testing.T) {
+		err := client.WithTransaction(ctx, func(ctx context.Context) error {
+			_, err := repo.Create(ctx, User{Name: "Alice"})
+			return err
+		}, txnOpts)
+		require.NoError(t, err)
+
+		exists, err := repo.Exists(ctx, bson.M{"name": "Alice"})
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("aborts when fn fails", func(t *testing.T) {
+		err := client.WithTransaction(ctx, func(ctx context.Context) error {
+			if _, err := repo.Create(ctx, User{Name: "Bob"}); err != nil {
+				return err
+			}
+			return fmt.Errorf("rollback")
+		}, txnOpts)
+		require.EqualError(t, err, "rollback")
+
+		exists, err := repo.Exists(ctx, bson.M{"name": "Bob"})
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("nested calls join the session of the context", func(t *testing.T) {
+		sess, err := client.StartSession()
+		require.NoError(t, err)
+		defer sess.EndSession(ctx)
+
+		createCarol := func(ctx context.Context) error {
+			return client.WithTransaction(ctx, func(ctx context.Context) error {
+				_, err := repo.Create(ctx, User{Name: "Carol"})
+				return err
+			})
+		}
+		err = client.WithTransaction(ContextWithSession(ctx, sess), func(ctx context.Context) error {
+			if err := createCarol(ctx); err != nil {
+				return err
+			}
+			return fmt.Errorf("rollback")
+		})
+		require.EqualError(t, err, "rollback")
+
+		exists, err := repo.Exists(ctx, bson.M{"name": "Carol"})
+		require.NoError(t, err)
+		assert.False(t, exists, "the nested write is rolled back with the enclosing transaction")
+	})
+}
+
+func TestNew_WarmPool(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	var created atomic.Int64
+	monitor := &event.PoolMonitor{Event: func(e *event.PoolEvent) {
+		if e.Type == event.ConnectionReady {
+			created.Add(1)
+		}
+	}}
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database),
+		WithWarmPool(5), WithClientOptions(options.Client().SetPoolMonitor(monitor)))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	assert.Greater(t, created.Load(), int64(1), "connections are established before the first request")
+}
+
+func TestRepository_WithOperationTimeout_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	_, err = NewRepository[User](client, "users").Create(ctx, User{Name: "Alice"})
+	require.NoError(t, err)
+
+	repo := NewRepository[User](client, "users", WithOperationTimeout(200*time.Millisecond))
+
+	users, err := repo.Find(ctx, bson.M{"name": "Alice"})
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+
+	start := time.Now()
+	_, err = repo.Find(ctx, bson.M{"$where": "sleep(5000) || true"})
+	require.Error(t, err)
+	assert.True(t, mongo.IsTimeout(err) || errors.Is(err, context.DeadlineExceeded), "unexpected error: %v", err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
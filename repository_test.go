@@ -0,0 +1,195 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type idDocument struct {
+	ID   primitive.ObjectID `bson:"_id,omitempty"`
+	Name string             `bson:"name"`
+}
+
+func TestSetInsertedID(t *testing.T) {
+	id := primitive.NewObjectID()
+
+	doc := idDocument{}
+	setInsertedID(&doc, id)
+	assert.Equal(t, id, doc.ID)
+
+	existing := primitive.NewObjectID()
+	doc = idDocument{ID: existing}
+	setInsertedID(&doc, id)
+	assert.Equal(t, existing, doc.ID, "a set _id is kept")
+
+	ptr := &idDocument{}
+	setInsertedID(&ptr, id)
+	assert.Equal(t, id, ptr.ID)
+
+	stringID := struct {
+		ID string `bson:"_id,omitempty"`
+	}{}
+	setInsertedID(&stringID, id)
+	assert.Empty(t, stringID.ID, "ids of another type are not set")
+
+	value := idDocument{}
+	setInsertedID(value, id)
+	setInsertedID(&bson.M{}, id)
+	setInsertedID((*idDocument)(nil), id)
+	assert.True(t, value.ID.IsZero())
+}
+
+func TestRepository_CreateID(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}))
+	id, err := NewRepository[idDocument](client, "docs").CreateID(ctx, idDocument{Name: "a"})
+	require.NoError(t, err)
+	assert.False(t, id.IsZero())
+
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}))
+	_, err = NewRepository[bson.M](client, "docs").CreateID(ctx, bson.M{"_id": "sku-1"})
+	var opErr *OperationError
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "create id", opErr.Op)
+	assert.ErrorContains(t, err, "inserted _id is string, not an ObjectID")
+}
+
+func TestRepository_Insert_SetsID(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	repo := NewRepository[idDocument](client, "docs")
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}))
+	doc := idDocument{Name: "a"}
+	id, err := repo.Insert(ctx, &doc)
+	require.NoError(t, err)
+	assert.False(t, doc.ID.IsZero())
+	assert.Equal(t, id, doc.ID)
+
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 2}))
+	docs := []idDocument{{Name: "b"}, {Name: "c"}}
+	ids, err := repo.CreateMany(ctx, docs)
+	require.NoError(t, err)
+	assert.Equal(t, ids[0], docs[0].ID)
+	assert.Equal(t, ids[1], docs[1].ID)
+
+	pointers := NewRepository[*idDocument](client, "docs")
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}))
+	ptr := &idDocument{Name: "d"}
+	id, err = pointers.Create(ctx, ptr)
+	require.NoError(t, err)
+	assert.Equal(t, id, ptr.ID)
+}
+
+func TestRepository_FindAndCount(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	repo := NewRepository[bson.M](client, "users")
+	qb := NewQueryBuilder().Equals("status", "active").Limit(1)
+
+	// The find and the count run concurrently, so both get the same reply: a page with
+	// one document and a count of 7
+	page := testhelpers.CursorResponse("testdb.users", bson.D{{Key: "n", Value: int32(7)}})
+	mock.AddResponses(page, page)
+	items, total, err := repo.FindAndCount(context.Background(), qb)
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.EqualValues(t, 7, total)
+
+	mock.AddResponses(page, testhelpers.CommandErrorResponse(2, "BadValue", "bad filter"))
+	_, _, err = repo.FindAndCount(context.Background(), qb)
+	assert.Error(t, err)
+}
+
+func TestRepository_FindAndCount_Session(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	repo := NewRepository[bson.M](client, "users")
+
+	sess, err := mock.Client.StartSession()
+	require.NoError(t, err)
+	defer sess.EndSession(context.Background())
+	ctx := ContextWithSession(context.Background(), sess)
+
+	mock.AddResponses(
+		testhelpers.CursorResponse("testdb.users", bson.D{{Key: "name", Value: "a"}}, bson.D{{Key: "name", Value: "b"}}),
+		testhelpers.CursorResponse("testdb.users", bson.D{{Key: "n", Value: int32(12)}}),
+	)
+	items, total, err := repo.FindAndCount(ctx, NewQueryBuilder().Limit(2))
+	require.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.EqualValues(t, 12, total)
+}
+
+func TestRepository_UpsertWithBuilders(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	repo := NewRepository[bson.M](client, "profiles")
+	ctx := context.Background()
+	qb := NewQueryBuilder().Equals("user_id", "u1").Sort("created_at", false)
+	ub := NewUpdateBuilder().Set("theme", "dark")
+
+	mock.AddResponses(testhelpers.SuccessResponse(
+		bson.E{Key: "lastErrorObject", Value: bson.D{{Key: "n", Value: 1}, {Key: "updatedExisting", Value: false}}},
+		bson.E{Key: "value", Value: bson.D{{Key: "user_id", Value: "u1"}, {Key: "theme", Value: "dark"}}},
+	))
+	profile, err := repo.UpsertWithBuilders(ctx, qb, ub)
+	require.NoError(t, err)
+	assert.Equal(t, "dark", (*profile)["theme"])
+	assert.Equal(t, "u1", (*profile)["user_id"])
+
+	mock.AddResponses(testhelpers.CommandErrorResponse(2, "BadValue", "bad update"))
+	_, err = repo.UpsertWithBuilders(ctx, qb, ub)
+	var opErr *OperationError
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "find one and update", opErr.Op)
+}
+
+func TestRepository_WithOperationTimeout(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("without timeout the context is unchanged", func(t *testing.T) {
+		repo := NewRepository[bson.M](&Client{}, "users")
+		got, cancel := repo.withTimeout(ctx)
+		defer cancel()
+		assert.Equal(t, ctx, got)
+	})
+
+	t.Run("sets the deadline", func(t *testing.T) {
+		repo := NewRepository[bson.M](&Client{}, "users", WithOperationTimeout(time.Second))
+		got, cancel := repo.withTimeout(ctx)
+		defer cancel()
+		deadline, ok := got.Deadline()
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+	})
+
+	t.Run("keeps an earlier context deadline", func(t *testing.T) {
+		repo := NewRepository[bson.M](&Client{}, "users", WithOperationTimeout(time.Hour))
+		parent, parentCancel := context.WithTimeout(ctx, time.Second)
+		defer parentCancel()
+		want, _ := parent.Deadline()
+
+		got, cancel := repo.withTimeout(parent)
+		defer cancel()
+		deadline, _ := got.Deadline()
+		assert.Equal(t, want, deadline)
+	})
+}
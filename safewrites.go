@@ -0,0 +1,71 @@
+package mongo_kit
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Safe Writes
+//
+// This file provides an opt-in guard rejecting updates and deletes of many documents
+// with an empty filter, so a missing filter cannot modify or wipe a whole collection.
+//
+// See docs/operations.md for detailed usage guide and examples.
+
+// fullCollection is the filter returned by AllowFullCollection. It encodes as an
+// empty document.
+type fullCollection struct{}
+
+// WithSafeWrites rejects UpdateMany, DeleteMany, UpdateManyBatched and
+// DeleteManyBatched calls whose filter is nil or empty with ErrFullCollectionWrite.
+// Pass AllowFullCollection as the filter to modify every document on purpose.
+//
+// Example:
+//
+//	client, err := mongo_kit.New(cfg, mongo_kit.WithSafeWrites())
+//	_, err = repo.DeleteMany(ctx, bson.M{})                          // ErrFullCollectionWrite
+//	_, err = repo.DeleteMany(ctx, mongo_kit.AllowFullCollection())   // deletes every document
+func WithSafeWrites() Option {
+	return func(c *Config) {
+		c.SafeWrites = true
+	}
+}
+
+// AllowFullCollection returns a filter matching every document that is accepted by
+// clients created WithSafeWrites, to update or delete a whole collection on purpose.
+func AllowFullCollection() any {
+	return fullCollection{}
+}
+
+// checkFullCollection returns ErrFullCollectionWrite for the given operation when safe
+// writes are enabled and filter matches every document without AllowFullCollection.
+// The caller MUST hold c.mu.RLock().
+func (c *Client) checkFullCollection(operation string, filter any) error {
+	if !c.config.SafeWrites || !isEmptyFilter(filter) {
+		return nil
+	}
+	return newOperationError(operation, ErrFullCollectionWrite)
+}
+
+// isEmptyFilter reports whether filter is nil or encodes as an empty document.
+// AllowFullCollection is not considered empty.
+func isEmptyFilter(filter any) bool {
+	switch f := filter.(type) {
+	case nil:
+		return true
+	case fullCollection:
+		return false
+	case bson.M:
+		return len(f) == 0
+	case map[string]any:
+		return len(f) == 0
+	case bson.D:
+		return len(f) == 0
+	case bson.Raw:
+		return len(f) <= 5
+	}
+	raw, err := bson.Marshal(filter)
+	if err != nil {
+		return false // the driver reports invalid filters
+	}
+	return len(raw) <= 5
+}
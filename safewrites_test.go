@@ -0,0 +1,85 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestIsEmptyFilter(t *testing.T) {
+	empty, err := bson.Marshal(bson.D{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		filter any
+		want   bool
+	}{
+		{"nil", nil, true},
+		{"empty bson.M", bson.M{}, true},
+		{"empty map", map[string]any{}, true},
+		{"empty bson.D", bson.D{}, true},
+		{"empty bson.Raw", bson.Raw(empty), true},
+		{"empty struct", struct{}{}, true},
+		{"bson.M", bson.M{"a": 1}, false},
+		{"bson.D", bson.D{{Key: "a", Value: 1}}, false},
+		{"struct", struct {
+			A int `bson:"a"`
+		}{}, false},
+		{"AllowFullCollection", AllowFullCollection(), false},
+		{"invalid", 42, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isEmptyFilter(tt.filter))
+		})
+	}
+}
+
+func TestSafeWrites(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"), WithSafeWrites())
+	require.NoError(t, err)
+	repo := NewRepository[bson.M](client, "users")
+	ctx := context.Background()
+	update := bson.M{"$set": bson.M{"active": false}}
+
+	_, err = repo.DeleteMany(ctx, bson.M{})
+	assert.ErrorIs(t, err, ErrFullCollectionWrite)
+	var opErr *OperationError
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "delete many", opErr.Op)
+
+	_, err = repo.UpdateMany(ctx, nil, update)
+	assert.ErrorIs(t, err, ErrFullCollectionWrite)
+
+	_, err = client.DeleteManyBatched(ctx, "users", nil, 10, nil)
+	assert.ErrorIs(t, err, ErrFullCollectionWrite)
+	_, err = client.UpdateManyBatched(ctx, "users", bson.D{}, update, 10, nil)
+	assert.ErrorIs(t, err, ErrFullCollectionWrite)
+
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 3}))
+	result, err := repo.DeleteMany(ctx, AllowFullCollection())
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, result.DeletedCount)
+
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+	_, err = repo.UpdateMany(ctx, bson.M{"role": "guest"}, update)
+	require.NoError(t, err)
+}
+
+func TestSafeWrites_Disabled(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 2}))
+	result, err := NewRepository[bson.M](client, "users").DeleteMany(context.Background(), bson.M{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, result.DeletedCount)
+}
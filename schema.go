@@ -0,0 +1,233 @@
+package mongo_kit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Schema Registry
+//
+// This file provides a central registry where document types declare their collection,
+// indexes, validator and TTL, and EnsureSchemas, which applies them at startup.
+//
+// See docs/repository.md for detailed usage guide and examples.
+
+// Schema describes the collection of a registered document type.
+type Schema struct {
+	Type             reflect.Type       // Registered document type
+	Collection       string             // Collection storing the documents
+	Indexes          []mongo.IndexModel // Declared indexes, including TTL indexes
+	Validator        any                // Document validator, e.g. bson.M{"$jsonSchema": ...} (default: none)
+	ValidationLevel  string             // Validation level: "off", "strict" or "moderate" (default: server default)
+	ValidationAction string             // Validation action: "error" or "warn" (default: server default)
+}
+
+// SchemaOption is a function that configures a Schema passed to Register.
+type SchemaOption func(*Schema)
+
+// WithIndexes declares indexes of the collection.
+func WithIndexes(indexes ...mongo.IndexModel) SchemaOption {
+	return func(s *Schema) {
+		s.Indexes = append(s.Indexes, indexes...)
+	}
+}
+
+// WithValidator sets the document validator of the collection, with an optional
+// validation level and action (pass "" to keep the server default).
+//
+// Example:
+//
+//	mongo_kit.WithValidator(bson.M{"$jsonSchema": bson.M{
+//	    "bsonType": "object",
+//	    "required": []string{"email"},
+//	}}, "moderate", "error")
+func WithValidator(validator any, level, action string) SchemaOption {
+	return func(s *Schema) {
+		s.Validator = validator
+		s.ValidationLevel = level
+		s.ValidationAction = action
+	}
+}
+
+// WithTTL declares a TTL index that deletes documents ttl after the date stored in field.
+func WithTTL(field string, ttl time.Duration) SchemaOption {
+	return WithIndexes(NewIndexBuilder().Key(field, 1).TTL(ttl).Build())
+}
+
+// schemas holds the registered schemas in registration order.
+var schemas struct {
+	mu     sync.RWMutex
+	byType map[reflect.Type]*Schema
+	order  []*Schema
+}
+
+// Register declares the collection of document type T with its indexes, validator
+// and TTL. Call it from an init function or at startup before EnsureSchemas.
+// It panics if collection is empty, if T is already registered, or if another type
+// is registered for the same collection.
+//
+// Example:
+//
+//	func init() {
+//	    mongo_kit.Register[User]("users",
+//	        mongo_kit.WithIndexes(mongo_kit.NewIndexBuilder().Key("email", 1).Unique().Build()),
+//	        mongo_kit.WithValidator(bson.M{"$jsonSchema": userSchema}, "", ""),
+//	    )
+//	    mongo_kit.Register[Session]("sessions", mongo_kit.WithTTL("expires_at", 0))
+//	}
+func Register[T any](collection string, opts ...SchemaOption) {
+	typ := reflect.TypeFor[T]()
+	if collection == "" {
+		panic(fmt.Sprintf("mongo_kit: Register[%s] requires a collection name", typ))
+	}
+
+	schema := &Schema{Type: typ, Collection: collection}
+	for _, opt := range opts {
+		opt(schema)
+	}
+
+	schemas.mu.Lock()
+	defer schemas.mu.Unlock()
+
+	if _, ok := schemas.byType[typ]; ok {
+		panic(fmt.Sprintf("mongo_kit: %s is already registered", typ))
+	}
+	for _, s := range schemas.order {
+		if s.Collection == collection {
+			panic(fmt.Sprintf("mongo_kit: collection %q is already registered for %s", collection, s.Type))
+		}
+	}
+
+	if schemas.byType == nil {
+		schemas.byType = make(map[reflect.Type]*Schema)
+	}
+	schemas.byType[typ] = schema
+	schemas.order = append(schemas.order, schema)
+}
+
+// SchemaFor returns the schema registered for T, and false if T is not registered.
+//
+// Example:
+//
+//	if schema, ok := mongo_kit.SchemaFor[User](); ok {
+//	    repo := mongo_kit.NewRepository[User](client, schema.Collection)
+//	}
+func SchemaFor[T any]() (Schema, bool) {
+	schemas.mu.RLock()
+	defer schemas.mu.RUnlock()
+
+	schema, ok := schemas.byType[reflect.TypeFor[T]()]
+	if !ok {
+		return Schema{}, false
+	}
+	return *schema, true
+}
+
+// Schemas returns the registered schemas in registration order.
+func Schemas() []Schema {
+	schemas.mu.RLock()
+	defer schemas.mu.RUnlock()
+
+	result := make([]Schema, len(schemas.order))
+	for i, s := range schemas.order {
+		result[i] = *s
+	}
+	return result
+}
+
+// EnsureSchemas applies every registered schema to the default database: missing
+// collections are created with their validator, validators of existing collections
+// are updated, and declared indexes are created, or recreated when their keys or
+// options changed. Indexes that are not declared are left in place.
+// Running EnsureSchemas at every startup is safe.
+//
+// Example:
+//
+//	if err := client.EnsureSchemas(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+func (c *Client) EnsureSchemas(ctx context.Context) error {
+	registered := Schemas()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	existing, err := c.defaultDB.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return c.operationError(ctx, start, "ensure schemas", err)
+	}
+	exists := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		exists[name] = true
+	}
+
+	spec := make(map[string][]mongo.IndexModel, len(registered))
+	for _, schema := range registered {
+		if err := c.ensureCollection(ctx, schema, exists[schema.Collection]); err != nil {
+			return c.reportError(ctx, start, err)
+		}
+		if len(schema.Indexes) > 0 {
+			spec[schema.Collection] = schema.Indexes
+		}
+	}
+
+	plan, err := c.planIndexes(ctx, spec, false)
+	if err != nil {
+		return c.reportError(ctx, start, err)
+	}
+	if err := c.applyIndexPlan(ctx, plan); err != nil {
+		return c.reportError(ctx, start, err)
+	}
+
+	return nil
+}
+
+// ensureCollection creates the collection of schema with its validator, or updates
+// the validator of an existing collection.
+// The caller MUST hold c.mu.RLock().
+func (c *Client) ensureCollection(ctx context.Context, schema Schema, exists bool) error {
+	if exists {
+		if schema.Validator == nil {
+			return nil
+		}
+		cmd, err := CollModOptions{
+			Validator:        schema.Validator,
+			ValidationLevel:  schema.ValidationLevel,
+			ValidationAction: schema.ValidationAction,
+		}.buildCommand(schema.Collection)
+		if err != nil {
+			return newOperationError("ensure schemas", err)
+		}
+		if err := c.defaultDB.RunCommand(ctx, cmd).Err(); err != nil {
+			return newOperationError("ensure schemas", fmt.Errorf("update validator of %s: %w", schema.Collection, err))
+		}
+		return nil
+	}
+
+	opts := options.CreateCollection()
+	if schema.Validator != nil {
+		opts.SetValidator(schema.Validator)
+	}
+	if schema.ValidationLevel != "" {
+		opts.SetValidationLevel(schema.ValidationLevel)
+	}
+	if schema.ValidationAction != "" {
+		opts.SetValidationAction(schema.ValidationAction)
+	}
+	if err := c.defaultDB.CreateCollection(ctx, schema.Collection, opts); err != nil {
+		return newOperationError("ensure schemas", fmt.Errorf("create %s: %w", schema.Collection, err))
+	}
+	return nil
+}
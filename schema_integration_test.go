@@ -0,0 +1,66 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type schemaSession struct {
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+func TestClient_EnsureSchemas_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	resetSchemas(t)
+
+	Register[User]("users",
+		WithIndexes(NewIndexBuilder().Key("email", 1).Unique().Build()),
+		WithValidator(bson.M{"$jsonSchema": bson.M{
+			"bsonType": "object",
+			"required": []string{"email"},
+		}}, "", ""),
+	)
+	Register[schemaSession]("sessions", WithTTL("expires_at", time.Hour))
+
+	// An existing collection keeps its data and gets the validator.
+	_, err = client.Database().Collection("users").InsertOne(ctx, bson.M{"email": "alice@test.com"})
+	require.NoError(t, err)
+
+	require.NoError(t, client.EnsureSchemas(ctx))
+	require.NoError(t, client.EnsureSchemas(ctx), "EnsureSchemas must be idempotent")
+
+	t.Run("validator rejects invalid documents", func(t *testing.T) {
+		_, err := NewRepository[bson.M](client, "users").Create(ctx, bson.M{"name": "no email"})
+		var validationErr *ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+
+	t.Run("indexes are created", func(t *testing.T) {
+		specs, err := client.Database().Collection("users").Indexes().ListSpecifications(ctx)
+		require.NoError(t, err)
+		assert.Len(t, specs, 2)
+
+		specs, err = client.Database().Collection("sessions").Indexes().ListSpecifications(ctx)
+		require.NoError(t, err)
+		require.Len(t, specs, 2)
+		assert.Equal(t, int32(3600), *specs[1].ExpireAfterSeconds)
+	})
+}
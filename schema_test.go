@@ -0,0 +1,81 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// resetSchemas empties the schema registry for the duration of the test.
+func resetSchemas(t *testing.T) {
+	schemas.mu.Lock()
+	byType, order := schemas.byType, schemas.order
+	schemas.byType, schemas.order = nil, nil
+	schemas.mu.Unlock()
+
+	t.Cleanup(func() {
+		schemas.mu.Lock()
+		schemas.byType, schemas.order = byType, order
+		schemas.mu.Unlock()
+	})
+}
+
+type schemaOrder struct {
+	Total float64 `bson:"total"`
+}
+
+func TestRegister(t *testing.T) {
+	resetSchemas(t)
+
+	email := NewIndexBuilder().Key("email", 1).Unique().Build()
+	validator := bson.M{"$jsonSchema": bson.M{"required": []string{"email"}}}
+	Register[User]("users",
+		WithIndexes(email),
+		WithValidator(validator, "moderate", "warn"),
+		WithTTL("expires_at", time.Hour),
+	)
+	Register[schemaOrder]("orders")
+
+	schema, ok := SchemaFor[User]()
+	require.True(t, ok)
+	assert.Equal(t, "users", schema.Collection)
+	assert.Equal(t, validator, schema.Validator)
+	assert.Equal(t, "moderate", schema.ValidationLevel)
+	assert.Equal(t, "warn", schema.ValidationAction)
+	require.Len(t, schema.Indexes, 2)
+	assert.Equal(t, email, schema.Indexes[0])
+	assert.Equal(t, int32(3600), *schema.Indexes[1].Options.ExpireAfterSeconds)
+
+	_, ok = SchemaFor[bson.M]()
+	assert.False(t, ok)
+
+	registered := Schemas()
+	require.Len(t, registered, 2)
+	assert.Equal(t, "users", registered[0].Collection)
+	assert.Equal(t, "orders", registered[1].Collection)
+
+	t.Run("panics on duplicate type", func(t *testing.T) {
+		assert.PanicsWithValue(t, "mongo_kit: mongo_kit.User is already registered", func() {
+			Register[User]("people")
+		})
+	})
+
+	t.Run("panics on duplicate collection", func(t *testing.T) {
+		assert.PanicsWithValue(t, `mongo_kit: collection "users" is already registered for mongo_kit.User`, func() {
+			Register[bson.M]("users")
+		})
+	})
+
+	t.Run("panics without collection", func(t *testing.T) {
+		assert.Panics(t, func() { Register[bson.D]("") })
+	})
+}
+
+func TestClient_EnsureSchemas_ClosedClient(t *testing.T) {
+	client := &Client{closed: true}
+	assert.ErrorIs(t, client.EnsureSchemas(context.Background()), ErrClientClosed)
+}
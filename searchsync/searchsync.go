@@ -0,0 +1,394 @@
+// Package searchsync keeps an external search index, such as Elasticsearch, OpenSearch
+// or Meilisearch, in sync with MongoDB collections: a full reindex in batches, then
+// continuous updates from change streams.
+//
+// See docs/searchsync.md for detailed usage guide and examples.
+package searchsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	"github.com/edaniel30/mongo-kit-go/cdc"
+)
+
+// defaultBatchSize is the number of documents sent per indexer call when
+// Config.BatchSize is not set.
+const defaultBatchSize = 500
+
+// Document is a document to index.
+type Document struct {
+	Index string   // Target index
+	ID    string   // Document ID, derived from _id
+	Body  bson.Raw // Document to index, after Source.Transform
+}
+
+// Indexer writes to a search engine. Adapters for Elasticsearch, OpenSearch,
+// Meilisearch or others implement it, typically with bulk APIs.
+type Indexer interface {
+	// Upsert creates or replaces docs in their indexes.
+	Upsert(ctx context.Context, docs []Document) error
+	// Delete removes the documents with ids from index. Missing IDs are not an error.
+	Delete(ctx context.Context, index string, ids []string) error
+}
+
+// Transform returns the body to index for doc, or nil to keep doc out of the index.
+type Transform func(doc bson.Raw) (bson.Raw, error)
+
+// Source is a collection kept in sync with an index.
+type Source struct {
+	Collection string    // Collection to index (required)
+	Index      string    // Target index (default: the collection name)
+	Transform  Transform // Shapes or filters the indexed documents (default: the whole document)
+}
+
+// Config describes the synchronization of a set of collections.
+type Config struct {
+	Name      string         // Identifies the sync in the token store (required with Tokens)
+	Sources   []Source       // Collections to index (required)
+	Indexer   Indexer        // Destination search engine (required)
+	Tokens    cdc.TokenStore // Persists a resume token per source (default: none)
+	BatchSize int            // Documents per indexer call (default: 500)
+}
+
+// validate checks that the configuration can run.
+func (c *Config) validate() error {
+	if len(c.Sources) == 0 {
+		return errors.New("searchsync: at least one source is required")
+	}
+	if c.Indexer == nil {
+		return errors.New("searchsync: Indexer is required")
+	}
+	if c.Tokens != nil && c.Name == "" {
+		return errors.New("searchsync: Name is required with Tokens")
+	}
+	if c.BatchSize < 0 {
+		return errors.New("searchsync: BatchSize cannot be negative")
+	}
+	for _, src := range c.Sources {
+		if src.Collection == "" {
+			return errors.New("searchsync: every source requires a Collection")
+		}
+	}
+	return nil
+}
+
+// withDefaults returns a copy of c with defaults applied.
+func (c Config) withDefaults() Config {
+	if c.BatchSize == 0 {
+		c.BatchSize = defaultBatchSize
+	}
+	sources := make([]Source, len(c.Sources))
+	for i, src := range c.Sources {
+		if src.Index == "" {
+			src.Index = src.Collection
+		}
+		sources[i] = src
+	}
+	c.Sources = sources
+	return c
+}
+
+// Reindex sends every document of the configured collections to the indexer, in
+// _id-ordered batches of BatchSize. It does not remove documents that exist only in
+// the index; recreate the index first for a clean rebuild.
+//
+// Example:
+//
+//	err := searchsync.Reindex(ctx, client, cfg)
+func Reindex(ctx context.Context, client *mongokit.Client, cfg Config) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	cfg = cfg.withDefaults()
+
+	for _, src := range cfg.Sources {
+		if err := reindexSource(ctx, client, cfg, src); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reindexSource sends the documents of one source to the indexer.
+func reindexSource(ctx context.Context, client *mongokit.Client, cfg Config, src Source) error {
+	repo := mongokit.NewRepository[bson.Raw](client, src.Collection)
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(cfg.BatchSize))
+
+	var lastID any
+	for {
+		filter := bson.D{}
+		if lastID != nil {
+			filter = bson.D{{Key: "_id", Value: bson.D{{Key: "$gt", Value: lastID}}}}
+		}
+		docs, err := repo.Find(ctx, filter, opts)
+		if err != nil {
+			return err
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+
+		var batch batch
+		for _, doc := range docs {
+			if err := batch.add(src, doc.Lookup("_id"), doc); err != nil {
+				return err
+			}
+		}
+		if err := batch.flush(ctx, cfg.Indexer); err != nil {
+			return err
+		}
+
+		if len(docs) < cfg.BatchSize {
+			return nil
+		}
+		lastID = docs[len(docs)-1].Lookup("_id")
+	}
+}
+
+// Run keeps the index in sync with the configured collections until ctx is done,
+// returning nil when ctx is cancelled, or the first error of a source.
+//
+// Each source tails its change stream and applies changes in batches: inserts,
+// updates and replacements upsert the current document, deletes remove it. After
+// each batch the resume token is saved to Tokens. A source without a saved token
+// opens its change stream first, then runs a full reindex, so no change made during
+// the reindex is lost.
+//
+// Example:
+//
+//	err := searchsync.Run(ctx, client, searchsync.Config{
+//	    Name:    "search",
+//	    Sources: []searchsync.Source{{Collection: "products"}, {Collection: "articles", Index: "blog"}},
+//	    Indexer: meiliIndexer,
+//	    Tokens:  cdc.MongoTokens(client, "search_tokens"),
+//	})
+func Run(ctx context.Context, client *mongokit.Client, cfg Config) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	cfg = cfg.withDefaults()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for _, src := range cfg.Sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := runSource(ctx, client, cfg, src); err != nil && ctx.Err() == nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+
+	return firstErr
+}
+
+// runSource tails the change stream of one source.
+func runSource(ctx context.Context, client *mongokit.Client, cfg Config, src Source) error {
+	tokenKey := cfg.Name + ":" + src.Collection
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+
+	resumed := false
+	if cfg.Tokens != nil {
+		token, found, err := cfg.Tokens.Load(ctx, tokenKey)
+		if err != nil {
+			return fmt.Errorf("searchsync: load resume token: %w", err)
+		}
+		if found {
+			opts.SetResumeAfter(token)
+			resumed = true
+		}
+	}
+
+	stream, err := client.Watch(ctx, src.Collection, nil, opts)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stream.Close(context.Background()) }()
+
+	if !resumed {
+		if err := reindexSource(ctx, client, cfg, src); err != nil {
+			return err
+		}
+		// Save the position of the stream, so a restart does not reindex again.
+		if token := stream.ResumeToken(); cfg.Tokens != nil && token != nil {
+			if err := cfg.Tokens.Save(ctx, tokenKey, token); err != nil {
+				return fmt.Errorf("searchsync: save resume token: %w", err)
+			}
+		}
+	}
+
+	for {
+		token, err := applyChanges(ctx, stream, cfg, src)
+		if err != nil {
+			return err
+		}
+		if cfg.Tokens != nil {
+			if err := cfg.Tokens.Save(ctx, tokenKey, token); err != nil {
+				return fmt.Errorf("searchsync: save resume token: %w", err)
+			}
+		}
+	}
+}
+
+// applyChanges waits for the next change, applies it with the changes already
+// received, up to BatchSize, and returns the resume token of the last one.
+func applyChanges(ctx context.Context, stream *mongo.ChangeStream, cfg Config, src Source) (bson.Raw, error) {
+	var (
+		b     batch
+		token bson.Raw
+	)
+	for n := 0; n < cfg.BatchSize; n++ {
+		if n > 0 && stream.RemainingBatchLength() == 0 {
+			break
+		}
+		if !stream.Next(ctx) {
+			if err := stream.Err(); err != nil {
+				return nil, err
+			}
+			return nil, ctx.Err()
+		}
+		event, err := mongokit.DecodeChangeEvent(stream)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.addEvent(src, event); err != nil {
+			return nil, err
+		}
+		token = event.ResumeToken
+	}
+
+	if err := b.flush(ctx, cfg.Indexer); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// batch collects the writes of a batch, keeping only the last write of each document.
+type batch struct {
+	index   string
+	order   []string
+	upserts map[string]Document
+	deletes map[string]bool
+}
+
+// addEvent adds the write of a change event. Events that do not change a document,
+// such as drops, are ignored.
+func (b *batch) addEvent(src Source, event mongokit.ChangeEvent) error {
+	id := event.DocumentKey.Lookup("_id")
+	switch event.OperationType {
+	case "insert", "update", "replace":
+		if len(event.FullDocument) == 0 {
+			// The document was deleted before the update was looked up.
+			b.remove(src, id)
+			return nil
+		}
+		return b.add(src, id, event.FullDocument)
+	case "delete":
+		b.remove(src, id)
+	}
+	return nil
+}
+
+// add adds an upsert of doc, or a delete if the source's transform drops it.
+func (b *batch) add(src Source, id bson.RawValue, doc bson.Raw) error {
+	body := doc
+	if src.Transform != nil {
+		var err error
+		if body, err = src.Transform(doc); err != nil {
+			return fmt.Errorf("searchsync: transform %s document %s: %w", src.Collection, DocumentID(id), err)
+		}
+		if body == nil {
+			b.remove(src, id)
+			return nil
+		}
+	}
+
+	key := b.track(src, id)
+	delete(b.deletes, key)
+	b.upserts[key] = Document{Index: src.Index, ID: key, Body: body}
+	return nil
+}
+
+// remove adds a delete of the document with id.
+func (b *batch) remove(src Source, id bson.RawValue) {
+	key := b.track(src, id)
+	delete(b.upserts, key)
+	b.deletes[key] = true
+}
+
+// track records the first write of a document and returns its ID.
+func (b *batch) track(src Source, id bson.RawValue) string {
+	if b.upserts == nil {
+		b.index = src.Index
+		b.upserts = make(map[string]Document)
+		b.deletes = make(map[string]bool)
+	}
+	key := DocumentID(id)
+	if _, ok := b.upserts[key]; !ok && !b.deletes[key] {
+		b.order = append(b.order, key)
+	}
+	return key
+}
+
+// flush sends the collected writes to the indexer.
+func (b *batch) flush(ctx context.Context, indexer Indexer) error {
+	var (
+		upserts []Document
+		deletes []string
+	)
+	for _, key := range b.order {
+		if doc, ok := b.upserts[key]; ok {
+			upserts = append(upserts, doc)
+		} else if b.deletes[key] {
+			deletes = append(deletes, key)
+		}
+	}
+
+	if len(upserts) > 0 {
+		if err := indexer.Upsert(ctx, upserts); err != nil {
+			return fmt.Errorf("searchsync: upsert into %s: %w", b.index, err)
+		}
+	}
+	if len(deletes) > 0 {
+		if err := indexer.Delete(ctx, b.index, deletes); err != nil {
+			return fmt.Errorf("searchsync: delete from %s: %w", b.index, err)
+		}
+	}
+	return nil
+}
+
+// DocumentID returns the search document ID of an _id value: the hex string of an
+// ObjectID, the value of a string, and relaxed Extended JSON otherwise.
+func DocumentID(id bson.RawValue) string {
+	if oid, ok := id.ObjectIDOK(); ok {
+		return oid.Hex()
+	}
+	if s, ok := id.StringValueOK(); ok {
+		return s
+	}
+	data, err := bson.MarshalExtJSON(bson.D{{Key: "v", Value: id}}, false, false)
+	if err != nil {
+		return id.String()
+	}
+	// Strip the {"v": ...} wrapper.
+	return string(data[len(`{"v":`) : len(data)-1])
+}
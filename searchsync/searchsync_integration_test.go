@@ -0,0 +1,143 @@
+package searchsync
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+	"github.com/edaniel30/mongo-kit-go/cdc"
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+// memoryIndex is an Indexer keeping the indexed documents in memory.
+type memoryIndex struct {
+	mu   sync.Mutex
+	docs map[string]bson.Raw
+}
+
+func (m *memoryIndex) Upsert(_ context.Context, docs []Document) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.docs == nil {
+		m.docs = map[string]bson.Raw{}
+	}
+	for _, doc := range docs {
+		m.docs[doc.Index+"/"+doc.ID] = doc.Body
+	}
+	return nil
+}
+
+func (m *memoryIndex) Delete(_ context.Context, index string, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		delete(m.docs, index+"/"+id)
+	}
+	return nil
+}
+
+func (m *memoryIndex) get(key string) (bson.Raw, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	doc, ok := m.docs[key]
+	return doc, ok
+}
+
+func (m *memoryIndex) len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.docs)
+}
+
+func TestSearchSync_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := mongokit.New(mongokit.DefaultConfig(), mongokit.WithURI(container.URI), mongokit.WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := mongokit.NewRepository[bson.M](client, "products")
+	for i := range 5 {
+		_, err := repo.Create(ctx, bson.M{"_id": i, "name": "product", "hidden": i == 4})
+		require.NoError(t, err)
+	}
+
+	cfg := Config{
+		Name: "search",
+		Sources: []Source{{Collection: "products", Index: "catalog", Transform: func(doc bson.Raw) (bson.Raw, error) {
+			if doc.Lookup("hidden").Boolean() {
+				return nil, nil
+			}
+			return doc, nil
+		}}},
+		Tokens:    cdc.MongoTokens(client, "search_tokens"),
+		BatchSize: 2,
+	}
+
+	t.Run("Reindex", func(t *testing.T) {
+		index := &memoryIndex{}
+		cfg := cfg
+		cfg.Indexer = index
+		require.NoError(t, Reindex(ctx, client, cfg))
+		assert.Equal(t, 4, index.len())
+	})
+
+	t.Run("Run", func(t *testing.T) {
+		index := &memoryIndex{}
+		cfg := cfg
+		cfg.Indexer = index
+
+		runCtx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, Run(runCtx, client, cfg))
+		}()
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		// The initial reindex indexes the existing documents.
+		testhelpers.AssertEventually(t, func() error {
+			if index.len() < 4 {
+				return assert.AnError
+			}
+			return nil
+		}, 10*time.Second)
+
+		_, err := repo.Create(ctx, bson.M{"_id": 10, "name": "new"})
+		require.NoError(t, err)
+		_, err = repo.UpdateByID(ctx, 0, bson.M{"$set": bson.M{"name": "renamed"}})
+		require.NoError(t, err)
+		_, err = repo.DeleteByID(ctx, 1)
+		require.NoError(t, err)
+
+		testhelpers.AssertEventually(t, func() error {
+			doc, ok := index.get("catalog/0")
+			if !ok || doc.Lookup("name").StringValue() != "renamed" {
+				return assert.AnError
+			}
+			if _, ok := index.get("catalog/10"); !ok {
+				return assert.AnError
+			}
+			if _, ok := index.get("catalog/1"); ok {
+				return assert.AnError
+			}
+			return nil
+		}, 10*time.Second)
+	})
+}
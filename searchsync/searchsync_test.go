@@ -0,0 +1,164 @@
+package searchsync
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	mongokit "github.com/edaniel30/mongo-kit-go"
+)
+
+type recordingIndexer struct {
+	mu      sync.Mutex
+	upserts []Document
+	deletes map[string][]string
+	err     error
+}
+
+func (r *recordingIndexer) Upsert(_ context.Context, docs []Document) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	r.upserts = append(r.upserts, docs...)
+	return nil
+}
+
+func (r *recordingIndexer) Delete(_ context.Context, index string, ids []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	if r.deletes == nil {
+		r.deletes = map[string][]string{}
+	}
+	r.deletes[index] = append(r.deletes[index], ids...)
+	return nil
+}
+
+func rawDoc(t *testing.T, doc any) bson.Raw {
+	t.Helper()
+	data, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	return data
+}
+
+func changeEvent(t *testing.T, op string, id any, doc any) mongokit.ChangeEvent {
+	event := mongokit.ChangeEvent{OperationType: op, DocumentKey: rawDoc(t, bson.M{"_id": id})}
+	if doc != nil {
+		event.FullDocument = rawDoc(t, doc)
+	}
+	return event
+}
+
+func TestConfig_Validate(t *testing.T) {
+	indexer := &recordingIndexer{}
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{"valid", Config{Sources: []Source{{Collection: "products"}}, Indexer: indexer}, ""},
+		{"no sources", Config{Indexer: indexer}, "at least one source"},
+		{"no indexer", Config{Sources: []Source{{Collection: "products"}}}, "Indexer is required"},
+		{"source without collection", Config{Sources: []Source{{}}, Indexer: indexer}, "requires a Collection"},
+		{"negative batch size", Config{Sources: []Source{{Collection: "products"}}, Indexer: indexer, BatchSize: -1}, "cannot be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.validate()
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestConfig_WithDefaults(t *testing.T) {
+	cfg := Config{Sources: []Source{{Collection: "products"}, {Collection: "articles", Index: "blog"}}}.withDefaults()
+	assert.Equal(t, defaultBatchSize, cfg.BatchSize)
+	assert.Equal(t, "products", cfg.Sources[0].Index)
+	assert.Equal(t, "blog", cfg.Sources[1].Index)
+}
+
+func TestBatch(t *testing.T) {
+	ctx := context.Background()
+	src := Source{Collection: "products", Index: "products"}
+
+	t.Run("keeps the last write of each document", func(t *testing.T) {
+		var b batch
+		require.NoError(t, b.addEvent(src, changeEvent(t, "insert", "a", bson.M{"_id": "a", "v": 1})))
+		require.NoError(t, b.addEvent(src, changeEvent(t, "update", "a", bson.M{"_id": "a", "v": 2})))
+		require.NoError(t, b.addEvent(src, changeEvent(t, "insert", "b", bson.M{"_id": "b"})))
+		require.NoError(t, b.addEvent(src, changeEvent(t, "delete", "b", nil)))
+		require.NoError(t, b.addEvent(src, changeEvent(t, "update", "c", nil))) // deleted before lookup
+		require.NoError(t, b.addEvent(src, changeEvent(t, "drop", "d", nil)))
+
+		indexer := &recordingIndexer{}
+		require.NoError(t, b.flush(ctx, indexer))
+
+		require.Len(t, indexer.upserts, 1)
+		assert.Equal(t, "a", indexer.upserts[0].ID)
+		assert.Equal(t, int32(2), indexer.upserts[0].Body.Lookup("v").Int32())
+		assert.Equal(t, []string{"b", "c"}, indexer.deletes["products"])
+	})
+
+	t.Run("transform shapes and filters documents", func(t *testing.T) {
+		src := src
+		src.Transform = func(doc bson.Raw) (bson.Raw, error) {
+			if doc.Lookup("hidden").Boolean() {
+				return nil, nil
+			}
+			name := doc.Lookup("name").StringValue()
+			return bson.Marshal(bson.M{"title": name})
+		}
+
+		var b batch
+		require.NoError(t, b.addEvent(src, changeEvent(t, "insert", "a", bson.M{"_id": "a", "name": "Lamp", "hidden": false})))
+		require.NoError(t, b.addEvent(src, changeEvent(t, "insert", "b", bson.M{"_id": "b", "name": "Desk", "hidden": true})))
+
+		indexer := &recordingIndexer{}
+		require.NoError(t, b.flush(ctx, indexer))
+		require.Len(t, indexer.upserts, 1)
+		assert.Equal(t, "Lamp", indexer.upserts[0].Body.Lookup("title").StringValue())
+		assert.Equal(t, []string{"b"}, indexer.deletes["products"])
+	})
+
+	t.Run("reports transform and indexer errors", func(t *testing.T) {
+		src := src
+		src.Transform = func(bson.Raw) (bson.Raw, error) { return nil, errors.New("bad document") }
+		var b batch
+		assert.ErrorContains(t, b.addEvent(src, changeEvent(t, "insert", "a", bson.M{"_id": "a"})), "bad document")
+
+		b = batch{}
+		require.NoError(t, b.addEvent(Source{Index: "products"}, changeEvent(t, "insert", "a", bson.M{"_id": "a"})))
+		err := b.flush(ctx, &recordingIndexer{err: errors.New("unavailable")})
+		assert.ErrorContains(t, err, "upsert into products")
+	})
+
+	t.Run("empty batch sends nothing", func(t *testing.T) {
+		var b batch
+		assert.NoError(t, b.flush(ctx, &recordingIndexer{err: errors.New("unexpected call")}))
+	})
+}
+
+func TestDocumentID(t *testing.T) {
+	oid := primitive.NewObjectID()
+	doc := rawDoc(t, bson.M{"oid": oid, "str": "sku-1", "num": 42, "obj": bson.M{"a": 1}})
+
+	assert.Equal(t, oid.Hex(), DocumentID(doc.Lookup("oid")))
+	assert.Equal(t, "sku-1", DocumentID(doc.Lookup("str")))
+	assert.Equal(t, "42", DocumentID(doc.Lookup("num")))
+	assert.Equal(t, `{"a":1}`, DocumentID(doc.Lookup("obj")))
+}
@@ -0,0 +1,189 @@
+package mongo_kit
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Server Metrics
+//
+// This file provides typed subsets of serverStatus and replSetGetStatus, the
+// connection, operation and replication metrics monitoring agents need, as a stable
+// Go API.
+//
+// See docs/operations.md for detailed usage guide and examples.
+
+// ServerStatus is a subset of the serverStatus command output. Raw holds the
+// complete document for metrics not covered by the typed fields.
+type ServerStatus struct {
+	Host        string          // Host name and port of the server
+	Version     string          // MongoDB version
+	Process     string          // "mongod" or "mongos"
+	Uptime      time.Duration   // Time since the server started
+	LocalTime   time.Time       // Current time of the server
+	Connections ConnectionStats // Client connections
+	Opcounters  OpCounters      // Operations since the server started
+	Raw         bson.Raw        // Complete serverStatus document
+}
+
+// ConnectionStats holds the connection counters of serverStatus.
+type ConnectionStats struct {
+	Current      int64 `bson:"current"`      // Open connections
+	Available    int64 `bson:"available"`    // Connections that can still be opened
+	Active       int64 `bson:"active"`       // Connections with an operation in progress
+	TotalCreated int64 `bson:"totalCreated"` // Connections created since the server started
+}
+
+// OpCounters holds the operation counters of serverStatus, counted since the server
+// started.
+type OpCounters struct {
+	Insert  int64 `bson:"insert"`
+	Query   int64 `bson:"query"`
+	Update  int64 `bson:"update"`
+	Delete  int64 `bson:"delete"`
+	GetMore int64 `bson:"getmore"`
+	Command int64 `bson:"command"`
+}
+
+// ReplicationInfo is a subset of the replSetGetStatus command output.
+type ReplicationInfo struct {
+	SetName string          // Replica set name
+	Members []ReplicaMember // Members in the order of the replica set configuration
+}
+
+// ReplicaMember is the status of a replica set member.
+type ReplicaMember struct {
+	Name       string        // Host name and port
+	State      string        // State, e.g. "PRIMARY", "SECONDARY", "ARBITER", "RECOVERING"
+	Healthy    bool          // Whether the member is reachable
+	Self       bool          // Whether the member is the server that reported the status
+	OptimeDate time.Time     // Time of the last operation applied by the member
+	Lag        time.Duration // Replication lag behind the primary; 0 for the primary, arbiters and without a primary
+	Ping       time.Duration // Round trip time to the member from the reporting server
+}
+
+// Primary returns the primary member, or false if the replica set has no primary.
+func (r *ReplicationInfo) Primary() (ReplicaMember, bool) {
+	for _, m := range r.Members {
+		if m.State == "PRIMARY" {
+			return m, true
+		}
+	}
+	return ReplicaMember{}, false
+}
+
+// MaxLag returns the largest replication lag of the healthy secondaries.
+func (r *ReplicationInfo) MaxLag() time.Duration {
+	var lag time.Duration
+	for _, m := range r.Members {
+		if m.Healthy && m.State == "SECONDARY" {
+			lag = max(lag, m.Lag)
+		}
+	}
+	return lag
+}
+
+// ServerStatus returns the connection and operation metrics of the server the client
+// is connected to.
+//
+// Example:
+//
+//	status, err := client.ServerStatus(ctx)
+//	metrics.Gauge("mongo.connections.current", status.Connections.Current)
+//	metrics.Counter("mongo.opcounters.query", status.Opcounters.Query)
+func (c *Client) ServerStatus(ctx context.Context) (*ServerStatus, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	raw, err := c.client.Database("admin").RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}}).Raw()
+	if err != nil {
+		return nil, c.operationError(ctx, start, "server status", err)
+	}
+
+	var result struct {
+		Host        string          `bson:"host"`
+		Version     string          `bson:"version"`
+		Process     string          `bson:"process"`
+		Uptime      float64         `bson:"uptime"`
+		LocalTime   time.Time       `bson:"localTime"`
+		Connections ConnectionStats `bson:"connections"`
+		Opcounters  OpCounters      `bson:"opcounters"`
+	}
+	if err := bson.Unmarshal(raw, &result); err != nil {
+		return nil, c.operationError(ctx, start, "server status decode", err)
+	}
+
+	return &ServerStatus{
+		Host:        result.Host,
+		Version:     result.Version,
+		Process:     result.Process,
+		Uptime:      time.Duration(result.Uptime * float64(time.Second)),
+		LocalTime:   result.LocalTime,
+		Connections: result.Connections,
+		Opcounters:  result.Opcounters,
+		Raw:         raw,
+	}, nil
+}
+
+// ReplicationInfo returns the state and replication lag of the members of the replica
+// set the client is connected to. Lag is measured from the optime of each member to
+// the optime of the primary. Fails on standalone servers and mongos.
+//
+// Example:
+//
+//	info, err := client.ReplicationInfo(ctx)
+//	for _, m := range info.Members {
+//	    metrics.Gauge("mongo.repl.lag_seconds", m.Lag.Seconds(), "member", m.Name)
+//	}
+func (c *Client) ReplicationInfo(ctx context.Context) (*ReplicationInfo, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	var result struct {
+		Set     string `bson:"set"`
+		Members []struct {
+			Name       string    `bson:"name"`
+			StateStr   string    `bson:"stateStr"`
+			Health     float64   `bson:"health"`
+			Self       bool      `bson:"self"`
+			OptimeDate time.Time `bson:"optimeDate"`
+			PingMs     int64     `bson:"pingMs"`
+		} `bson:"members"`
+	}
+	if err := c.client.Database("admin").RunCommand(ctx, bson.D{{Key: "replSetGetStatus", Value: 1}}).Decode(&result); err != nil {
+		return nil, c.operationError(ctx, start, "replication info", err)
+	}
+
+	info := &ReplicationInfo{SetName: result.Set, Members: make([]ReplicaMember, len(result.Members))}
+	for i, m := range result.Members {
+		info.Members[i] = ReplicaMember{
+			Name:       m.Name,
+			State:      m.StateStr,
+			Healthy:    m.Health == 1,
+			Self:       m.Self,
+			OptimeDate: m.OptimeDate,
+			Ping:       time.Duration(m.PingMs) * time.Millisecond,
+		}
+	}
+	if primary, ok := info.Primary(); ok {
+		for i, m := range info.Members {
+			if m.State == "SECONDARY" && !m.OptimeDate.IsZero() {
+				info.Members[i].Lag = max(primary.OptimeDate.Sub(m.OptimeDate), 0)
+			}
+		}
+	}
+
+	return info, nil
+}
@@ -0,0 +1,49 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestClient_ServerMetrics_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+
+	t.Run("ServerStatus", func(t *testing.T) {
+		status, err := client.ServerStatus(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "mongod", status.Process)
+		assert.NotEmpty(t, status.Version)
+		assert.Positive(t, status.Connections.Current)
+		assert.Positive(t, status.Opcounters.Command)
+		assert.Positive(t, status.Uptime)
+	})
+
+	t.Run("ReplicationInfo", func(t *testing.T) {
+		info, err := client.ReplicationInfo(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "rs0", info.SetName)
+		require.Len(t, info.Members, 1)
+
+		primary, ok := info.Primary()
+		require.True(t, ok)
+		assert.True(t, primary.Self)
+		assert.True(t, primary.Healthy)
+		assert.Zero(t, info.MaxLag())
+	})
+}
@@ -0,0 +1,130 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestClient_ServerMetrics_ClosedClient(t *testing.T) {
+	client := &Client{closed: true}
+	ctx := context.Background()
+
+	_, err := client.ServerStatus(ctx)
+	assert.ErrorIs(t, err, ErrClientClosed)
+
+	_, err = client.ReplicationInfo(ctx)
+	assert.ErrorIs(t, err, ErrClientClosed)
+}
+
+func TestClient_ServerStatus(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	mock.AddResponses(testhelpers.SuccessResponse(
+		bson.E{Key: "host", Value: "db1:27017"},
+		bson.E{Key: "version", Value: "7.0.12"},
+		bson.E{Key: "process", Value: "mongod"},
+		bson.E{Key: "uptime", Value: 90.5},
+		bson.E{Key: "connections", Value: bson.D{
+			{Key: "current", Value: int32(12)},
+			{Key: "available", Value: int32(838848)},
+			{Key: "totalCreated", Value: int32(40)},
+			{Key: "active", Value: int32(3)},
+		}},
+		bson.E{Key: "opcounters", Value: bson.D{
+			{Key: "insert", Value: int64(10)},
+			{Key: "query", Value: int64(20)},
+			{Key: "update", Value: int64(5)},
+			{Key: "delete", Value: int64(1)},
+			{Key: "getmore", Value: int64(2)},
+			{Key: "command", Value: int64(300)},
+		}},
+	))
+
+	status, err := client.ServerStatus(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "db1:27017", status.Host)
+	assert.Equal(t, "7.0.12", status.Version)
+	assert.Equal(t, "mongod", status.Process)
+	assert.Equal(t, 90500*time.Millisecond, status.Uptime)
+	assert.Equal(t, ConnectionStats{Current: 12, Available: 838848, Active: 3, TotalCreated: 40}, status.Connections)
+	assert.Equal(t, OpCounters{Insert: 10, Query: 20, Update: 5, Delete: 1, GetMore: 2, Command: 300}, status.Opcounters)
+	assert.Equal(t, "7.0.12", status.Raw.Lookup("version").StringValue())
+}
+
+func TestClient_ReplicationInfo(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	optime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	member := func(name, state string, health float64, optime time.Time) bson.D {
+		return bson.D{
+			{Key: "name", Value: name},
+			{Key: "stateStr", Value: state},
+			{Key: "health", Value: health},
+			{Key: "optimeDate", Value: optime},
+			{Key: "pingMs", Value: int64(2)},
+		}
+	}
+
+	t.Run("computes the lag of secondaries", func(t *testing.T) {
+		mock.AddResponses(testhelpers.SuccessResponse(
+			bson.E{Key: "set", Value: "rs0"},
+			bson.E{Key: "members", Value: bson.A{
+				member("db1:27017", "PRIMARY", 1, optime),
+				member("db2:27017", "SECONDARY", 1, optime.Add(-3*time.Second)),
+				member("db3:27017", "SECONDARY", 0, optime.Add(-time.Hour)),
+				member("db4:27017", "ARBITER", 1, time.Time{}),
+			}},
+		))
+
+		info, err := client.ReplicationInfo(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "rs0", info.SetName)
+		require.Len(t, info.Members, 4)
+
+		primary, ok := info.Primary()
+		require.True(t, ok)
+		assert.Equal(t, "db1:27017", primary.Name)
+		assert.Zero(t, primary.Lag)
+		assert.Equal(t, 3*time.Second, info.Members[1].Lag)
+		assert.Equal(t, 2*time.Millisecond, info.Members[1].Ping)
+		assert.False(t, info.Members[2].Healthy)
+		assert.Equal(t, time.Hour, info.Members[2].Lag)
+		assert.Zero(t, info.Members[3].Lag)
+		assert.Equal(t, 3*time.Second, info.MaxLag(), "unhealthy members are ignored")
+	})
+
+	t.Run("reports no lag without a primary", func(t *testing.T) {
+		mock.AddResponses(testhelpers.SuccessResponse(
+			bson.E{Key: "set", Value: "rs0"},
+			bson.E{Key: "members", Value: bson.A{
+				member("db2:27017", "SECONDARY", 1, optime.Add(-3*time.Second)),
+			}},
+		))
+
+		info, err := client.ReplicationInfo(ctx)
+		require.NoError(t, err)
+		_, ok := info.Primary()
+		assert.False(t, ok)
+		assert.Zero(t, info.MaxLag())
+	})
+
+	t.Run("fails on standalone servers", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CommandErrorResponse(76, "NoReplicationEnabled", "not running with --replSet"))
+		_, err := client.ReplicationInfo(ctx)
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "replication info", opErr.Op)
+	})
+}
@@ -0,0 +1,167 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Repository Statistics
+//
+// This file provides grouped counts and basic statistics (sum, average, minimum and
+// maximum) computed by small $group pipelines, so common reports do not require writing aggregation code.
+//
+// See docs/repository.md for detailed usage guide and examples.
+
+// CountBy returns the number of documents matching the filter for each value of field,
+// e.g. the number of orders per status. Values are keyed by their string form:
+// strings as is, ObjectIDs as hex, other values formatted with fmt.Sprint, and
+// documents where field is missing or null under "". A nil filter counts every document.
+//
+// Example:
+//
+//	counts, err := orders.CountBy(ctx, "status", bson.M{"created_at": bson.M{"$gte": since}})
+//	// counts["paid"] == 42, counts["refunded"] == 3
+func (r *Repository[T]) CountBy(ctx context.Context, field string, filter any) (map[string]int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if field == "" {
+		return nil, newOperationError("count by", errors.New("field cannot be empty"))
+	}
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$" + field},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+	var groups []bson.Raw
+	if err := r.client.aggregate(ctx, r.collection, pipeline, &groups); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(groups))
+	for _, group := range groups {
+		key, err := r.groupKey(group.Lookup("_id"))
+		if err != nil {
+			return nil, newOperationError("count by decode", err)
+		}
+		n, ok := group.Lookup("count").AsInt64OK()
+		if !ok {
+			return nil, newOperationError("count by decode", fmt.Errorf("group %q has no count", key))
+		}
+		counts[key] += n
+	}
+	return counts, nil
+}
+
+// Sum returns the sum of the numeric values of field across the documents matching the
+// filter, or 0 when there are none. Non-numeric values are ignored. A nil filter sums
+// over every document.
+//
+// Example:
+//
+//	revenue, err := orders.Sum(ctx, "total", bson.M{"status": "paid"})
+func (r *Repository[T]) Sum(ctx context.Context, field string, filter any) (float64, error) {
+	sum, err := r.statistic(ctx, "sum", "$sum", field, filter)
+	if errors.Is(err, ErrNotFound) {
+		return 0, nil
+	}
+	return sum, err
+}
+
+// Avg returns the average of the numeric values of field across the documents matching
+// the filter. Non-numeric values are ignored. Returns ErrNotFound if no document has a
+// numeric value.
+func (r *Repository[T]) Avg(ctx context.Context, field string, filter any) (float64, error) {
+	return r.statistic(ctx, "avg", "$avg", field, filter)
+}
+
+// Min returns the smallest numeric value of field across the documents matching the
+// filter. Non-numeric values are ignored. Returns ErrNotFound if no document has a
+// numeric value.
+func (r *Repository[T]) Min(ctx context.Context, field string, filter any) (float64, error) {
+	return r.statistic(ctx, "min", "$min", field, filter)
+}
+
+// Max returns the largest numeric value of field across the documents matching the
+// filter. Non-numeric values are ignored. Returns ErrNotFound if no document has a
+// numeric value.
+func (r *Repository[T]) Max(ctx context.Context, field string, filter any) (float64, error) {
+	return r.statistic(ctx, "max", "$max", field, filter)
+}
+
+// statistic computes accumulator over the numeric values of field across the documents
+// matching the filter. Returns ErrNotFound if no document has a numeric value.
+func (r *Repository[T]) statistic(ctx context.Context, op, accumulator, field string, filter any) (float64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if field == "" {
+		return 0, newOperationError(op, errors.New("field cannot be empty"))
+	}
+	if filter == nil {
+		filter = bson.M{}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$match", Value: bson.D{{Key: field, Value: bson.D{{Key: "$type", Value: "number"}}}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "value", Value: bson.D{{Key: accumulator, Value: "$" + field}}},
+		}}},
+	}
+	var groups []bson.Raw
+	if err := r.client.aggregate(ctx, r.collection, pipeline, &groups); err != nil {
+		return 0, err
+	}
+	if len(groups) == 0 {
+		return 0, ErrNotFound
+	}
+
+	value := groups[0].Lookup("value")
+	switch value.Type {
+	case bsontype.Double:
+		return value.Double(), nil
+	case bsontype.Int32:
+		return float64(value.Int32()), nil
+	case bsontype.Int64:
+		return float64(value.Int64()), nil
+	case bsontype.Decimal128:
+		f, err := strconv.ParseFloat(value.Decimal128().String(), 64)
+		if err != nil {
+			return 0, newOperationError(op+" decode", err)
+		}
+		return f, nil
+	default:
+		return 0, newOperationError(op+" decode", fmt.Errorf("%s is not a number", value.Type))
+	}
+}
+
+// groupKey returns the string form of a group value used as a CountBy key.
+func (r *Repository[T]) groupKey(value bson.RawValue) (string, error) {
+	switch value.Type {
+	case bsontype.String:
+		return value.StringValue(), nil
+	case bsontype.ObjectID:
+		return value.ObjectID().Hex(), nil
+	case bsontype.Null, bsontype.Undefined, 0:
+		return "", nil
+	}
+	var v any
+	if err := value.UnmarshalWithRegistry(r.client.bsonRegistry(), &v); err != nil {
+		return "", err
+	}
+	return fmt.Sprint(v), nil
+}
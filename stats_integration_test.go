@@ -0,0 +1,70 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestRepository_Stats_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := NewRepository[bson.M](client, "orders")
+	_, err = repo.CreateMany(ctx, []bson.M{
+		{"status": "paid", "total": 10, "region": "eu"},
+		{"status": "paid", "total": 30, "region": "us"},
+		{"status": "pending", "total": 5.5, "region": "eu"},
+		{"total": 1},
+	})
+	require.NoError(t, err)
+
+	t.Run("CountBy counts per value", func(t *testing.T) {
+		counts, err := repo.CountBy(ctx, "status", nil)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"paid": 2, "pending": 1, "": 1}, counts)
+
+		counts, err = repo.CountBy(ctx, "status", bson.M{"region": "eu"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int64{"paid": 1, "pending": 1}, counts)
+	})
+
+	t.Run("statistics of numeric values", func(t *testing.T) {
+		sum, err := repo.Sum(ctx, "total", nil)
+		require.NoError(t, err)
+		assert.Equal(t, 46.5, sum)
+
+		avg, err := repo.Avg(ctx, "total", bson.M{"status": "paid"})
+		require.NoError(t, err)
+		assert.Equal(t, 20.0, avg)
+
+		lowest, err := repo.Min(ctx, "total", nil)
+		require.NoError(t, err)
+		assert.Equal(t, 1.0, lowest)
+
+		highest, err := repo.Max(ctx, "total", bson.M{"region": "eu"})
+		require.NoError(t, err)
+		assert.Equal(t, 10.0, highest)
+
+		_, err = repo.Max(ctx, "status", nil)
+		assert.ErrorIs(t, err, ErrNotFound, "strings are not numbers")
+
+		sum, err = repo.Sum(ctx, "total", bson.M{"status": "cancelled"})
+		require.NoError(t, err)
+		assert.Zero(t, sum)
+	})
+}
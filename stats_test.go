@@ -0,0 +1,107 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func newStatsRepository(t *testing.T) (*Repository[bson.M], *testhelpers.MockClient) {
+	t.Helper()
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	return NewRepository[bson.M](client, "orders"), mock
+}
+
+func TestRepository_CountBy(t *testing.T) {
+	repo, mock := newStatsRepository(t)
+	oid := primitive.NewObjectID()
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.orders",
+		bson.D{{Key: "_id", Value: "paid"}, {Key: "count", Value: int32(3)}},
+		bson.D{{Key: "_id", Value: nil}, {Key: "count", Value: int32(2)}},
+		bson.D{{Key: "_id", Value: int32(5)}, {Key: "count", Value: int64(1)}},
+		bson.D{{Key: "_id", Value: oid}, {Key: "count", Value: int32(4)}},
+	))
+	counts, err := repo.CountBy(context.Background(), "status", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"paid": 3, "": 2, "5": 1, oid.Hex(): 4}, counts)
+}
+
+func TestRepository_CountBy_Errors(t *testing.T) {
+	repo, mock := newStatsRepository(t)
+	ctx := context.Background()
+
+	_, err := repo.CountBy(ctx, "", nil)
+	var opErr *OperationError
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "count by", opErr.Op)
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.orders", bson.D{{Key: "_id", Value: "paid"}}))
+	_, err = repo.CountBy(ctx, "status", nil)
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "count by decode", opErr.Op)
+
+	mock.AddResponses(testhelpers.CommandErrorResponse(2, "BadValue", "bad group"))
+	_, err = repo.CountBy(ctx, "status", bson.M{})
+	assert.Error(t, err)
+}
+
+func TestRepository_Statistics(t *testing.T) {
+	ctx := context.Background()
+	decimal, err := primitive.ParseDecimal128("12.5")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		run   func(*Repository[bson.M]) (float64, error)
+		value any
+		want  float64
+	}{
+		{"Sum int32", func(r *Repository[bson.M]) (float64, error) { return r.Sum(ctx, "total", nil) }, int32(40), 40},
+		{"Sum decimal", func(r *Repository[bson.M]) (float64, error) { return r.Sum(ctx, "total", nil) }, decimal, 12.5},
+		{"Avg", func(r *Repository[bson.M]) (float64, error) { return r.Avg(ctx, "total", bson.M{}) }, 7.5, 7.5},
+		{"Min int64", func(r *Repository[bson.M]) (float64, error) { return r.Min(ctx, "total", nil) }, int64(-3), -3},
+		{"Max", func(r *Repository[bson.M]) (float64, error) { return r.Max(ctx, "total", nil) }, 99.0, 99},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mock := newStatsRepository(t)
+			mock.AddResponses(testhelpers.CursorResponse("testdb.orders", bson.D{{Key: "_id", Value: nil}, {Key: "value", Value: tt.value}}))
+			got, err := tt.run(repo)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRepository_Statistics_NoValues(t *testing.T) {
+	repo, mock := newStatsRepository(t)
+	ctx := context.Background()
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.orders"))
+	sum, err := repo.Sum(ctx, "total", nil)
+	require.NoError(t, err)
+	assert.Zero(t, sum)
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.orders"))
+	_, err = repo.Avg(ctx, "total", nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = repo.Max(ctx, "", nil)
+	var opErr *OperationError
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "max", opErr.Op)
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.orders", bson.D{{Key: "_id", Value: nil}, {Key: "value", Value: "x"}}))
+	_, err = repo.Min(ctx, "total", nil)
+	require.ErrorAs(t, err, &opErr)
+	assert.Equal(t, "min decode", opErr.Op)
+}
@@ -0,0 +1,93 @@
+package testing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// pollInterval is how often AssertEventually retries its condition.
+const pollInterval = 50 * time.Millisecond
+
+// DocumentCounter is implemented by *mongo_kit.Repository[T]. The assertion helpers
+// accept it so they can be used with repositories of any document type.
+type DocumentCounter interface {
+	Count(ctx context.Context, filter any, opts ...*options.CountOptions) (int64, error)
+}
+
+// AssertDocExists checks that at least one document of repo matches filter.
+// It marks the test as failed otherwise and returns whether the check passed.
+//
+// Example:
+//
+//	testhelpers.AssertDocExists(t, userRepo, bson.M{"email": "alice@example.com"})
+func AssertDocExists(t testing.TB, repo DocumentCounter, filter any) bool {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), fixtureTimeout)
+	defer cancel()
+
+	count, err := repo.Count(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		t.Errorf("failed to count documents matching %v: %v", filter, err)
+		return false
+	}
+	if count == 0 {
+		t.Errorf("expected a document matching %v, found none", filter)
+		return false
+	}
+	return true
+}
+
+// AssertCount checks that exactly n documents of repo match filter.
+// It marks the test as failed otherwise and returns whether the check passed.
+//
+// Example:
+//
+//	testhelpers.AssertCount(t, orderRepo, bson.M{"status": "paid"}, 3)
+func AssertCount(t testing.TB, repo DocumentCounter, filter any, n int64) bool {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), fixtureTimeout)
+	defer cancel()
+
+	count, err := repo.Count(ctx, filter)
+	if err != nil {
+		t.Errorf("failed to count documents matching %v: %v", filter, err)
+		return false
+	}
+	if count != n {
+		t.Errorf("expected %d document(s) matching %v, found %d", n, filter, count)
+		return false
+	}
+	return true
+}
+
+// AssertEventually calls fn until it returns nil or timeout elapses, e.g. to wait for
+// a change stream consumer or a background job. It marks the test as failed with the
+// last error of fn on timeout and returns whether fn succeeded.
+//
+// Example:
+//
+//	testhelpers.AssertEventually(t, func() error {
+//	    _, err := auditRepo.FindOne(ctx, bson.M{"user_id": id})
+//	    return err
+//	}, 5*time.Second)
+func AssertEventually(t testing.TB, fn func() error, timeout time.Duration) bool {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := fn()
+		if err == nil {
+			return true
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("condition not met within %s: %v", timeout, err)
+			return false
+		}
+		time.Sleep(pollInterval)
+	}
+}
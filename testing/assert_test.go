@@ -0,0 +1,82 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// fakeCounter returns a fixed count or error.
+type fakeCounter struct {
+	count int64
+	err   error
+}
+
+func (f fakeCounter) Count(context.Context, any, ...*options.CountOptions) (int64, error) {
+	return f.count, f.err
+}
+
+// recorder captures the failures reported by assertion helpers.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertDocExists(t *testing.T) {
+	rec := &recorder{TB: t}
+	assert.True(t, AssertDocExists(rec, fakeCounter{count: 1}, nil))
+	assert.Empty(t, rec.errors)
+
+	assert.False(t, AssertDocExists(rec, fakeCounter{}, map[string]any{"name": "Alice"}))
+	assert.False(t, AssertDocExists(rec, fakeCounter{err: errors.New("boom")}, nil))
+	assert.Len(t, rec.errors, 2)
+	assert.Contains(t, rec.errors[0], "found none")
+	assert.Contains(t, rec.errors[1], "boom")
+}
+
+func TestAssertCount(t *testing.T) {
+	rec := &recorder{TB: t}
+	assert.True(t, AssertCount(rec, fakeCounter{count: 3}, nil, 3))
+	assert.Empty(t, rec.errors)
+
+	assert.False(t, AssertCount(rec, fakeCounter{count: 2}, nil, 3))
+	assert.Equal(t, []string{"expected 3 document(s) matching <nil>, found 2"}, rec.errors)
+}
+
+func TestAssertEventually(t *testing.T) {
+	t.Run("succeeds once fn does", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		calls := 0
+		ok := AssertEventually(rec, func() error {
+			calls++
+			if calls < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		}, time.Second)
+
+		assert.True(t, ok)
+		assert.Equal(t, 3, calls)
+		assert.Empty(t, rec.errors)
+	})
+
+	t.Run("fails with the last error on timeout", func(t *testing.T) {
+		rec := &recorder{TB: t}
+		ok := AssertEventually(rec, func() error { return errors.New("not yet") }, 100*time.Millisecond)
+
+		assert.False(t, ok)
+		assert.Len(t, rec.errors, 1)
+		assert.Contains(t, rec.errors[0], "not yet")
+	})
+}
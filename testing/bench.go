@@ -0,0 +1,174 @@
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BenchTarget is implemented by *mongo_kit.Repository[T]. BenchRepository accepts it so
+// it can benchmark repositories of any document type.
+type BenchTarget[T any] interface {
+	Create(ctx context.Context, document T) (any, error)
+	CreateMany(ctx context.Context, documents []T, opts ...*options.InsertManyOptions) ([]any, error)
+	FindByID(ctx context.Context, id any) (*T, error)
+	Find(ctx context.Context, filter any, opts ...*options.FindOptions) ([]T, error)
+	UpdateByID(ctx context.Context, id any, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
+	DeleteMany(ctx context.Context, filter any) (*mongo.DeleteResult, error)
+}
+
+// BenchProfile describes the data set and queries of BenchRepository.
+type BenchProfile[T any] struct {
+	Factory   func(i int) T // Builds the i-th synthetic document (required)
+	Documents int           // Documents seeded before the read and update benchmarks (default: 1000)
+	BatchSize int           // Documents per CreateMany call in the InsertMany benchmark (default: 100)
+	Filter    any           // Filter of the Find benchmark (default: all documents)
+	FindLimit int64         // Maximum documents returned by the Find benchmark (default: 20)
+	Update    any           // Update of the UpdateByID benchmark; the benchmark is skipped when nil
+}
+
+// withDefaults returns the profile with zero fields set to their defaults.
+func (p BenchProfile[T]) withDefaults() BenchProfile[T] {
+	if p.Documents <= 0 {
+		p.Documents = 1000
+	}
+	if p.BatchSize <= 0 {
+		p.BatchSize = 100
+	}
+	if p.Filter == nil {
+		p.Filter = bson.M{}
+	}
+	if p.FindLimit <= 0 {
+		p.FindLimit = 20
+	}
+	return p
+}
+
+// GenerateDocs returns n documents built by factory, which receives the index of the
+// document, from 0 to n-1.
+func GenerateDocs[T any](n int, factory func(i int) T) []T {
+	docs := make([]T, n)
+	for i := range n {
+		docs[i] = factory(i)
+	}
+	return docs
+}
+
+// BenchRepository runs a standard set of sub-benchmarks against repo so the performance
+// of the kit can be compared between changes: InsertOne, InsertMany, FindByID, Find and,
+// when the profile has an Update, UpdateByID. The collection is emptied before each
+// sub-benchmark and the read benchmarks run against profile.Documents seeded documents.
+// InsertMany also reports docs/s. It skips in short mode.
+//
+// Example:
+//
+//	func BenchmarkUsers(b *testing.B) {
+//	    container := testhelpers.SetupMongoContainer(b)
+//	    defer container.Teardown(b)
+//	    client, _ := mongo_kit.New(mongo_kit.DefaultConfig(), mongo_kit.WithURI(container.URI))
+//	    defer client.Close(context.Background())
+//
+//	    testhelpers.BenchRepository(b, mongo_kit.NewRepository[User](client, "users"),
+//	        testhelpers.BenchProfile[User]{
+//	            Factory: func(i int) User { return User{Name: fmt.Sprintf("user-%d", i), Age: i % 90} },
+//	            Filter:  bson.M{"age": bson.M{"$gte": 30}},
+//	            Update:  bson.M{"$inc": bson.M{"age": 1}},
+//	        })
+//	}
+func BenchRepository[T any](b *testing.B, repo BenchTarget[T], profile BenchProfile[T]) {
+	b.Helper()
+
+	if testing.Short() {
+		b.Skip("skipping benchmark in short mode")
+	}
+	if profile.Factory == nil {
+		b.Fatal("bench: profile Factory is required")
+	}
+	profile = profile.withDefaults()
+	ctx := context.Background()
+
+	b.Run("InsertOne", func(b *testing.B) {
+		resetBench(b, repo)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.Create(ctx, profile.Factory(i)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("InsertMany", func(b *testing.B) {
+		resetBench(b, repo)
+		batch := GenerateDocs(profile.BatchSize, profile.Factory)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			// Documents without an _id field get a new one on every insert.
+			if _, err := repo.CreateMany(ctx, batch); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(b.N*profile.BatchSize)/b.Elapsed().Seconds(), "docs/s")
+	})
+
+	b.Run("FindByID", func(b *testing.B) {
+		ids := seedBench(b, repo, profile)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.FindByID(ctx, ids[i%len(ids)]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Find", func(b *testing.B) {
+		seedBench(b, repo, profile)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.Find(ctx, profile.Filter, options.Find().SetLimit(profile.FindLimit)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	if profile.Update != nil {
+		b.Run("UpdateByID", func(b *testing.B) {
+			ids := seedBench(b, repo, profile)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := repo.UpdateByID(ctx, ids[i%len(ids)], profile.Update); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// resetBench deletes all documents of repo.
+func resetBench[T any](b *testing.B, repo BenchTarget[T]) {
+	b.Helper()
+	if _, err := repo.DeleteMany(context.Background(), bson.M{}); err != nil {
+		b.Fatalf("failed to reset benchmark collection: %v", err)
+	}
+}
+
+// seedBench replaces the documents of repo with profile.Documents synthetic documents
+// and returns their _id values.
+func seedBench[T any](b *testing.B, repo BenchTarget[T], profile BenchProfile[T]) []any {
+	b.Helper()
+	resetBench(b, repo)
+
+	ids := make([]any, 0, profile.Documents)
+	for start := 0; start < profile.Documents; start += profile.BatchSize {
+		n := min(profile.BatchSize, profile.Documents-start)
+		batch := GenerateDocs(n, func(i int) T { return profile.Factory(start + i) })
+		inserted, err := repo.CreateMany(context.Background(), batch)
+		if err != nil {
+			b.Fatalf("failed to seed benchmark collection: %v", err)
+		}
+		ids = append(ids, inserted...)
+	}
+	return ids
+}
@@ -0,0 +1,27 @@
+package testing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestBenchProfile_Defaults(t *testing.T) {
+	p := BenchProfile[bson.M]{}.withDefaults()
+	assert.Equal(t, 1000, p.Documents)
+	assert.Equal(t, 100, p.BatchSize)
+	assert.Equal(t, bson.M{}, p.Filter)
+	assert.Equal(t, int64(20), p.FindLimit)
+
+	p = BenchProfile[bson.M]{Documents: 10, BatchSize: 5, FindLimit: 1, Filter: bson.M{"a": 1}}.withDefaults()
+	assert.Equal(t, 10, p.Documents)
+	assert.Equal(t, 5, p.BatchSize)
+	assert.Equal(t, bson.M{"a": 1}, p.Filter)
+	assert.Equal(t, int64(1), p.FindLimit)
+}
+
+func TestGenerateDocs(t *testing.T) {
+	docs := GenerateDocs(3, func(i int) int { return i * 10 })
+	assert.Equal(t, []int{0, 10, 20}, docs)
+}
@@ -0,0 +1,49 @@
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CleanCollections deletes all documents of the named collections, keeping their
+// indexes and validation rules, so test cases can share collections without seeing
+// each other's data. Missing collections are ignored. The test fails on any error.
+//
+// Example:
+//
+//	t.Cleanup(func() { testhelpers.CleanCollections(t, client, "users", "orders") })
+func CleanCollections(t testing.TB, client DatabaseProvider, names ...string) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), fixtureTimeout)
+	defer cancel()
+
+	db := client.Database()
+	for _, name := range names {
+		if _, err := db.Collection(name).DeleteMany(ctx, bson.D{}); err != nil {
+			t.Fatalf("failed to clean %s: %v", name, err)
+		}
+	}
+}
+
+// CleanAll deletes all documents of every collection in the client's database, keeping
+// their indexes and validation rules. Views and system collections are left untouched.
+// The test fails on any error.
+func CleanAll(t testing.TB, client DatabaseProvider) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), fixtureTimeout)
+	defer cancel()
+
+	names, err := client.Database().ListCollectionNames(ctx, bson.M{
+		"type": "collection",
+		"name": bson.M{"$not": bson.M{"$regex": "^system\\."}},
+	})
+	if err != nil {
+		t.Fatalf("failed to list collections: %v", err)
+	}
+
+	CleanCollections(t, client, names...)
+}
@@ -0,0 +1,66 @@
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestClean_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := Shared(t)
+
+	ctx := context.Background()
+	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(container.URI))
+	require.NoError(t, err)
+	defer func() { _ = mongoClient.Disconnect(ctx) }()
+
+	client := testDatabase{db: mongoClient.Database(IsolatedDatabase(t))}
+	users := client.db.Collection("users")
+	orders := client.db.Collection("orders")
+
+	seed := func(t *testing.T) {
+		_, err := users.InsertMany(ctx, []any{bson.M{"name": "Alice"}, bson.M{"name": "Bob"}})
+		require.NoError(t, err)
+		_, err = orders.InsertOne(ctx, bson.M{"total": 10})
+		require.NoError(t, err)
+	}
+	count := func(t *testing.T, coll *mongo.Collection) int64 {
+		n, err := coll.CountDocuments(ctx, bson.M{})
+		require.NoError(t, err)
+		return n
+	}
+
+	_, err = users.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "name", Value: 1}}})
+	require.NoError(t, err)
+
+	t.Run("CleanCollections cleans only the named collections", func(t *testing.T) {
+		seed(t)
+
+		CleanCollections(t, client, "users", "missing")
+
+		assert.Equal(t, int64(0), count(t, users))
+		assert.Equal(t, int64(1), count(t, orders))
+
+		specs, err := users.Indexes().ListSpecifications(ctx)
+		require.NoError(t, err)
+		assert.Len(t, specs, 2)
+	})
+
+	t.Run("CleanAll cleans every collection", func(t *testing.T) {
+		seed(t)
+
+		CleanAll(t, client)
+
+		assert.Equal(t, int64(0), count(t, users))
+		assert.Equal(t, int64(0), count(t, orders))
+	})
+}
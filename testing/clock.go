@@ -0,0 +1,46 @@
+package testing
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a clock for tests that only moves when told to. It implements
+// mongo_kit.Clock; pass it to mongo_kit.WithClock to freeze time for a client.
+// It is safe for concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a FakeClock frozen at now.
+//
+// Example:
+//
+//	clock := testhelpers.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+//	client, err := mongo_kit.New(cfg, mongo_kit.WithClock(clock))
+//	clock.Advance(24 * time.Hour)
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to t.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
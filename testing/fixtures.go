@@ -0,0 +1,178 @@
+package testing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gopkg.in/yaml.v3"
+)
+
+// fixtureTimeout bounds the database work of a single fixture helper call.
+const fixtureTimeout = 30 * time.Second
+
+// DatabaseProvider is implemented by *mongo_kit.Client. The helpers in this package
+// accept it so they can be used with any client without importing mongo_kit.
+type DatabaseProvider interface {
+	Database() *mongo.Database
+}
+
+// LoadFixtures inserts the documents of every .json, .yaml and .yml file in dir into
+// the collection named after the file, e.g. users.json into "users". Each file holds an
+// array of documents; extended JSON values such as {"$oid": "..."} and {"$date": "..."}
+// are decoded to their BSON types, in YAML files too. Field order is kept for JSON files
+// only. The test fails on any error.
+//
+// Example:
+//
+//	// testdata/fixtures/users.json:
+//	// [{"_id": {"$oid": "65a1f0c2e4b0a1b2c3d4e5f6"}, "name": "Alice", "created_at": {"$date": "2024-01-15T00:00:00Z"}}]
+//	testhelpers.LoadFixtures(t, client, "testdata/fixtures")
+func LoadFixtures(t testing.TB, client DatabaseProvider, dir string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read fixtures directory: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), fixtureTimeout)
+	defer cancel()
+
+	db := client.Database()
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".json" && ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		docs, err := readFixtureFile(path)
+		if err != nil {
+			t.Fatalf("failed to read fixture %s: %v", path, err)
+		}
+		if len(docs) == 0 {
+			continue
+		}
+
+		collection := strings.TrimSuffix(entry.Name(), ext)
+		if _, err := db.Collection(collection).InsertMany(ctx, docs); err != nil {
+			t.Fatalf("failed to load fixture %s: %v", path, err)
+		}
+	}
+}
+
+// DumpFixtures writes the documents of collections to dir as relaxed extended JSON,
+// one <collection>.json file per collection, in the format LoadFixtures reads.
+// Relaxed extended JSON writes numbers as plain JSON numbers, so a small int64 is
+// loaded back as an int32.
+// All collections of the database are dumped when none are given. Use it to capture
+// the state produced by a test as fixtures for other tests.
+//
+// Example:
+//
+//	testhelpers.DumpFixtures(t, client, "testdata/fixtures", "users", "orders")
+func DumpFixtures(t testing.TB, client DatabaseProvider, dir string, collections ...string) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), fixtureTimeout)
+	defer cancel()
+
+	db := client.Database()
+	if len(collections) == 0 {
+		names, err := db.ListCollectionNames(ctx, bson.M{"type": "collection"})
+		if err != nil {
+			t.Fatalf("failed to list collections: %v", err)
+		}
+		collections = names
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("failed to create fixtures directory: %v", err)
+	}
+
+	for _, collection := range slices.Sorted(slices.Values(collections)) {
+		data, err := dumpCollection(ctx, db.Collection(collection))
+		if err != nil {
+			t.Fatalf("failed to dump collection %s: %v", collection, err)
+		}
+
+		path := filepath.Join(dir, collection+".json")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("failed to write fixture %s: %v", path, err)
+		}
+	}
+}
+
+// readFixtureFile decodes the array of extended JSON documents in a JSON or YAML file.
+func readFixtureFile(path string) ([]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+		var value any
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		if data, err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+
+	// Extended JSON must be a document at the top level, so wrap the array.
+	wrapped := append(append([]byte(`{"docs":`), data...), '}')
+	var fixture struct {
+		Docs []bson.D `bson:"docs"`
+	}
+	if err := bson.UnmarshalExtJSON(wrapped, false, &fixture); err != nil {
+		return nil, fmt.Errorf("expected an array of documents: %w", err)
+	}
+
+	docs := make([]any, len(fixture.Docs))
+	for i, doc := range fixture.Docs {
+		docs[i] = doc
+	}
+	return docs, nil
+}
+
+// dumpCollection encodes the documents of coll, in _id order, as an indented JSON array.
+func dumpCollection(ctx context.Context, coll *mongo.Collection) ([]byte, error) {
+	cursor, err := coll.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	docs := []json.RawMessage{}
+	for cursor.Next(ctx) {
+		doc, err := bson.MarshalExtJSON(cursor.Current, false, false)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(docs); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
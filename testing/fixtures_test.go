@@ -0,0 +1,96 @@
+package testing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestReadFixtureFile(t *testing.T) {
+	t.Run("json with extended values", func(t *testing.T) {
+		docs, err := readFixtureFile("testdata/fixtures/users.json")
+		require.NoError(t, err)
+		require.Len(t, docs, 2)
+
+		alice := docs[0].(bson.D)
+		id, _ := primitive.ObjectIDFromHex("65a1f0c2e4b0a1b2c3d4e5f6")
+		assert.Equal(t, bson.E{Key: "_id", Value: id}, alice[0])
+		assert.Equal(t, bson.E{Key: "name", Value: "Alice"}, alice[1])
+		assert.Equal(t, primitive.NewDateTimeFromTime(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)), alice.Map()["created_at"])
+		assert.Equal(t, int64(41), docs[1].(bson.D).Map()["age"])
+	})
+
+	t.Run("yaml with extended values", func(t *testing.T) {
+		docs, err := readFixtureFile("testdata/fixtures/orders.yaml")
+		require.NoError(t, err)
+		require.Len(t, docs, 1)
+
+		order := docs[0].(bson.D).Map()
+		assert.IsType(t, primitive.ObjectID{}, order["user_id"])
+		assert.Equal(t, 99.5, order["total"])
+		assert.Equal(t, primitive.NewDateTimeFromTime(time.Date(2024, 2, 1, 10, 30, 0, 0, time.UTC)), order["placed_at"])
+	})
+
+	t.Run("rejects non-array files", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bad.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"name": "Alice"}`), 0o644))
+
+		_, err := readFixtureFile(path)
+		assert.ErrorContains(t, err, "expected an array of documents")
+	})
+}
+
+// testDatabase implements DatabaseProvider for a driver database.
+type testDatabase struct {
+	db *mongo.Database
+}
+
+func (d testDatabase) Database() *mongo.Database {
+	return d.db
+}
+
+func TestFixtures_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := Shared(t)
+
+	ctx := context.Background()
+	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(container.URI))
+	require.NoError(t, err)
+	defer func() { _ = mongoClient.Disconnect(ctx) }()
+
+	client := testDatabase{db: mongoClient.Database(IsolatedDatabase(t))}
+
+	LoadFixtures(t, client, "testdata/fixtures")
+
+	count, err := client.db.Collection("users").CountDocuments(ctx, bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	count, err = client.db.Collection("orders").CountDocuments(ctx, bson.M{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	dir := t.TempDir()
+	DumpFixtures(t, client, dir)
+
+	dumped, err := readFixtureFile(filepath.Join(dir, "users.json"))
+	require.NoError(t, err)
+	original, err := readFixtureFile("testdata/fixtures/users.json")
+	require.NoError(t, err)
+	require.Len(t, dumped, 2)
+	assert.Equal(t, original[0], dumped[0])
+	assert.Equal(t, "Bob", dumped[1].(bson.D).Map()["name"])
+	assert.FileExists(t, filepath.Join(dir, "orders.json"))
+}
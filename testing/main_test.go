@@ -0,0 +1,11 @@
+package testing
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain runs the integration tests of the package on one shared MongoDB container.
+func TestMain(m *testing.M) {
+	os.Exit(SharedContainer(m))
+}
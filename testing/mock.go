@@ -0,0 +1,244 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/address"
+	"go.mongodb.org/mongo-driver/mongo/description"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
+)
+
+// Mock Deployment
+//
+// A mock deployment answers every command of a driver client with the next queued
+// response instead of sending it to a server, so unit tests can simulate results and
+// server errors without Docker. It is modeled on the mock deployment of the driver's
+// own test suite.
+
+const mockAddress = address.Address("127.0.0.1:27017")
+
+var mockSessionTimeoutMinutes int64 = 30
+
+// mockDescription is the server description reported by mock connections.
+var mockDescription = description.Server{
+	CanonicalAddr:            mockAddress,
+	MaxDocumentSize:          16777216,
+	MaxMessageSize:           48000000,
+	MaxBatchCount:            100000,
+	SessionTimeoutMinutes:    uint32(mockSessionTimeoutMinutes),
+	SessionTimeoutMinutesPtr: &mockSessionTimeoutMinutes,
+	Kind:                     description.RSPrimary,
+	WireVersion:              &description.VersionRange{Max: topology.SupportedWireVersions.Max},
+}
+
+// MockClient is a driver client backed by a mock deployment. Queue the server replies
+// of a test with AddResponses, in the order the commands are sent.
+// Wrap Client with mongo_kit.NewFromClient to test code that uses a mongo_kit client.
+type MockClient struct {
+	Client *mongo.Client
+
+	deployment *mockDeployment
+}
+
+// NewMockClient creates a driver client whose commands are answered by queued responses.
+// The client is disconnected when the test finishes. A command sent when no response is
+// left fails with an error.
+//
+// Example:
+//
+//	mock := testhelpers.NewMockClient(t)
+//	mock.AddResponses(testhelpers.WriteErrorResponse(0, 11000, "duplicate key"))
+//
+//	client, err := mongo_kit.NewFromClient(mock.Client, mongo_kit.DefaultConfig(),
+//	    mongo_kit.WithDatabase("testdb"),
+//	)
+//	_, err = mongo_kit.NewRepository[User](client, "users").Create(ctx, user)
+//	// errors.As(err, &dupErr) with dupErr *mongo_kit.DuplicateKeyError
+func NewMockClient(t testing.TB) *MockClient {
+	t.Helper()
+
+	deployment := &mockDeployment{}
+	client, err := mongo.Connect(context.Background(), &options.ClientOptions{Deployment: deployment})
+	if err != nil {
+		t.Fatalf("failed to create mock client: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Disconnect(context.Background()) })
+
+	return &MockClient{Client: client, deployment: deployment}
+}
+
+// AddResponses queues server replies, returned one per command in order.
+func (m *MockClient) AddResponses(responses ...bson.D) {
+	m.deployment.conn.add(responses...)
+}
+
+// ClearResponses discards the queued replies.
+func (m *MockClient) ClearResponses() {
+	m.deployment.conn.clear()
+}
+
+// SuccessResponse returns the reply of a successful command with the given fields,
+// e.g. SuccessResponse(bson.E{Key: "n", Value: 1}) for a write that affected one document.
+func SuccessResponse(elems ...bson.E) bson.D {
+	return append(bson.D{{Key: "ok", Value: 1}}, elems...)
+}
+
+// CursorResponse returns the reply of a find or aggregate command that returns docs in
+// a single batch. ns is the namespace, e.g. "testdb.users".
+func CursorResponse(ns string, docs ...any) bson.D {
+	batch := bson.A{}
+	batch = append(batch, docs...)
+
+	return SuccessResponse(bson.E{Key: "cursor", Value: bson.D{
+		{Key: "id", Value: int64(0)},
+		{Key: "ns", Value: ns},
+		{Key: "firstBatch", Value: batch},
+	}})
+}
+
+// CommandErrorResponse returns the reply of a command that failed with the given server
+// error, e.g. CommandErrorResponse(50, "MaxTimeMSExpired", "operation exceeded time limit").
+func CommandErrorResponse(code int32, name, message string) bson.D {
+	return bson.D{
+		{Key: "ok", Value: 0},
+		{Key: "code", Value: code},
+		{Key: "codeName", Value: name},
+		{Key: "errmsg", Value: message},
+	}
+}
+
+// WriteErrorResponse returns the reply of a write command in which the write at index
+// failed, e.g. WriteErrorResponse(0, 11000, "duplicate key") for a duplicate key.
+func WriteErrorResponse(index int, code int, message string) bson.D {
+	return SuccessResponse(bson.E{Key: "writeErrors", Value: bson.A{
+		bson.D{
+			{Key: "index", Value: index},
+			{Key: "code", Value: code},
+			{Key: "errmsg", Value: message},
+		},
+	}})
+}
+
+// mockConnection implements driver.Connection and answers with queued responses.
+type mockConnection struct {
+	mu        sync.Mutex
+	responses []bson.D
+}
+
+var _ driver.Connection = (*mockConnection)(nil)
+
+func (c *mockConnection) add(responses ...bson.D) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses = append(c.responses, responses...)
+}
+
+func (c *mockConnection) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses = nil
+}
+
+func (c *mockConnection) WriteWireMessage(context.Context, []byte) error { return nil }
+
+// ReadWireMessage returns the next queued response as an OP_MSG reply.
+func (c *mockConnection) ReadWireMessage(context.Context) ([]byte, error) {
+	c.mu.Lock()
+	if len(c.responses) == 0 {
+		c.mu.Unlock()
+		return nil, errors.New("mock: no responses remaining")
+	}
+	next := c.responses[0]
+	c.responses = c.responses[1:]
+	c.mu.Unlock()
+
+	doc, err := bson.Marshal(next)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, dst := wiremessage.AppendHeaderStart(nil, wiremessage.NextRequestID(), 0, wiremessage.OpMsg)
+	dst = wiremessage.AppendMsgFlags(dst, 0)
+	dst = wiremessage.AppendMsgSectionType(dst, wiremessage.SingleDocument)
+	dst = append(dst, doc...)
+	return bsoncore.UpdateLength(dst, idx, int32(len(dst[idx:]))), nil
+}
+
+func (c *mockConnection) Description() description.Server { return mockDescription }
+func (c *mockConnection) Close() error                    { return nil }
+func (c *mockConnection) ID() string                      { return "mock" }
+func (c *mockConnection) ServerConnectionID() *int64      { return nil }
+func (c *mockConnection) DriverConnectionID() uint64      { return 0 }
+func (c *mockConnection) Address() address.Address        { return mockAddress }
+func (c *mockConnection) Stale() bool                     { return false }
+func (c *mockConnection) OIDCTokenGenID() uint64          { return 0 }
+func (c *mockConnection) SetOIDCTokenGenID(uint64)        {}
+
+// mockDeployment implements driver.Deployment with a single mock connection.
+type mockDeployment struct {
+	conn    mockConnection
+	updates chan description.Topology
+}
+
+var (
+	_ driver.Deployment   = (*mockDeployment)(nil)
+	_ driver.Server       = (*mockDeployment)(nil)
+	_ driver.Connector    = (*mockDeployment)(nil)
+	_ driver.Disconnector = (*mockDeployment)(nil)
+	_ driver.Subscriber   = (*mockDeployment)(nil)
+)
+
+func (d *mockDeployment) SelectServer(context.Context, description.ServerSelector) (driver.Server, error) {
+	return d, nil
+}
+
+func (d *mockDeployment) Kind() description.TopologyKind { return description.Single }
+
+func (d *mockDeployment) Connection(context.Context) (driver.Connection, error) {
+	return &d.conn, nil
+}
+
+func (d *mockDeployment) RTTMonitor() driver.RTTMonitor { return mockRTTMonitor{} }
+
+func (d *mockDeployment) Connect() error { return nil }
+
+func (d *mockDeployment) Disconnect(context.Context) error {
+	if d.updates != nil {
+		close(d.updates)
+		d.updates = nil
+	}
+	return nil
+}
+
+// Subscribe reports a topology that supports sessions, so sessions and transactions
+// can be started against the mock.
+func (d *mockDeployment) Subscribe() (*driver.Subscription, error) {
+	if d.updates == nil {
+		d.updates = make(chan description.Topology, 1)
+		d.updates <- description.Topology{
+			SessionTimeoutMinutes:    uint32(mockSessionTimeoutMinutes),
+			SessionTimeoutMinutesPtr: &mockSessionTimeoutMinutes,
+		}
+	}
+	return &driver.Subscription{Updates: d.updates}, nil
+}
+
+func (d *mockDeployment) Unsubscribe(*driver.Subscription) error { return nil }
+
+// mockRTTMonitor reports a zero round-trip time.
+type mockRTTMonitor struct{}
+
+func (mockRTTMonitor) EWMA() time.Duration { return 0 }
+func (mockRTTMonitor) Min() time.Duration  { return 0 }
+func (mockRTTMonitor) P90() time.Duration  { return 0 }
+func (mockRTTMonitor) Stats() string       { return "" }
@@ -0,0 +1,56 @@
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMockClient(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockClient(t)
+	users := mock.Client.Database("testdb").Collection("users")
+
+	t.Run("returns queued documents", func(t *testing.T) {
+		mock.AddResponses(CursorResponse("testdb.users", bson.D{{Key: "name", Value: "Alice"}}))
+
+		var doc bson.M
+		require.NoError(t, users.FindOne(ctx, bson.M{}).Decode(&doc))
+		assert.Equal(t, "Alice", doc["name"])
+	})
+
+	t.Run("returns write results", func(t *testing.T) {
+		mock.AddResponses(SuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		_, err := users.InsertOne(ctx, bson.M{"name": "Bob"})
+		require.NoError(t, err)
+	})
+
+	t.Run("returns write errors", func(t *testing.T) {
+		mock.AddResponses(WriteErrorResponse(0, 11000, "duplicate key"))
+
+		_, err := users.InsertOne(ctx, bson.M{"name": "Bob"})
+		assert.True(t, mongo.IsDuplicateKeyError(err))
+	})
+
+	t.Run("returns command errors", func(t *testing.T) {
+		mock.AddResponses(CommandErrorResponse(50, "MaxTimeMSExpired", "operation exceeded time limit"))
+
+		_, err := users.CountDocuments(ctx, bson.M{})
+		var cmdErr mongo.CommandError
+		require.ErrorAs(t, err, &cmdErr)
+		assert.Equal(t, int32(50), cmdErr.Code)
+	})
+
+	t.Run("fails without responses", func(t *testing.T) {
+		mock.AddResponses(SuccessResponse())
+		mock.ClearResponses()
+
+		_, err := users.InsertOne(ctx, bson.M{"name": "Carol"})
+		assert.Error(t, err)
+	})
+}
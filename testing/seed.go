@@ -0,0 +1,108 @@
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Seeder builds the documents to insert for a test with a fluent API.
+// Create it with Seed and insert the documents with Insert.
+type Seeder struct {
+	t       testing.TB
+	client  DatabaseProvider
+	order   []string
+	docs    map[string][]any
+	current string
+}
+
+// Seed starts a Seeder for client. Documents inserted by the Seeder are deleted
+// when the test finishes.
+//
+// Example:
+//
+//	ids := testhelpers.Seed(t, client).
+//	    Collection("users").Docs(alice, bob).
+//	    Collection("orders").FromFactory(10, func(i int) any {
+//	        return Order{UserID: alice.ID, Total: float64(i * 10)}
+//	    }).
+//	    Insert()
+func Seed(t testing.TB, client DatabaseProvider) *Seeder {
+	return &Seeder{t: t, client: client, docs: make(map[string][]any)}
+}
+
+// Collection selects the collection that following Docs and FromFactory calls add to.
+func (s *Seeder) Collection(name string) *Seeder {
+	if _, ok := s.docs[name]; !ok {
+		s.order = append(s.order, name)
+		s.docs[name] = nil
+	}
+	s.current = name
+	return s
+}
+
+// Docs adds documents to the current collection.
+func (s *Seeder) Docs(docs ...any) *Seeder {
+	s.t.Helper()
+	s.requireCollection()
+	s.docs[s.current] = append(s.docs[s.current], docs...)
+	return s
+}
+
+// FromFactory adds n documents built by factory to the current collection.
+// factory receives the index of the document, from 0 to n-1.
+func (s *Seeder) FromFactory(n int, factory func(i int) any) *Seeder {
+	s.t.Helper()
+	s.requireCollection()
+	for i := range n {
+		s.docs[s.current] = append(s.docs[s.current], factory(i))
+	}
+	return s
+}
+
+// Insert inserts the documents, collection by collection in the order they were
+// selected, and returns the inserted _id values by collection. It registers a cleanup
+// with t.Cleanup that deletes exactly these documents. The test fails on any error.
+func (s *Seeder) Insert() map[string][]any {
+	s.t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), fixtureTimeout)
+	defer cancel()
+
+	db := s.client.Database()
+	ids := make(map[string][]any, len(s.order))
+	s.t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), fixtureTimeout)
+		defer cancel()
+
+		for collection, collectionIDs := range ids {
+			if _, err := db.Collection(collection).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": collectionIDs}}); err != nil {
+				s.t.Logf("failed to clean up seeded %s: %v", collection, err)
+			}
+		}
+	})
+
+	for _, collection := range s.order {
+		docs := s.docs[collection]
+		if len(docs) == 0 {
+			continue
+		}
+
+		result, err := db.Collection(collection).InsertMany(ctx, docs)
+		if err != nil {
+			s.t.Fatalf("failed to seed %s: %v", collection, err)
+		}
+		ids[collection] = result.InsertedIDs
+	}
+
+	return ids
+}
+
+// requireCollection fails the test if no collection has been selected.
+func (s *Seeder) requireCollection() {
+	s.t.Helper()
+	if s.current == "" {
+		s.t.Fatalf("seed: call Collection before adding documents")
+	}
+}
@@ -0,0 +1,65 @@
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestSeeder_Builder(t *testing.T) {
+	s := Seed(t, nil).
+		Collection("users").Docs(bson.M{"name": "Alice"}, bson.M{"name": "Bob"}).
+		Collection("orders").FromFactory(3, func(i int) any { return bson.M{"n": i} }).
+		Collection("users").Docs(bson.M{"name": "Carol"})
+
+	assert.Equal(t, []string{"users", "orders"}, s.order)
+	assert.Len(t, s.docs["users"], 3)
+	assert.Equal(t, []any{bson.M{"n": 0}, bson.M{"n": 1}, bson.M{"n": 2}}, s.docs["orders"])
+}
+
+func TestSeeder_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := Shared(t)
+
+	ctx := context.Background()
+	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(container.URI))
+	require.NoError(t, err)
+	defer func() { _ = mongoClient.Disconnect(ctx) }()
+
+	client := testDatabase{db: mongoClient.Database(IsolatedDatabase(t))}
+	users := client.db.Collection("users")
+	_, err = users.InsertOne(ctx, bson.M{"name": "Existing"})
+	require.NoError(t, err)
+
+	t.Run("inserts documents", func(t *testing.T) {
+		ids := Seed(t, client).
+			Collection("users").Docs(bson.M{"name": "Alice"}, bson.M{"name": "Bob"}).
+			Collection("orders").FromFactory(5, func(i int) any { return bson.M{"n": i} }).
+			Insert()
+
+		assert.Len(t, ids["users"], 2)
+		assert.Len(t, ids["orders"], 5)
+
+		count, err := users.CountDocuments(ctx, bson.M{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	t.Run("cleanup removes only seeded documents", func(t *testing.T) {
+		count, err := users.CountDocuments(ctx, bson.M{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+
+		count, err = client.db.Collection("orders").CountDocuments(ctx, bson.M{})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+}
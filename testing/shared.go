@@ -0,0 +1,119 @@
+package testing
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/testcontainers/testcontainers-go"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// shared is the container shared by the tests of a package through SharedContainer.
+var shared struct {
+	enabled   bool
+	once      sync.Once
+	container *MongoContainer
+	client    *mongo.Client // Used to drop isolated databases
+	err       error
+	databases atomic.Int64
+}
+
+// SharedContainer runs the tests of a package with one MongoDB container shared by all
+// of them, instead of one container per test, and returns the exit code for os.Exit.
+// The container is started by the first test that calls Shared, so packages whose
+// integration tests are skipped with -short never start it, and it is terminated
+// after all tests have run.
+//
+// Example:
+//
+//	func TestMain(m *testing.M) {
+//	    os.Exit(testhelpers.SharedContainer(m))
+//	}
+func SharedContainer(m *testing.M) int {
+	flag.Parse()
+	shared.enabled = true
+
+	code := m.Run()
+
+	if shared.client != nil {
+		_ = shared.client.Disconnect(context.Background())
+	}
+	if shared.container != nil {
+		if err := testcontainers.TerminateContainer(shared.container.MongoDBContainer); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to terminate MongoDB container: %v\n", err)
+		}
+	}
+
+	return code
+}
+
+// Shared returns the container started by SharedContainer, starting it on first use.
+// It skips the test in short mode and fails it if TestMain does not call SharedContainer.
+// Use IsolatedDatabase to keep the data of tests apart.
+//
+// Example:
+//
+//	container := testhelpers.Shared(t)
+//	client, err := mongokit.New(mongokit.DefaultConfig(),
+//	    mongokit.WithURI(container.URI),
+//	    mongokit.WithDatabase(testhelpers.IsolatedDatabase(t)),
+//	)
+func Shared(t testing.TB) *MongoContainer {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	if !shared.enabled {
+		t.Fatal("testhelpers.Shared requires TestMain to call testhelpers.SharedContainer")
+	}
+
+	shared.once.Do(func() {
+		shared.container, shared.err = startMongoContainer()
+		if shared.err == nil {
+			shared.client, shared.err = mongo.Connect(context.Background(), options.Client().ApplyURI(shared.container.URI))
+		}
+	})
+	if shared.err != nil {
+		t.Fatal(shared.err)
+	}
+
+	return shared.container
+}
+
+// IsolatedDatabase returns the name of a database on the shared container that no other
+// test uses, and drops the database when the test finishes.
+func IsolatedDatabase(t testing.TB) string {
+	t.Helper()
+
+	Shared(t)
+	name := isolatedDatabaseName(t.Name(), shared.databases.Add(1))
+
+	t.Cleanup(func() {
+		if err := shared.client.Database(name).Drop(context.Background()); err != nil {
+			t.Logf("failed to drop database %s: %v", name, err)
+		}
+	})
+
+	return name
+}
+
+// invalidDatabaseChars matches characters that are not safe in database names.
+var invalidDatabaseChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// isolatedDatabaseName derives a valid database name from a test name and a sequence
+// number that makes it unique. Names are kept well below the 64 byte limit.
+func isolatedDatabaseName(testName string, seq int64) string {
+	name := invalidDatabaseChars.ReplaceAllString(testName, "_")
+	if len(name) > 40 {
+		name = name[:40]
+	}
+	return fmt.Sprintf("test_%s_%d", name, seq)
+}
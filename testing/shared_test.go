@@ -0,0 +1,17 @@
+package testing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsolatedDatabaseName(t *testing.T) {
+	assert.Equal(t, "test_TestUsers_create_user_1", isolatedDatabaseName("TestUsers/create user", 1))
+	assert.Equal(t, "test_TestA_b_c_12", isolatedDatabaseName("TestA/b.c", 12))
+
+	long := isolatedDatabaseName("Test"+strings.Repeat("x", 100), 7)
+	assert.Equal(t, "test_Test"+strings.Repeat("x", 36)+"_7", long)
+	assert.Less(t, len(long), 64)
+}
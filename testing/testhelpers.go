@@ -2,6 +2,7 @@ package testing
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -14,20 +15,30 @@ type MongoContainer struct {
 	URI string
 }
 
-func SetupMongoContainer(t *testing.T) *MongoContainer {
+func SetupMongoContainer(t testing.TB) *MongoContainer {
 	t.Helper()
 
+	container, err := startMongoContainer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return container
+}
+
+// startMongoContainer starts a single-node replica set container and resolves its URI.
+func startMongoContainer() (*MongoContainer, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
 	defer cancel()
 
 	container, err := mongodb.Run(ctx, "mongo:7", mongodb.WithReplicaSet("rs0"))
 	if err != nil {
-		t.Fatalf("failed to start MongoDB container: %v", err)
+		return nil, fmt.Errorf("failed to start MongoDB container: %w", err)
 	}
 
 	uri, err := container.ConnectionString(ctx)
 	if err != nil {
-		t.Fatalf("failed to get MongoDB connection string: %v", err)
+		_ = testcontainers.TerminateContainer(container)
+		return nil, fmt.Errorf("failed to get MongoDB connection string: %w", err)
 	}
 
 	// Add directConnection for replica set to work from host
@@ -36,10 +47,10 @@ func SetupMongoContainer(t *testing.T) *MongoContainer {
 	return &MongoContainer{
 		MongoDBContainer: container,
 		URI:              uri,
-	}
+	}, nil
 }
 
-func (c *MongoContainer) Teardown(t *testing.T) {
+func (c *MongoContainer) Teardown(t testing.TB) {
 	t.Helper()
 
 	if err := testcontainers.TerminateContainer(c.MongoDBContainer); err != nil {
@@ -0,0 +1,110 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Full-Text Search
+//
+// This file provides text search on repositories, ranked by relevance, on the text
+// index of a collection.
+//
+// See docs/repository.md for detailed usage guide and examples.
+
+// scoreField is the field text searches store the relevance score of each result in.
+const scoreField = "_score"
+
+// TextSearchOptions configures SearchText.
+type TextSearchOptions struct {
+	Fields   []string // Fields of the text index to create if needed (default: use the existing index)
+	Language string   // Language for stemming and stop words (default: the index language)
+	Filter   any      // Additional query filter (default: none)
+	Skip     int64    // Results to skip, for pagination (default: 0)
+	Limit    int64    // Maximum number of results (default: all)
+}
+
+// TextResult is a document returned by SearchText with its relevance score.
+type TextResult[T any] struct {
+	Document T       // The matching document
+	Score    float64 // Relevance score; higher is more relevant
+}
+
+// SearchText runs a $text search for query and returns the matching documents, most
+// relevant first, with their scores. query follows the $text syntax: words match any
+// of them, "quoted phrases" must appear and -word excludes documents.
+//
+// A collection has at most one text index. When opts.Fields is set, a text index on
+// those fields is created on the first search of the repository; otherwise the
+// collection must already have one.
+//
+// Example:
+//
+//	results, err := repo.SearchText(ctx, "wireless headphones", mongo_kit.TextSearchOptions{
+//	    Fields: []string{"name", "description"},
+//	    Filter: bson.M{"in_stock": true},
+//	    Skip:   20,
+//	    Limit:  20,
+//	})
+//	for _, r := range results {
+//	    fmt.Printf("%.2f %s\n", r.Score, r.Document.Name)
+//	}
+func (r *Repository[T]) SearchText(ctx context.Context, query string, opts TextSearchOptions) ([]TextResult[T], error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	if query == "" {
+		return nil, newOperationError("search text", errors.New("query cannot be empty"))
+	}
+	if opts.Skip < 0 || opts.Limit < 0 {
+		return nil, newOperationError("search text", errors.New("Skip and Limit cannot be negative"))
+	}
+
+	if len(opts.Fields) > 0 {
+		ib := NewIndexBuilder()
+		for _, field := range opts.Fields {
+			ib.Text(field)
+		}
+		if err := r.textIndex.ensure(ctx, r.client, r.collection, ib.Build()); err != nil {
+			return nil, err
+		}
+	}
+
+	text := bson.D{{Key: "$search", Value: query}}
+	if opts.Language != "" {
+		text = append(text, bson.E{Key: "$language", Value: opts.Language})
+	}
+	filter := bson.D{{Key: "$text", Value: text}}
+	if opts.Filter != nil {
+		filter = bson.D{{Key: "$and", Value: bson.A{filter, opts.Filter}}}
+	}
+
+	score := bson.D{{Key: "$meta", Value: "textScore"}}
+	findOpts := options.Find().
+		SetProjection(bson.D{{Key: scoreField, Value: score}}).
+		SetSort(bson.D{{Key: scoreField, Value: score}})
+	if opts.Skip > 0 {
+		findOpts.SetSkip(opts.Skip)
+	}
+	if opts.Limit > 0 {
+		findOpts.SetLimit(opts.Limit)
+	}
+
+	var docs []bson.Raw
+	if err := r.client.find(ctx, r.collection, filter, &docs, findOpts); err != nil {
+		return nil, err
+	}
+
+	documents, scores, err := r.decodeScored(ctx, "search text", docs, scoreField)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]TextResult[T], len(docs))
+	for i := range docs {
+		results[i] = TextResult[T]{Document: documents[i], Score: scores[i]}
+	}
+	return results, nil
+}
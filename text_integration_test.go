@@ -0,0 +1,58 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestRepository_SearchText_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := NewRepository[article](client, "articles")
+	_, err = repo.CreateMany(ctx, []article{
+		{Title: "Go generics", Body: "Generics in Go make generic code type safe"},
+		{Title: "Go modules", Body: "Dependency management"},
+		{Title: "Rust ownership", Body: "Borrowing and lifetimes"},
+	})
+	require.NoError(t, err)
+
+	opts := TextSearchOptions{Fields: []string{"title", "body"}}
+	results, err := repo.SearchText(ctx, "generics", opts)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Go generics", results[0].Document.Title)
+	assert.Greater(t, results[0].Score, 0.0)
+
+	results, err = repo.SearchText(ctx, "go", opts)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "Go generics", results[0].Document.Title, "more mentions rank first")
+	assert.GreaterOrEqual(t, results[0].Score, results[1].Score)
+
+	opts.Skip, opts.Limit = 1, 1
+	results, err = repo.SearchText(ctx, "go", opts)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Go modules", results[0].Document.Title)
+
+	results, err = repo.SearchText(ctx, "go", TextSearchOptions{Filter: bson.M{"title": "Go modules"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Go modules", results[0].Document.Title)
+}
@@ -0,0 +1,57 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type article struct {
+	Title string `bson:"title"`
+	Body  string `bson:"body"`
+}
+
+func TestRepository_SearchText(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	repo := NewRepository[article](client, "articles")
+	opts := TextSearchOptions{Fields: []string{"title", "body"}, Limit: 10}
+
+	mock.AddResponses(
+		testhelpers.SuccessResponse(), // createIndexes
+		testhelpers.CursorResponse("testdb.articles",
+			bson.D{{Key: "title", Value: "Go generics"}, {Key: scoreField, Value: 1.5}},
+			bson.D{{Key: "title", Value: "Go modules"}, {Key: scoreField, Value: 0.75}},
+		),
+	)
+	results, err := repo.SearchText(ctx, "go", opts)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "Go generics", results[0].Document.Title)
+	assert.Equal(t, 1.5, results[0].Score)
+	assert.Equal(t, 0.75, results[1].Score)
+
+	// The text index is created on the first search only.
+	mock.AddResponses(testhelpers.CursorResponse("testdb.articles"))
+	results, err = repo.SearchText(ctx, "rust", opts)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestRepository_SearchText_InvalidArguments(t *testing.T) {
+	repo := NewRepository[article](&Client{}, "articles")
+	ctx := context.Background()
+
+	_, err := repo.SearchText(ctx, "", TextSearchOptions{})
+	assert.Error(t, err)
+	_, err = repo.SearchText(ctx, "go", TextSearchOptions{Skip: -1})
+	assert.Error(t, err)
+}
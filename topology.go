@@ -0,0 +1,191 @@
+package mongo_kit
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/description"
+)
+
+// Topology Events
+//
+// This file turns the driver's server discovery and monitoring (SDAM) events into
+// TopologyEvents, so applications can log failovers and pause heavy jobs during
+// replica set elections.
+//
+// See docs/operations.md for detailed usage guide and examples.
+
+// topologyEventBuffer is the number of events buffered per subscriber. Events are
+// dropped for subscribers whose buffer is full, so a slow reader never blocks the
+// driver's monitoring.
+const topologyEventBuffer = 64
+
+// TopologyEventType is the kind of a TopologyEvent.
+type TopologyEventType string
+
+const (
+	TopologyPrimaryElected     TopologyEventType = "primary_elected"      // A server became primary
+	TopologyPrimaryStepDown    TopologyEventType = "primary_step_down"    // The primary stepped down or became unreachable
+	TopologyNoPrimary          TopologyEventType = "no_primary"           // The replica set has no primary, e.g. during an election
+	TopologyServerStateChanged TopologyEventType = "server_state_changed" // A server changed state, e.g. from secondary to unknown
+	TopologyServerAdded        TopologyEventType = "server_added"         // A server joined the deployment
+	TopologyServerRemoved      TopologyEventType = "server_removed"       // A server left the deployment
+)
+
+// TopologyEvent is a change of the deployment's topology.
+type TopologyEvent struct {
+	Type          TopologyEventType // Kind of change
+	Address       string            // Address of the server, empty for TopologyNoPrimary
+	PreviousState string            // Previous state of the server, e.g. "RSPrimary" (empty when not applicable)
+	State         string            // New state of the server, e.g. "RSSecondary" or "Unknown" (empty when not applicable)
+	Time          time.Time         // When the change was observed
+}
+
+// topologyMonitor fans topology events out to subscribers.
+type topologyMonitor struct {
+	mu          sync.Mutex
+	subscribers []chan TopologyEvent
+	closed      bool
+}
+
+// subscribe returns a new channel receiving events, closed when the monitor closes.
+func (m *topologyMonitor) subscribe() <-chan TopologyEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ch := make(chan TopologyEvent, topologyEventBuffer)
+	if m.closed {
+		close(ch)
+		return ch
+	}
+	m.subscribers = append(m.subscribers, ch)
+	return ch
+}
+
+// publish sends event to every subscriber with room in its buffer.
+func (m *topologyMonitor) publish(event TopologyEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return
+	}
+	for _, ch := range m.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// close closes the channels of all subscribers.
+func (m *topologyMonitor) close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return
+	}
+	m.closed = true
+	for _, ch := range m.subscribers {
+		close(ch)
+	}
+	m.subscribers = nil
+}
+
+// serverMonitor returns a driver ServerMonitor publishing the events of a driver
+// client, timestamped with now. Callbacks of next, if any, keep being called.
+func (m *topologyMonitor) serverMonitor(now func() time.Time, next *event.ServerMonitor) *event.ServerMonitor {
+	monitor := &event.ServerMonitor{}
+	if next != nil {
+		*monitor = *next
+	}
+
+	monitor.ServerDescriptionChanged = func(e *event.ServerDescriptionChangedEvent) {
+		if next != nil && next.ServerDescriptionChanged != nil {
+			next.ServerDescriptionChanged(e)
+		}
+		previous, current := e.PreviousDescription.Kind, e.NewDescription.Kind
+		if previous == current {
+			return
+		}
+		eventType := TopologyServerStateChanged
+		switch {
+		case current == description.RSPrimary:
+			eventType = TopologyPrimaryElected
+		case previous == description.RSPrimary:
+			eventType = TopologyPrimaryStepDown
+		}
+		m.publish(TopologyEvent{
+			Type:          eventType,
+			Address:       e.Address.String(),
+			PreviousState: previous.String(),
+			State:         current.String(),
+			Time:          now(),
+		})
+	}
+
+	monitor.TopologyDescriptionChanged = func(e *event.TopologyDescriptionChangedEvent) {
+		if next != nil && next.TopologyDescriptionChanged != nil {
+			next.TopologyDescriptionChanged(e)
+		}
+		previous, current := e.PreviousDescription, e.NewDescription
+		for _, server := range current.Servers {
+			if !containsServer(previous.Servers, server) {
+				m.publish(TopologyEvent{Type: TopologyServerAdded, Address: server.Addr.String(), Time: now()})
+			}
+		}
+		for _, server := range previous.Servers {
+			if !containsServer(current.Servers, server) {
+				m.publish(TopologyEvent{Type: TopologyServerRemoved, Address: server.Addr.String(), Time: now()})
+			}
+		}
+		if current.Kind == description.ReplicaSetNoPrimary && previous.Kind != description.ReplicaSetNoPrimary {
+			m.publish(TopologyEvent{Type: TopologyNoPrimary, Time: now()})
+		}
+	}
+
+	return monitor
+}
+
+// containsServer reports whether servers has a server with the address of server.
+func containsServer(servers []description.Server, server description.Server) bool {
+	return slices.ContainsFunc(servers, func(s description.Server) bool { return s.Addr == server.Addr })
+}
+
+// TopologyEvents returns a channel receiving the topology changes of the deployment:
+// primary elections and step-downs, replica sets without a primary, server state
+// changes and servers joining or leaving. Each call returns a new subscription; the
+// channel is closed when the client is closed.
+//
+// Events are buffered and dropped when the reader falls behind, so monitoring never
+// blocks the driver. Subscribers only see changes after they subscribe; the initial
+// discovery made by New has already happened. Clients created with NewFromClient
+// receive no events until ApplyConfig or Reconnect connects a new driver client.
+//
+// Example:
+//
+//	go func() {
+//	    for e := range client.TopologyEvents() {
+//	        switch e.Type {
+//	        case mongo_kit.TopologyNoPrimary:
+//	            jobs.Pause()
+//	        case mongo_kit.TopologyPrimaryElected:
+//	            log.Printf("new primary %s", e.Address)
+//	            jobs.Resume()
+//	        }
+//	    }
+//	}()
+func (c *Client) TopologyEvents() <-chan TopologyEvent {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.topology == nil || c.closed {
+		ch := make(chan TopologyEvent)
+		close(ch)
+		return ch
+	}
+	return c.topology.subscribe()
+}
@@ -0,0 +1,124 @@
+package mongo_kit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/address"
+	"go.mongodb.org/mongo-driver/mongo/description"
+)
+
+func serverChanged(addr string, previous, current description.ServerKind) *event.ServerDescriptionChangedEvent {
+	return &event.ServerDescriptionChangedEvent{
+		Address:             address.Address(addr),
+		PreviousDescription: description.Server{Addr: address.Address(addr), Kind: previous},
+		NewDescription:      description.Server{Addr: address.Address(addr), Kind: current},
+	}
+}
+
+func servers(addrs ...string) []description.Server {
+	out := make([]description.Server, len(addrs))
+	for i, addr := range addrs {
+		out[i] = description.Server{Addr: address.Address(addr)}
+	}
+	return out
+}
+
+func receive(t *testing.T, ch <-chan TopologyEvent) TopologyEvent {
+	t.Helper()
+	select {
+	case e := <-ch:
+		return e
+	default:
+		t.Fatal("no topology event")
+		return TopologyEvent{}
+	}
+}
+
+func TestTopologyMonitor_ServerDescriptionChanged(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	m := &topologyMonitor{}
+	events := m.subscribe()
+	monitor := m.serverMonitor(func() time.Time { return now }, nil)
+
+	monitor.ServerDescriptionChanged(serverChanged("a:27017", description.RSPrimary, description.RSSecondary))
+	assert.Equal(t, TopologyEvent{
+		Type: TopologyPrimaryStepDown, Address: "a:27017", PreviousState: "RSPrimary", State: "RSSecondary", Time: now,
+	}, receive(t, events))
+
+	monitor.ServerDescriptionChanged(serverChanged("b:27017", description.RSSecondary, description.RSPrimary))
+	e := receive(t, events)
+	assert.Equal(t, TopologyPrimaryElected, e.Type)
+	assert.Equal(t, "b:27017", e.Address)
+
+	monitor.ServerDescriptionChanged(serverChanged("c:27017", description.RSSecondary, description.Unknown))
+	e = receive(t, events)
+	assert.Equal(t, TopologyServerStateChanged, e.Type)
+	assert.Equal(t, "Unknown", e.State)
+
+	// Heartbeats that do not change the state are not reported
+	monitor.ServerDescriptionChanged(serverChanged("c:27017", description.Unknown, description.Unknown))
+	assert.Empty(t, events)
+}
+
+func TestTopologyMonitor_TopologyDescriptionChanged(t *testing.T) {
+	m := &topologyMonitor{}
+	events := m.subscribe()
+	monitor := m.serverMonitor(time.Now, nil)
+
+	monitor.TopologyDescriptionChanged(&event.TopologyDescriptionChangedEvent{
+		PreviousDescription: description.Topology{Kind: description.ReplicaSetWithPrimary, Servers: servers("a:1", "b:1")},
+		NewDescription:      description.Topology{Kind: description.ReplicaSetNoPrimary, Servers: servers("b:1", "c:1")},
+	})
+
+	assert.Equal(t, TopologyServerAdded, receive(t, events).Type)
+	removed := receive(t, events)
+	assert.Equal(t, TopologyServerRemoved, removed.Type)
+	assert.Equal(t, "a:1", removed.Address)
+	assert.Equal(t, TopologyNoPrimary, receive(t, events).Type)
+	assert.Empty(t, events)
+}
+
+func TestTopologyMonitor_ChainsMonitor(t *testing.T) {
+	var called bool
+	next := &event.ServerMonitor{
+		ServerDescriptionChanged: func(*event.ServerDescriptionChangedEvent) { called = true },
+		ServerOpening:            func(*event.ServerOpeningEvent) {},
+	}
+	m := &topologyMonitor{}
+	monitor := m.serverMonitor(time.Now, next)
+
+	monitor.ServerDescriptionChanged(serverChanged("a:1", description.Unknown, description.Standalone))
+	assert.True(t, called)
+	assert.NotNil(t, monitor.ServerOpening)
+}
+
+func TestTopologyMonitor_DropsWhenFull(t *testing.T) {
+	m := &topologyMonitor{}
+	events := m.subscribe()
+	for range topologyEventBuffer + 10 {
+		m.publish(TopologyEvent{Type: TopologyNoPrimary})
+	}
+	assert.Len(t, events, topologyEventBuffer)
+}
+
+func TestClient_TopologyEvents(t *testing.T) {
+	client := &Client{topology: &topologyMonitor{}}
+	first := client.TopologyEvents()
+	second := client.TopologyEvents()
+
+	client.topology.publish(TopologyEvent{Type: TopologyNoPrimary})
+	assert.Equal(t, TopologyNoPrimary, receive(t, first).Type)
+	assert.Equal(t, TopologyNoPrimary, receive(t, second).Type)
+
+	client.topology.close()
+	_, ok := <-first
+	assert.False(t, ok, "channels are closed with the client")
+
+	closed := (&Client{closed: true}).TopologyEvents()
+	_, ok = <-closed
+	require.False(t, ok)
+}
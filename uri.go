@@ -0,0 +1,154 @@
+package mongo_kit
+
+import (
+	"net/url"
+	"strings"
+)
+
+// URIBuilder provides a fluent interface for building MongoDB connection URIs.
+// Credentials, database names and option values are escaped, so values containing
+// special characters such as '@', ':' or '/' produce a valid URI.
+//
+// Example:
+//
+//	uri, err := mongo_kit.NewURIBuilder().
+//	    Hosts("db1:27017", "db2:27017").
+//	    Credentials("app", "p@ss:word").
+//	    AuthSource("admin").
+//	    ReplicaSet("rs0").
+//	    TLS(true).
+//	    Build()
+type URIBuilder struct {
+	srv        bool
+	hosts      []string
+	username   string
+	password   string
+	database   string
+	authSource string
+	replicaSet string
+	tls        *bool
+	options    url.Values
+}
+
+// NewURIBuilder creates a new URIBuilder instance.
+func NewURIBuilder() *URIBuilder {
+	return &URIBuilder{
+		options: url.Values{},
+	}
+}
+
+// Hosts adds one or more hosts in "host" or "host:port" form.
+func (b *URIBuilder) Hosts(hosts ...string) *URIBuilder {
+	b.hosts = append(b.hosts, hosts...)
+	return b
+}
+
+// SRV uses the mongodb+srv scheme with the given DNS seed list host.
+// An SRV URI must have exactly one host without a port.
+func (b *URIBuilder) SRV(host string) *URIBuilder {
+	b.srv = true
+	b.hosts = []string{host}
+	return b
+}
+
+// Credentials sets the username and password used to authenticate.
+func (b *URIBuilder) Credentials(username, password string) *URIBuilder {
+	b.username = username
+	b.password = password
+	return b
+}
+
+// Database sets the database in the URI path, used as the default authentication database.
+func (b *URIBuilder) Database(database string) *URIBuilder {
+	b.database = database
+	return b
+}
+
+// AuthSource sets the database used to authenticate the credentials.
+func (b *URIBuilder) AuthSource(database string) *URIBuilder {
+	b.authSource = database
+	return b
+}
+
+// ReplicaSet sets the name of the replica set to connect to.
+func (b *URIBuilder) ReplicaSet(name string) *URIBuilder {
+	b.replicaSet = name
+	return b
+}
+
+// TLS enables or disables TLS for the connection.
+func (b *URIBuilder) TLS(enabled bool) *URIBuilder {
+	b.tls = &enabled
+	return b
+}
+
+// Option sets an arbitrary connection string option, e.g. Option("retryWrites", "false").
+func (b *URIBuilder) Option(key, value string) *URIBuilder {
+	b.options.Set(key, value)
+	return b
+}
+
+// Build returns the connection URI.
+// Returns a ConfigError if no host is set, a host is empty, an SRV host has a port
+// or more than one host, or a password is set without a username.
+func (b *URIBuilder) Build() (string, error) {
+	if len(b.hosts) == 0 {
+		return "", newConfigFieldError("URI", "at least one host is required")
+	}
+	for _, host := range b.hosts {
+		if strings.TrimSpace(host) == "" {
+			return "", newConfigFieldError("URI", "host cannot be empty")
+		}
+	}
+	if b.srv && (len(b.hosts) != 1 || strings.Contains(b.hosts[0], ":")) {
+		return "", newConfigFieldError("URI", "SRV URIs require exactly one host without a port")
+	}
+	if b.username == "" && b.password != "" {
+		return "", newConfigFieldError("URI", "password requires a username")
+	}
+
+	var sb strings.Builder
+	if b.srv {
+		sb.WriteString("mongodb+srv://")
+	} else {
+		sb.WriteString("mongodb://")
+	}
+
+	if b.username != "" {
+		userInfo := url.User(b.username)
+		if b.password != "" {
+			userInfo = url.UserPassword(b.username, b.password)
+		}
+		sb.WriteString(userInfo.String())
+		sb.WriteString("@")
+	}
+
+	sb.WriteString(strings.Join(b.hosts, ","))
+	sb.WriteString("/")
+	sb.WriteString(url.QueryEscape(b.database))
+
+	query := url.Values{}
+	for key, values := range b.options {
+		query[key] = values
+	}
+	if b.authSource != "" {
+		query.Set("authSource", b.authSource)
+	}
+	if b.replicaSet != "" {
+		query.Set("replicaSet", b.replicaSet)
+	}
+	if b.tls != nil {
+		if *b.tls {
+			query.Set("tls", "true")
+		} else {
+			query.Set("tls", "false")
+		}
+	}
+
+	if len(query) > 0 {
+		sb.WriteString("?")
+		sb.WriteString(query.Encode())
+	}
+
+	return sb.String(), nil
+}
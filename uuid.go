@@ -0,0 +1,128 @@
+package mongo_kit
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UUIDs
+//
+// This file provides a codec that stores uuid.UUID values as BSON binary subtype 4,
+// the standard UUID representation shared with other drivers and tools.
+
+var tUUID = reflect.TypeFor[uuid.UUID]()
+
+// RegisterUUIDCodec registers encoders and decoders on registry that store uuid.UUID
+// as BSON binary subtype 4. Decoding also accepts the legacy subtype 3, generic
+// 16-byte binaries and UUID strings.
+//
+// Example:
+//
+//	registry := bson.NewRegistry()
+//	mongo_kit.RegisterUUIDCodec(registry)
+//	mongo_kit.WithBSONRegistry(registry)
+func RegisterUUIDCodec(registry *bsoncodec.Registry) {
+	registry.RegisterTypeEncoder(tUUID, bsoncodec.ValueEncoderFunc(encodeUUID))
+	registry.RegisterTypeDecoder(tUUID, bsoncodec.ValueDecoderFunc(decodeUUID))
+}
+
+// WithUUIDCodec stores uuid.UUID values as BSON binary subtype 4 in all operations,
+// using a new registry with the default codecs and the UUID codec. It cannot be combined
+// with a BSONRegistry: clients with a custom registry call RegisterUUIDCodec on it
+// instead, and New returns a ConfigError when both are set.
+//
+// Example:
+//
+//	client, err := mongo_kit.New(cfg, mongo_kit.WithUUIDCodec())
+//	_, err = repo.Create(ctx, Order{ID: uuid.New()}) // _id stored as UUID("...")
+func WithUUIDCodec() Option {
+	return func(c *Config) {
+		c.uuidRegistry = bson.NewRegistry()
+		RegisterUUIDCodec(c.uuidRegistry)
+		if c.BSONRegistry == nil {
+			c.BSONRegistry = c.uuidRegistry
+		}
+	}
+}
+
+// encodeUUID writes a uuid.UUID as binary subtype 4.
+func encodeUUID(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	if !val.IsValid() || val.Type() != tUUID {
+		return bsoncodec.ValueEncoderError{Name: "UUIDEncodeValue", Types: []reflect.Type{tUUID}, Received: val}
+	}
+	u := val.Interface().(uuid.UUID)
+	return vw.WriteBinaryWithSubtype(u[:], bson.TypeBinaryUUID)
+}
+
+// decodeUUID reads a uuid.UUID from a 16-byte binary, a UUID string or null.
+func decodeUUID(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != tUUID {
+		return bsoncodec.ValueDecoderError{Name: "UUIDDecodeValue", Types: []reflect.Type{tUUID}, Received: val}
+	}
+
+	var u uuid.UUID
+	switch vr.Type() {
+	case bsontype.Binary:
+		data, subtype, err := vr.ReadBinary()
+		if err != nil {
+			return err
+		}
+		if subtype != bson.TypeBinaryUUID && subtype != bson.TypeBinaryUUIDOld && subtype != bson.TypeBinaryGeneric {
+			return fmt.Errorf("cannot decode binary subtype %#x into a UUID", subtype)
+		}
+		if u, err = uuid.FromBytes(data); err != nil {
+			return err
+		}
+	case bsontype.String:
+		s, err := vr.ReadString()
+		if err != nil {
+			return err
+		}
+		if u, err = uuid.Parse(s); err != nil {
+			return err
+		}
+	case bsontype.Null:
+		if err := vr.ReadNull(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot decode %v into a UUID", vr.Type())
+	}
+
+	val.Set(reflect.ValueOf(u))
+	return nil
+}
+
+// uuidFilterValue returns a uuid.UUID, or each UUID of a []uuid.UUID, as binary
+// subtype 4 so builder filters match stored UUIDs without the UUID codec.
+// Other values are returned unchanged.
+func uuidFilterValue(value any) any {
+	switch v := value.(type) {
+	case uuid.UUID:
+		return primitive.Binary{Subtype: bson.TypeBinaryUUID, Data: v[:]}
+	case []uuid.UUID:
+		values := make([]any, len(v))
+		for i, u := range v {
+			values[i] = uuidFilterValue(u)
+		}
+		return values
+	default:
+		return value
+	}
+}
+
+// uuidFilterValues applies uuidFilterValue to each value.
+func uuidFilterValues(values []any) []any {
+	converted := make([]any, len(values))
+	for i, v := range values {
+		converted[i] = uuidFilterValue(v)
+	}
+	return converted
+}
@@ -0,0 +1,181 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type uuidDoc struct {
+	ID uuid.UUID `bson:"_id"`
+}
+
+func TestUUIDCodec(t *testing.T) {
+	registry := bson.NewRegistry()
+	RegisterUUIDCodec(registry)
+	id := uuid.New()
+
+	t.Run("encodes as binary subtype 4", func(t *testing.T) {
+		data, err := bson.MarshalWithRegistry(registry, uuidDoc{ID: id})
+		require.NoError(t, err)
+
+		subtype, bytes := bson.Raw(data).Lookup("_id").Binary()
+		assert.Equal(t, bson.TypeBinaryUUID, subtype)
+		assert.Equal(t, id[:], bytes)
+
+		var out uuidDoc
+		require.NoError(t, bson.UnmarshalWithRegistry(registry, data, &out))
+		assert.Equal(t, id, out.ID)
+	})
+
+	t.Run("decodes legacy representations", func(t *testing.T) {
+		for _, value := range []any{
+			primitive.Binary{Subtype: bson.TypeBinaryUUIDOld, Data: id[:]},
+			primitive.Binary{Subtype: bson.TypeBinaryGeneric, Data: id[:]},
+			id.String(),
+		} {
+			data, err := bson.Marshal(bson.M{"_id": value})
+			require.NoError(t, err)
+
+			var out uuidDoc
+			require.NoError(t, bson.UnmarshalWithRegistry(registry, data, &out))
+			assert.Equal(t, id, out.ID)
+		}
+	})
+
+	t.Run("decodes null as the nil UUID", func(t *testing.T) {
+		data, err := bson.Marshal(bson.M{"_id": nil})
+		require.NoError(t, err)
+
+		out := uuidDoc{ID: id}
+		require.NoError(t, bson.UnmarshalWithRegistry(registry, data, &out))
+		assert.Equal(t, uuid.Nil, out.ID)
+	})
+
+	t.Run("rejects other values", func(t *testing.T) {
+		data, err := bson.Marshal(bson.M{"_id": 42})
+		require.NoError(t, err)
+
+		var out uuidDoc
+		assert.Error(t, bson.UnmarshalWithRegistry(registry, data, &out))
+	})
+}
+
+func TestWithUUIDCodec(t *testing.T) {
+	cfg := withOptions(DefaultConfig(), WithUUIDCodec())
+	require.NotNil(t, cfg.BSONRegistry)
+
+	encoder, err := cfg.BSONRegistry.LookupEncoder(tUUID)
+	require.NoError(t, err)
+	assert.NotNil(t, encoder)
+
+	t.Run("rejects a configured registry", func(t *testing.T) {
+		registry := bson.NewRegistry()
+		for _, opts := range [][]Option{
+			{WithBSONRegistry(registry), WithUUIDCodec()},
+			{WithUUIDCodec(), WithBSONRegistry(registry)},
+		} {
+			cfg := withOptions(DefaultConfig(), opts...)
+			var cfgErr *ConfigError
+			require.ErrorAs(t, cfg.validate(), &cfgErr)
+			assert.Equal(t, "BSONRegistry", cfgErr.Field)
+		}
+	})
+
+	t.Run("RegisterUUIDCodec on a custom registry", func(t *testing.T) {
+		registry := bson.NewRegistry()
+		RegisterUUIDCodec(registry)
+		cfg := withOptions(DefaultConfig(), WithBSONRegistry(registry))
+		require.NoError(t, cfg.validate())
+
+		data, err := bson.MarshalWithRegistry(cfg.BSONRegistry, bson.M{"_id": uuid.New()})
+		require.NoError(t, err)
+		subtype, _ := bson.Raw(data).Lookup("_id").Binary()
+		assert.Equal(t, bson.TypeBinaryUUID, subtype)
+	})
+}
+
+func TestQueryBuilder_UUID(t *testing.T) {
+	id := uuid.New()
+	other := uuid.New()
+	binary := func(u uuid.UUID) primitive.Binary {
+		return primitive.Binary{Subtype: bson.TypeBinaryUUID, Data: u[:]}
+	}
+
+	filter := NewQueryBuilder().
+		Equals("_id", id).
+		NotEquals("parent", other).
+		In("tags", id, "x").
+		Filter("related", []uuid.UUID{id, other}).
+		GetFilter()
+
+	assert.Equal(t, bson.D{
+		{Key: "_id", Value: binary(id)},
+		{Key: "parent", Value: bson.M{"$ne": binary(other)}},
+		{Key: "tags", Value: bson.M{"$in": []any{binary(id), "x"}}},
+		{Key: "related", Value: []any{binary(id), binary(other)}},
+	}, filter)
+}
+
+func TestRepository_ByID_UUID(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	repo := NewRepository[bson.M](client, "orders")
+	id := uuid.New()
+	binary := primitive.Binary{Subtype: bson.TypeBinaryUUID, Data: id[:]}
+	stored := bson.D{{Key: "_id", Value: binary}}
+
+	t.Run("FindByID", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CursorResponse("testdb.orders", stored))
+		found, err := repo.FindByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, binary, (*found)["_id"])
+	})
+
+	t.Run("ExistsByID", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CursorResponse("testdb.orders", stored))
+		exists, err := repo.ExistsByID(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, exists)
+	})
+
+	t.Run("UpdateByID and DeleteByID", func(t *testing.T) {
+		mock.AddResponses(
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+			testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}),
+		)
+		updated, err := repo.UpdateByID(ctx, id, bson.M{"$set": bson.M{"status": "paid"}})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), updated.ModifiedCount)
+
+		deleted, err := repo.DeleteByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted.DeletedCount)
+	})
+
+	t.Run("rejects the nil UUID", func(t *testing.T) {
+		_, err := repo.FindByID(ctx, uuid.Nil)
+		assert.ErrorContains(t, err, "UUID cannot be nil")
+	})
+}
+
+func TestConvertID(t *testing.T) {
+	id := uuid.New()
+	value, err := convertID(id, "find by id")
+	require.NoError(t, err)
+	assert.Equal(t, primitive.Binary{Subtype: bson.TypeBinaryUUID, Data: id[:]}, value)
+
+	_, err = convertID(42, "find by id")
+	assert.ErrorIs(t, err, mongo.ErrInvalidIndexValue)
+}
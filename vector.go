@@ -0,0 +1,204 @@
+package mongo_kit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Vector Search
+//
+// This file provides a repository for documents with embeddings, managing their Atlas
+// Vector Search index and running similarity searches.
+//
+// See docs/repository.md for detailed usage guide and examples.
+
+// VectorSimilarity is the function a vector index compares embeddings with.
+type VectorSimilarity string
+
+const (
+	VectorCosine     VectorSimilarity = "cosine"     // Angle between vectors
+	VectorEuclidean  VectorSimilarity = "euclidean"  // Distance between vector ends
+	VectorDotProduct VectorSimilarity = "dotProduct" // Cosine on normalized vectors, cheaper to compute
+)
+
+// codeIndexAlreadyExists is the server error code for an index that already exists.
+const codeIndexAlreadyExists = 68
+
+// EmbeddingConfig describes the vector field of an EmbeddingStore and its index.
+type EmbeddingConfig struct {
+	Field        string           // Field holding the embedding (default: "embedding")
+	Dimensions   int              // Number of dimensions of the embeddings (required)
+	Similarity   VectorSimilarity // Similarity function (default: VectorCosine)
+	IndexName    string           // Name of the vector index (default: collection + "_vector")
+	FilterFields []string         // Fields SimilaritySearch filters may use (default: none)
+}
+
+// VectorResult is a document returned by SimilaritySearch with its similarity score.
+type VectorResult[T any] struct {
+	Document T       // The matching document
+	Score    float64 // Similarity score, from 0 to 1; higher is more similar
+}
+
+// EmbeddingStore is a Repository for documents with embeddings, searchable by
+// similarity with Atlas Vector Search. Vector Search requires MongoDB Atlas or a
+// local Atlas deployment.
+type EmbeddingStore[T any] struct {
+	*Repository[T]
+
+	config EmbeddingConfig
+	index  lazyIndex
+}
+
+// NewEmbeddingStore creates an EmbeddingStore for the specified collection.
+//
+// Example:
+//
+//	docs := mongo_kit.NewEmbeddingStore[Doc](client, "docs", mongo_kit.EmbeddingConfig{
+//	    Dimensions:   1536,
+//	    FilterFields: []string{"tenant_id"},
+//	})
+func NewEmbeddingStore[T any](client *Client, collection string, config EmbeddingConfig, opts ...RepositoryOption) *EmbeddingStore[T] {
+	if config.Field == "" {
+		config.Field = "embedding"
+	}
+	if config.Similarity == "" {
+		config.Similarity = VectorCosine
+	}
+	if config.IndexName == "" {
+		config.IndexName = collection + "_vector"
+	}
+	return &EmbeddingStore[T]{
+		Repository: NewRepository[T](client, collection, opts...),
+		config:     config,
+	}
+}
+
+// IndexDefinition returns the Atlas Vector Search index definition of the store, e.g.
+// for infrastructure-as-code tools that manage indexes outside the application.
+func (s *EmbeddingStore[T]) IndexDefinition() bson.D {
+	fields := bson.A{bson.D{
+		{Key: "type", Value: "vector"},
+		{Key: "path", Value: s.config.Field},
+		{Key: "numDimensions", Value: s.config.Dimensions},
+		{Key: "similarity", Value: string(s.config.Similarity)},
+	}}
+	for _, field := range s.config.FilterFields {
+		fields = append(fields, bson.D{{Key: "type", Value: "filter"}, {Key: "path", Value: field}})
+	}
+	return bson.D{{Key: "fields", Value: fields}}
+}
+
+// EnsureIndex creates the vector index of the store unless it already exists. Atlas
+// builds the index asynchronously, so searches may return no results for a short while.
+// An existing index with another definition is left unchanged.
+func (s *EmbeddingStore[T]) EnsureIndex(ctx context.Context) error {
+	if s.config.Dimensions <= 0 {
+		return newOperationError("ensure vector index", errors.New("Dimensions must be positive"))
+	}
+
+	return s.index.create(func() error {
+		_, err := s.client.CreateSearchIndex(ctx, s.collection, mongo.SearchIndexModel{
+			Definition: s.IndexDefinition(),
+			Options:    options.SearchIndexes().SetName(s.config.IndexName).SetType("vectorSearch"),
+		})
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && cmdErr.Code == codeIndexAlreadyExists {
+			return nil
+		}
+		return err
+	})
+}
+
+// UpsertEmbedding sets the embedding of the document with id, creating a document with
+// only the _id and the embedding when none exists.
+//
+// Example:
+//
+//	vector, err := embedder.Embed(ctx, doc.Text)
+//	err = store.UpsertEmbedding(ctx, doc.ID, vector)
+func (s *EmbeddingStore[T]) UpsertEmbedding(ctx context.Context, id any, vector []float64) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if err := s.checkVector(vector); err != nil {
+		return newOperationError("upsert embedding", err)
+	}
+	_, err := s.client.updateOne(ctx, s.collection,
+		bson.D{{Key: "_id", Value: id}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: s.config.Field, Value: vector}}}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// SimilaritySearch returns the k documents whose embeddings are most similar to vector,
+// most similar first, with their scores. filter restricts the candidates and may only
+// use the configured FilterFields; pass nil for no filter. The vector index is created
+// on the first search if needed.
+//
+// Example:
+//
+//	results, err := store.SimilaritySearch(ctx, queryVector, 5, bson.M{"tenant_id": tenantID})
+//	for _, r := range results {
+//	    fmt.Printf("%.3f %s\n", r.Score, r.Document.Title)
+//	}
+func (s *EmbeddingStore[T]) SimilaritySearch(ctx context.Context, vector []float64, k int, filter any) ([]VectorResult[T], error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	if err := s.checkVector(vector); err != nil {
+		return nil, newOperationError("similarity search", err)
+	}
+	if k <= 0 {
+		return nil, newOperationError("similarity search", errors.New("k must be positive"))
+	}
+	if err := s.EnsureIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	stage := bson.D{
+		{Key: "index", Value: s.config.IndexName},
+		{Key: "path", Value: s.config.Field},
+		{Key: "queryVector", Value: vector},
+		{Key: "numCandidates", Value: min(k*10, 10000)},
+		{Key: "limit", Value: k},
+	}
+	if filter != nil {
+		stage = append(stage, bson.E{Key: "filter", Value: filter})
+	}
+	pipeline := []bson.D{
+		{{Key: "$vectorSearch", Value: stage}},
+		{{Key: "$addFields", Value: bson.D{{Key: scoreField, Value: bson.D{{Key: "$meta", Value: "vectorSearchScore"}}}}}},
+	}
+
+	var docs []bson.Raw
+	if err := s.client.aggregate(ctx, s.collection, pipeline, &docs); err != nil {
+		return nil, err
+	}
+
+	documents, scores, err := s.decodeScored(ctx, "similarity search", docs, scoreField)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]VectorResult[T], len(docs))
+	for i := range docs {
+		results[i] = VectorResult[T]{Document: documents[i], Score: scores[i]}
+	}
+	return results, nil
+}
+
+// checkVector checks that vector has the configured number of dimensions.
+func (s *EmbeddingStore[T]) checkVector(vector []float64) error {
+	if s.config.Dimensions <= 0 {
+		return errors.New("Dimensions must be positive")
+	}
+	if len(vector) != s.config.Dimensions {
+		return fmt.Errorf("vector has %d dimensions, expected %d", len(vector), s.config.Dimensions)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+// Vector Search needs an Atlas deployment, so only embedding writes are tested here;
+// SimilaritySearch is covered by the mock-based tests.
+func TestEmbeddingStore_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	store := NewEmbeddingStore[embeddedDoc](client, "docs", EmbeddingConfig{Dimensions: 3})
+
+	_, err = store.Create(ctx, embeddedDoc{ID: "a", Title: "first"})
+	require.NoError(t, err)
+	require.NoError(t, store.UpsertEmbedding(ctx, "a", []float64{0.1, 0.2, 0.3}))
+	require.NoError(t, store.UpsertEmbedding(ctx, "b", []float64{0.4, 0.5, 0.6}))
+
+	doc, err := store.FindByID(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "first", doc.Title)
+	assert.Equal(t, []float64{0.1, 0.2, 0.3}, doc.Embedding)
+
+	doc, err = store.FindByID(ctx, "b")
+	require.NoError(t, err)
+	assert.Empty(t, doc.Title)
+	assert.Equal(t, []float64{0.4, 0.5, 0.6}, doc.Embedding)
+}
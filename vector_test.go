@@ -0,0 +1,83 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+type embeddedDoc struct {
+	ID        string    `bson:"_id"`
+	Title     string    `bson:"title"`
+	Embedding []float64 `bson:"embedding"`
+}
+
+func TestEmbeddingStore_IndexDefinition(t *testing.T) {
+	store := NewEmbeddingStore[embeddedDoc](&Client{}, "docs", EmbeddingConfig{
+		Dimensions:   3,
+		FilterFields: []string{"tenant_id"},
+	})
+
+	expected := bson.D{{Key: "fields", Value: bson.A{
+		bson.D{
+			{Key: "type", Value: "vector"},
+			{Key: "path", Value: "embedding"},
+			{Key: "numDimensions", Value: 3},
+			{Key: "similarity", Value: "cosine"},
+		},
+		bson.D{{Key: "type", Value: "filter"}, {Key: "path", Value: "tenant_id"}},
+	}}}
+	assert.Equal(t, expected, store.IndexDefinition())
+	assert.Equal(t, "docs_vector", store.config.IndexName)
+}
+
+func TestEmbeddingStore(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	store := NewEmbeddingStore[embeddedDoc](client, "docs", EmbeddingConfig{Dimensions: 3})
+
+	mock.AddResponses(testhelpers.SuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+	require.NoError(t, store.UpsertEmbedding(ctx, "a", []float64{0.1, 0.2, 0.3}))
+
+	mock.AddResponses(
+		testhelpers.SuccessResponse(bson.E{Key: "indexesCreated", Value: bson.A{
+			bson.D{{Key: "id", Value: "1"}, {Key: "name", Value: "docs_vector"}},
+		}}),
+		testhelpers.CursorResponse("testdb.docs",
+			bson.D{{Key: "_id", Value: "a"}, {Key: "title", Value: "first"}, {Key: scoreField, Value: 0.98}},
+			bson.D{{Key: "_id", Value: "b"}, {Key: "title", Value: "second"}, {Key: scoreField, Value: 0.71}},
+		),
+	)
+	results, err := store.SimilaritySearch(ctx, []float64{0.1, 0.2, 0.3}, 2, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "first", results[0].Document.Title)
+	assert.Equal(t, 0.98, results[0].Score)
+
+	// An index created elsewhere is not an error.
+	other := NewEmbeddingStore[embeddedDoc](client, "docs", EmbeddingConfig{Dimensions: 3})
+	mock.AddResponses(testhelpers.CommandErrorResponse(68, "IndexAlreadyExists", "Duplicate Index"))
+	assert.NoError(t, other.EnsureIndex(ctx))
+}
+
+func TestEmbeddingStore_InvalidArguments(t *testing.T) {
+	store := NewEmbeddingStore[embeddedDoc](&Client{}, "docs", EmbeddingConfig{Dimensions: 3})
+	ctx := context.Background()
+
+	assert.Error(t, store.UpsertEmbedding(ctx, "a", []float64{1, 2}))
+	_, err := store.SimilaritySearch(ctx, []float64{1, 2, 3}, 0, nil)
+	assert.Error(t, err)
+	_, err = store.SimilaritySearch(ctx, []float64{1}, 5, nil)
+	assert.Error(t, err)
+
+	noDimensions := NewEmbeddingStore[embeddedDoc](&Client{}, "docs", EmbeddingConfig{})
+	assert.Error(t, noDimensions.EnsureIndex(ctx))
+}
@@ -0,0 +1,130 @@
+package mongo_kit
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Cache Warming
+//
+// This file provides Warm and WarmIndexes, which run the hot queries of a repository
+// and scan its indexes ahead of traffic, so the first requests after a deploy or a
+// restart are served from warm caches.
+//
+// See docs/repository.md for detailed usage guide and examples.
+
+// Warm runs the given queries so their results are cached: in the QueryCache of the
+// client for repositories created WithQueryCaching, and in the server cache otherwise.
+// Filters are run with Find, and QueryBuilders with FindWithBuilder, so the same calls
+// made later are served from the query cache. Queries run concurrently, at most
+// DefaultParallelism at a time; every query runs and their errors are joined.
+//
+// Example:
+//
+//	// After startup, before accepting traffic
+//	err := settings.Warm(ctx,
+//	    bson.M{"scope": "global"},
+//	    mongo_kit.NewQueryBuilder().Equals("scope", "checkout").Sort("key", true),
+//	)
+func (r *Repository[T]) Warm(ctx context.Context, filters ...any) error {
+	fns := make([]func(ctx context.Context) error, len(filters))
+	for i, filter := range filters {
+		fns[i] = func(ctx context.Context) error {
+			var err error
+			if qb, ok := filter.(*QueryBuilder); ok {
+				_, err = r.FindWithBuilder(ctx, qb)
+			} else {
+				_, err = r.Find(ctx, filter)
+			}
+			return err
+		}
+	}
+	return Parallel(ctx, fns...)
+}
+
+// WarmIndexes scans every regular index of the collection so it is loaded into the
+// server cache. Text, geospatial, hashed, wildcard and partial indexes are skipped.
+// The scans read whole indexes, so run it before traffic rather than under load.
+//
+// Example:
+//
+//	if err := orders.WarmIndexes(ctx); err != nil {
+//	    log.Printf("index warm-up failed: %v", err)
+//	}
+func (r *Repository[T]) WarmIndexes(ctx context.Context) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	indexes, err := r.client.listIndexes(ctx, r.collection)
+	if err != nil {
+		return err
+	}
+
+	var fns []func(ctx context.Context) error
+	for _, index := range indexes {
+		name, ok := index.Lookup("name").StringValueOK()
+		if !ok || !isScannableIndex(index) {
+			continue
+		}
+		fns = append(fns, func(ctx context.Context) error {
+			_, err := r.client.countDocuments(ctx, r.collection, bson.D{}, options.Count().SetHint(name))
+			return err
+		})
+	}
+	return Parallel(ctx, fns...)
+}
+
+// isScannableIndex reports whether index, as listed by listIndexes, is a regular,
+// non-partial index, which a hinted count of every document scans completely.
+func isScannableIndex(index bson.Raw) bool {
+	if _, err := index.LookupErr("partialFilterExpression"); err == nil {
+		return false
+	}
+	keyDoc, ok := index.Lookup("key").DocumentOK()
+	if !ok {
+		return false
+	}
+	keys, err := keyDoc.Elements()
+	if err != nil || len(keys) == 0 {
+		return false
+	}
+	for _, key := range keys {
+		if _, ok := key.Value().AsInt64OK(); !ok {
+			return false // "text", "2dsphere", "hashed", ...
+		}
+		if key.Key() == "$**" || strings.HasSuffix(key.Key(), ".$**") {
+			return false
+		}
+	}
+	return true
+}
+
+// listIndexes returns the index documents of collection, as listed by listIndexes.
+func (c *Client) listIndexes(ctx context.Context, collection string) ([]bson.Raw, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if err := c.checkState(); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	ctx, _, cancel := budgeted(ctx)
+	defer cancel()
+	cursor, err := c.getCollection(collection).Indexes().List(ctx)
+	if err != nil {
+		return nil, c.collectionError(ctx, start, "list indexes", collection, err, nil, nil)
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	var indexes []bson.Raw
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return nil, c.collectionError(ctx, start, "list indexes", collection, err, nil, nil)
+	}
+
+	return indexes, nil
+}
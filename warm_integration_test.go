@@ -0,0 +1,77 @@
+package mongo_kit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestRepository_Warm_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	container := testhelpers.Shared(t)
+	database := testhelpers.IsolatedDatabase(t)
+
+	var mu sync.Mutex
+	var hints []string
+	finds := 0
+	monitor := &event.CommandMonitor{Started: func(_ context.Context, e *event.CommandStartedEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch e.CommandName {
+		case "find":
+			finds++
+		case "aggregate":
+			if hint, ok := e.Command.Lookup("hint").StringValueOK(); ok {
+				hints = append(hints, hint)
+			}
+		}
+	}}
+
+	client, err := New(DefaultConfig(), WithURI(container.URI), WithDatabase(database),
+		WithQueryCache(NewMemoryQueryCache()), WithClientOptions(options.Client().SetMonitor(monitor)))
+	require.NoError(t, err)
+	defer func() { _ = client.Close(context.Background()) }()
+
+	ctx := context.Background()
+	repo := NewRepository[bson.M](client, "settings", WithQueryCaching(time.Minute))
+	_, err = repo.CreateMany(ctx, []bson.M{{"scope": "global", "key": "theme"}, {"scope": "checkout", "key": "currency"}})
+	require.NoError(t, err)
+	_, err = client.CreateIndexes(ctx, "settings", []mongo.IndexModel{
+		{Keys: bson.D{{Key: "scope", Value: 1}, {Key: "key", Value: 1}}},
+		{Keys: bson.D{{Key: "key", Value: "text"}}},
+	})
+	require.NoError(t, err)
+
+	t.Run("Warm caches the queries", func(t *testing.T) {
+		require.NoError(t, repo.Warm(ctx, bson.M{"scope": "global"}, NewQueryBuilder().Equals("scope", "checkout")))
+
+		results, err := repo.Find(ctx, bson.M{"scope": "global"})
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, 2, finds, "the find after Warm is served from the cache")
+	})
+
+	t.Run("WarmIndexes scans the regular indexes", func(t *testing.T) {
+		require.NoError(t, repo.WarmIndexes(ctx))
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.ElementsMatch(t, []string{"_id_", "scope_1_key_1"}, hints)
+	})
+}
@@ -0,0 +1,86 @@
+package mongo_kit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+
+	testhelpers "github.com/edaniel30/mongo-kit-go/testing"
+)
+
+func TestRepository_Warm(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"), WithQueryCache(NewMemoryQueryCache()))
+	require.NoError(t, err)
+	repo := NewRepository[bson.M](client, "settings", WithQueryCaching(time.Minute))
+	ctx := context.Background()
+
+	t.Run("caches the results of the queries", func(t *testing.T) {
+		response := testhelpers.CursorResponse("testdb.settings", bson.D{{Key: "key", Value: "theme"}})
+		mock.AddResponses(response, response)
+		qb := NewQueryBuilder().Equals("scope", "checkout").Sort("key", true)
+		require.NoError(t, repo.Warm(ctx, bson.M{"scope": "global"}, qb))
+
+		// Served from the cache: the mock has no response left
+		results, err := repo.Find(ctx, bson.M{"scope": "global"})
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+		results, err = repo.FindWithBuilder(ctx, qb)
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("returns query errors", func(t *testing.T) {
+		mock.AddResponses(testhelpers.CommandErrorResponse(2, "BadValue", "unknown operator"))
+		err := repo.Warm(ctx, bson.M{"scope": bson.M{"$bad": 1}})
+		var opErr *OperationError
+		require.ErrorAs(t, err, &opErr)
+		assert.Equal(t, "find", opErr.Op)
+	})
+
+	t.Run("does nothing without queries", func(t *testing.T) {
+		assert.NoError(t, repo.Warm(ctx))
+	})
+}
+
+func TestRepository_WarmIndexes(t *testing.T) {
+	mock := testhelpers.NewMockClient(t)
+	client, err := NewFromClient(mock.Client, DefaultConfig(), WithDatabase("testdb"))
+	require.NoError(t, err)
+	repo := NewRepository[bson.M](client, "orders")
+
+	mock.AddResponses(testhelpers.CursorResponse("testdb.orders",
+		bson.D{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}, {Key: "name", Value: "_id_"}},
+		bson.D{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}}, {Key: "name", Value: "status_1_created_at_-1"}},
+		bson.D{{Key: "v", Value: 2}, {Key: "key", Value: bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: 1}}}, {Key: "name", Value: "title_text"}},
+	))
+	count := testhelpers.CursorResponse("testdb.orders", bson.D{{Key: "n", Value: 10}})
+	mock.AddResponses(count, count)
+
+	require.NoError(t, repo.WarmIndexes(context.Background()))
+}
+
+func TestIsScannableIndex(t *testing.T) {
+	index := func(elems ...bson.E) bson.Raw {
+		raw, err := bson.Marshal(append(bson.D{{Key: "name", Value: "idx"}}, elems...))
+		require.NoError(t, err)
+		return raw
+	}
+	key := func(keys bson.D) bson.E { return bson.E{Key: "key", Value: keys} }
+
+	assert.True(t, isScannableIndex(index(key(bson.D{{Key: "_id", Value: 1}}))))
+	assert.True(t, isScannableIndex(index(key(bson.D{{Key: "a", Value: 1}, {Key: "b", Value: -1.0}}))))
+	assert.True(t, isScannableIndex(index(key(bson.D{{Key: "a", Value: 1}}), bson.E{Key: "sparse", Value: true})))
+
+	assert.False(t, isScannableIndex(index(key(bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: 1}}))))
+	assert.False(t, isScannableIndex(index(key(bson.D{{Key: "location", Value: "2dsphere"}}))))
+	assert.False(t, isScannableIndex(index(key(bson.D{{Key: "a", Value: "hashed"}}))))
+	assert.False(t, isScannableIndex(index(key(bson.D{{Key: "$**", Value: 1}}))))
+	assert.False(t, isScannableIndex(index(key(bson.D{{Key: "attrs.$**", Value: 1}}))))
+	assert.False(t, isScannableIndex(index(key(bson.D{{Key: "a", Value: 1}}), bson.E{Key: "partialFilterExpression", Value: bson.D{{Key: "a", Value: bson.D{{Key: "$gt", Value: 0}}}}})))
+	assert.False(t, isScannableIndex(index()))
+}